@@ -16,9 +16,17 @@ import (
 	"github.com/rwa-platform/channel-service/internal/kafka"
 	"github.com/rwa-platform/channel-service/internal/redis"
 	"github.com/rwa-platform/channel-service/internal/services"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
+	kafkago "github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
+// consumerGroup Kafka消费组
+const consumerGroup = "channel-service-group"
+
 func main() {
 	// 初始化配置
 	cfg, err := config.Load()
@@ -31,11 +39,30 @@ func main() {
 
 	logrus.Info("Starting RWA Channel Service...")
 
+	// 初始化链路追踪，未启用时各接入点只传递trace上下文
+	if cfg.TracingEnabled {
+		shutdown, err := tracing.Setup(context.Background(), "channel-service", cfg.TracingEndpoint)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logrus.Errorf("Failed to flush traces: %v", err)
+			}
+		}()
+		logrus.Infof("Tracing enabled, exporting to %s", cfg.TracingEndpoint)
+	}
+
 	// 初始化数据库
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logrus.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("Failed to register database tracing: %v", err)
+	}
 
 	// 初始化Redis
 	redisClient, err := redis.NewClient(cfg.RedisURL)
@@ -50,26 +77,66 @@ func main() {
 	}
 	defer kafkaProducer.Close()
 
+	kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, consumerGroup)
+	if err != nil {
+		logrus.Fatalf("Failed to create Kafka consumer: %v", err)
+	}
+	defer kafkaConsumer.Close()
+
 	// 初始化服务
 	channelService := services.NewChannelService(db, redisClient, kafkaProducer, cfg)
 	matchingService := services.NewMatchingService(db, redisClient, kafkaProducer, cfg)
 	attributionService := services.NewAttributionService(db, redisClient, kafkaProducer, cfg)
 
+	// 注册定时任务，任务定义和执行记录保存在数据库中
+	if err := scheduler.Migrate(db); err != nil {
+		logrus.Fatalf("Failed to migrate scheduler tables: %v", err)
+	}
+	jobScheduler := scheduler.New(db, "channel-service")
+	for _, register := range []func(*scheduler.Scheduler) error{
+		channelService.RegisterJobs,
+		matchingService.RegisterJobs,
+		attributionService.RegisterJobs,
+	} {
+		if err := register(jobScheduler); err != nil {
+			logrus.Fatalf("Failed to register scheduled jobs: %v", err)
+		}
+	}
+
 	// 启动后台服务
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 启动渠道数据同步
-	go channelService.StartChannelSync(ctx)
-	
-	// 启动撮合引擎
-	go matchingService.StartMatchingEngine(ctx)
+	// Prometheus指标在单独的端口上暴露
+	if cfg.MetricsEnabled {
+		go func() {
+			logrus.Infof("Metrics server starting on port %d", cfg.MetricsPort)
+			if err := metrics.Serve(ctx, cfg.MetricsPort); err != nil {
+				logrus.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+		go metrics.WatchConsumerLag(ctx, cfg.KafkaBrokers, consumerGroup, []string{"regulatory-events"}, 30*time.Second)
+	}
+
+	// 启动定时任务（渠道数据同步、撮合队列处理、归因统计）
+	go jobScheduler.Start(ctx)
 	
-	// 启动归因统计
+	// 启动归因事件处理
 	go attributionService.StartAttributionTracking(ctx)
 
+	// 消费监管事件
+	go startKafkaConsumers(ctx, kafkaConsumer, channelService)
+
 	// 初始化HTTP服务器
-	router := setupRouter(channelService, matchingService, attributionService)
+	statsSections := []stats.Section{
+		matchingService.FillStats,
+		attributionService.FillStats,
+		jobScheduler.FillStats,
+		stats.CacheSection,
+		stats.RedisSection(redisClient),
+		stats.TableSection(db, "channels", "attribution_events", "conversion_events", "attribution_stats"),
+	}
+	router := setupRouter(channelService, matchingService, attributionService, statsSections)
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
@@ -102,6 +169,19 @@ func main() {
 	logrus.Info("Server exited")
 }
 
+func startKafkaConsumers(ctx context.Context, consumer *kafka.Consumer, channelService *services.ChannelService) {
+	go func() {
+		if err := consumer.Subscribe("regulatory-events", metrics.ConsumeHandler(consumerGroup, func(ctx context.Context, message kafkago.Message) error {
+			return channelService.HandleRegulatoryEvent(message.Value)
+		})); err != nil {
+			logrus.Errorf("Failed to subscribe to topic regulatory-events: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	logrus.Info("Kafka consumers stopped")
+}
+
 func setupLogger(level string) {
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 	
@@ -123,6 +203,7 @@ func setupRouter(
 	channelService *services.ChannelService,
 	matchingService *services.MatchingService,
 	attributionService *services.AttributionService,
+	statsSections []stats.Section,
 ) *gin.Engine {
 	if gin.Mode() == gin.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
@@ -131,6 +212,8 @@ func setupRouter(
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware("channel-service"))
+	router.Use(metrics.Middleware())
 
 	// 健康检查
 	router.GET("/health", handlers.HealthCheck)
@@ -170,7 +253,7 @@ func setupRouter(
 		// 管理接口
 		admin := v1.Group("/admin")
 		{
-			admin.GET("/stats", handlers.GetSystemStats(channelService, matchingService, attributionService))
+			admin.GET("/stats", handlers.GetSystemStats(statsSections...))
 			admin.POST("/sync/all", handlers.SyncAllChannels(channelService))
 			admin.GET("/health/detailed", handlers.DetailedHealthCheck(channelService))
 		}
@@ -135,5 +135,5 @@ func setDefaults() {
 	viper.SetDefault("METRICS_ENABLED", true)
 	viper.SetDefault("METRICS_PORT", 9003)
 	viper.SetDefault("TRACING_ENABLED", false)
-	viper.SetDefault("TRACING_ENDPOINT", "http://localhost:14268/api/traces")
+	viper.SetDefault("TRACING_ENDPOINT", "http://localhost:4318")
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/rwa-platform/channel-service/internal/services"
+	"github.com/rwa-platform/shared/stats"
 )
 
 // HealthCheck 健康检查
@@ -350,25 +351,20 @@ func GetConversions(attributionService *services.AttributionService) gin.Handler
 	}
 }
 
-// GetSystemStats 获取系统统计
-func GetSystemStats(
-	channelService *services.ChannelService,
-	matchingService *services.MatchingService,
-	attributionService *services.AttributionService,
-) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// TODO: 实现系统统计逻辑
-		c.JSON(http.StatusOK, gin.H{
-			"message": "System stats functionality not implemented yet",
-		})
-	}
+// GetSystemStats 获取系统统计：队列深度、归因事件入库量、定时任务最近成功时间、缓存命中率和表记录数
+func GetSystemStats(sections ...stats.Section) gin.HandlerFunc {
+	return stats.Handler("channel-service", sections...)
 }
 
 // SyncAllChannels 同步所有渠道
 func SyncAllChannels(channelService *services.ChannelService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// TODO: 实现所有渠道同步
-		c.JSON(http.StatusOK, gin.H{
+		if err := channelService.TriggerSync(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
 			"message": "All channels sync initiated",
 		})
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/rwa-platform/channel-service/internal/config"
 	"github.com/rwa-platform/channel-service/internal/kafka"
 	"github.com/rwa-platform/channel-service/internal/models"
+	"github.com/rwa-platform/shared/scheduler"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -84,9 +85,6 @@ func (s *AttributionService) StartAttributionTracking(ctx context.Context) {
 	// 启动转化事件处理器
 	go s.processConversionEvents(ctx)
 	
-	// 启动统计计算器
-	go s.calculateAttributionStats(ctx)
-	
 	<-ctx.Done()
 	s.logger.Info("Attribution tracking service stopped")
 }
@@ -161,18 +159,18 @@ func (s *AttributionService) processConversionEvents(ctx context.Context) {
 	}
 }
 
-func (s *AttributionService) calculateAttributionStats(ctx context.Context) {
-	ticker := time.NewTicker(1 * time.Hour) // 每小时计算一次统计数据
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			s.updateAttributionStats()
-		}
-	}
+// attributionStatsJob 归因统计的调度任务名
+const attributionStatsJob = "attribution-stats"
+
+// RegisterJobs 注册归因统计任务，每小时计算一次当天的渠道统计
+func (s *AttributionService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	return jobs.Register(scheduler.Spec{
+		Name:     attributionStatsJob,
+		Schedule: scheduler.Every(time.Hour),
+		Timeout:  15 * time.Minute,
+		Missed:   scheduler.MissedRunOnce,
+		Run:      s.updateAttributionStats,
+	})
 }
 
 func (s *AttributionService) TrackEvent(event *AttributionEvent) error {
@@ -370,21 +368,24 @@ func (s *AttributionService) updateChannelStats(conversion *ConversionEvent) {
 	}
 }
 
-func (s *AttributionService) updateAttributionStats() {
+func (s *AttributionService) updateAttributionStats(ctx context.Context) error {
 	s.logger.Debug("Updating attribution statistics")
 	
 	// 获取所有活跃渠道
 	var channels []models.Channel
-	if err := s.db.Where("status = ? AND is_active = ?", "active", true).Find(&channels).Error; err != nil {
-		s.logger.Errorf("Failed to fetch channels for stats: %v", err)
-		return
+	if err := s.db.WithContext(ctx).Where("status = ? AND is_active = ?", "active", true).Find(&channels).Error; err != nil {
+		return fmt.Errorf("failed to fetch channels for stats: %v", err)
 	}
 
 	// 计算每个渠道的统计数据
 	for _, channel := range channels {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		stats := s.calculateChannelStats(channel.ID)
 		s.saveAttributionStats(stats)
 	}
+	return nil
 }
 
 func (s *AttributionService) calculateChannelStats(channelID string) *AttributionStats {
@@ -11,16 +11,22 @@ import (
 	"github.com/rwa-platform/channel-service/internal/config"
 	"github.com/rwa-platform/channel-service/internal/kafka"
 	"github.com/rwa-platform/channel-service/internal/models"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// channelCache 渠道详情缓存的命中计数
+var channelCache = stats.NewCacheCounter("channel")
+
 type ChannelService struct {
 	db     *gorm.DB
 	redis  *redis.Client
 	kafka  *kafka.Producer
 	config *config.Config
 	logger *logrus.Logger
+	jobs   *scheduler.Scheduler
 }
 
 type ChannelSyncResult struct {
@@ -40,39 +46,44 @@ func NewChannelService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *ka
 	}
 }
 
-func (s *ChannelService) StartChannelSync(ctx context.Context) {
-	s.logger.Info("Starting channel synchronization service")
-	
-	ticker := time.NewTicker(time.Duration(s.config.ChannelSyncInterval) * time.Second)
-	defer ticker.Stop()
-
-	// 立即执行一次
-	s.syncAllChannels(ctx)
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Channel sync service stopped")
-			return
-		case <-ticker.C:
-			s.syncAllChannels(ctx)
-		}
+// channelSyncJob 渠道同步的调度任务名
+const channelSyncJob = "channel-sync"
+
+// RegisterJobs 注册渠道同步任务，按CHANNEL_SYNC_INTERVAL固定间隔执行
+func (s *ChannelService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	s.jobs = jobs
+
+	interval := time.Duration(s.config.ChannelSyncInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
 	}
+	return jobs.Register(scheduler.Spec{
+		Name:     channelSyncJob,
+		Schedule: scheduler.Every(interval),
+		Jitter:   30 * time.Second,
+		Timeout:  15 * time.Minute,
+		Missed:   scheduler.MissedRunOnce,
+		Run:      s.syncAllChannels,
+	})
+}
+
+// TriggerSync 手动触发渠道同步
+func (s *ChannelService) TriggerSync() error {
+	return s.jobs.Trigger(context.Background(), channelSyncJob)
 }
 
-func (s *ChannelService) syncAllChannels(ctx context.Context) {
+func (s *ChannelService) syncAllChannels(ctx context.Context) error {
 	s.logger.Info("Starting channel synchronization cycle")
 
 	// 获取所有活跃渠道
 	var channels []models.Channel
 	if err := s.db.Where("status = ? AND is_active = ?", "active", true).Find(&channels).Error; err != nil {
-		s.logger.Errorf("Failed to fetch channels: %v", err)
-		return
+		return fmt.Errorf("failed to fetch channels: %v", err)
 	}
 
 	if len(channels) == 0 {
 		s.logger.Warn("No active channels found for synchronization")
-		return
+		return nil
 	}
 
 	// 并发同步渠道
@@ -114,6 +125,12 @@ func (s *ChannelService) syncAllChannels(ctx context.Context) {
 
 	// 发布同步完成事件
 	s.publishSyncEvent(successCount, errorCount)
+
+	// 部分渠道失败时记为失败的执行，便于在任务执行记录中发现
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d channel syncs failed", errorCount, len(channels))
+	}
+	return nil
 }
 
 func (s *ChannelService) syncChannel(ctx context.Context, channel models.Channel) ChannelSyncResult {
@@ -365,9 +382,11 @@ func (s *ChannelService) GetChannelByID(id string) (*models.Channel, error) {
 	if err == nil {
 		var channel models.Channel
 		if err := json.Unmarshal([]byte(cached), &channel); err == nil {
+			channelCache.Hit()
 			return &channel, nil
 		}
 	}
+	channelCache.Miss()
 
 	// 从数据库获取
 	var channel models.Channel
@@ -425,3 +444,51 @@ func (s *ChannelService) publishChannelEvent(eventType string, channel *models.C
 		s.logger.Errorf("Failed to publish channel event: %v", err)
 	}
 }
+
+// regulatoryReviewTypes 渠道被吊销牌照或受到制裁时暂停撮合，等待人工复核
+var regulatoryReviewTypes = map[string]bool{
+	"license_revocation": true,
+	"sanctions":          true,
+}
+
+// HandleRegulatoryEvent 处理数据采集服务发布的监管事件
+//
+// 高或严重级别的牌照吊销、制裁事件将关联渠道的状态改为under_review，撮合和归因只使用active渠道。
+// 恢复须由运营人员确认后通过UpdateChannel完成
+func (s *ChannelService) HandleRegulatoryEvent(message []byte) error {
+	var event struct {
+		Type       string   `json:"type"`
+		ID         string   `json:"id"`
+		EventType  string   `json:"event_type"`
+		Severity   string   `json:"severity"`
+		Title      string   `json:"title"`
+		URL        string   `json:"url"`
+		ChannelIDs []string `json:"channel_ids"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		return fmt.Errorf("failed to decode regulatory event: %v", err)
+	}
+	if event.Type != "regulatory_event" || !regulatoryReviewTypes[event.EventType] {
+		return nil
+	}
+	if event.Severity != "high" && event.Severity != "critical" {
+		return nil
+	}
+
+	for _, channelID := range event.ChannelIDs {
+		channel, err := s.GetChannelByID(channelID)
+		if err != nil {
+			s.logger.Warnf("Regulatory event %s references unknown channel %s: %v", event.ID, channelID, err)
+			continue
+		}
+		if channel.Status != "active" {
+			continue
+		}
+
+		if err := s.UpdateChannel(channelID, map[string]interface{}{"status": "under_review"}); err != nil {
+			return fmt.Errorf("failed to suspend channel %s: %v", channelID, err)
+		}
+		s.logger.Warnf("Channel %s put under review after %s %s event: %s (%s)", channelID, event.Severity, event.EventType, event.Title, event.URL)
+	}
+	return nil
+}
@@ -13,10 +13,16 @@ import (
 	"github.com/rwa-platform/channel-service/internal/config"
 	"github.com/rwa-platform/channel-service/internal/kafka"
 	"github.com/rwa-platform/channel-service/internal/models"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// eligibleChannelsCache 可用渠道缓存的命中计数
+var eligibleChannelsCache = stats.NewCacheCounter("eligible_channels")
+
 type MatchingService struct {
 	db     *gorm.DB
 	redis  *redis.Client
@@ -81,24 +87,26 @@ func NewMatchingService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *k
 	}
 }
 
-func (s *MatchingService) StartMatchingEngine(ctx context.Context) {
-	s.logger.Info("Starting matching engine")
-	
-	ticker := time.NewTicker(time.Duration(s.config.MatchingInterval) * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Matching engine stopped")
-			return
-		case <-ticker.C:
-			s.processMatchingQueue(ctx)
-		}
-	}
+// matchingJob 处理撮合队列的调度任务名
+const matchingJob = "channel-matching"
+
+// RegisterJobs 注册撮合队列处理任务，按MATCHING_INTERVAL固定间隔执行，每次处理到队列为空
+func (s *MatchingService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	interval := time.Duration(s.config.MatchingInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return jobs.Register(scheduler.Spec{
+		Name:     matchingJob,
+		Schedule: scheduler.Every(interval),
+		Timeout:  5 * time.Minute,
+		// 请求保留在队列中，下一次执行时处理，错过的执行不需要补
+		Missed: scheduler.MissedSkip,
+		Run:    s.processMatchingQueue,
+	})
 }
 
-func (s *MatchingService) processMatchingQueue(ctx context.Context) {
+func (s *MatchingService) processMatchingQueue(ctx context.Context) error {
 	// 处理待撮合的请求队列
 	queueKey := "matching:queue"
 	
@@ -107,10 +115,9 @@ func (s *MatchingService) processMatchingQueue(ctx context.Context) {
 		result, err := s.redis.BLPop(ctx, time.Second, queueKey).Result()
 		if err != nil {
 			if err == redis.Nil {
-				break // 队列为空
+				return nil // 队列为空
 			}
-			s.logger.Errorf("Failed to pop from matching queue: %v", err)
-			break
+			return fmt.Errorf("failed to pop from matching queue: %v", err)
 		}
 
 		if len(result) < 2 {
@@ -136,7 +143,8 @@ func (s *MatchingService) processMatchingQueue(ctx context.Context) {
 	}
 }
 
-func (s *MatchingService) MatchChannels(request *MatchingRequest) ([]*MatchingResult, error) {
+func (s *MatchingService) MatchChannels(request *MatchingRequest) (results []*MatchingResult, err error) {
+	defer func(started time.Time) { metrics.ObserveMatching(started, err) }(time.Now())
 	s.logger.Debugf("Matching channels for asset %s, amount %f", request.AssetID, request.Amount)
 
 	// 获取支持该资产的渠道
@@ -150,7 +158,6 @@ func (s *MatchingService) MatchChannels(request *MatchingRequest) ([]*MatchingRe
 	}
 
 	// 计算每个渠道的匹配分数
-	var results []*MatchingResult
 	for _, channel := range channels {
 		result := s.calculateChannelMatch(channel, request)
 		if result.MatchScore >= s.config.MinMatchingScore {
@@ -179,9 +186,11 @@ func (s *MatchingService) getEligibleChannels(assetID, userRegion string) ([]*mo
 	cached, err := s.redis.Get(context.Background(), cacheKey).Result()
 	if err == nil {
 		if err := json.Unmarshal([]byte(cached), &channels); err == nil {
+			eligibleChannelsCache.Hit()
 			return channels, nil
 		}
 	}
+	eligibleChannelsCache.Miss()
 
 	// 从数据库查询
 	query := s.db.Where("status = ? AND is_active = ?", "active", true)
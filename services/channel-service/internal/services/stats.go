@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rwa-platform/shared/stats"
+)
+
+// FillStats 撮合请求队列的深度
+func (s *MatchingService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.QueueSection(s.redis, "matching:queue")(ctx, report)
+}
+
+// FillStats 归因事件和转化事件队列的深度，以及按类型统计的入库量
+func (s *AttributionService) FillStats(ctx context.Context, report *stats.Report) error {
+	sections := []stats.Section{
+		stats.QueueSection(s.redis, "attribution:events"),
+		stats.QueueSection(s.redis, "attribution:conversions"),
+		stats.IngestionSection(s.db, "attribution_events", "event_type", "timestamp"),
+		stats.IngestionSection(s.db, "conversion_events", "conversion_type", "timestamp"),
+	}
+	for _, section := range sections {
+		if err := section(ctx, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
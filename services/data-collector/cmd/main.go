@@ -16,6 +16,10 @@ import (
 	"github.com/rwa-platform/data-collector/internal/kafka"
 	"github.com/rwa-platform/data-collector/internal/redis"
 	"github.com/rwa-platform/data-collector/internal/services"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,6 +35,22 @@ func main() {
 
 	logrus.Info("Starting RWA Data Collector Service...")
 
+	// 初始化链路追踪，未启用时各接入点只传递trace上下文
+	if cfg.TracingEnabled {
+		shutdown, err := tracing.Setup(context.Background(), "data-collector", cfg.TracingEndpoint)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logrus.Errorf("Failed to flush traces: %v", err)
+			}
+		}()
+		logrus.Infof("Tracing enabled, exporting to %s", cfg.TracingEndpoint)
+	}
+
 	// 初始化数据库
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
@@ -54,22 +74,69 @@ func main() {
 	priceService := services.NewPriceService(db, redisClient, kafkaProducer, cfg)
 	blockchainService := services.NewBlockchainService(db, redisClient, kafkaProducer, cfg)
 	newsService := services.NewNewsService(db, redisClient, kafkaProducer, cfg)
+	filingService := services.NewFilingService(db, redisClient, kafkaProducer, cfg)
+	dataSourceService := services.NewDataSourceService(db, redisClient, kafkaProducer, cfg)
+
+	// 上游请求计入数据源健康统计，API密钥从数据源配置读取
+	priceService.UseDataSources(dataSourceService)
+	newsService.UseDataSources(dataSourceService)
+	filingService.UseDataSources(dataSourceService)
+
+	// 注册定时任务，任务定义和执行记录保存在数据库中
+	jobScheduler := scheduler.New(db, "data-collector")
+	for _, register := range []func(*scheduler.Scheduler) error{
+		priceService.RegisterJobs,
+		blockchainService.RegisterJobs,
+		filingService.RegisterJobs,
+		newsService.RegisterJobs,
+	} {
+		if err := register(jobScheduler); err != nil {
+			logrus.Fatalf("Failed to register scheduled jobs: %v", err)
+		}
+	}
 
 	// 启动后台服务
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 启动价格数据采集
-	go priceService.StartPriceCollection(ctx)
+	// 采集开始前加载数据源密钥，之后定期刷新
+	dataSourceService.Load(ctx)
+	go dataSourceService.Start(ctx)
+
+	// Prometheus指标在单独的端口上暴露
+	if cfg.MetricsEnabled {
+		if err := metrics.RegisterRedisPool("data-collector", redisClient.PoolStats); err != nil {
+			logrus.Warnf("Failed to register Redis pool metrics: %v", err)
+		}
+		go func() {
+			logrus.Infof("Metrics server starting on port %d", cfg.MetricsPort)
+			if err := metrics.Serve(ctx, cfg.MetricsPort); err != nil {
+				logrus.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+	}
+
+	// 新闻采集任务执行前加载实体识别器并导入配置的关键词
+	newsService.PrepareNewsCollection(ctx)
+
+	// 启动定时任务（价格采集、储备证明检查、监管申报采集、新闻采集和回填）
+	go jobScheduler.Start(ctx)
 	
 	// 启动区块链数据采集
 	go blockchainService.StartBlockchainIndexing(ctx)
-	
-	// 启动新闻数据采集
-	go newsService.StartNewsCollection(ctx)
 
 	// 初始化HTTP服务器
-	router := setupRouter(priceService, blockchainService, newsService)
+	statsSections := []stats.Section{
+		priceService.FillStats,
+		blockchainService.FillStats,
+		newsService.FillStats,
+		filingService.FillStats,
+		jobScheduler.FillStats,
+		stats.CacheSection,
+		redisClient.StatsSection(),
+		database.GetStats(db),
+	}
+	router := setupRouter(priceService, blockchainService, newsService, filingService, dataSourceService, jobScheduler, statsSections)
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
@@ -119,7 +186,7 @@ func setupLogger(level string) {
 	}
 }
 
-func setupRouter(priceService *services.PriceService, blockchainService *services.BlockchainService, newsService *services.NewsService) *gin.Engine {
+func setupRouter(priceService *services.PriceService, blockchainService *services.BlockchainService, newsService *services.NewsService, filingService *services.FilingService, dataSourceService *services.DataSourceService, jobScheduler *scheduler.Scheduler, statsSections []stats.Section) *gin.Engine {
 	if gin.Mode() == gin.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -127,6 +194,8 @@ func setupRouter(priceService *services.PriceService, blockchainService *service
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware("data-collector"))
+	router.Use(metrics.Middleware())
 
 	// 健康检查
 	router.GET("/health", handlers.HealthCheck)
@@ -146,13 +215,39 @@ func setupRouter(priceService *services.PriceService, blockchainService *service
 		{
 			blockchain.GET("/assets/:address", handlers.GetAssetInfo(blockchainService))
 			blockchain.GET("/transactions/:hash", handlers.GetTransaction(blockchainService))
+			blockchain.GET("/wallets/:address/transfers", handlers.GetWalletTransfers(blockchainService))
+			blockchain.GET("/wallets/:address/transactions", handlers.GetWalletTransactions(blockchainService))
+			blockchain.GET("/reserves/:asset_id", handlers.GetReserveHistory(blockchainService))
+			blockchain.GET("/events", handlers.GetDecodedEvents(blockchainService))
+			blockchain.GET("/bridges", handlers.GetBridgeTransfers(blockchainService))
+		}
+
+		// 钱包监控接口
+		watchlist := v1.Group("/watchlist")
+		{
+			watchlist.GET("/", handlers.GetWatchedWallets(blockchainService))
+			watchlist.POST("/", handlers.AddWatchedWallet(blockchainService))
+			watchlist.DELETE("/:id", handlers.RemoveWatchedWallet(blockchainService))
+			watchlist.GET("/:id/balances", handlers.GetWalletBalances(blockchainService))
 		}
 
 		// 新闻相关接口
 		news := v1.Group("/news")
 		{
 			news.GET("/", handlers.GetNews(newsService))
+			news.GET("/search", handlers.SearchNews(newsService))
+			news.GET("/sentiment", handlers.GetNewsSentiment(newsService))
+			news.GET("/regulatory-events", handlers.GetRegulatoryEvents(newsService))
+			news.GET("/stories/:id", handlers.GetNewsStory(newsService))
 			news.GET("/:id", handlers.GetNewsDetail(newsService))
+			news.GET("/:id/entities", handlers.GetNewsEntities(newsService))
+		}
+
+		// 监管申报接口
+		filings := v1.Group("/filings")
+		{
+			filings.GET("/", handlers.GetFilings(filingService))
+			filings.GET("/:id", handlers.GetFiling(filingService))
 		}
 
 		// 管理接口
@@ -160,7 +255,45 @@ func setupRouter(priceService *services.PriceService, blockchainService *service
 		{
 			admin.POST("/sync/prices", handlers.TriggerPriceSync(priceService))
 			admin.POST("/sync/blockchain", handlers.TriggerBlockchainSync(blockchainService))
-			admin.GET("/stats", handlers.GetStats(priceService, blockchainService, newsService))
+			admin.GET("/indexers", handlers.GetIndexerStatuses(blockchainService))
+			admin.POST("/indexers/:chain/pause", handlers.PauseIndexer(blockchainService))
+			admin.POST("/indexers/:chain/resume", handlers.ResumeIndexer(blockchainService))
+			admin.POST("/indexers/:chain/rewind", handlers.RewindIndexer(blockchainService))
+			admin.GET("/chains", handlers.GetChains(blockchainService))
+			admin.POST("/chains", handlers.AddChain(blockchainService))
+			admin.DELETE("/chains/:chain", handlers.RemoveChain(blockchainService))
+			admin.GET("/abis", handlers.GetABIs(blockchainService))
+			admin.POST("/abis", handlers.UploadABI(blockchainService))
+			admin.DELETE("/abis/:id", handlers.DeleteABI(blockchainService))
+			admin.POST("/abis/:id/redecode", handlers.RedecodeABI(blockchainService))
+			admin.GET("/bridges", handlers.GetBridgeContracts(blockchainService))
+			admin.POST("/bridges", handlers.AddBridgeContract(blockchainService))
+			admin.DELETE("/bridges/:id", handlers.RemoveBridgeContract(blockchainService))
+			admin.GET("/sync-jobs/:id", handlers.GetSyncJob(blockchainService))
+			admin.GET("/data-sources", handlers.GetDataSources(dataSourceService))
+			admin.POST("/data-sources", handlers.CreateDataSource(dataSourceService))
+			admin.POST("/data-sources/rotate-keys", handlers.RotateDataSourceKeys(dataSourceService))
+			admin.GET("/data-sources/:id", handlers.GetDataSource(dataSourceService))
+			admin.PUT("/data-sources/:id", handlers.UpdateDataSource(dataSourceService))
+			admin.DELETE("/data-sources/:id", handlers.DeleteDataSource(dataSourceService))
+			admin.POST("/data-sources/:id/test", handlers.TestDataSource(dataSourceService))
+			admin.GET("/feeds", handlers.GetFeeds(newsService))
+			admin.POST("/feeds", handlers.AddFeed(newsService))
+			admin.DELETE("/feeds/:id", handlers.RemoveFeed(newsService))
+			admin.GET("/news/subscriptions", handlers.GetNewsSubscriptions(newsService))
+			admin.POST("/news/subscriptions", handlers.SaveNewsSubscription(newsService))
+			admin.DELETE("/news/subscriptions/:id", handlers.RemoveNewsSubscription(newsService))
+			admin.POST("/news/subscriptions/sync-assets", handlers.SyncAssetNewsSubscriptions(newsService))
+			admin.POST("/news/sentiment/rescore", handlers.RescoreNewsSentiment(newsService))
+			admin.POST("/news/entities/relink", handlers.RelinkNewsEntities(newsService))
+			admin.GET("/regulatory-events", handlers.GetRegulatoryEventQueue(newsService))
+			admin.POST("/regulatory-events/:id/review", handlers.ReviewRegulatoryEvent(newsService))
+			admin.POST("/filings/sync", handlers.TriggerFilingSync(filingService))
+			admin.GET("/jobs", handlers.GetScheduledJobs(jobScheduler))
+			admin.PATCH("/jobs/:id", handlers.UpdateScheduledJob(jobScheduler))
+			admin.POST("/jobs/:id/trigger", handlers.TriggerScheduledJob(jobScheduler))
+			admin.GET("/jobs/:id/runs", handlers.GetScheduledJobRuns(jobScheduler))
+			admin.GET("/stats", handlers.GetStats(statsSections...))
 		}
 	}
 
@@ -0,0 +1,277 @@
+// Package abidecode 按合约ABI解码EVM事件日志
+//
+// 注册表中的ABI有两种：指定合约地址的ABI只用于该合约；未指定地址的ABI按事件签名(topic0)
+// 匹配，只对跟踪中的合约生效，避免把全链同签名的日志都解码入库
+package abidecode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNoEvents ABI中没有可解码的事件
+var ErrNoEvents = errors.New("abi defines no events")
+
+// Source 注册表中的一份ABI
+type Source struct {
+	ID      string
+	Chain   string // 为空时适用于所有链
+	Address string // 为空时按事件签名匹配
+	ABI     abi.ABI
+}
+
+// Contract 跟踪中的合约，Chain为空时适用于所有链
+type Contract struct {
+	Chain   string
+	Address string
+}
+
+// Event 解码后的事件，Args中整数统一为十进制字符串，地址为校验和格式，字节为0x开头的小写十六进制
+type Event struct {
+	ABIID     string
+	Name      string
+	Signature string // 规范签名，如Transfer(address,address,uint256)
+	Topic     common.Hash
+	Args      map[string]interface{}
+}
+
+type contractKey struct {
+	chain   string
+	address common.Address
+}
+
+type candidate struct {
+	abiID string
+	event abi.Event
+}
+
+// Registry 并发安全的ABI注册表，Load整体替换内容
+type Registry struct {
+	mu         sync.RWMutex
+	contracts  map[contractKey]*Source
+	signatures map[common.Hash][]candidate
+	tracked    map[contractKey]bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		contracts:  make(map[contractKey]*Source),
+		signatures: make(map[common.Hash][]candidate),
+		tracked:    make(map[contractKey]bool),
+	}
+}
+
+// ExtractABI 返回ABI数组，Hardhat/Truffle编译产物只取其中的abi字段
+func ExtractABI(raw []byte) ([]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] != '{' {
+		return raw, nil
+	}
+
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err != nil {
+		return nil, err
+	}
+	if len(artifact.ABI) == 0 {
+		return nil, fmt.Errorf("expected an ABI array or an object with an abi field")
+	}
+	return artifact.ABI, nil
+}
+
+// ParseABI 解析ABI JSON，同时接受编译产物格式，ABI中至少要有一个事件
+func ParseABI(raw []byte) (abi.ABI, error) {
+	raw, err := ExtractABI(raw)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(raw))
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	if len(parsed.Events) == 0 {
+		return abi.ABI{}, ErrNoEvents
+	}
+	return parsed, nil
+}
+
+// Load 替换注册表中的ABI和跟踪合约；同一签名有多个ABI时按sources顺序优先
+func (r *Registry) Load(sources []Source, tracked []Contract) {
+	contracts := make(map[contractKey]*Source)
+	signatures := make(map[common.Hash][]candidate)
+	trackedSet := make(map[contractKey]bool)
+
+	for i := range sources {
+		source := &sources[i]
+		if source.Address != "" {
+			key := contractKey{chain: source.Chain, address: common.HexToAddress(source.Address)}
+			contracts[key] = source
+			trackedSet[key] = true
+			continue
+		}
+		for _, event := range source.ABI.Events {
+			if event.Anonymous {
+				continue
+			}
+			signatures[event.ID] = append(signatures[event.ID], candidate{abiID: source.ID, event: event})
+		}
+	}
+	for _, contract := range tracked {
+		if common.IsHexAddress(contract.Address) {
+			trackedSet[contractKey{chain: contract.Chain, address: common.HexToAddress(contract.Address)}] = true
+		}
+	}
+
+	r.mu.Lock()
+	r.contracts = contracts
+	r.signatures = signatures
+	r.tracked = trackedSet
+	r.mu.Unlock()
+}
+
+// Tracks 合约是否在跟踪范围内（上传过ABI或属于平台资产）
+func (r *Registry) Tracks(chain string, address common.Address) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tracked[contractKey{chain, address}] || r.tracked[contractKey{"", address}]
+}
+
+// Decode 解码一条日志，没有匹配的ABI或合约不在跟踪范围内时返回nil
+// 合约ABI优先于签名匹配；ERC-20和ERC-721的Transfer签名相同，按indexed参数数量区分
+func (r *Registry) Decode(chain string, log *types.Log) (*Event, error) {
+	// 匿名事件没有签名，无法匹配
+	if len(log.Topics) == 0 {
+		return nil, nil
+	}
+	topic := log.Topics[0]
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range []contractKey{{chain, log.Address}, {"", log.Address}} {
+		source, ok := r.contracts[key]
+		if !ok {
+			continue
+		}
+		event, err := source.ABI.EventByID(topic)
+		if err == nil && indexedCount(event) == len(log.Topics)-1 {
+			return decodeEvent(source.ID, *event, log)
+		}
+	}
+
+	if !r.tracked[contractKey{chain, log.Address}] && !r.tracked[contractKey{"", log.Address}] {
+		return nil, nil
+	}
+	for _, match := range r.signatures[topic] {
+		if indexedCount(&match.event) == len(log.Topics)-1 {
+			return decodeEvent(match.abiID, match.event, log)
+		}
+	}
+	return nil, nil
+}
+
+func indexedCount(event *abi.Event) int {
+	count := 0
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			count++
+		}
+	}
+	return count
+}
+
+func decodeEvent(abiID string, event abi.Event, log *types.Log) (*Event, error) {
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+
+	values := make(map[string]interface{}, len(event.Inputs))
+	if err := event.Inputs.UnpackIntoMap(values, log.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s data: %v", event.Sig, err)
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("failed to decode %s topics: %v", event.Sig, err)
+	}
+
+	args := make(map[string]interface{}, len(event.Inputs))
+	for _, input := range event.Inputs {
+		args[input.Name] = normalize(input.Type, reflect.ValueOf(values[input.Name]))
+	}
+
+	return &Event{
+		ABIID:     abiID,
+		Name:      event.RawName,
+		Signature: event.Sig,
+		Topic:     event.ID,
+		Args:      args,
+	}, nil
+}
+
+// normalize 将解码结果转换为便于JSON存储和按值查询的形式
+func normalize(t abi.Type, v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	// indexed的动态类型（string、bytes、数组）在topic中只有keccak256哈希
+	if hash, ok := v.Interface().(common.Hash); ok {
+		return hash.Hex()
+	}
+
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		if value, ok := v.Interface().(*big.Int); ok {
+			return value.String()
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(v.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(v.Uint(), 10)
+		}
+	case abi.AddressTy:
+		if address, ok := v.Interface().(common.Address); ok {
+			return address.Hex()
+		}
+	case abi.BytesTy, abi.FixedBytesTy, abi.FunctionTy:
+		data := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(data), v)
+		return hexutil.Encode(data)
+	case abi.SliceTy, abi.ArrayTy:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = normalize(*t.Elem, v.Index(i))
+		}
+		return items
+	case abi.TupleTy:
+		fields := make(map[string]interface{}, len(t.TupleElems))
+		for i, elem := range t.TupleElems {
+			name := t.TupleRawNames[i]
+			if name == "" {
+				name = fmt.Sprintf("field%d", i)
+			}
+			fields[name] = normalize(*elem, v.Field(i))
+		}
+		return fields
+	case abi.StringTy:
+		// PostgreSQL的jsonb不接受\u0000和非法UTF-8
+		return strings.ReplaceAll(strings.ToValidUTF8(v.String(), "\uFFFD"), "\x00", "")
+	}
+	return v.Interface()
+}
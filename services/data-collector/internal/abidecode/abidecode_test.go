@@ -0,0 +1,224 @@
+package abidecode
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const erc20ABI = `[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","inputs":[
+		{"name":"owner","type":"address","indexed":true},
+		{"name":"spender","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+// erc721ABI 与ERC-20的Transfer签名相同，但tokenId是indexed
+const erc721ABI = `[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"tokenId","type":"uint256","indexed":true}]}
+]`
+
+const navOracleABI = `[
+	{"type":"event","name":"NavUpdated","inputs":[
+		{"name":"fund","type":"string","indexed":true},
+		{"name":"reportId","type":"bytes32","indexed":false},
+		{"name":"report","type":"tuple","indexed":false,"components":[
+			{"name":"nav","type":"uint256"},
+			{"name":"decimals","type":"uint8"},
+			{"name":"holdings","type":"address[]"}]},
+		{"name":"note","type":"string","indexed":false}]}
+]`
+
+var (
+	token  = common.HexToAddress("0x96F6eF951840721AdBF46Ac996b59E0235CB985C")
+	oracle = common.HexToAddress("0x1a2B3c4d5E6f708192a3B4c5D6E7f8091A2b3C4d")
+	other  = common.HexToAddress("0x0000000000000000000000000000000000000bad")
+	alice  = common.HexToAddress("0x00000000000000000000000000000000000A11cE")
+	bob    = common.HexToAddress("0x0000000000000000000000000000000000000B0b")
+)
+
+func mustParse(t *testing.T, raw string) abi.ABI {
+	t.Helper()
+	parsed, err := ParseABI([]byte(raw))
+	require.NoError(t, err)
+	return parsed
+}
+
+func addressTopic(address common.Address) common.Hash {
+	return common.BytesToHash(address.Bytes())
+}
+
+func transferLog(contract common.Address, value *big.Int) *types.Log {
+	return &types.Log{
+		Address: contract,
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			addressTopic(alice),
+			addressTopic(bob),
+		},
+		Data: common.LeftPadBytes(value.Bytes(), 32),
+	}
+}
+
+func TestParseABI(t *testing.T) {
+	parsed, err := ParseABI([]byte(erc20ABI))
+	require.NoError(t, err)
+	assert.Len(t, parsed.Events, 2)
+
+	// Hardhat编译产物
+	artifact := []byte(`{"contractName":"Token","abi":` + erc721ABI + `,"bytecode":"0x6080"}`)
+	parsed, err = ParseABI(artifact)
+	require.NoError(t, err)
+	assert.Contains(t, parsed.Events, "Transfer")
+
+	extracted, err := ExtractABI(artifact)
+	require.NoError(t, err)
+	assert.Equal(t, erc721ABI, string(extracted))
+
+	_, err = ParseABI([]byte(`[{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"type":"uint256"}]}]`))
+	assert.ErrorIs(t, err, ErrNoEvents)
+
+	_, err = ParseABI([]byte(`{"bytecode":"0x"}`))
+	assert.Error(t, err)
+
+	_, err = ParseABI([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestDecodeBySignature(t *testing.T) {
+	registry := NewRegistry()
+	registry.Load([]Source{
+		{ID: "erc721", ABI: mustParse(t, erc721ABI)},
+		{ID: "erc20", ABI: mustParse(t, erc20ABI)},
+	}, []Contract{{Address: token.Hex()}})
+
+	value, _ := new(big.Int).SetString("1500000000000000000000", 10)
+	event, err := registry.Decode("ethereum", transferLog(token, value))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	// indexed参数数量不同，跳过同签名的ERC-721定义
+	assert.Equal(t, "erc20", event.ABIID)
+	assert.Equal(t, "Transfer", event.Name)
+	assert.Equal(t, "Transfer(address,address,uint256)", event.Signature)
+	assert.Equal(t, map[string]interface{}{
+		"from":  alice.Hex(),
+		"to":    bob.Hex(),
+		"value": "1500000000000000000000",
+	}, event.Args)
+
+	nft := &types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			addressTopic(alice),
+			addressTopic(bob),
+			common.BigToHash(big.NewInt(42)),
+		},
+	}
+	event, err = registry.Decode("ethereum", nft)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "erc721", event.ABIID)
+	assert.Equal(t, "42", event.Args["tokenId"])
+
+	// 未跟踪的合约不按签名解码
+	event, err = registry.Decode("ethereum", transferLog(other, value))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+}
+
+func TestDecodeByContract(t *testing.T) {
+	oracleABI := mustParse(t, navOracleABI)
+	registry := NewRegistry()
+	registry.Load([]Source{
+		{ID: "oracle", Chain: "ethereum", Address: oracle.Hex(), ABI: oracleABI},
+		{ID: "erc20", ABI: mustParse(t, erc20ABI)},
+	}, nil)
+
+	assert.True(t, registry.Tracks("ethereum", oracle))
+	assert.False(t, registry.Tracks("polygon", oracle))
+
+	report := struct {
+		Nav      *big.Int
+		Decimals uint8
+		Holdings []common.Address
+	}{big.NewInt(1_0234_5678), 8, []common.Address{alice, bob}}
+	reportID := [32]byte{0xab, 0xcd}
+	data, err := oracleABI.Events["NavUpdated"].Inputs.NonIndexed().Pack(reportID, report, "May\x00 NAV")
+	require.NoError(t, err)
+
+	log := &types.Log{
+		Address: oracle,
+		Topics: []common.Hash{
+			oracleABI.Events["NavUpdated"].ID,
+			crypto.Keccak256Hash([]byte("USDY")),
+		},
+		Data: data,
+	}
+	event, err := registry.Decode("ethereum", log)
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	assert.Equal(t, "oracle", event.ABIID)
+	assert.Equal(t, "NavUpdated", event.Name)
+	assert.Equal(t, "NavUpdated(string,bytes32,(uint256,uint8,address[]),string)", event.Signature)
+	// indexed的string只能得到哈希
+	assert.Equal(t, crypto.Keccak256Hash([]byte("USDY")).Hex(), event.Args["fund"])
+	assert.Equal(t, "0xabcd"+strings.Repeat("0", 60), event.Args["reportId"])
+	assert.Equal(t, map[string]interface{}{
+		"nav":      "102345678",
+		"decimals": "8",
+		"holdings": []interface{}{alice.Hex(), bob.Hex()},
+	}, event.Args["report"])
+	assert.Equal(t, "May NAV", event.Args["note"])
+
+	// 合约ABI只适用于注册的链
+	event, err = registry.Decode("polygon", log)
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	// 合约ABI中没有的事件回退到签名匹配
+	event, err = registry.Decode("ethereum", transferLog(oracle, big.NewInt(7)))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "erc20", event.ABIID)
+
+	// 数据与ABI不符时返回错误
+	log.Data = data[:40]
+	_, err = registry.Decode("ethereum", log)
+	assert.Error(t, err)
+}
+
+func TestRegistryLoadReplaces(t *testing.T) {
+	registry := NewRegistry()
+	registry.Load([]Source{{ID: "erc20", ABI: mustParse(t, erc20ABI)}}, []Contract{{Chain: "ethereum", Address: token.Hex()}})
+
+	event, err := registry.Decode("ethereum", transferLog(token, big.NewInt(1)))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+
+	registry.Load(nil, []Contract{{Chain: "ethereum", Address: token.Hex()}})
+	event, err = registry.Decode("ethereum", transferLog(token, big.NewInt(1)))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+
+	// 没有topic的匿名事件
+	event, err = registry.Decode("ethereum", &types.Log{Address: token})
+	require.NoError(t, err)
+	assert.Nil(t, event)
+}
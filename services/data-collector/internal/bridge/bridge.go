@@ -0,0 +1,128 @@
+// Package bridge 从跨链桥合约的解码事件中提取转账的一侧（出站或入站）
+//
+// 同一笔跨链转账在源链上表现为锁定或销毁，在目标链上表现为铸造或释放，两侧通过桥协议的消息ID关联
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 转账方向：出站为源链上的锁定/销毁，入站为目标链上的铸造/释放
+const (
+	Outbound = "outbound"
+	Inbound  = "inbound"
+)
+
+// ErrMissingArg 事件中缺少定义的参数
+var ErrMissingArg = errors.New("missing event argument")
+
+// Definition 桥合约事件的参数映射，参数路径用.访问元组字段（如message.messageId）
+// MessageIDArg可以用逗号列出多个参数，按顺序以/连接成消息ID（如Wormhole的emitterChain,emitter,sequence）
+type Definition struct {
+	Protocol       string
+	Direction      string
+	MessageIDArg   string
+	TokenArg       string
+	AmountArg      string
+	SenderArg      string
+	RecipientArg   string
+	RemoteChainArg string
+}
+
+// Leg 跨链转账的一侧，可选参数未定义时为空
+type Leg struct {
+	Protocol    string
+	Direction   string
+	MessageID   string
+	Token       string
+	Amount      string
+	Sender      string
+	Recipient   string
+	RemoteChain string
+}
+
+// Validate 检查定义是否完整
+func (d Definition) Validate() error {
+	if strings.TrimSpace(d.Protocol) == "" {
+		return fmt.Errorf("protocol is required")
+	}
+	if d.Direction != Outbound && d.Direction != Inbound {
+		return fmt.Errorf("direction must be %s or %s", Outbound, Inbound)
+	}
+	if strings.TrimSpace(d.MessageIDArg) == "" {
+		return fmt.Errorf("message id argument is required")
+	}
+	return nil
+}
+
+// Extract 按定义从解码参数中提取转账的一侧
+func Extract(def Definition, args map[string]interface{}) (*Leg, error) {
+	var parts []string
+	for _, path := range strings.Split(def.MessageIDArg, ",") {
+		value, ok := Lookup(args, strings.TrimSpace(path))
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingArg, path)
+		}
+		parts = append(parts, value)
+	}
+
+	leg := &Leg{
+		Protocol:  def.Protocol,
+		Direction: def.Direction,
+		MessageID: strings.Join(parts, "/"),
+	}
+	for _, field := range []struct {
+		path   string
+		target *string
+	}{
+		{def.TokenArg, &leg.Token},
+		{def.AmountArg, &leg.Amount},
+		{def.SenderArg, &leg.Sender},
+		{def.RecipientArg, &leg.Recipient},
+		{def.RemoteChainArg, &leg.RemoteChain},
+	} {
+		if field.path == "" {
+			continue
+		}
+		value, ok := Lookup(args, field.path)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingArg, field.path)
+		}
+		*field.target = value
+	}
+	return leg, nil
+}
+
+// Lookup 按路径读取标量参数，十六进制值统一为小写以便两侧匹配（地址除外，保持校验和格式）
+func Lookup(args map[string]interface{}, path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+
+	var current interface{} = args
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = fields[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		// 42位的是地址，其余十六进制（bytes32消息ID等）转为小写
+		if strings.HasPrefix(value, "0x") && len(value) != 42 {
+			return strings.ToLower(value), true
+		}
+		return value, true
+	case bool:
+		return fmt.Sprint(value), true
+	case float64:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
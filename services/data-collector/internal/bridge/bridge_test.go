@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	t.Run("nested message", func(t *testing.T) {
+		// CCIP OnRamp的CCIPSendRequested事件，消息是一个元组
+		args := map[string]interface{}{
+			"message": map[string]interface{}{
+				"messageId":           "0xABCDEF0000000000000000000000000000000000000000000000000000000001",
+				"sender":              "0x00000000000000000000000000000000000A11cE",
+				"receiver":            "0x0000000000000000000000000000000000000B0b",
+				"sourceChainSelector": "5009297550715157269",
+			},
+		}
+		def := Definition{
+			Protocol:       "ccip",
+			Direction:      Outbound,
+			MessageIDArg:   "message.messageId",
+			SenderArg:      "message.sender",
+			RecipientArg:   "message.receiver",
+			RemoteChainArg: "message.sourceChainSelector",
+		}
+
+		leg, err := Extract(def, args)
+		require.NoError(t, err)
+		assert.Equal(t, &Leg{
+			Protocol:    "ccip",
+			Direction:   Outbound,
+			MessageID:   "0xabcdef0000000000000000000000000000000000000000000000000000000001",
+			Sender:      "0x00000000000000000000000000000000000A11cE",
+			Recipient:   "0x0000000000000000000000000000000000000B0b",
+			RemoteChain: "5009297550715157269",
+		}, leg)
+	})
+
+	t.Run("composite message id", func(t *testing.T) {
+		args := map[string]interface{}{
+			"emitterChainId": "2",
+			"emitterAddress": "0x0000000000000000000000003ee18b2214aff97000d974cf647e7c347e8fa585",
+			"sequence":       "171293",
+			"amount":         "2500000000",
+		}
+		def := Definition{
+			Protocol:     "wormhole",
+			Direction:    Inbound,
+			MessageIDArg: "emitterChainId, emitterAddress, sequence",
+			AmountArg:    "amount",
+		}
+
+		leg, err := Extract(def, args)
+		require.NoError(t, err)
+		assert.Equal(t, "2/0x0000000000000000000000003ee18b2214aff97000d974cf647e7c347e8fa585/171293", leg.MessageID)
+		assert.Equal(t, "2500000000", leg.Amount)
+		assert.Empty(t, leg.Sender)
+	})
+
+	t.Run("missing argument", func(t *testing.T) {
+		_, err := Extract(Definition{Protocol: "ccip", Direction: Inbound, MessageIDArg: "messageId"}, map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrMissingArg)
+
+		_, err = Extract(Definition{Protocol: "ccip", Direction: Inbound, MessageIDArg: "messageId", AmountArg: "report.amount"},
+			map[string]interface{}{"messageId": "0x01", "report": "0x"})
+		assert.ErrorIs(t, err, ErrMissingArg)
+	})
+}
+
+func TestDefinitionValidate(t *testing.T) {
+	assert.NoError(t, Definition{Protocol: "ccip", Direction: Outbound, MessageIDArg: "messageId"}.Validate())
+	assert.Error(t, Definition{Direction: Outbound, MessageIDArg: "messageId"}.Validate())
+	assert.Error(t, Definition{Protocol: "ccip", Direction: "burn", MessageIDArg: "messageId"}.Validate())
+	assert.Error(t, Definition{Protocol: "ccip", Direction: Inbound}.Validate())
+}
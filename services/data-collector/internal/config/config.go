@@ -26,6 +26,15 @@ type Config struct {
 	BSCRPC      string `mapstructure:"BSC_RPC_URL"`
 	PolygonRPC  string `mapstructure:"POLYGON_RPC_URL"`
 
+	// Solana索引配置
+	SolanaMintAddresses []string `mapstructure:"SOLANA_MINT_ADDRESSES"`
+
+	// 储备证明监控配置
+	ReserveAttestationDir    string `mapstructure:"RESERVE_ATTESTATION_DIR"`     // 托管方/审计方证明文件目录
+	ReserveCheckInterval     int    `mapstructure:"RESERVE_CHECK_INTERVAL"`      // 秒
+	ReserveFeedMaxAge        int    `mapstructure:"RESERVE_FEED_MAX_AGE"`        // 小时，链上储备喂价超过该时间未更新视为过期
+	ReserveAttestationMaxAge int    `mapstructure:"RESERVE_ATTESTATION_MAX_AGE"` // 小时
+
 	// 外部API配置
 	CoinGeckoAPIKey     string `mapstructure:"COINGECKO_API_KEY"`
 	CoinMarketCapAPIKey string `mapstructure:"COINMARKETCAP_API_KEY"`
@@ -33,10 +42,35 @@ type Config struct {
 	DuneAPIKey          string `mapstructure:"DUNE_API_KEY"`
 	NewsAPIKey          string `mapstructure:"NEWS_API_KEY"`
 
+	// 数据源API密钥加密，密钥文件修改后按间隔重新加载；未配置时不能通过管理接口设置API密钥
+	DataSourceKeyringFile     string `mapstructure:"DATA_SOURCE_KEYRING_FILE"`
+	DataSourceRefreshInterval int    `mapstructure:"DATA_SOURCE_REFRESH_INTERVAL"` // 秒
+
+	// 新闻情绪模型（可选），未配置或不可用时使用内置金融词典
+	SentimentModelURL string `mapstructure:"SENTIMENT_MODEL_URL"`
+
+	// 新闻关键词和语言
+	NewsKeywords           string   `mapstructure:"NEWS_KEYWORDS"`            // 逗号分隔，关键词后可用冒号指定语言，如"stablecoin:en|de,稳定币:zh"；只在没有任何检索订阅时导入
+	NewsLanguages          []string `mapstructure:"NEWS_LANGUAGES"`           // 未指定语言的关键词及资产订阅使用的语言
+	NewsDictionaryDir      string   `mapstructure:"NEWS_DICTIONARY_DIR"`      // 额外的分类和标签词典（*.json），同一语言会替换内置词典
+	NewsAssetSubscriptions bool     `mapstructure:"NEWS_ASSET_SUBSCRIPTIONS"` // 按活跃资产的名称和别名自动生成检索订阅
+
+	// 监管申报（EDGAR格式的索引和XBRL数据），本地目录和远程地址可以同时使用
+	FilingsDir          string   `mapstructure:"FILINGS_DIR"`           // 本地索引（*.idx）和companyfacts（*.json）文件目录
+	FilingsBaseURL      string   `mapstructure:"FILINGS_BASE_URL"`      // 归档根目录，如https://www.sec.gov/Archives，为空时不抓取远程索引
+	FilingsFactsURL     string   `mapstructure:"FILINGS_FACTS_URL"`     // companyfacts接口地址
+	FilingsUserAgent    string   `mapstructure:"FILINGS_USER_AGENT"`    // SEC要求请求头包含联系方式
+	FilingsForms        []string `mapstructure:"FILINGS_FORMS"`         // 采集的申报类型，修订版（/A）随原类型采集
+	FilingsInterval     int      `mapstructure:"FILINGS_INTERVAL"`      // 秒
+	FilingsLookbackDays int      `mapstructure:"FILINGS_LOOKBACK_DAYS"` // 每次抓取最近几天的每日索引
+
 	// 数据采集配置
 	PriceCollectionInterval      int `mapstructure:"PRICE_COLLECTION_INTERVAL"`      // 秒
 	BlockchainSyncInterval       int `mapstructure:"BLOCKCHAIN_SYNC_INTERVAL"`       // 秒
+	BlockchainBatchSize          int `mapstructure:"BLOCKCHAIN_BATCH_SIZE"`          // 每轮最多索引的区块数
+	RPCBatchSize                 int `mapstructure:"RPC_BATCH_SIZE"`                 // 单个JSON-RPC批量请求包含的调用数
 	NewsCollectionInterval       int `mapstructure:"NEWS_COLLECTION_INTERVAL"`       // 秒
+	NewsFeedInterval             int `mapstructure:"NEWS_FEED_INTERVAL"`             // 秒，RSS/Atom订阅源未单独配置时的抓取间隔
 	MaxConcurrentRequests        int `mapstructure:"MAX_CONCURRENT_REQUESTS"`
 	RequestTimeout               int `mapstructure:"REQUEST_TIMEOUT"`                // 秒
 	RetryAttempts                int `mapstructure:"RETRY_ATTEMPTS"`
@@ -79,6 +113,21 @@ func Load() (*Config, error) {
 		viper.Set("KAFKA_BROKERS", strings.Split(brokers, ","))
 	}
 
+	// 处理新闻语言列表
+	if languages := viper.GetString("NEWS_LANGUAGES"); languages != "" {
+		viper.Set("NEWS_LANGUAGES", strings.Split(languages, ","))
+	}
+
+	// 处理申报类型列表
+	if forms := viper.GetString("FILINGS_FORMS"); forms != "" {
+		viper.Set("FILINGS_FORMS", strings.Split(forms, ","))
+	}
+
+	// 处理Solana mint地址列表
+	if mints := viper.GetString("SOLANA_MINT_ADDRESSES"); mints != "" {
+		viper.Set("SOLANA_MINT_ADDRESSES", strings.Split(mints, ","))
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, err
@@ -110,12 +159,28 @@ func setDefaults() {
 	// 数据采集默认配置
 	viper.SetDefault("PRICE_COLLECTION_INTERVAL", 60)      // 1分钟
 	viper.SetDefault("BLOCKCHAIN_SYNC_INTERVAL", 300)      // 5分钟
+	viper.SetDefault("BLOCKCHAIN_BATCH_SIZE", 50)
+	viper.SetDefault("RPC_BATCH_SIZE", 20)
 	viper.SetDefault("NEWS_COLLECTION_INTERVAL", 1800)     // 30分钟
+	viper.SetDefault("NEWS_FEED_INTERVAL", 900)            // 15分钟
+	viper.SetDefault("NEWS_KEYWORDS", "stablecoin,treasury,RWA,real world assets,USDT,USDC,DAI,government bonds,money market,defi")
+	viper.SetDefault("NEWS_LANGUAGES", []string{"en"})
+	viper.SetDefault("NEWS_ASSET_SUBSCRIPTIONS", false)
+	viper.SetDefault("FILINGS_FACTS_URL", "https://data.sec.gov/api/xbrl/companyfacts")
+	viper.SetDefault("FILINGS_FORMS", []string{"10-K", "10-Q", "8-K", "20-F", "6-K", "N-CSR", "N-CSRS", "NPORT-P", "N-MFP3", "S-1", "D"})
+	viper.SetDefault("FILINGS_INTERVAL", 3600)
+	viper.SetDefault("FILINGS_LOOKBACK_DAYS", 3)
+	viper.SetDefault("DATA_SOURCE_REFRESH_INTERVAL", 60)
 	viper.SetDefault("MAX_CONCURRENT_REQUESTS", 10)
 	viper.SetDefault("REQUEST_TIMEOUT", 30)
 	viper.SetDefault("RETRY_ATTEMPTS", 3)
 	viper.SetDefault("RETRY_DELAY", 5)
 
+	// 储备证明默认配置
+	viper.SetDefault("RESERVE_CHECK_INTERVAL", 3600)      // 1小时
+	viper.SetDefault("RESERVE_FEED_MAX_AGE", 48)          // 2天
+	viper.SetDefault("RESERVE_ATTESTATION_MAX_AGE", 840)  // 35天，覆盖月度证明
+
 	// 缓存默认配置
 	viper.SetDefault("CACHE_TTL", 3600)           // 1小时
 	viper.SetDefault("PRICE_CACHE_TTL", 300)      // 5分钟
@@ -125,4 +190,5 @@ func setDefaults() {
 	viper.SetDefault("METRICS_ENABLED", true)
 	viper.SetDefault("METRICS_PORT", 9090)
 	viper.SetDefault("TRACING_ENABLED", false)
+	viper.SetDefault("TRACING_ENDPOINT", "http://localhost:4318")
 }
@@ -2,9 +2,16 @@ package database
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/newsdict"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
+	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -23,6 +30,11 @@ func NewConnection(databaseURL string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	// 传入带span的ctx（db.WithContext）的查询记录为子span
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("failed to register database tracing: %v", err)
+	}
+
 	// 配置连接池
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -33,6 +45,9 @@ func NewConnection(databaseURL string) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
+	if err := metrics.RegisterDB("rwa_platform", sqlDB); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %v", err)
+	}
 
 	// 自动迁移
 	if err := autoMigrate(db); err != nil {
@@ -43,16 +58,115 @@ func NewConnection(databaseURL string) (*gorm.DB, error) {
 }
 
 func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := dedupTokenTransfers(db); err != nil {
+		return err
+	}
+	backfillCursors := db.Migrator().HasTable(&models.ChainCursor{}) &&
+		!db.Migrator().HasColumn(&models.ChainCursor{}, "initialized")
+
+	if err := db.AutoMigrate(
 		&models.Asset{},
 		&models.PriceData{},
 		&models.BlockchainTransaction{},
 		&models.TokenTransfer{},
 		&models.NewsArticle{},
+		&models.NewsStory{},
+		&models.NewsEntity{},
+		&models.RegulatoryEvent{},
+		&models.NewsSubscription{},
+		&models.Filing{},
 		&models.DataSource{},
 		&models.SyncJob{},
 		&models.MetricData{},
-	)
+		&models.ChainCursor{},
+		&models.SignatureCursor{},
+		&models.WatchedWallet{},
+		&models.WalletBalance{},
+		&models.Chain{},
+		&models.ContractABI{},
+		&models.DecodedEvent{},
+		&models.BridgeContract{},
+		&models.BridgeTransfer{},
+	); err != nil {
+		return err
+	}
+	if backfillCursors {
+		// initialized列加入之前写入过的游标都大于0
+		if err := db.Model(&models.ChainCursor{}).Where("last_block > 0").Update("initialized", true).Error; err != nil {
+			return fmt.Errorf("failed to backfill chain cursors: %v", err)
+		}
+	}
+	if err := scheduler.Migrate(db); err != nil {
+		return err
+	}
+	return migrateNewsSearch(db)
+}
+
+// dedupTokenTransfers 在创建唯一索引idx_token_transfer_unique之前删除重复的代币转账，
+// 每个(chain, transaction_hash, log_index)保留最早写入的一条。索引已存在时不做任何事
+func dedupTokenTransfers(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.TokenTransfer{}) || migrator.HasIndex(&models.TokenTransfer{}, "idx_token_transfer_unique") {
+		return nil
+	}
+
+	result := db.Exec(`DELETE FROM token_transfers a USING token_transfers b
+		WHERE a.chain = b.chain
+			AND a.transaction_hash = b.transaction_hash
+			AND a.log_index = b.log_index
+			AND (a.created_at, a.id) > (b.created_at, b.id)`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove duplicate token transfers: %v", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logrus.Warnf("Removed %d duplicate token transfers before creating idx_token_transfer_unique", result.RowsAffected)
+	}
+	return nil
+}
+
+// migrateNewsSearch 新闻全文检索使用的生成列和GIN索引，标题、摘要、正文的权重依次降低
+// 每篇文章按自身的language选择检索配置，中日韩文等使用simple
+//
+// 生成列不在模型中声明，避免AutoMigrate比较列类型时尝试修改它。列注释记录生成时使用的配置表达式，
+// 表达式变化（包括早期固定使用english的版本）时删除并重建该列
+func migrateNewsSearch(db *gorm.DB) error {
+	config := newsdict.SearchConfigSQL("language")
+
+	var columns []struct {
+		Comment *string
+	}
+	if err := db.Raw(`SELECT col_description(a.attrelid, a.attnum) AS comment FROM pg_attribute a
+		WHERE a.attrelid = 'news_articles'::regclass AND a.attname = 'search_vector' AND NOT a.attisdropped`).
+		Scan(&columns).Error; err != nil {
+		return fmt.Errorf("failed to inspect news search vector: %v", err)
+	}
+	if len(columns) > 0 && (columns[0].Comment == nil || *columns[0].Comment != config) {
+		logrus.Info("Rebuilding news search vector with per-language text search configurations")
+		if err := db.Exec("ALTER TABLE news_articles DROP COLUMN search_vector").Error; err != nil {
+			return fmt.Errorf("failed to drop news search vector: %v", err)
+		}
+	}
+
+	if err := db.Exec(fmt.Sprintf(`ALTER TABLE news_articles ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector(%[1]s, COALESCE(title, '')), 'A') ||
+		setweight(to_tsvector(%[1]s, COALESCE(summary, '')), 'B') ||
+		setweight(to_tsvector(%[1]s, COALESCE(content, '')), 'C')
+	) STORED`, config)).Error; err != nil {
+		return fmt.Errorf("failed to add news search vector: %v", err)
+	}
+	if err := db.Exec("COMMENT ON COLUMN news_articles.search_vector IS " + quoteLiteral(config)).Error; err != nil {
+		return fmt.Errorf("failed to record news search configuration: %v", err)
+	}
+
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_news_search_vector ON news_articles USING GIN (search_vector)").Error; err != nil {
+		return fmt.Errorf("failed to create news search index: %v", err)
+	}
+	return nil
+}
+
+// quoteLiteral SQL字符串字面量，COMMENT ON不支持参数绑定
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
 }
 
 // 创建索引
@@ -122,55 +236,20 @@ func HealthCheck(db *gorm.DB) error {
 	return sqlDB.Ping()
 }
 
-// 获取数据库统计信息
-func GetStats(db *gorm.DB) (map[string]interface{}, error) {
-	stats := make(map[string]interface{})
-
-	// 获取各表的记录数
-	var count int64
-
-	// 资产数量
-	if err := db.Model(&models.Asset{}).Count(&count).Error; err == nil {
-		stats["assets_count"] = count
-	}
-
-	// 价格数据数量
-	if err := db.Model(&models.PriceData{}).Count(&count).Error; err == nil {
-		stats["price_data_count"] = count
-	}
-
-	// 区块链交易数量
-	if err := db.Model(&models.BlockchainTransaction{}).Count(&count).Error; err == nil {
-		stats["blockchain_transactions_count"] = count
-	}
-
-	// 代币转账数量
-	if err := db.Model(&models.TokenTransfer{}).Count(&count).Error; err == nil {
-		stats["token_transfers_count"] = count
-	}
-
-	// 新闻文章数量
-	if err := db.Model(&models.NewsArticle{}).Count(&count).Error; err == nil {
-		stats["news_articles_count"] = count
-	}
-
-	// 获取连接池统计
-	sqlDB, err := db.DB()
-	if err == nil {
-		dbStats := sqlDB.Stats()
-		stats["db_stats"] = map[string]interface{}{
-			"open_connections":     dbStats.OpenConnections,
-			"in_use":              dbStats.InUse,
-			"idle":                dbStats.Idle,
-			"wait_count":          dbStats.WaitCount,
-			"wait_duration":       dbStats.WaitDuration.String(),
-			"max_idle_closed":     dbStats.MaxIdleClosed,
-			"max_idle_time_closed": dbStats.MaxIdleTimeClosed,
-			"max_lifetime_closed": dbStats.MaxLifetimeClosed,
-		}
-	}
-
-	return stats, nil
+// GetStats 主要表的记录数和连接池状态，作为/admin/stats的database部分
+func GetStats(db *gorm.DB) stats.Section {
+	return stats.TableSection(db,
+		"assets",
+		"price_data",
+		"blockchain_transactions",
+		"token_transfers",
+		"decoded_events",
+		"bridge_transfers",
+		"news_articles",
+		"regulatory_events",
+		"filings",
+		"wallet_balances",
+	)
 }
 
 // 清理旧数据
@@ -182,7 +261,13 @@ func CleanupOldData(db *gorm.DB, days int) error {
 		return fmt.Errorf("failed to cleanup old price data: %v", err)
 	}
 
-	// 清理旧的新闻文章
+	// 清理旧的新闻报道和文章
+	if err := db.Where("last_seen_at < ?", cutoffTime).Delete(&models.NewsStory{}).Error; err != nil {
+		return fmt.Errorf("failed to cleanup old news stories: %v", err)
+	}
+	if err := db.Where("article_id IN (?)", db.Model(&models.NewsArticle{}).Select("id").Where("published_at < ?", cutoffTime)).Delete(&models.NewsEntity{}).Error; err != nil {
+		return fmt.Errorf("failed to cleanup old news entities: %v", err)
+	}
 	if err := db.Where("published_at < ?", cutoffTime).Delete(&models.NewsArticle{}).Error; err != nil {
 		return fmt.Errorf("failed to cleanup old news articles: %v", err)
 	}
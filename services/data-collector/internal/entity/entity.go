@@ -0,0 +1,231 @@
+// Package entity 在新闻文本中识别平台已知的资产、发行方和渠道
+//
+// 合约地址的匹配最可靠；名称和别名按整词、不区分大小写匹配；代码（如USDY）要求大写完全一致，
+// 避免与普通单词混淆。发行方被提及时，其发行的资产以较低的置信度一并关联
+package entity
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 实体类型
+const (
+	TypeAsset   = "asset"
+	TypeIssuer  = "issuer"
+	TypeChannel = "channel"
+)
+
+// 匹配方式
+const (
+	MatchAddress = "address"
+	MatchName    = "name"
+	MatchAlias   = "alias"
+	MatchSymbol  = "symbol"
+	MatchIssuer  = "issuer"
+)
+
+// 各匹配方式的基础置信度
+var baseConfidence = map[string]float64{
+	MatchAddress: 0.95,
+	MatchName:    0.85,
+	MatchAlias:   0.75,
+	MatchSymbol:  0.7,
+}
+
+const (
+	// shortSymbolPenalty 三个字符以内的代码更容易误匹配
+	shortSymbolPenalty = 0.15
+	// titleBonus 标题中出现时提高置信度
+	titleBonus = 0.1
+	// mentionBonus 每多出现一次提高的置信度，最多加到maxMentionBonus
+	mentionBonus    = 0.03
+	maxMentionBonus = 0.09
+	// issuerPropagation 通过发行方关联到资产时的置信度折扣
+	issuerPropagation = 0.7
+)
+
+// Entity 可被识别的实体；Assets只用于发行方，列出其发行的资产ID
+type Entity struct {
+	Type      string
+	ID        string
+	Name      string
+	Aliases   []string
+	Symbols   []string
+	Addresses []string
+	Assets    []string
+}
+
+// Link 文章与实体的关联
+type Link struct {
+	Type       string
+	ID         string
+	Name       string
+	MatchType  string
+	Matched    string
+	Confidence float64
+	Mentions   int
+}
+
+type term struct {
+	entity    *Entity
+	matchType string
+	text      string
+	pattern   *regexp.Regexp
+}
+
+// Linker 实体识别器，创建后只读，可并发使用
+type Linker struct {
+	terms []term
+}
+
+// NewLinker 为实体的名称、别名、代码和地址编译匹配规则
+func NewLinker(entities []Entity) *Linker {
+	linker := &Linker{}
+	for i := range entities {
+		entity := &entities[i]
+		add := func(matchType, text string, caseSensitive bool) {
+			text = strings.TrimSpace(text)
+			if len(text) < 2 {
+				return
+			}
+			linker.terms = append(linker.terms, term{
+				entity:    entity,
+				matchType: matchType,
+				text:      text,
+				pattern:   wordPattern(text, caseSensitive),
+			})
+		}
+
+		nameMatch := MatchName
+		if entity.Type == TypeIssuer {
+			nameMatch = MatchIssuer
+		}
+		add(nameMatch, entity.Name, false)
+		for _, alias := range entity.Aliases {
+			add(MatchAlias, alias, false)
+		}
+		for _, symbol := range entity.Symbols {
+			add(MatchSymbol, symbol, true)
+		}
+		for _, address := range entity.Addresses {
+			add(MatchAddress, address, false)
+		}
+	}
+	return linker
+}
+
+// Link 识别标题和正文中的实体，每个实体只保留置信度最高的匹配，按置信度降序返回
+func (l *Linker) Link(title, body string) []Link {
+	best := make(map[string]*Link)
+	issuers := make(map[*Entity]*Link)
+
+	for _, t := range l.terms {
+		inTitle := len(t.pattern.FindAllStringIndex(title, -1))
+		mentions := inTitle + len(t.pattern.FindAllStringIndex(body, -1))
+		if mentions == 0 {
+			continue
+		}
+
+		confidence := baseConfidence[t.matchType]
+		if t.matchType == MatchIssuer {
+			confidence = baseConfidence[MatchName]
+		}
+		if t.matchType == MatchSymbol && len(t.text) <= 3 {
+			confidence -= shortSymbolPenalty
+		}
+		if inTitle > 0 {
+			confidence += titleBonus
+		}
+		bonus := float64(mentions-1) * mentionBonus
+		if bonus > maxMentionBonus {
+			bonus = maxMentionBonus
+		}
+		confidence = round(minFloat(confidence+bonus, 1))
+
+		key := t.entity.Type + ":" + t.entity.ID
+		link := best[key]
+		if link == nil {
+			link = &Link{Type: t.entity.Type, ID: t.entity.ID, Name: t.entity.Name}
+			best[key] = link
+			if t.entity.Type == TypeIssuer {
+				issuers[t.entity] = link
+			}
+		}
+		// 名称和别名可能重叠（如"Ondo Finance"和"Ondo"），提及次数取各匹配规则中的最大值
+		if mentions > link.Mentions {
+			link.Mentions = mentions
+		}
+		if confidence > link.Confidence {
+			link.Confidence = confidence
+			link.MatchType = t.matchType
+			link.Matched = t.text
+		}
+	}
+
+	// 发行方的资产没有被直接提及时，按折扣后的置信度关联
+	for issuerEntity, issuer := range issuers {
+		for _, assetID := range issuerEntity.Assets {
+			assetKey := TypeAsset + ":" + assetID
+			if best[assetKey] != nil {
+				continue
+			}
+			best[assetKey] = &Link{
+				Type:       TypeAsset,
+				ID:         assetID,
+				Name:       issuerEntity.Name,
+				MatchType:  MatchIssuer,
+				Matched:    issuer.Matched,
+				Confidence: round(issuer.Confidence * issuerPropagation),
+				Mentions:   issuer.Mentions,
+			}
+		}
+	}
+
+	links := make([]Link, 0, len(best))
+	for _, link := range best {
+		links = append(links, *link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Confidence != links[j].Confidence {
+			return links[i].Confidence > links[j].Confidence
+		}
+		if links[i].Type != links[j].Type {
+			return links[i].Type < links[j].Type
+		}
+		return links[i].ID < links[j].ID
+	})
+	return links
+}
+
+// wordPattern 整词匹配；词首尾不是字母数字时（如"S&P"）不加边界
+func wordPattern(text string, caseSensitive bool) *regexp.Regexp {
+	pattern := strings.Join(strings.Fields(regexp.QuoteMeta(text)), `\s+`)
+	if isWordByte(text[0]) {
+		pattern = `(?:^|[^\p{L}\p{N}_])` + pattern
+	}
+	if isWordByte(text[len(text)-1]) {
+		pattern += `(?:$|[^\p{L}\p{N}_])`
+	}
+	if !caseSensitive {
+		pattern = `(?i)` + pattern
+	}
+	return regexp.MustCompile(pattern)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
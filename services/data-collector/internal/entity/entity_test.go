@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLinker() *Linker {
+	return NewLinker([]Entity{
+		{
+			Type:      TypeAsset,
+			ID:        "asset-usdy",
+			Name:      "Ondo US Dollar Yield",
+			Aliases:   []string{"US Dollar Yield Token"},
+			Symbols:   []string{"USDY"},
+			Addresses: []string{"0x96F6eF951840721AdBF46Ac996b59E0235CB985C"},
+		},
+		{Type: TypeAsset, ID: "asset-ousg", Name: "Ondo Short-Term US Government Treasuries", Symbols: []string{"OUSG"}},
+		{Type: TypeAsset, ID: "asset-ust", Name: "TerraUSD", Symbols: []string{"UST"}},
+		{Type: TypeIssuer, ID: "ondo finance", Name: "Ondo Finance", Aliases: []string{"Ondo"}, Assets: []string{"asset-usdy", "asset-ousg"}},
+		{Type: TypeChannel, ID: "channel-coinbase", Name: "Coinbase", Aliases: []string{"Coinbase Exchange"}},
+	})
+}
+
+func find(links []Link, entityType, id string) *Link {
+	for i := range links {
+		if links[i].Type == entityType && links[i].ID == id {
+			return &links[i]
+		}
+	}
+	return nil
+}
+
+func TestLinkAddressAndSymbol(t *testing.T) {
+	links := testLinker().Link(
+		"USDY lists on Coinbase",
+		"The token contract 0x96f6ef951840721adbf46ac996b59e0235cb985c was added to the exchange. Trading in usdy pairs starts Monday.",
+	)
+
+	usdy := find(links, TypeAsset, "asset-usdy")
+	require.NotNil(t, usdy)
+	assert.Equal(t, MatchAddress, usdy.MatchType)
+	assert.Equal(t, 0.95, usdy.Confidence)
+
+	coinbase := find(links, TypeChannel, "channel-coinbase")
+	require.NotNil(t, coinbase)
+	assert.Equal(t, MatchName, coinbase.MatchType)
+	assert.Equal(t, 0.95, coinbase.Confidence)
+
+	// 按置信度降序
+	assert.Equal(t, "asset-usdy", links[0].ID)
+}
+
+func TestLinkSymbolCaseAndBoundaries(t *testing.T) {
+	linker := testLinker()
+
+	// 代码需大写完全一致，且不能是其他词的一部分
+	assert.Empty(t, linker.Link("Must-read: the best trust products", "Adjust your portfolio"))
+	assert.Empty(t, linker.Link("USTB yields rise", ""))
+
+	links := linker.Link("", "UST depegged again.")
+	require.Len(t, links, 1)
+	assert.Equal(t, MatchSymbol, links[0].MatchType)
+	// 三个字符的代码置信度较低
+	assert.Equal(t, 0.55, links[0].Confidence)
+
+	links = linker.Link("", "Holders of OUSG redeemed $20m.")
+	require.Len(t, links, 1)
+	assert.Equal(t, 0.7, links[0].Confidence)
+}
+
+func TestLinkIssuerPropagation(t *testing.T) {
+	links := testLinker().Link("Ondo Finance expands to Solana", "Ondo said the move broadens access.")
+
+	issuer := find(links, TypeIssuer, "ondo finance")
+	require.NotNil(t, issuer)
+	// "Ondo"和"Ondo Finance"重叠，提及次数不重复计算
+	assert.Equal(t, 2, issuer.Mentions)
+	assert.Equal(t, MatchIssuer, issuer.MatchType)
+	assert.Equal(t, 0.95, issuer.Confidence)
+
+	// 发行方的资产以折扣后的置信度关联
+	for _, id := range []string{"asset-usdy", "asset-ousg"} {
+		link := find(links, TypeAsset, id)
+		require.NotNil(t, link, id)
+		assert.Equal(t, MatchIssuer, link.MatchType)
+		assert.Equal(t, 0.66, link.Confidence)
+	}
+
+	// 资产被直接提及时保留直接匹配
+	links = testLinker().Link("Ondo Finance expands to Solana", "USDY will be the first token available.")
+	usdy := find(links, TypeAsset, "asset-usdy")
+	require.NotNil(t, usdy)
+	assert.Equal(t, MatchSymbol, usdy.MatchType)
+}
+
+func TestLinkMultiWordNames(t *testing.T) {
+	links := testLinker().Link("", "The US Dollar\nYield Token now has $400m outstanding.")
+	usdy := find(links, TypeAsset, "asset-usdy")
+	require.NotNil(t, usdy)
+	assert.Equal(t, MatchAlias, usdy.MatchType)
+	assert.Equal(t, "US Dollar Yield Token", usdy.Matched)
+	assert.Equal(t, 0.75, usdy.Confidence)
+}
@@ -0,0 +1,354 @@
+package evmrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Block 区块及其交易收据
+// 节点返回的交易类型无法解码时（如L2的系统交易），对应位置的交易为nil，收据仍按位置对齐
+type Block struct {
+	Number       uint64
+	Hash         common.Hash
+	Header       *types.Header
+	Transactions []*types.Transaction
+	TxHashes     []common.Hash
+	Senders      []common.Address
+	Receipts     []*types.Receipt
+}
+
+// Time 区块时间
+func (b *Block) Time() time.Time {
+	return time.Unix(int64(b.Header.Time), 0)
+}
+
+type rpcBlock struct {
+	Hash         common.Hash      `json:"hash"`
+	Transactions []rpcTransaction `json:"transactions"`
+}
+
+type rpcTransaction struct {
+	tx   *types.Transaction
+	Hash common.Hash    `json:"hash"`
+	From common.Address `json:"from"`
+}
+
+func (t *rpcTransaction) UnmarshalJSON(msg []byte) error {
+	type extra rpcTransaction
+	if err := json.Unmarshal(msg, (*extra)(t)); err != nil {
+		return err
+	}
+
+	var tx types.Transaction
+	if err := json.Unmarshal(msg, &tx); err != nil {
+		if errors.Is(err, types.ErrTxTypeNotSupported) {
+			return nil
+		}
+		return err
+	}
+	t.tx = &tx
+	return nil
+}
+
+// FetchBlocks 获取[from, to]区间内的区块和收据
+// 区块通过批量eth_getBlockByNumber获取；收据优先使用eth_getBlockReceipts，节点不支持时退化为批量eth_getTransactionReceipt。
+// 出错时返回出错位置之前已完整获取的区块
+func (p *Pool) FetchBlocks(ctx context.Context, from, to uint64) ([]*Block, error) {
+	if to < from {
+		return nil, nil
+	}
+
+	numbers := make([]uint64, to-from+1)
+	for i := range numbers {
+		numbers[i] = from + uint64(i)
+	}
+	return p.FetchBlocksByNumber(ctx, numbers)
+}
+
+// FetchBlocksByNumber 按给定顺序获取一组不一定连续的区块和收据，出错时同样返回已完整获取的前缀
+func (p *Pool) FetchBlocksByNumber(ctx context.Context, numbers []uint64) ([]*Block, error) {
+	count := len(numbers)
+	if count == 0 {
+		return nil, nil
+	}
+	blocks := make([]*Block, count)
+
+	// 按批量大小切分区块请求
+	var chunks [][2]int
+	for start := 0; start < count; start += p.opts.BatchSize {
+		chunks = append(chunks, [2]int{start, min(start+p.opts.BatchSize, count)})
+	}
+
+	chunkErrs := p.parallel(ctx, len(chunks), func(ctx context.Context, i int) error {
+		start, end := chunks[i][0], chunks[i][1]
+		fetched, err := p.fetchHeaders(ctx, numbers[start:end])
+		if err != nil {
+			return err
+		}
+		copy(blocks[start:end], fetched)
+		return nil
+	})
+
+	// 只为连续获取成功的区块拉取收据
+	available := count
+	var firstErr error
+	for i, err := range chunkErrs {
+		if err != nil {
+			available = chunks[i][0]
+			firstErr = err
+			break
+		}
+	}
+
+	receiptErrs := p.parallel(ctx, available, func(ctx context.Context, i int) error {
+		return p.fetchReceipts(ctx, blocks[i])
+	})
+	for i, err := range receiptErrs {
+		if err != nil {
+			return blocks[:i], fmt.Errorf("failed to get receipts for block %d: %v", blocks[i].Number, err)
+		}
+	}
+
+	if firstErr != nil {
+		return blocks[:available], fmt.Errorf("failed to get block %d: %v", numbers[available], firstErr)
+	}
+	return blocks, nil
+}
+
+// parallel 以受限并发执行n个任务，返回各任务的错误
+func (p *Pool) parallel(ctx context.Context, n int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, p.opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < n; j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+	return errs
+}
+
+// fetchHeaders 用一个批量请求获取一组区块及交易
+func (p *Pool) fetchHeaders(ctx context.Context, numbers []uint64) ([]*Block, error) {
+	var blocks []*Block
+
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		raws := make([]json.RawMessage, len(numbers))
+		batch := make([]rpc.BatchElem, len(raws))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(numbers[i]), true},
+				Result: &raws[i],
+			}
+		}
+
+		if err := endpoint.client.BatchCallContext(ctx, batch); err != nil {
+			return err
+		}
+
+		decoded := make([]*Block, len(batch))
+		for i, elem := range batch {
+			number := numbers[i]
+			if elem.Error != nil {
+				return fmt.Errorf("block %d: %v", number, elem.Error)
+			}
+
+			block, err := decodeBlock(raws[i])
+			if err != nil {
+				return fmt.Errorf("block %d: %v", number, err)
+			}
+			if block.Number != number {
+				return fmt.Errorf("block %d: node returned block %d", number, block.Number)
+			}
+			decoded[i] = block
+		}
+
+		blocks = decoded
+		return nil
+	})
+
+	return blocks, err
+}
+
+func decodeBlock(raw json.RawMessage) (*Block, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, fmt.Errorf("not found")
+	}
+
+	var header types.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, err
+	}
+
+	var body rpcBlock
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+
+	block := &Block{
+		Number:       header.Number.Uint64(),
+		Hash:         body.Hash,
+		Header:       &header,
+		Transactions: make([]*types.Transaction, len(body.Transactions)),
+		TxHashes:     make([]common.Hash, len(body.Transactions)),
+		Senders:      make([]common.Address, len(body.Transactions)),
+	}
+	for i, tx := range body.Transactions {
+		block.Transactions[i] = tx.tx
+		block.TxHashes[i] = tx.Hash
+		block.Senders[i] = tx.From
+	}
+
+	return block, nil
+}
+
+func (p *Pool) fetchReceipts(ctx context.Context, block *Block) error {
+	if len(block.TxHashes) == 0 {
+		return nil
+	}
+
+	return p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		var receipts []*types.Receipt
+		var err error
+
+		if atomic.LoadInt32(&endpoint.blockReceipts) != receiptsUnsupported {
+			receipts, err = endpoint.blockReceiptsFor(ctx, block)
+			if err != nil && isMethodUnsupported(err) {
+				if atomic.SwapInt32(&endpoint.blockReceipts, receiptsUnsupported) != receiptsUnsupported {
+					p.logger.Infof("RPC endpoint %s for %s does not support eth_getBlockReceipts, falling back to batched receipts", RedactURL(endpoint.url), p.chain)
+				}
+				receipts, err = nil, nil
+			} else if err == nil {
+				atomic.StoreInt32(&endpoint.blockReceipts, receiptsSupported)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if receipts == nil {
+			receipts, err = endpoint.batchReceipts(ctx, block, p.opts.BatchSize)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := checkReceipts(block, receipts); err != nil {
+			return err
+		}
+		block.Receipts = receipts
+		return nil
+	})
+}
+
+func (e *Endpoint) blockReceiptsFor(ctx context.Context, block *Block) ([]*types.Receipt, error) {
+	var receipts []*types.Receipt
+	if err := e.client.CallContext(ctx, &receipts, "eth_getBlockReceipts", hexutil.EncodeUint64(block.Number)); err != nil {
+		return nil, err
+	}
+	if receipts == nil {
+		return nil, fmt.Errorf("receipts for block %d not found", block.Number)
+	}
+	return receipts, nil
+}
+
+// batchReceipts 将每笔交易的收据请求合并为批量请求
+func (e *Endpoint) batchReceipts(ctx context.Context, block *Block, batchSize int) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(block.TxHashes))
+
+	for start := 0; start < len(block.TxHashes); start += batchSize {
+		end := min(start+batchSize, len(block.TxHashes))
+
+		batch := make([]rpc.BatchElem, end-start)
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{block.TxHashes[start+i]},
+				Result: &receipts[start+i],
+			}
+		}
+
+		if err := e.client.BatchCallContext(ctx, batch); err != nil {
+			return nil, err
+		}
+		for i, elem := range batch {
+			if elem.Error != nil {
+				return nil, fmt.Errorf("receipt %s: %v", block.TxHashes[start+i].Hex(), elem.Error)
+			}
+		}
+	}
+
+	return receipts, nil
+}
+
+// checkReceipts 校验收据与区块交易一一对应，防止节点在重组期间返回不一致的数据
+func checkReceipts(block *Block, receipts []*types.Receipt) error {
+	if len(receipts) != len(block.TxHashes) {
+		return fmt.Errorf("block %d has %d transactions but %d receipts", block.Number, len(block.TxHashes), len(receipts))
+	}
+
+	for i, receipt := range receipts {
+		if receipt == nil {
+			return fmt.Errorf("receipt %s not found", block.TxHashes[i].Hex())
+		}
+		if receipt.TxHash != block.TxHashes[i] {
+			return fmt.Errorf("receipt %d of block %d is for %s, expected %s", i, block.Number, receipt.TxHash.Hex(), block.TxHashes[i].Hex())
+		}
+		if receipt.BlockHash != (common.Hash{}) && receipt.BlockHash != block.Hash {
+			return fmt.Errorf("receipt %s belongs to block %s, expected %s", receipt.TxHash.Hex(), receipt.BlockHash.Hex(), block.Hash.Hex())
+		}
+	}
+
+	return nil
+}
+
+// isMethodUnsupported 判断节点是否不支持该RPC方法
+func isMethodUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, hint := range []string{"method not found", "not supported", "unsupported", "does not exist", "not available"} {
+		if strings.Contains(message, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
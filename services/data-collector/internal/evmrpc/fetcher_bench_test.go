@@ -0,0 +1,87 @@
+package evmrpc
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// 基准测试参数：模拟一次HTTP往返约1ms的本地节点
+const (
+	benchBlocks      = 20
+	benchTxsPerBlock = 50
+	benchLatency     = time.Millisecond
+)
+
+func benchmarkFetchBlocks(b *testing.B, supportsBlockReceipts bool, opts Options) {
+	node := newStubNode(b, benchBlocks, benchTxsPerBlock, supportsBlockReceipts)
+	node.latency = benchLatency
+
+	pool, err := Dial(context.Background(), "ethereum", []string{node.URL()}, opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer pool.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		blocks, err := pool.FetchBlocks(context.Background(), 0, benchBlocks-1)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(blocks) != benchBlocks {
+			b.Fatalf("fetched %d blocks", len(blocks))
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N*benchBlocks)/b.Elapsed().Seconds(), "blocks/s")
+	b.ReportMetric(float64(node.requests())/float64(b.N), "requests/op")
+}
+
+func BenchmarkFetchBlocks(b *testing.B) {
+	b.Run("BlockReceipts", func(b *testing.B) {
+		benchmarkFetchBlocks(b, true, Options{BatchSize: 20, Concurrency: 4})
+	})
+	b.Run("BatchedReceipts", func(b *testing.B) {
+		benchmarkFetchBlocks(b, false, Options{BatchSize: 20, Concurrency: 4})
+	})
+	b.Run("BlockReceiptsSequential", func(b *testing.B) {
+		benchmarkFetchBlocks(b, true, Options{BatchSize: 20, Concurrency: 1})
+	})
+}
+
+// BenchmarkFetchBlocksPerTransaction 旧实现：逐块BlockByNumber并为每笔交易单独请求收据
+func BenchmarkFetchBlocksPerTransaction(b *testing.B) {
+	node := newStubNode(b, benchBlocks, benchTxsPerBlock, true)
+	node.latency = benchLatency
+
+	client, err := ethclient.Dial(node.URL())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for number := uint64(0); number < benchBlocks; number++ {
+			block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, tx := range block.Transactions() {
+				if _, err := client.TransactionReceipt(ctx, tx.Hash()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N*benchBlocks)/b.Elapsed().Seconds(), "blocks/s")
+	b.ReportMetric(float64(node.requests())/float64(b.N), "requests/op")
+}
@@ -0,0 +1,180 @@
+package evmrpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialStub(t *testing.T, opts Options, nodes ...*stubNode) *Pool {
+	t.Helper()
+
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		urls[i] = node.URL()
+	}
+
+	pool, err := Dial(context.Background(), "ethereum", urls, opts)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func assertBlocks(t *testing.T, blocks []*Block, from uint64, txsPerBlock int) {
+	t.Helper()
+
+	for i, block := range blocks {
+		assert.Equal(t, from+uint64(i), block.Number)
+		require.Len(t, block.Transactions, txsPerBlock)
+		require.Len(t, block.Receipts, txsPerBlock)
+		for j, tx := range block.Transactions {
+			require.NotNil(t, tx)
+			assert.Equal(t, tx.Hash(), block.Receipts[j].TxHash)
+			assert.Equal(t, stubSender, block.Senders[j])
+			assert.Equal(t, block.Hash, block.Receipts[j].BlockHash)
+			require.Len(t, block.Receipts[j].Logs, 1)
+		}
+	}
+}
+
+func TestFetchBlocksWithBlockReceipts(t *testing.T) {
+	node := newStubNode(t, 10, 5, true)
+	pool := dialStub(t, Options{BatchSize: 10, Concurrency: 2}, node)
+
+	blocks, err := pool.FetchBlocks(context.Background(), 0, 9)
+	require.NoError(t, err)
+	require.Len(t, blocks, 10)
+	assertBlocks(t, blocks, 0, 5)
+
+	// 1个区块批量请求 + 每个区块1个eth_getBlockReceipts
+	assert.Equal(t, int64(11), node.requests())
+
+	status := pool.Status()
+	require.Len(t, status, 1)
+	require.NotNil(t, status[0].BlockReceipts)
+	assert.True(t, *status[0].BlockReceipts)
+}
+
+func TestFetchBlocksFallsBackToBatchedReceipts(t *testing.T) {
+	node := newStubNode(t, 6, 7, false)
+	pool := dialStub(t, Options{BatchSize: 3, Concurrency: 1}, node)
+
+	blocks, err := pool.FetchBlocks(context.Background(), 1, 5)
+	require.NoError(t, err)
+	require.Len(t, blocks, 5)
+	assertBlocks(t, blocks, 1, 7)
+
+	status := pool.Status()
+	require.NotNil(t, status[0].BlockReceipts)
+	assert.False(t, *status[0].BlockReceipts)
+	assert.True(t, status[0].Healthy)
+}
+
+func TestFetchBlocksFailsOver(t *testing.T) {
+	primary := newStubNode(t, 4, 2, true)
+	secondary := newStubNode(t, 4, 2, true)
+	primary.setFailing(true)
+
+	pool := dialStub(t, Options{BatchSize: 4, Concurrency: 1}, primary, secondary)
+
+	blocks, err := pool.FetchBlocks(context.Background(), 0, 3)
+	require.NoError(t, err)
+	assertBlocks(t, blocks, 0, 2)
+
+	status := pool.Status()
+	assert.False(t, status[0].Healthy)
+	assert.Equal(t, 1, status[0].ConsecutiveFailures)
+	assert.True(t, status[1].Healthy)
+
+	// 主节点冷却期间直接使用备用节点
+	before := primary.requests()
+	head, err := pool.BlockNumber(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), head)
+	assert.Equal(t, before, primary.requests())
+}
+
+func TestFetchBlocksReturnsPrefixOnError(t *testing.T) {
+	node := newStubNode(t, 5, 1, true)
+	pool := dialStub(t, Options{BatchSize: 2, Concurrency: 2}, node)
+
+	blocks, err := pool.FetchBlocks(context.Background(), 0, 7)
+	require.Error(t, err)
+	require.Len(t, blocks, 4)
+	assertBlocks(t, blocks, 0, 1)
+}
+
+func TestFetchBlocksEmptyBlocks(t *testing.T) {
+	node := newStubNode(t, 3, 0, false)
+	pool := dialStub(t, Options{}, node)
+
+	blocks, err := pool.FetchBlocks(context.Background(), 0, 2)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	for _, block := range blocks {
+		assert.Empty(t, block.Transactions)
+	}
+}
+
+func TestFetchBlocksByNumber(t *testing.T) {
+	node := newStubNode(t, 10, 2, true)
+	pool := dialStub(t, Options{BatchSize: 2, Concurrency: 2}, node)
+
+	blocks, err := pool.FetchBlocksByNumber(context.Background(), []uint64{7, 2, 5})
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	assert.Equal(t, uint64(7), blocks[0].Number)
+	assert.Equal(t, uint64(2), blocks[1].Number)
+	assert.Equal(t, uint64(5), blocks[2].Number)
+	assertBlocks(t, blocks[1:2], 2, 2)
+}
+
+func TestBlockAtTime(t *testing.T) {
+	node := newStubNode(t, 10, 0, true)
+	pool := dialStub(t, Options{}, node)
+	ctx := context.Background()
+
+	// 区块时间为1700000000 + number*12
+	number, err := pool.BlockAtTime(ctx, time.Unix(1700000000+40, 0), 9)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), number)
+
+	number, err = pool.BlockAtTime(ctx, time.Unix(1700000000+48, 0), 9)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), number)
+
+	number, err = pool.BlockAtTime(ctx, time.Unix(1600000000, 0), 9)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), number)
+
+	number, err = pool.BlockAtTime(ctx, time.Unix(1800000000, 0), 9)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), number)
+}
+
+func TestVerifyChainID(t *testing.T) {
+	node := newStubNode(t, 1, 0, true)
+	down := newStubNode(t, 1, 0, true)
+	down.setFailing(true)
+	pool := dialStub(t, Options{}, down, node)
+	ctx := context.Background()
+
+	require.NoError(t, pool.VerifyChainID(ctx, stubChainID.Uint64()))
+
+	err := pool.VerifyChainID(ctx, 10)
+	assert.ErrorIs(t, err, ErrChainIDMismatch)
+
+	node.setFailing(true)
+	err = pool.VerifyChainID(ctx, stubChainID.Uint64())
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrChainIDMismatch)
+}
+
+func TestParseURLs(t *testing.T) {
+	assert.Equal(t, []string{"https://a.example", "https://b.example/v2/key"}, ParseURLs(" https://a.example, ,https://b.example/v2/key"))
+	assert.Nil(t, ParseURLs(""))
+	assert.Equal(t, "https://b.example", RedactURL("https://b.example/v2/key"))
+}
@@ -0,0 +1,75 @@
+package evmrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// HeaderByNumber 获取区块头（不含交易）
+func (p *Pool) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	var header *types.Header
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		return endpoint.client.CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return header, nil
+}
+
+// BlockAtTime 二分查找时间戳不早于t的第一个区块，t晚于最新区块时返回head+1
+func (p *Pool) BlockAtTime(ctx context.Context, t time.Time, head uint64) (uint64, error) {
+	target := uint64(t.Unix())
+	low, high := uint64(0), head+1
+
+	for low < high {
+		mid := low + (high-low)/2
+		header, err := p.HeaderByNumber(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if header.Time < target {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+
+	return low, nil
+}
+
+// FilterLogs 获取[from, to]区间内指定合约的日志，topics为空时不过滤事件类型
+// 节点返回的JSON-RPC错误（如区间或结果数超限）不会触发故障切换，由调用方缩小区间重试
+func (p *Pool) FilterLogs(ctx context.Context, from, to uint64, addresses []common.Address, topics [][]common.Hash) ([]types.Log, error) {
+	filter := map[string]interface{}{
+		"fromBlock": hexutil.EncodeUint64(from),
+		"toBlock":   hexutil.EncodeUint64(to),
+	}
+	if len(addresses) > 0 {
+		filter["address"] = addresses
+	}
+	if len(topics) > 0 {
+		filter["topics"] = topics
+	}
+
+	var logs []types.Log
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		err := endpoint.client.CallContext(ctx, &logs, "eth_getLogs", filter)
+		var rpcErr rpc.Error
+		if err != nil && errors.As(err, &rpcErr) {
+			return Permanent(err)
+		}
+		return err
+	})
+	return logs, err
+}
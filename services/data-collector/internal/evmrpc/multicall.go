@@ -0,0 +1,150 @@
+package evmrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Multicall3Address 在主流EVM链上均以相同地址部署
+var Multicall3Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+const multicall3ABI = `[{"inputs":[{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bool","name":"allowFailure","type":"bool"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall3.Call3[]","name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall3.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"}]`
+
+var multicallABI = mustParseABI(multicall3ABI)
+
+// Call 单个只读合约调用
+type Call struct {
+	Target   common.Address
+	CallData []byte
+}
+
+// CallResult 合约调用结果，Success为false时ReturnData为revert数据
+type CallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// Aggregate 通过Multicall3将多个eth_call合并为一次调用，单个调用失败不影响其他调用
+// blockNumber为nil时查询最新区块
+func (p *Pool) Aggregate(ctx context.Context, calls []Call, blockNumber *big.Int) ([]CallResult, error) {
+	if len(calls) == 0 {
+		return nil, nil
+	}
+
+	args := make([]call3, len(calls))
+	for i, c := range calls {
+		args[i] = call3{Target: c.Target, AllowFailure: true, CallData: c.CallData}
+	}
+
+	input, err := multicallABI.Pack("aggregate3", args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack multicall: %v", err)
+	}
+
+	output, err := p.Call(ctx, Multicall3Address, input, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	// 链上未部署Multicall3时逐个调用
+	if len(output) == 0 {
+		return p.callEach(ctx, calls, blockNumber)
+	}
+
+	unpacked, err := multicallABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack multicall: %v", err)
+	}
+
+	results := *abi.ConvertType(unpacked[0], new([]CallResult)).(*[]CallResult)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
+
+func (p *Pool) callEach(ctx context.Context, calls []Call, blockNumber *big.Int) ([]CallResult, error) {
+	results := make([]CallResult, len(calls))
+	for i, c := range calls {
+		output, err := p.Call(ctx, c.Target, c.CallData, blockNumber)
+		if err != nil {
+			if !isRevert(err) {
+				return nil, err
+			}
+			continue
+		}
+		results[i] = CallResult{Success: true, ReturnData: output}
+	}
+	return results, nil
+}
+
+// Call 执行单个eth_call
+func (p *Pool) Call(ctx context.Context, to common.Address, data []byte, blockNumber *big.Int) ([]byte, error) {
+	block := blockTag(blockNumber)
+
+	var result hexutil.Bytes
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		err := endpoint.client.CallContext(ctx, &result, "eth_call", map[string]interface{}{
+			"to":   to,
+			"data": hexutil.Bytes(data),
+		}, block)
+		// 合约revert是调用本身的结果，不应切换节点重试
+		if err != nil && isRevert(err) {
+			return Permanent(err)
+		}
+		return err
+	})
+	return result, err
+}
+
+// CodeAt 获取合约字节码，外部账户返回空
+func (p *Pool) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	var code hexutil.Bytes
+	err := p.CallContext(ctx, &code, "eth_getCode", account, blockTag(blockNumber))
+	return code, err
+}
+
+// StorageAt 读取合约存储槽
+func (p *Pool) StorageAt(ctx context.Context, account common.Address, slot common.Hash, blockNumber *big.Int) (common.Hash, error) {
+	var value hexutil.Bytes
+	if err := p.CallContext(ctx, &value, "eth_getStorageAt", account, slot, blockTag(blockNumber)); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(value), nil
+}
+
+func blockTag(blockNumber *big.Int) string {
+	if blockNumber == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(blockNumber)
+}
+
+func isRevert(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
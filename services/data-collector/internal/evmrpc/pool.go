@@ -0,0 +1,323 @@
+package evmrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/sirupsen/logrus"
+)
+
+// 节点故障后的冷却时间，连续失败时指数增长
+const (
+	minCooldown = 5 * time.Second
+	maxCooldown = 2 * time.Minute
+)
+
+// eth_getBlockReceipts支持状态
+const (
+	receiptsUnknown int32 = iota
+	receiptsSupported
+	receiptsUnsupported
+)
+
+// Options 批量获取参数
+type Options struct {
+	BatchSize   int // 单个批量请求包含的调用数
+	Concurrency int // 同时进行中的请求数
+}
+
+// Endpoint 单个RPC节点
+type Endpoint struct {
+	url    string
+	client *rpc.Client
+
+	mu        sync.Mutex
+	failures  int
+	downUntil time.Time
+	lastError string
+
+	blockReceipts int32
+}
+
+// EndpointStatus 节点健康状态
+type EndpointStatus struct {
+	URL                 string     `json:"url"`
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DownUntil           *time.Time `json:"down_until,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	BlockReceipts       *bool      `json:"block_receipts,omitempty"`
+}
+
+// Pool 同一条链的多个RPC节点，按配置顺序优先使用，失败时切换到下一个
+type Pool struct {
+	chain     string
+	endpoints []*Endpoint
+	opts      Options
+	logger    *logrus.Logger
+}
+
+// ParseURLs 解析逗号分隔的RPC地址列表
+func ParseURLs(value string) []string {
+	var urls []string
+	for _, url := range strings.Split(value, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+// Dial 连接链的所有RPC节点，单个节点连接失败不影响其他节点
+func Dial(ctx context.Context, chain string, urls []string, opts Options) (*Pool, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 20
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	pool := &Pool{
+		chain:  chain,
+		opts:   opts,
+		logger: logrus.New(),
+	}
+
+	var lastErr error
+	for _, url := range urls {
+		client, err := rpc.DialContext(ctx, url)
+		if err != nil {
+			lastErr = err
+			pool.logger.Errorf("Failed to connect to %s endpoint %s: %v", chain, RedactURL(url), err)
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &Endpoint{url: url, client: client})
+	}
+
+	if len(pool.endpoints) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no rpc urls configured")
+		}
+		return nil, fmt.Errorf("failed to connect to %s: %v", chain, lastErr)
+	}
+
+	return pool, nil
+}
+
+// Chain 链名称
+func (p *Pool) Chain() string {
+	return p.chain
+}
+
+// Close 关闭所有节点连接
+func (p *Pool) Close() {
+	for _, endpoint := range p.endpoints {
+		endpoint.client.Close()
+	}
+}
+
+// Status 返回各节点的健康状态
+func (p *Pool) Status() []EndpointStatus {
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	now := time.Now()
+
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		status := EndpointStatus{
+			URL:                 RedactURL(endpoint.url),
+			Healthy:             !now.Before(endpoint.downUntil),
+			ConsecutiveFailures: endpoint.failures,
+			LastError:           endpoint.lastError,
+		}
+		if !status.Healthy {
+			downUntil := endpoint.downUntil
+			status.DownUntil = &downUntil
+		}
+		endpoint.mu.Unlock()
+
+		switch atomic.LoadInt32(&endpoint.blockReceipts) {
+		case receiptsSupported:
+			supported := true
+			status.BlockReceipts = &supported
+		case receiptsUnsupported:
+			supported := false
+			status.BlockReceipts = &supported
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// permanentError 与节点无关的错误，不触发故障切换
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误与节点无关，Do遇到时直接返回而不切换节点
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Do 依次在节点上执行fn直到成功；处于冷却期的节点只在其他节点都失败后才会尝试
+func (p *Pool) Do(ctx context.Context, fn func(ctx context.Context, endpoint *Endpoint) error) error {
+	var lastErr error
+	for _, endpoint := range p.candidates() {
+		err := fn(ctx, endpoint)
+		if err == nil {
+			endpoint.markSuccess()
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			endpoint.markSuccess()
+			return permanent.err
+		}
+
+		lastErr = err
+		cooldown := endpoint.markFailure(err)
+		p.logger.Warnf("RPC endpoint %s for %s failed, cooling down for %v: %v", RedactURL(endpoint.url), p.chain, cooldown, err)
+	}
+
+	return fmt.Errorf("all %d %s endpoints failed: %v", len(p.endpoints), p.chain, lastErr)
+}
+
+// candidates 健康节点按配置顺序在前，冷却中的节点按恢复时间在后
+func (p *Pool) candidates() []*Endpoint {
+	now := time.Now()
+	healthy := make([]*Endpoint, 0, len(p.endpoints))
+	var cooling []*Endpoint
+
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		down := now.Before(endpoint.downUntil)
+		endpoint.mu.Unlock()
+
+		if down {
+			cooling = append(cooling, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+
+	sort.SliceStable(cooling, func(i, j int) bool { return cooling[i].recoversBefore(cooling[j]) })
+
+	return append(healthy, cooling...)
+}
+
+// BlockNumber 获取最新区块号
+func (p *Pool) BlockNumber(ctx context.Context) (uint64, error) {
+	var number hexutil.Uint64
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		return endpoint.client.CallContext(ctx, &number, "eth_blockNumber")
+	})
+	return uint64(number), err
+}
+
+// ChainID 获取节点返回的链ID
+func (p *Pool) ChainID(ctx context.Context) (uint64, error) {
+	var chainID hexutil.Uint64
+	err := p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		return endpoint.client.CallContext(ctx, &chainID, "eth_chainId")
+	})
+	return uint64(chainID), err
+}
+
+// ErrChainIDMismatch 节点返回的链ID与配置不一致
+var ErrChainIDMismatch = errors.New("rpc chain id mismatch")
+
+// VerifyChainID 逐个校验节点返回的链ID，任一节点不一致时返回ErrChainIDMismatch
+// 不可达的节点跳过，全部不可达时返回最后的连接错误
+func (p *Pool) VerifyChainID(ctx context.Context, expected uint64) error {
+	verified := 0
+	var lastErr error
+	for _, endpoint := range p.endpoints {
+		var chainID hexutil.Uint64
+		if err := endpoint.client.CallContext(ctx, &chainID, "eth_chainId"); err != nil {
+			lastErr = err
+			p.logger.Warnf("Failed to get chain id from %s endpoint %s: %v", p.chain, RedactURL(endpoint.url), err)
+			continue
+		}
+		if uint64(chainID) != expected {
+			return fmt.Errorf("%w: %s endpoint %s returned %d, expected %d", ErrChainIDMismatch, p.chain, RedactURL(endpoint.url), uint64(chainID), expected)
+		}
+		verified++
+	}
+
+	if verified == 0 {
+		return fmt.Errorf("failed to verify chain id of %s: %v", p.chain, lastErr)
+	}
+	return nil
+}
+
+// CallContext 在可用节点上执行任意RPC调用
+func (p *Pool) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return p.Do(ctx, func(ctx context.Context, endpoint *Endpoint) error {
+		return endpoint.client.CallContext(ctx, result, method, args...)
+	})
+}
+
+func (e *Endpoint) markSuccess() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures = 0
+	e.downUntil = time.Time{}
+	e.lastError = ""
+}
+
+func (e *Endpoint) markFailure(err error) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures++
+	cooldown := minCooldown
+	for i := 1; i < e.failures && cooldown < maxCooldown; i++ {
+		cooldown *= 2
+	}
+	if cooldown > maxCooldown {
+		cooldown = maxCooldown
+	}
+
+	e.downUntil = time.Now().Add(cooldown)
+	e.lastError = err.Error()
+	return cooldown
+}
+
+func (e *Endpoint) recoversBefore(other *Endpoint) bool {
+	e.mu.Lock()
+	downUntil := e.downUntil
+	e.mu.Unlock()
+
+	other.mu.Lock()
+	defer other.mu.Unlock()
+	return downUntil.Before(other.downUntil)
+}
+
+// RedactURL 去掉路径和参数，避免在日志中泄露API key
+func RedactURL(url string) string {
+	schemeEnd := strings.Index(url, "://")
+	if schemeEnd < 0 {
+		return url
+	}
+	if slash := strings.Index(url[schemeEnd+3:], "/"); slash >= 0 {
+		return url[:schemeEnd+3+slash]
+	}
+	return url
+}
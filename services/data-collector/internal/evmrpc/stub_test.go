@@ -0,0 +1,299 @@
+package evmrpc
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	stubKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	stubSender   = crypto.PubkeyToAddress(stubKey.PublicKey)
+	stubToken    = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	transferSig  = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	stubChainID  = big.NewInt(1)
+	stubBlockGas = uint64(30000000)
+)
+
+// stubNode 内存中的JSON-RPC节点，用于测试和基准测试
+type stubNode struct {
+	server *httptest.Server
+
+	blocks        map[uint64]json.RawMessage
+	blockReceipts map[uint64]json.RawMessage
+	receipts      map[common.Hash]json.RawMessage
+	head          uint64
+
+	supportsBlockReceipts bool
+	latency               time.Duration
+	failing               int32
+	httpRequests          int64
+}
+
+type stubRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type stubResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *stubError      `json:"error,omitempty"`
+}
+
+type stubError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newStubNode 生成numBlocks个区块，每个区块包含txsPerBlock笔带ERC-20 Transfer日志的交易
+func newStubNode(tb testing.TB, numBlocks, txsPerBlock int, supportsBlockReceipts bool) *stubNode {
+	tb.Helper()
+
+	node := &stubNode{
+		blocks:                make(map[uint64]json.RawMessage),
+		blockReceipts:         make(map[uint64]json.RawMessage),
+		receipts:              make(map[common.Hash]json.RawMessage),
+		supportsBlockReceipts: supportsBlockReceipts,
+	}
+
+	signer := types.NewEIP155Signer(stubChainID)
+	parent := common.Hash{}
+	nonce := uint64(0)
+
+	for number := uint64(0); number < uint64(numBlocks); number++ {
+		var txs []*types.Transaction
+		for i := 0; i < txsPerBlock; i++ {
+			tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: big.NewInt(1000000000),
+				Gas:      60000,
+				To:       &stubToken,
+				Value:    big.NewInt(0),
+				Data:     common.Hex2Bytes("a9059cbb"),
+			}), signer, stubKey)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			nonce++
+			txs = append(txs, tx)
+		}
+
+		header := &types.Header{
+			ParentHash: parent,
+			UncleHash:  types.EmptyUncleHash,
+			Root:       common.Hash{},
+			TxHash:     types.EmptyTxsHash,
+			Difficulty: big.NewInt(0),
+			Number:     new(big.Int).SetUint64(number),
+			GasLimit:   stubBlockGas,
+			GasUsed:    uint64(len(txs)) * 50000,
+			Time:       1700000000 + number*12,
+		}
+		if len(txs) > 0 {
+			header.TxHash = crypto.Keccak256Hash(header.Number.Bytes())
+		}
+		blockHash := header.Hash()
+		parent = blockHash
+
+		var txJSON []map[string]interface{}
+		var receipts []json.RawMessage
+		for i, tx := range txs {
+			fields := mustMap(tb, tx)
+			fields["from"] = stubSender
+			fields["blockHash"] = blockHash
+			fields["blockNumber"] = hexutil.EncodeUint64(number)
+			fields["transactionIndex"] = hexutil.EncodeUint64(uint64(i))
+			txJSON = append(txJSON, fields)
+
+			receipt := &types.Receipt{
+				Type:              tx.Type(),
+				Status:            types.ReceiptStatusSuccessful,
+				CumulativeGasUsed: uint64(i+1) * 50000,
+				TxHash:            tx.Hash(),
+				GasUsed:           50000,
+				BlockHash:         blockHash,
+				BlockNumber:       header.Number,
+				TransactionIndex:  uint(i),
+				Logs: []*types.Log{{
+					Address:     stubToken,
+					Topics:      []common.Hash{transferSig, common.BytesToHash(stubSender.Bytes()), common.BytesToHash(stubToken.Bytes())},
+					Data:        common.BigToHash(big.NewInt(int64(i + 1))).Bytes(),
+					BlockNumber: number,
+					TxHash:      tx.Hash(),
+					TxIndex:     uint(i),
+					BlockHash:   blockHash,
+					Index:       uint(i),
+				}},
+			}
+			receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+			raw, err := json.Marshal(receipt)
+			if err != nil {
+				tb.Fatal(err)
+			}
+			node.receipts[tx.Hash()] = raw
+			receipts = append(receipts, raw)
+		}
+
+		block := mustMap(tb, header)
+		if txJSON == nil {
+			txJSON = []map[string]interface{}{}
+		}
+		block["transactions"] = txJSON
+		block["uncles"] = []common.Hash{}
+
+		raw, err := json.Marshal(block)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		node.blocks[number] = raw
+
+		if receipts == nil {
+			receipts = []json.RawMessage{}
+		}
+		if raw, err = json.Marshal(receipts); err != nil {
+			tb.Fatal(err)
+		}
+		node.blockReceipts[number] = raw
+		node.head = number
+	}
+
+	node.server = httptest.NewServer(http.HandlerFunc(node.serveHTTP))
+	tb.Cleanup(node.server.Close)
+	return node
+}
+
+func (n *stubNode) URL() string {
+	return n.server.URL
+}
+
+func (n *stubNode) setFailing(failing bool) {
+	value := int32(0)
+	if failing {
+		value = 1
+	}
+	atomic.StoreInt32(&n.failing, value)
+}
+
+func (n *stubNode) requests() int64 {
+	return atomic.LoadInt64(&n.httpRequests)
+}
+
+func (n *stubNode) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&n.httpRequests, 1)
+	if n.latency > 0 {
+		time.Sleep(n.latency)
+	}
+	if atomic.LoadInt32(&n.failing) == 1 {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) == 0 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if body[0] == '[' {
+		var requests []stubRequest
+		if err := json.Unmarshal(body, &requests); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses := make([]stubResponse, len(requests))
+		for i, req := range requests {
+			responses[i] = n.handle(req)
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+		return
+	}
+
+	var req stubRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(n.handle(req))
+}
+
+func (n *stubNode) handle(req stubRequest) stubResponse {
+	resp := stubResponse{JSONRPC: "2.0", ID: req.ID}
+
+	switch req.Method {
+	case "eth_chainId":
+		resp.Result = hexutil.EncodeBig(stubChainID)
+	case "eth_blockNumber":
+		resp.Result = hexutil.EncodeUint64(n.head)
+	case "eth_getBlockByNumber":
+		if raw, ok := n.blocks[n.blockParam(req)]; ok {
+			resp.Result = raw
+		} else {
+			resp.Result = json.RawMessage("null")
+		}
+	case "eth_getBlockReceipts":
+		if !n.supportsBlockReceipts {
+			resp.Error = &stubError{Code: -32601, Message: "the method eth_getBlockReceipts does not exist/is not available"}
+			break
+		}
+		if raw, ok := n.blockReceipts[n.blockParam(req)]; ok {
+			resp.Result = raw
+		} else {
+			resp.Result = json.RawMessage("null")
+		}
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		if len(req.Params) > 0 {
+			_ = json.Unmarshal(req.Params[0], &hash)
+		}
+		if raw, ok := n.receipts[hash]; ok {
+			resp.Result = raw
+		} else {
+			resp.Result = json.RawMessage("null")
+		}
+	default:
+		resp.Error = &stubError{Code: -32601, Message: "the method " + req.Method + " does not exist/is not available"}
+	}
+
+	return resp
+}
+
+func (n *stubNode) blockParam(req stubRequest) uint64 {
+	if len(req.Params) == 0 {
+		return 0
+	}
+	var number hexutil.Uint64
+	if err := json.Unmarshal(req.Params[0], &number); err != nil {
+		return ^uint64(0)
+	}
+	return uint64(number)
+}
+
+func mustMap(tb testing.TB, value interface{}) map[string]interface{} {
+	tb.Helper()
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		tb.Fatal(err)
+	}
+	return fields
+}
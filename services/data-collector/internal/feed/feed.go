@@ -0,0 +1,320 @@
+// Package feed 解析RSS 2.0、RSS 1.0(RDF)和Atom订阅源，支持基于ETag/Last-Modified的条件请求
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrUnknownFormat 文档不是RSS或Atom
+var ErrUnknownFormat = errors.New("unknown feed format")
+
+// Feed 订阅源内容，RSS与Atom统一为同一结构
+type Feed struct {
+	Title    string
+	Link     string
+	Language string
+	Items    []Item
+}
+
+// Item 订阅源条目，文本字段已去除HTML标签；Published未知时为零值
+type Item struct {
+	GUID       string
+	Title      string
+	Link       string
+	Summary    string
+	Content    string
+	Author     string
+	Categories []string
+	Published  time.Time
+}
+
+// rssItem 中的link可能混有空的atom:link（rel=self），取第一个非空值
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Links       []string `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	GUID        string   `xml:"guid"`
+	Categories  []string `xml:"category"`
+}
+
+type rssChannel struct {
+	Title    string    `xml:"title"`
+	Links    []string  `xml:"link"`
+	Language string    `xml:"language"`
+	Items    []rssItem `xml:"item"`
+}
+
+// rssDocument RSS 2.0的条目在channel内，RSS 1.0的条目与channel同级
+type rssDocument struct {
+	Channel rssChannel `xml:"channel"`
+	Items   []rssItem  `xml:"item"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term  string `xml:"term,attr"`
+		Label string `xml:"label,attr"`
+	} `xml:"category"`
+}
+
+type atomDocument struct {
+	Title   atomText    `xml:"title"`
+	Lang    string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// Parse 按根元素识别格式并解析订阅源
+func Parse(r io.Reader) (*Feed, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	decoder.CharsetReader = charsetReader
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, ErrUnknownFormat
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid feed: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch strings.ToLower(start.Name.Local) {
+		case "rss", "rdf":
+			var doc rssDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, fmt.Errorf("invalid rss feed: %v", err)
+			}
+			return doc.feed(), nil
+		case "feed":
+			var doc atomDocument
+			if err := decoder.DecodeElement(&doc, &start); err != nil {
+				return nil, fmt.Errorf("invalid atom feed: %v", err)
+			}
+			return doc.feed(), nil
+		default:
+			return nil, fmt.Errorf("%w: root element <%s>", ErrUnknownFormat, start.Name.Local)
+		}
+	}
+}
+
+func (d *rssDocument) feed() *Feed {
+	feed := &Feed{
+		Title:    cleanText(d.Channel.Title),
+		Link:     strings.TrimSpace(firstNonEmpty(d.Channel.Links...)),
+		Language: normalizeLanguage(d.Channel.Language),
+	}
+
+	for _, item := range append(d.Channel.Items, d.Items...) {
+		entry := Item{
+			GUID:    strings.TrimSpace(item.GUID),
+			Title:   cleanText(item.Title),
+			Link:    strings.TrimSpace(firstNonEmpty(item.Links...)),
+			Summary: cleanText(item.Description),
+			Content: cleanText(item.Content),
+			Author:  cleanText(firstNonEmpty(item.Creator, item.Author)),
+		}
+		for _, category := range item.Categories {
+			if category = cleanText(category); category != "" {
+				entry.Categories = append(entry.Categories, category)
+			}
+		}
+		entry.Published = parseTime(firstNonEmpty(item.PubDate, item.Date))
+		// guid默认isPermaLink=true，没有link时可以作为链接
+		if entry.Link == "" && isURL(entry.GUID) {
+			entry.Link = entry.GUID
+		}
+		if entry.GUID == "" {
+			entry.GUID = entry.Link
+		}
+		feed.Items = append(feed.Items, entry)
+	}
+	return feed
+}
+
+func (d *atomDocument) feed() *Feed {
+	feed := &Feed{
+		Title:    cleanText(d.Title.String()),
+		Link:     atomAlternate(d.Links),
+		Language: normalizeLanguage(d.Lang),
+	}
+
+	for _, entry := range d.Entries {
+		item := Item{
+			GUID:    strings.TrimSpace(entry.ID),
+			Title:   cleanText(entry.Title.String()),
+			Link:    atomAlternate(entry.Links),
+			Summary: cleanText(entry.Summary.String()),
+			Content: cleanText(entry.Content.String()),
+		}
+		var authors []string
+		for _, author := range entry.Authors {
+			if name := cleanText(author.Name); name != "" {
+				authors = append(authors, name)
+			}
+		}
+		item.Author = strings.Join(authors, ", ")
+		for _, category := range entry.Categories {
+			if term := cleanText(firstNonEmpty(category.Label, category.Term)); term != "" {
+				item.Categories = append(item.Categories, term)
+			}
+		}
+		item.Published = parseTime(firstNonEmpty(entry.Published, entry.Updated))
+		if item.GUID == "" {
+			item.GUID = item.Link
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed
+}
+
+// atomAlternate 返回rel=alternate（或未指定rel）的链接
+func atomAlternate(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// 发布时间的常见格式，RSS规范要求RFC822，但实际订阅源格式很杂
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04:05 -0700",
+	"02 Jan 2006 15:04:05 MST",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	tagPattern        = regexp.MustCompile(`(?s)<[^>]*>`)
+	scriptPattern     = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// cleanText 去除HTML标签、反转义实体并合并空白
+func cleanText(value string) string {
+	if value == "" {
+		return ""
+	}
+	value = scriptPattern.ReplaceAllString(value, " ")
+	value = tagPattern.ReplaceAllString(value, " ")
+	value = html.UnescapeString(value)
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(value, " "))
+}
+
+// normalizeLanguage 将en-US等语言标签转为小写的主语言代码
+func normalizeLanguage(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, "-_"); i > 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func isURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+// charsetReader 支持常见的单字节编码，其余编码直接报错
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		buf.Grow(len(data))
+		for _, b := range data {
+			if b < utf8.RuneSelf {
+				buf.WriteByte(b)
+			} else {
+				buf.WriteRune(rune(b))
+			}
+		}
+		return &buf, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
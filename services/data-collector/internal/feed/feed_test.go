@@ -0,0 +1,190 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseFixture(t *testing.T, name string) *Feed {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	defer file.Close()
+
+	feed, err := Parse(file)
+	require.NoError(t, err)
+	return feed
+}
+
+func TestParseRSS(t *testing.T) {
+	feed := parseFixture(t, "rss2.xml")
+
+	assert.Equal(t, "Ondo Finance & Partners", feed.Title)
+	// 空的atom:link不覆盖channel的link
+	assert.Equal(t, "https://ondo.example.com/", feed.Link)
+	assert.Equal(t, "en", feed.Language)
+	require.Len(t, feed.Items, 3)
+
+	item := feed.Items[0]
+	assert.Equal(t, "ondo-2024-usdy-mantle", item.GUID)
+	assert.Equal(t, "USDY now live on Mantle", item.Title)
+	assert.Equal(t, "/blog/usdy-mantle", item.Link)
+	assert.Equal(t, "The tokenized treasury note USDY is now available on Mantle.", item.Summary)
+	assert.Equal(t, "Ondo Finance today announced that USDY, a tokenized note secured by short-term US Treasuries, is live on Mantle.", item.Content)
+	assert.Equal(t, "Ondo Team", item.Author)
+	assert.Equal(t, []string{"Product", "Treasuries"}, item.Categories)
+	assert.Equal(t, time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC), item.Published)
+
+	// 没有link时使用永久链接形式的guid
+	item = feed.Items[1]
+	assert.Equal(t, "https://ondo.example.com/blog/attestation-q1", item.Link)
+	assert.Equal(t, "Monthly reserve attestation for OUSG & USDY.", item.Summary)
+	assert.Equal(t, time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), item.Published)
+
+	// 没有guid时以link作为标识，无法解析的日期为零值
+	item = feed.Items[2]
+	assert.Equal(t, "https://ondo.example.com/careers/senior-engineer", item.GUID)
+	assert.True(t, item.Published.IsZero())
+}
+
+func TestParseAtom(t *testing.T) {
+	feed := parseFixture(t, "atom.xml")
+
+	assert.Equal(t, "SEC Press Releases", feed.Title)
+	assert.Equal(t, "https://sec.example.gov/newsroom", feed.Link)
+	assert.Equal(t, "en", feed.Language)
+	require.Len(t, feed.Items, 2)
+
+	item := feed.Items[0]
+	assert.Equal(t, "tag:sec.example.gov,2024:press-release-2024-31", item.GUID)
+	assert.Equal(t, "SEC Charges Issuer Over Unregistered Tokenized Securities", item.Title)
+	assert.Equal(t, "https://sec.example.gov/news/press-release/2024-31", item.Link)
+	assert.Equal(t, "The Commission today charged an issuer of tokenized real estate.", item.Summary)
+	assert.Equal(t, "The Securities and Exchange Commission today announced charges.", item.Content)
+	assert.Equal(t, "Office of Public Affairs", item.Author)
+	assert.Equal(t, []string{"Enforcement"}, item.Categories)
+	assert.Equal(t, time.Date(2024, 3, 6, 15, 15, 0, 0, time.UTC), item.Published)
+
+	// 没有published时使用updated
+	item = feed.Items[1]
+	assert.Equal(t, "https://sec.example.gov/news/statement/2024-01", item.Link)
+	assert.Equal(t, "Chair, Commissioner", item.Author)
+	assert.Equal(t, time.Date(2024, 1, 10, 16, 0, 0, 0, time.UTC), item.Published)
+}
+
+func TestParseRDFAndCharset(t *testing.T) {
+	feed := parseFixture(t, "rdf.xml")
+	assert.Equal(t, "Central Bank News", feed.Title)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "CBDC pilot enters second phase", feed.Items[0].Title)
+	assert.Equal(t, "Press Office", feed.Items[0].Author)
+	assert.Equal(t, time.Date(2024, 2, 20, 7, 0, 0, 0, time.UTC), feed.Items[0].Published)
+
+	feed = parseFixture(t, "latin1.xml")
+	assert.Equal(t, "es", feed.Language)
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "Emisión de bonos tokenizados", feed.Items[0].Title)
+
+	_, err := Parse(strings.NewReader(`<html><body>not a feed</body></html>`))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, err = Parse(strings.NewReader(``))
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
+
+func TestFetchConditional(t *testing.T) {
+	body, err := os.ReadFile(filepath.Join("testdata", "rss2.xml"))
+	require.NoError(t, err)
+
+	const etag = `"v1"`
+	const lastModified = "Tue, 05 Mar 2024 15:00:00 GMT"
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	result, err := Fetch(ctx, server.Client(), server.URL+"/feeds/news.xml", "", "")
+	require.NoError(t, err)
+	assert.False(t, result.NotModified)
+	assert.Equal(t, etag, result.ETag)
+	assert.Equal(t, lastModified, result.LastModified)
+	require.Len(t, result.Feed.Items, 3)
+	// 相对链接按订阅源地址解析
+	assert.Equal(t, server.URL+"/blog/usdy-mantle", result.Feed.Items[0].Link)
+
+	result, err = Fetch(ctx, server.Client(), server.URL+"/feeds/news.xml", result.ETag, result.LastModified)
+	require.NoError(t, err)
+	assert.True(t, result.NotModified)
+	assert.Nil(t, result.Feed)
+	assert.Equal(t, etag, result.ETag)
+	assert.Equal(t, lastModified, result.LastModified)
+	assert.Equal(t, 2, requests)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer failing.Close()
+	_, err = Fetch(ctx, failing.Client(), failing.URL, "", "")
+	assert.Error(t, err)
+}
+
+func TestRulesMatch(t *testing.T) {
+	feed := parseFixture(t, "rss2.xml")
+	launch, attestation, hiring := feed.Items[0], feed.Items[1], feed.Items[2]
+
+	// 没有关键词时接受全部条目
+	keyword, category, ok := Rules{Category: "issuer"}.Match(hiring)
+	assert.True(t, ok)
+	assert.Empty(t, keyword)
+	assert.Equal(t, "issuer", category)
+
+	rules := Rules{
+		Keywords: []string{"USDY", "OUSG"},
+		Exclude:  []string{"hiring"},
+		Category: "issuer",
+		CategoryRules: []CategoryRule{
+			{Keywords: []string{"attestation", "reserve"}, Category: "proof_of_reserve"},
+			{Keywords: []string{"live", "launch"}, Category: "product"},
+		},
+	}
+
+	keyword, category, ok = rules.Match(launch)
+	assert.True(t, ok)
+	assert.Equal(t, "USDY", keyword)
+	assert.Equal(t, "product", category)
+
+	keyword, category, ok = rules.Match(attestation)
+	assert.True(t, ok)
+	assert.Equal(t, "USDY", keyword)
+	assert.Equal(t, "proof_of_reserve", category)
+
+	_, _, ok = rules.Match(hiring)
+	assert.False(t, ok)
+
+	// 整词匹配且不区分大小写
+	_, _, ok = Rules{Keywords: []string{"usd"}}.Match(launch)
+	assert.False(t, ok)
+	_, _, ok = Rules{Keywords: []string{"us treasuries"}}.Match(launch)
+	assert.True(t, ok)
+	_, _, ok = Rules{Keywords: []string{"S&P"}}.Match(Item{Title: "S&P upgrades tokenized fund"})
+	assert.True(t, ok)
+}
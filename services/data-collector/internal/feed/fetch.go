@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxFeedSize 订阅源响应的最大字节数
+const maxFeedSize = 10 << 20
+
+// Result 抓取结果；NotModified为true时Feed为空，调用方应保留原有的ETag/Last-Modified
+type Result struct {
+	Feed         *Feed
+	ETag         string
+	LastModified string
+	NotModified  bool
+}
+
+// Fetch 抓取并解析订阅源，etag和lastModified为上次响应的校验值，用于条件请求
+func Fetch(ctx context.Context, client *http.Client, feedURL, etag, lastModified string) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/rdf+xml, application/xml;q=0.9, text/xml;q=0.8")
+	req.Header.Set("User-Agent", "RWA-Platform-DataCollector/1.0")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &Result{ETag: etag, LastModified: lastModified, NotModified: true}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	feed, err := Parse(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// 相对链接以最终响应地址（跟随重定向后）为基准解析
+	base := resp.Request.URL
+	feed.Link = resolve(base, feed.Link)
+	for i := range feed.Items {
+		feed.Items[i].Link = resolve(base, feed.Items[i].Link)
+	}
+
+	return &Result{
+		Feed:         feed,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func resolve(base *url.URL, link string) string {
+	if link == "" || base == nil {
+		return link
+	}
+	ref, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return link
+	}
+	return base.ResolveReference(ref).String()
+}
@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CategoryRule 条目匹配任一关键词时归入指定分类
+type CategoryRule struct {
+	Keywords []string `json:"keywords"`
+	Category string   `json:"category"`
+}
+
+// Rules 订阅源的过滤与分类规则，关键词不区分大小写且按整词匹配
+//
+// Keywords为空时接受全部条目（发行方公告、监管机构新闻稿等专用源通常不需要过滤），
+// 命中Exclude的条目总是丢弃。分类依次取第一条命中的CategoryRule，否则使用Category
+type Rules struct {
+	Keywords      []string       `json:"keywords,omitempty"`
+	Exclude       []string       `json:"exclude,omitempty"`
+	Category      string         `json:"category,omitempty"`
+	CategoryRules []CategoryRule `json:"category_rules,omitempty"`
+}
+
+// Match 判断条目是否需要采集，返回命中的关键词（可能为空）和分类
+func (r Rules) Match(item Item) (keyword, category string, ok bool) {
+	text := strings.Join([]string{item.Title, item.Summary, item.Content, strings.Join(item.Categories, " ")}, "\n")
+
+	if findKeyword(text, r.Exclude) != "" {
+		return "", "", false
+	}
+
+	keyword = findKeyword(text, r.Keywords)
+	if len(r.Keywords) > 0 && keyword == "" {
+		return "", "", false
+	}
+
+	category = r.Category
+	for _, rule := range r.CategoryRules {
+		if findKeyword(text, rule.Keywords) != "" {
+			category = rule.Category
+			break
+		}
+	}
+	return keyword, category, true
+}
+
+// findKeyword 返回第一个在文本中出现的关键词
+func findKeyword(text string, keywords []string) string {
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		if keywordPattern(keyword).MatchString(text) {
+			return keyword
+		}
+	}
+	return ""
+}
+
+// keywordPattern 构造整词匹配的正则；关键词首尾不是单词字符时（如"S&P"）不加边界
+func keywordPattern(keyword string) *regexp.Regexp {
+	pattern := regexp.QuoteMeta(keyword)
+	if isWordChar(keyword[0]) {
+		pattern = `\b` + pattern
+	}
+	if isWordChar(keyword[len(keyword)-1]) {
+		pattern += `\b`
+	}
+	return regexp.MustCompile(`(?i)` + pattern)
+}
+
+func isWordChar(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
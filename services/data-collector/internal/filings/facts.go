@@ -0,0 +1,248 @@
+package filings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fact XBRL中的一个数值，Start为空表示时点数（如资产总额），否则为期间数
+type Fact struct {
+	Taxonomy     string     `json:"taxonomy"`
+	Concept      string     `json:"concept"`
+	Unit         string     `json:"unit"`
+	Value        float64    `json:"value"`
+	Start        *time.Time `json:"start,omitempty"`
+	End          time.Time  `json:"end"`
+	FiscalYear   int        `json:"fiscal_year"`
+	FiscalPeriod string     `json:"fiscal_period"`
+	Form         string     `json:"form"`
+	Filed        time.Time  `json:"filed"`
+	Accession    string     `json:"accession"`
+}
+
+// CompanyFacts 一家公司全部申报中的XBRL数值
+type CompanyFacts struct {
+	CIK        string `json:"cik"` // 不含前导零
+	EntityName string `json:"entity_name"`
+	Facts      []Fact `json:"facts"`
+}
+
+// companyFactsFile companyfacts接口的原始格式：facts -> 分类标准 -> 概念 -> 单位 -> 数值
+type companyFactsFile struct {
+	CIK        json.RawMessage `json:"cik"`
+	EntityName string          `json:"entityName"`
+	Facts      map[string]map[string]struct {
+		Units map[string][]struct {
+			Start string          `json:"start"`
+			End   string          `json:"end"`
+			Val   json.RawMessage `json:"val"`
+			Accn  string          `json:"accn"`
+			FY    int             `json:"fy"`
+			FP    string          `json:"fp"`
+			Form  string          `json:"form"`
+			Filed string          `json:"filed"`
+		} `json:"units"`
+	} `json:"facts"`
+}
+
+// ParseCompanyFacts 解析companyfacts JSON，日期或数值无效的条目跳过
+func ParseCompanyFacts(r io.Reader) (*CompanyFacts, error) {
+	var raw companyFactsFile
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	cik := strings.TrimLeft(unquote(raw.CIK), "0")
+	if cik == "" {
+		return nil, fmt.Errorf("company facts have no cik")
+	}
+
+	facts := &CompanyFacts{CIK: cik, EntityName: raw.EntityName}
+	for taxonomy, concepts := range raw.Facts {
+		for concept, data := range concepts {
+			for unit, values := range data.Units {
+				for _, v := range values {
+					value, err := strconv.ParseFloat(unquote(v.Val), 64)
+					if err != nil {
+						continue
+					}
+					end, err := time.Parse("2006-01-02", v.End)
+					if err != nil {
+						continue
+					}
+					fact := Fact{
+						Taxonomy:     taxonomy,
+						Concept:      concept,
+						Unit:         unit,
+						Value:        value,
+						End:          end,
+						FiscalYear:   v.FY,
+						FiscalPeriod: v.FP,
+						Form:         v.Form,
+						Accession:    v.Accn,
+					}
+					if start, err := time.Parse("2006-01-02", v.Start); err == nil {
+						fact.Start = &start
+					}
+					if filed, err := time.Parse("2006-01-02", v.Filed); err == nil {
+						fact.Filed = filed
+					}
+					facts.Facts = append(facts.Facts, fact)
+				}
+			}
+		}
+	}
+	return facts, nil
+}
+
+// unquote CIK和数值可能是数字或字符串
+func unquote(raw json.RawMessage) string {
+	return strings.Trim(strings.TrimSpace(string(raw)), `"`)
+}
+
+// LoadCompanyFacts 读取目录下所有.json文件，按CIK返回；格式错误的文件单独报告，不影响其他文件
+func LoadCompanyFacts(dir string) (map[string]*CompanyFacts, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	companies := make(map[string]*CompanyFacts)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		facts, err := ParseCompanyFacts(file)
+		file.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Name(), err))
+			continue
+		}
+		companies[facts.CIK] = facts
+	}
+	return companies, errs
+}
+
+// KeyFacts 从一份申报中提取的关键数据，没有披露的为nil
+type KeyFacts struct {
+	PeriodEnd         *time.Time `json:"period_end,omitempty"`
+	FiscalYear        int        `json:"fiscal_year,omitempty"`
+	FiscalPeriod      string     `json:"fiscal_period,omitempty"`
+	Currency          string     `json:"currency,omitempty"`
+	AUM               *float64   `json:"aum,omitempty"`
+	NAV               *float64   `json:"nav,omitempty"`
+	NAVPerShare       *float64   `json:"nav_per_share,omitempty"`
+	TotalAssets       *float64   `json:"total_assets,omitempty"`
+	SharesOutstanding *float64   `json:"shares_outstanding,omitempty"`
+}
+
+// Empty 没有提取到任何数值
+func (k KeyFacts) Empty() bool {
+	return k.AUM == nil && k.NAV == nil && k.NAVPerShare == nil && k.TotalAssets == nil && k.SharesOutstanding == nil
+}
+
+// keyConcepts 各项关键数据对应的XBRL概念，按优先级排列；不区分分类标准，以便识别发行方的自定义概念
+var keyConcepts = struct {
+	AUM, NAV, NAVPerShare, TotalAssets, Shares []string
+}{
+	AUM:         []string{"AssetsUnderManagementCarryingAmount", "AssetsUnderManagement"},
+	NAV:         []string{"AssetsNet", "NetAssets", "NetAssetValue"},
+	NAVPerShare: []string{"NetAssetValuePerShare", "NetAssetValuePerUnit"},
+	TotalAssets: []string{"Assets"},
+	Shares:      []string{"SharesOutstanding", "EntityCommonStockSharesOutstanding"},
+}
+
+// KeyFacts 提取某份申报的关键数据。申报中同时包含本期和比较期的数值，取截止日最新的一个；
+// 报告期截止日取自财务数值而非封面（dei）信息，封面的股数日期通常晚于报告期
+func (c *CompanyFacts) KeyFacts(accession string) KeyFacts {
+	var facts []Fact
+	for _, fact := range c.Facts {
+		if fact.Accession == accession {
+			facts = append(facts, fact)
+		}
+	}
+
+	var key KeyFacts
+	var period *Fact
+	for i := range facts {
+		if facts[i].Taxonomy == "dei" {
+			continue
+		}
+		if period == nil || facts[i].End.After(period.End) {
+			period = &facts[i]
+		}
+	}
+	if period != nil {
+		end := period.End
+		key.PeriodEnd = &end
+		key.FiscalYear = period.FiscalYear
+		key.FiscalPeriod = period.FiscalPeriod
+	}
+
+	monetary := func(concepts []string) *float64 {
+		fact := latest(facts, concepts, isCurrency)
+		if fact == nil {
+			return nil
+		}
+		if key.Currency == "" {
+			key.Currency = fact.Unit
+		}
+		value := fact.Value
+		return &value
+	}
+	key.AUM = monetary(keyConcepts.AUM)
+	key.NAV = monetary(keyConcepts.NAV)
+	key.TotalAssets = monetary(keyConcepts.TotalAssets)
+	if fact := latest(facts, keyConcepts.NAVPerShare, func(unit string) bool { return strings.HasSuffix(unit, "/shares") }); fact != nil {
+		value := fact.Value
+		key.NAVPerShare = &value
+	}
+	if fact := latest(facts, keyConcepts.Shares, func(unit string) bool { return unit == "shares" }); fact != nil {
+		value := fact.Value
+		key.SharesOutstanding = &value
+	}
+	return key
+}
+
+// latest 按概念的优先级查找，同一概念取截止日最新的数值
+func latest(facts []Fact, concepts []string, unit func(string) bool) *Fact {
+	for _, concept := range concepts {
+		var found *Fact
+		for i := range facts {
+			if facts[i].Concept != concept || !unit(facts[i].Unit) {
+				continue
+			}
+			if found == nil || facts[i].End.After(found.End) {
+				found = &facts[i]
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// isCurrency 货币单位为三位大写字母，如USD
+func isCurrency(unit string) bool {
+	if len(unit) != 3 {
+		return false
+	}
+	for _, r := range unit {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package filings
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseIndexFile(t *testing.T, name string) []Entry {
+	file, err := os.Open(name)
+	require.NoError(t, err)
+	defer file.Close()
+
+	entries, err := ParseIndex(file)
+	require.NoError(t, err)
+	return entries
+}
+
+func TestParseMasterIndex(t *testing.T) {
+	entries := parseIndexFile(t, "testdata/master.20240514.idx")
+
+	// 日期无效的行跳过
+	require.Len(t, entries, 4)
+	assert.Equal(t, Entry{
+		CIK:      "1876042",
+		Company:  "Franklin OnChain U.S. Government Money Fund",
+		FormType: "N-CSRS",
+		FiledAt:  time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC),
+		Path:     "edgar/data/1876042/0001137439-24-000512.txt",
+	}, entries[0])
+	assert.Equal(t, "0001137439-24-000512", entries[0].Accession())
+
+	// CIK去掉前导零
+	assert.Equal(t, "320193", entries[2].CIK)
+}
+
+func TestParseFixedWidthIndex(t *testing.T) {
+	entries := parseIndexFile(t, "testdata/form.idx")
+
+	require.Len(t, entries, 3)
+	assert.Equal(t, "10-K", entries[0].FormType)
+	assert.Equal(t, "SECURITIZE INC", entries[0].Company)
+	assert.Equal(t, "1712546", entries[0].CIK)
+	assert.Equal(t, time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC), entries[0].FiledAt)
+
+	// 申报类型可以包含空格
+	assert.Equal(t, "SC 13G", entries[2].FormType)
+	assert.Equal(t, "BLACKROCK USD INSTITUTIONAL DIGITAL LIQUIDITY FUND", entries[2].Company)
+	assert.Equal(t, "0001993391-24-000007", entries[2].Accession())
+}
+
+func TestParseIndexWithoutHeader(t *testing.T) {
+	file, err := os.Open("testdata/facts/README.txt")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = ParseIndex(file)
+	assert.Error(t, err)
+}
+
+func TestBaseFormAndDailyIndexPath(t *testing.T) {
+	assert.Equal(t, "10-Q", BaseForm("10-q/A"))
+	assert.Equal(t, "N-CSRS", BaseForm(" N-CSRS "))
+	assert.Equal(t, "edgar/daily-index/2024/QTR2/master.20240514.idx", DailyIndexPath(time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "edgar/daily-index/2023/QTR4/master.20231229.idx", DailyIndexPath(time.Date(2023, 12, 29, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNormalizeCompany(t *testing.T) {
+	assert.Equal(t, "ONDO FINANCE", NormalizeCompany("Ondo Finance Inc /DE/"))
+	assert.Equal(t, "ONDO FINANCE", NormalizeCompany("Ondo Finance, Inc."))
+	assert.Equal(t, "WISDOMTREE", NormalizeCompany("WISDOMTREE, INC."))
+	assert.Equal(t, "FRANKLIN ONCHAIN U S GOVERNMENT MONEY FUND", NormalizeCompany("Franklin OnChain U.S. Government Money Fund"))
+	assert.Equal(t, "AT AND T", NormalizeCompany("AT&T Inc"))
+}
+
+func TestLoadCompanyFacts(t *testing.T) {
+	companies, errs := LoadCompanyFacts("testdata/facts")
+
+	// 缺少CIK的文件单独报告，非.json文件忽略
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "broken.json")
+
+	require.Len(t, companies, 1)
+	facts := companies["1876042"]
+	require.NotNil(t, facts)
+	assert.Equal(t, "Franklin OnChain U.S. Government Money Fund", facts.EntityName)
+	// 数值无效的条目跳过
+	assert.Len(t, facts.Facts, 8)
+}
+
+func TestKeyFacts(t *testing.T) {
+	companies, _ := LoadCompanyFacts("testdata/facts")
+	facts := companies["1876042"]
+	require.NotNil(t, facts)
+
+	key := facts.KeyFacts("0001137439-24-000512")
+	require.NotNil(t, key.PeriodEnd)
+	// 报告期不受封面股数日期的影响
+	assert.Equal(t, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), *key.PeriodEnd)
+	assert.Equal(t, 2024, key.FiscalYear)
+	assert.Equal(t, "Q1", key.FiscalPeriod)
+	assert.Equal(t, "USD", key.Currency)
+
+	// 比较期的净资产不会覆盖本期
+	require.NotNil(t, key.NAV)
+	assert.Equal(t, 380118000.55, *key.NAV)
+	require.NotNil(t, key.AUM)
+	assert.Equal(t, 380500000.0, *key.AUM)
+	require.NotNil(t, key.NAVPerShare)
+	assert.Equal(t, 1.0, *key.NAVPerShare)
+	require.NotNil(t, key.TotalAssets)
+	assert.Equal(t, 380402114.0, *key.TotalAssets)
+	require.NotNil(t, key.SharesOutstanding)
+	assert.Equal(t, 380125000.0, *key.SharesOutstanding)
+	assert.False(t, key.Empty())
+
+	assert.True(t, facts.KeyFacts("0000000000-00-000000").Empty())
+}
@@ -0,0 +1,178 @@
+// Package filings 解析EDGAR格式的监管申报数据：全文索引（master.idx、form.idx、company.idx）和XBRL公司财务数据（companyfacts JSON）
+package filings
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Entry 索引中的一份申报，Path为相对于归档根目录的路径，如edgar/data/320193/0000320193-24-000010.txt
+type Entry struct {
+	CIK      string    `json:"cik"` // 不含前导零
+	Company  string    `json:"company"`
+	FormType string    `json:"form_type"`
+	FiledAt  time.Time `json:"filed_at"`
+	Path     string    `json:"path"`
+}
+
+var accessionPattern = regexp.MustCompile(`\d{10}-\d{2}-\d{6}`)
+
+// Accession 申报编号，从文件路径中取得；路径中没有编号时返回空
+func (e Entry) Accession() string {
+	return accessionPattern.FindString(path.Base(e.Path))
+}
+
+// BaseForm 去掉修订后缀的申报类型，如10-K/A为10-K
+func BaseForm(formType string) string {
+	return strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(formType)), "/A")
+}
+
+// indexColumns 定宽索引的列名，按表头中的位置切分
+var indexColumns = []string{"Company Name", "Form Type", "CIK", "Date Filed", "File Name"}
+
+// ParseIndex 解析EDGAR索引文件：master.idx为|分隔，form.idx和company.idx为定宽列。
+// 表头之前的说明行被忽略，格式不完整的行跳过
+func ParseIndex(r io.Reader) ([]Entry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var header string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "CIK|") || strings.Contains(line, "Company Name") && strings.Contains(line, "File Name") {
+			header = line
+			break
+		}
+	}
+	if header == "" {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("index header not found")
+	}
+
+	var parse func(string) (Entry, bool)
+	if strings.Contains(header, "|") {
+		parse = parseMasterLine
+	} else {
+		offsets := make(map[string]int)
+		for _, column := range indexColumns {
+			offset := strings.Index(header, column)
+			if offset < 0 {
+				return nil, fmt.Errorf("index column %q not found", column)
+			}
+			offsets[column] = offset
+		}
+		parse = func(line string) (Entry, bool) {
+			return parseFixedLine(line, offsets)
+		}
+	}
+
+	var entries []Entry
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+		if line == "" || strings.HasPrefix(line, "---") {
+			continue
+		}
+		if entry, ok := parse(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseMasterLine(line string) (Entry, bool) {
+	fields := strings.Split(line, "|")
+	if len(fields) != 5 {
+		return Entry{}, false
+	}
+	return newEntry(fields[0], fields[1], fields[2], fields[3], fields[4])
+}
+
+// parseFixedLine 按表头位置切分，各列起点在表头中的顺序因文件而异
+func parseFixedLine(line string, offsets map[string]int) (Entry, bool) {
+	column := func(name string) string {
+		start := offsets[name]
+		end := len(line)
+		for _, offset := range offsets {
+			if offset > start && offset < end {
+				end = offset
+			}
+		}
+		if start >= len(line) {
+			return ""
+		}
+		return strings.TrimSpace(line[start:end])
+	}
+	return newEntry(column("CIK"), column("Company Name"), column("Form Type"), column("Date Filed"), column("File Name"))
+}
+
+func newEntry(cik, company, formType, filed, filePath string) (Entry, bool) {
+	cik = strings.TrimLeft(strings.TrimSpace(cik), "0")
+	filePath = strings.TrimSpace(filePath)
+	if cik == "" || filePath == "" {
+		return Entry{}, false
+	}
+	filedAt, err := parseDate(strings.TrimSpace(filed))
+	if err != nil {
+		return Entry{}, false
+	}
+	return Entry{
+		CIK:      cik,
+		Company:  strings.TrimSpace(company),
+		FormType: strings.TrimSpace(formType),
+		FiledAt:  filedAt,
+		Path:     filePath,
+	}, true
+}
+
+// parseDate 索引中的日期为2006-01-02，较早的文件为20060102
+func parseDate(value string) (time.Time, error) {
+	if len(value) == 8 {
+		return time.Parse("20060102", value)
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// DailyIndexPath 某天的master索引在归档根目录下的路径
+func DailyIndexPath(day time.Time) string {
+	quarter := (int(day.Month())-1)/3 + 1
+	return fmt.Sprintf("edgar/daily-index/%d/QTR%d/master.%s.idx", day.Year(), quarter, day.Format("20060102"))
+}
+
+// companySuffixes 比较公司名称时忽略的组织形式后缀
+var companySuffixes = map[string]bool{
+	"INC": true, "INCORPORATED": true, "CORP": true, "CORPORATION": true, "CO": true, "COMPANY": true,
+	"LLC": true, "LP": true, "LLP": true, "LTD": true, "LIMITED": true, "PLC": true,
+	"SA": true, "AG": true, "NV": true, "BV": true, "GMBH": true, "THE": true,
+}
+
+var (
+	// stateSuffixPattern EDGAR名称中的注册州后缀，如"ACME CORP /DE/"
+	stateSuffixPattern = regexp.MustCompile(`\s/[A-Z]{2}/?\s*$`)
+	nonWordPattern     = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// NormalizeCompany 规范化公司名称用于匹配：大写、去掉标点、注册州和组织形式后缀
+func NormalizeCompany(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	name = stateSuffixPattern.ReplaceAllString(name, "")
+	name = strings.ReplaceAll(name, "&", " AND ")
+	words := strings.Fields(nonWordPattern.ReplaceAllString(name, " "))
+
+	var kept []string
+	for _, word := range words {
+		if !companySuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
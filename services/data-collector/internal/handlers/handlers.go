@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rwa-platform/data-collector/internal/services"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
 )
 
 // HealthCheck 健康检查
@@ -102,14 +107,19 @@ func GetAssetInfo(blockchainService *services.BlockchainService) gin.HandlerFunc
 			return
 		}
 
-		asset, err := blockchainService.GetAssetInfo(address)
+		refresh := c.Query("refresh") == "true"
+		profile, err := blockchainService.GetAssetInfo(c.Request.Context(), address, refresh)
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+			if errors.Is(err, services.ErrAssetNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "asset not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"data": asset,
+			"data": profile,
 		})
 	}
 }
@@ -153,9 +163,23 @@ func GetNews(newsService *services.NewsService) gin.HandlerFunc {
 		category := c.Query("category")
 		source := c.Query("source")
 		language := c.Query("language")
+		// asset_id按实体关联筛选提及该资产的新闻
+		assetID := c.Query("asset_id")
 
-		news, total, err := newsService.GetNews(page, limit, category, source, language)
+		// clustered=true时按报道返回，转载的文章合并为一条
+		var news interface{}
+		var total int
+		var err error
+		if c.Query("clustered") == "true" {
+			news, total, err = newsService.GetNewsStories(page, limit, category, source, language, assetID)
+		} else {
+			news, total, err = newsService.GetNews(page, limit, category, source, language, assetID)
+		}
 		if err != nil {
+			if errors.Is(err, services.ErrInvalidAssetID) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get news"})
 			return
 		}
@@ -172,6 +196,76 @@ func GetNews(newsService *services.NewsService) gin.HandlerFunc {
 	}
 }
 
+// SearchNews 全文检索新闻，返回高亮片段以及按分类和来源的分面统计
+func SearchNews(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		query := services.NewsSearchQuery{
+			Text:     c.Query("q"),
+			Category: c.Query("category"),
+			Source:   c.Query("source"),
+			Language: c.Query("language"),
+			AssetID:  c.Query("asset_id"),
+			Sort:     c.Query("sort"),
+			Page:     page,
+			Limit:    limit,
+		}
+		if value := c.Query("from"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from time format"})
+				return
+			}
+			query.From = &parsed
+		}
+		if value := c.Query("to"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to time format"})
+				return
+			}
+			query.To = &parsed
+		}
+		if value := c.Query("min_relevance"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_relevance"})
+				return
+			}
+			query.MinRelevance = parsed
+		}
+
+		result, err := newsService.SearchNews(c.Request.Context(), query)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidSearchQuery) || errors.Is(err, services.ErrInvalidAssetID) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data":   result.Hits,
+			"facets": result.Facets,
+			"meta": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       result.Total,
+				"total_pages": (result.Total + limit - 1) / limit,
+			},
+		})
+	}
+}
+
 // GetNewsDetail 获取新闻详情
 func GetNewsDetail(newsService *services.NewsService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -193,6 +287,261 @@ func GetNewsDetail(newsService *services.NewsService) gin.HandlerFunc {
 	}
 }
 
+// GetNewsStory 获取新闻报道及转载它的所有文章
+func GetNewsStory(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		story, err := newsService.GetNewsStory(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrStoryNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": story,
+		})
+	}
+}
+
+// GetNewsEntities 获取新闻关联的资产、发行方和渠道
+func GetNewsEntities(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entities, err := newsService.GetNewsEntities(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": entities,
+		})
+	}
+}
+
+// GetRegulatoryEvents 获取已发布的监管事件，可按事件类型和资产筛选
+func GetRegulatoryEvents(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listRegulatoryEvents(c, newsService, services.RegulatoryStatusPublished)
+	}
+}
+
+// GetRegulatoryEventQueue 获取监管事件复核队列，默认返回待复核的事件
+func GetRegulatoryEventQueue(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		listRegulatoryEvents(c, newsService, c.DefaultQuery("status", services.RegulatoryStatusPending))
+	}
+}
+
+func listRegulatoryEvents(c *gin.Context, newsService *services.NewsService, status string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	events, total, err := newsService.ListRegulatoryEvents(c.Request.Context(), services.RegulatoryEventQuery{
+		Status:    status,
+		EventType: c.Query("event_type"),
+		AssetID:   c.Query("asset_id"),
+		Page:      page,
+		Limit:     limit,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRegulatoryQuery) || errors.Is(err, services.ErrInvalidAssetID) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"meta": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// ReviewRegulatoryEvent 复核监管事件，批准后发布到regulatory-events
+func ReviewRegulatoryEvent(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var review services.RegulatoryReview
+		if err := c.ShouldBindJSON(&review); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		event, err := newsService.ReviewRegulatoryEvent(c.Request.Context(), c.Param("id"), review)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrRegulatoryEventNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrInvalidRegulatoryReview), errors.Is(err, services.ErrInvalidAssetID):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": event,
+		})
+	}
+}
+
+// GetNewsSentiment 按时间段和分类聚合新闻情绪，可按资产筛选，默认最近30天按天聚合
+func GetNewsSentiment(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := services.SentimentQuery{
+			Asset:    c.Query("asset"),
+			Category: c.Query("category"),
+			Interval: c.Query("interval"),
+			To:       time.Now(),
+		}
+		query.From = query.To.AddDate(0, 0, -30)
+
+		if value := c.Query("from"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from time format"})
+				return
+			}
+			query.From = parsed
+		}
+		if value := c.Query("to"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to time format"})
+				return
+			}
+			query.To = parsed
+		}
+
+		summary, err := newsService.GetSentiment(c.Request.Context(), query)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidSentimentQuery):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, services.ErrAssetNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": summary,
+		})
+	}
+}
+
+// RescoreNewsSentiment 创建情绪回填任务，all=true时重新计算所有文章
+func RescoreNewsSentiment(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := newsService.RescoreSentiment(c.Request.Context(), c.Query("all") == "true")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// RelinkNewsEntities 按当前的资产和渠道重新关联历史新闻，days限定只处理最近几天的文章
+func RelinkNewsEntities(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid days"})
+			return
+		}
+
+		job, err := newsService.RelinkEntities(c.Request.Context(), days)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// GetFilings 获取监管申报列表，可按资产、发行方和申报类型筛选
+func GetFilings(filingService *services.FilingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		filings, total, err := filingService.ListFilings(c.Request.Context(), services.FilingQuery{
+			AssetID:  c.Query("asset_id"),
+			Issuer:   c.Query("issuer"),
+			FormType: c.Query("form_type"),
+			Page:     page,
+			Limit:    limit,
+		})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAssetID) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": filings,
+			"meta": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + limit - 1) / limit,
+			},
+		})
+	}
+}
+
+// GetFiling 获取监管申报详情
+func GetFiling(filingService *services.FilingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filing, err := filingService.GetFiling(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrFilingNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": filing,
+		})
+	}
+}
+
 // TriggerPriceSync 触发价格同步
 func TriggerPriceSync(priceService *services.PriceService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -221,71 +570,1066 @@ func TriggerBlockchainSync(blockchainService *services.BlockchainService) gin.Ha
 	}
 }
 
-// GetStats 获取统计信息
-func GetStats(priceService *services.PriceService, blockchainService *services.BlockchainService, newsService *services.NewsService) gin.HandlerFunc {
+// GetIndexerStatuses 获取各链索引器状态
+func GetIndexerStatuses(blockchainService *services.BlockchainService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		stats := gin.H{
-			"timestamp": time.Now().Unix(),
-			"service":   "data-collector",
-		}
+		c.JSON(http.StatusOK, gin.H{
+			"data": blockchainService.GetIndexerStatuses(),
+		})
+	}
+}
+
+// PauseIndexer 暂停指定链的索引
+func PauseIndexer(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chain := c.Param("chain")
 
-		// 这里可以添加各种统计信息
-		// 例如：最近采集的数据量、错误率、性能指标等
+		if err := blockchainService.PauseIndexer(chain); err != nil {
+			respondIndexerError(c, err)
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"data": stats,
+			"message": "indexer paused",
 		})
 	}
 }
 
-// ErrorHandler 错误处理中间件
-func ErrorHandler() gin.HandlerFunc {
+// ResumeIndexer 恢复指定链的索引
+func ResumeIndexer(blockchainService *services.BlockchainService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Next()
+		chain := c.Param("chain")
 
-		if len(c.Errors) > 0 {
-			err := c.Errors.Last()
-			
-			switch err.Type {
-			case gin.ErrorTypeBind:
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": "Invalid request format",
-					"details": err.Error(),
-				})
-			case gin.ErrorTypePublic:
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": err.Error(),
-				})
-			default:
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-				})
-			}
+		if err := blockchainService.ResumeIndexer(chain); err != nil {
+			respondIndexerError(c, err)
+			return
 		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "indexer resumed",
+		})
 	}
 }
 
-// CORSMiddleware CORS中间件
-func CORSMiddleware() gin.HandlerFunc {
+// RewindIndexer 回退指定链的索引游标
+func RewindIndexer(blockchainService *services.BlockchainService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		chain := c.Param("chain")
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		var request struct {
+			Block *uint64 `json:"block" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "block is required"})
 			return
 		}
 
-		c.Next()
+		if err := blockchainService.RewindIndexer(c.Request.Context(), chain, *request.Block); err != nil {
+			respondIndexerError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "indexer rewound",
+		})
 	}
 }
 
-// RateLimitMiddleware 限流中间件
-func RateLimitMiddleware() gin.HandlerFunc {
+// GetReserveHistory 获取资产的抵押率时间序列，默认最近30天
+func GetReserveHistory(blockchainService *services.BlockchainService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 这里可以实现基于Redis的限流逻辑
-		c.Next()
+		to := time.Now()
+		from := to.AddDate(0, 0, -30)
+
+		if value := c.Query("from"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from time format"})
+				return
+			}
+			from = parsed
+		}
+		if value := c.Query("to"); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to time format"})
+				return
+			}
+			to = parsed
+		}
+
+		history, err := blockchainService.GetReserveHistory(c.Request.Context(), c.Param("asset_id"), from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": history,
+		})
+	}
+}
+
+// GetChains 获取链注册表
+func GetChains(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		chains, err := blockchainService.ListChains(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": chains,
+		})
+	}
+}
+
+// AddChain 添加或更新链，校验节点链ID后立即开始索引
+func AddChain(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var chain services.ChainDefinition
+		if err := c.ShouldBindJSON(&chain); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		info, err := blockchainService.AddChain(c.Request.Context(), chain)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidChain) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": info,
+		})
+	}
+}
+
+// RemoveChain 从注册表中移除链并停止索引
+func RemoveChain(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := blockchainService.RemoveChain(c.Request.Context(), c.Param("chain")); err != nil {
+			if errors.Is(err, services.ErrUnknownChain) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "chain not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "chain removed",
+		})
+	}
+}
+
+func respondIndexerError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrUnknownChain) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "chain not found"})
+		return
+	}
+	if errors.Is(err, services.ErrIndexerBusy) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// TriggerFilingSync 触发监管申报采集
+func TriggerFilingSync(filingService *services.FilingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := filingService.TriggerSync(); err != nil {
+			if errors.Is(err, services.ErrFilingsDisabled) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "filing sync triggered successfully",
+		})
+	}
+}
+
+// GetScheduledJobs 获取各服务的定时任务及最近一次执行的状态、耗时和错误
+func GetScheduledJobs(jobScheduler *scheduler.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		jobs, err := jobScheduler.ListJobs(c.Request.Context(), c.Query("service"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": jobs,
+		})
+	}
+}
+
+// GetScheduledJobRuns 获取定时任务的执行记录
+func GetScheduledJobRuns(jobScheduler *scheduler.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+		if page < 1 {
+			page = 1
+		}
+		if limit < 1 || limit > 100 {
+			limit = 20
+		}
+
+		runs, total, err := jobScheduler.ListRuns(c.Request.Context(), scheduler.RunQuery{
+			JobID:  c.Param("id"),
+			Status: c.Query("status"),
+			Page:   page,
+			Limit:  limit,
+		})
+		if err != nil {
+			if errors.Is(err, scheduler.ErrJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": runs,
+			"meta": gin.H{
+				"page":        page,
+				"limit":       limit,
+				"total":       total,
+				"total_pages": (total + limit - 1) / limit,
+			},
+		})
+	}
+}
+
+// TriggerScheduledJob 手动触发定时任务，由任务所属服务的调度器执行
+func TriggerScheduledJob(jobScheduler *scheduler.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := jobScheduler.TriggerJob(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, scheduler.ErrJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// UpdateScheduledJob 暂停、恢复定时任务或覆盖其调度表达式
+func UpdateScheduledJob(jobScheduler *scheduler.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var update scheduler.JobUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		job, err := jobScheduler.UpdateJob(c.Request.Context(), c.Param("id"), update)
+		if err != nil {
+			switch {
+			case errors.Is(err, scheduler.ErrJobNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			case errors.Is(err, scheduler.ErrInvalidSchedule):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// GetStats 采集服务的运行统计：各来源写入量、任务和采集器最近成功时间、积压、缓存命中率和表记录数
+func GetStats(sections ...stats.Section) gin.HandlerFunc {
+	return stats.Handler("data-collector", sections...)
+}
+
+// ErrorHandler 错误处理中间件
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) > 0 {
+			err := c.Errors.Last()
+			
+			switch err.Type {
+			case gin.ErrorTypeBind:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "Invalid request format",
+					"details": err.Error(),
+				})
+			case gin.ErrorTypePublic:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": err.Error(),
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Internal server error",
+				})
+			}
+		}
+	}
+}
+
+// CORSMiddleware CORS中间件
+func CORSMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Credentials", "true")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitMiddleware 限流中间件
+func RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 这里可以实现基于Redis的限流逻辑
+		c.Next()
+	}
+}
+
+// GetWalletTransfers 获取钱包的代币转账记录
+func GetWalletTransfers(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, ok := parseWalletActivityQuery(c)
+		if !ok {
+			return
+		}
+
+		transfers, nextCursor, err := blockchainService.GetWalletTransfers(c.Request.Context(), query)
+		if err != nil {
+			respondWalletActivityError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": transfers,
+			"meta": gin.H{
+				"limit":       query.Limit,
+				"next_cursor": nextCursor,
+			},
+		})
+	}
+}
+
+// GetWalletTransactions 获取钱包的交易记录
+func GetWalletTransactions(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, ok := parseWalletActivityQuery(c)
+		if !ok {
+			return
+		}
+
+		transactions, nextCursor, err := blockchainService.GetWalletTransactions(c.Request.Context(), query)
+		if err != nil {
+			respondWalletActivityError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": transactions,
+			"meta": gin.H{
+				"limit":       query.Limit,
+				"next_cursor": nextCursor,
+			},
+		})
+	}
+}
+
+// parseWalletActivityQuery 解析钱包活动查询参数，参数错误时直接返回400
+func parseWalletActivityQuery(c *gin.Context) (services.WalletActivityQuery, bool) {
+	query := services.WalletActivityQuery{
+		Address:   c.Param("address"),
+		Chain:     c.Query("chain"),
+		Token:     c.Query("token"),
+		Direction: c.Query("direction"),
+		Cursor:    c.Query("cursor"),
+	}
+
+	if query.Address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "address is required"})
+		return query, false
+	}
+
+	switch query.Direction {
+	case "", "all":
+		query.Direction = ""
+	case services.DirectionIn, services.DirectionOut:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be one of in, out, all"})
+		return query, false
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+		return query, false
+	}
+	query.Limit = limit
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		value := c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s time format", param.name)})
+			return query, false
+		}
+		*param.target = &parsed
+	}
+
+	return query, true
+}
+
+func respondWalletActivityError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// GetWatchedWallets 获取用户关联的钱包
+func GetWatchedWallets(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.Query("user_id")
+		if userID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+			return
+		}
+
+		wallets, err := blockchainService.GetWatchedWallets(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get wallets"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": wallets,
+		})
+	}
+}
+
+// AddWatchedWallet 为用户关联钱包地址
+func AddWatchedWallet(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			UserID  string  `json:"user_id" binding:"required"`
+			Address string  `json:"address" binding:"required"`
+			Label   *string `json:"label"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		wallet, err := blockchainService.AddWatchedWallet(c.Request.Context(), req.UserID, req.Address, req.Label)
+		if errors.Is(err, services.ErrInvalidAddress) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": wallet,
+		})
+	}
+}
+
+// RemoveWatchedWallet 停止监控钱包
+func RemoveWatchedWallet(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := blockchainService.RemoveWatchedWallet(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, services.ErrWalletNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "wallet removed",
+		})
+	}
+}
+
+// GetWalletBalances 获取钱包的已确认余额
+func GetWalletBalances(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		balances, err := blockchainService.GetWalletBalances(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get balances"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": balances,
+		})
+	}
+}
+
+// GetDecodedEvents 按合约、事件名和参数值查询解码事件，参数条件写作arg.<name>=<value>
+func GetDecodedEvents(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := services.DecodedEventQuery{
+			Chain:    c.Query("chain"),
+			Contract: c.Query("contract"),
+			Event:    c.Query("event"),
+			Cursor:   c.Query("cursor"),
+			Args:     make(map[string]string),
+		}
+
+		for key, values := range c.Request.URL.Query() {
+			if name := strings.TrimPrefix(key, "arg."); name != key && name != "" && len(values) > 0 {
+				query.Args[name] = values[0]
+			}
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		query.Limit = limit
+
+		for _, param := range []struct {
+			name   string
+			target **uint64
+		}{{"from_block", &query.FromBlock}, {"to_block", &query.ToBlock}} {
+			value := c.Query(param.name)
+			if value == "" {
+				continue
+			}
+			parsed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s", param.name)})
+				return
+			}
+			*param.target = &parsed
+		}
+
+		for _, param := range []struct {
+			name   string
+			target **time.Time
+		}{{"from", &query.From}, {"to", &query.To}} {
+			value := c.Query(param.name)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid %s time format", param.name)})
+				return
+			}
+			*param.target = &parsed
+		}
+
+		events, nextCursor, err := blockchainService.GetDecodedEvents(c.Request.Context(), query)
+		if err != nil {
+			respondWalletActivityError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": events,
+			"meta": gin.H{
+				"limit":       query.Limit,
+				"next_cursor": nextCursor,
+			},
+		})
+	}
+}
+
+// GetABIs 获取已上传的合约ABI
+func GetABIs(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		abis, err := blockchainService.ListABIs(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": abis,
+		})
+	}
+}
+
+// UploadABI 上传合约ABI，并创建历史日志的重新解码任务
+func UploadABI(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var upload services.ABIUpload
+		if err := c.ShouldBindJSON(&upload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		record, job, err := blockchainService.UploadABI(c.Request.Context(), upload)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidABI) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": record,
+			"job":  job,
+		})
+	}
+}
+
+// DeleteABI 删除合约ABI，已解码的事件保留
+func DeleteABI(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := blockchainService.DeleteABI(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, services.ErrABINotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "abi deleted",
+		})
+	}
+}
+
+// RedecodeABI 用已有ABI重新解码历史日志
+func RedecodeABI(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := blockchainService.RedecodeABI(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrABINotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// GetSyncJob 获取同步任务的状态和进度
+func GetSyncJob(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := blockchainService.GetSyncJob(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			if errors.Is(err, services.ErrSyncJobNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": job,
+		})
+	}
+}
+
+// GetBridgeTransfers 查询跨链转账，address匹配发送方或接收方
+func GetBridgeTransfers(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query := services.BridgeTransferQuery{
+			Address:  c.Query("address"),
+			Protocol: c.Query("protocol"),
+			Status:   c.Query("status"),
+			Cursor:   c.Query("cursor"),
+		}
+
+		switch query.Status {
+		case "", services.BridgeStatusPending, services.BridgeStatusCompleted:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of pending, completed"})
+			return
+		}
+
+		limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if err != nil || limit < 1 || limit > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		query.Limit = limit
+
+		transfers, nextCursor, err := blockchainService.GetBridgeTransfers(c.Request.Context(), query)
+		if err != nil {
+			respondWalletActivityError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": transfers,
+			"meta": gin.H{
+				"limit":       query.Limit,
+				"next_cursor": nextCursor,
+			},
+		})
+	}
+}
+
+// GetBridgeContracts 获取已知的跨链桥合约
+func GetBridgeContracts(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		contracts, err := blockchainService.ListBridgeContracts(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": contracts,
+		})
+	}
+}
+
+// AddBridgeContract 添加跨链桥合约，并关联已解码的历史事件
+func AddBridgeContract(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.BridgeContractInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		contract, job, err := blockchainService.AddBridgeContract(c.Request.Context(), input)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidBridge) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": contract,
+			"job":  job,
+		})
+	}
+}
+
+// RemoveBridgeContract 停用跨链桥合约
+func RemoveBridgeContract(blockchainService *services.BlockchainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := blockchainService.RemoveBridgeContract(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, services.ErrBridgeNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "bridge contract removed",
+		})
+	}
+}
+
+// GetNewsSubscriptions 获取新闻检索订阅及统计
+func GetNewsSubscriptions(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptions, err := newsService.ListSubscriptions(c.Request.Context(), c.Query("origin"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": subscriptions,
+		})
+	}
+}
+
+// SaveNewsSubscription 添加或更新新闻检索订阅
+func SaveNewsSubscription(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.SubscriptionInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		subscription, err := newsService.SaveSubscription(c.Request.Context(), input)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidSubscription) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": subscription,
+		})
+	}
+}
+
+// RemoveNewsSubscription 停用新闻检索订阅
+func RemoveNewsSubscription(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := newsService.RemoveSubscription(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, services.ErrSubscriptionNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "news subscription removed",
+		})
+	}
+}
+
+// SyncAssetNewsSubscriptions 按活跃资产的名称和别名生成检索订阅
+func SyncAssetNewsSubscriptions(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := newsService.SyncAssetSubscriptions(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": result,
+		})
+	}
+}
+
+// GetFeeds 获取新闻订阅源及采集状态
+func GetFeeds(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		feeds, err := newsService.ListFeeds(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": feeds,
+		})
+	}
+}
+
+// AddFeed 添加或更新RSS/Atom订阅源
+func AddFeed(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.FeedInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		feed, err := newsService.AddFeed(c.Request.Context(), input)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidFeed) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": feed,
+		})
+	}
+}
+
+// RemoveFeed 停用订阅源
+func RemoveFeed(newsService *services.NewsService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := newsService.RemoveFeed(c.Request.Context(), c.Param("id")); err != nil {
+			if errors.Is(err, services.ErrFeedNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "feed removed",
+		})
+	}
+}
+
+// respondDataSourceError 数据源接口的错误响应
+func respondDataSourceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDataSourceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidDataSource):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrKeyringNotConfigured):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetDataSources 获取数据源及健康状况，可按type筛选
+func GetDataSources(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sources, err := dataSourceService.ListDataSources(c.Request.Context(), c.Query("type"))
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": sources,
+		})
+	}
+}
+
+// GetDataSource 获取数据源详情及健康状况
+func GetDataSource(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source, err := dataSourceService.GetDataSource(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": source,
+		})
+	}
+}
+
+// CreateDataSource 创建数据源
+func CreateDataSource(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input services.DataSourceInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		source, err := dataSourceService.CreateDataSource(c.Request.Context(), input)
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"data": source,
+		})
+	}
+}
+
+// UpdateDataSource 更新数据源，api_key为空字符串时清除密钥
+func UpdateDataSource(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var update services.DataSourceUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		source, err := dataSourceService.UpdateDataSource(c.Request.Context(), c.Param("id"), update)
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": source,
+		})
+	}
+}
+
+// DeleteDataSource 删除数据源
+func DeleteDataSource(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := dataSourceService.DeleteDataSource(c.Request.Context(), c.Param("id")); err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "data source deleted",
+		})
+	}
+}
+
+// TestDataSource 测试数据源连接，上游错误体现在结果中
+func TestDataSource(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := dataSourceService.TestDataSource(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": result,
+		})
+	}
+}
+
+// RotateDataSourceKeys 重新加载密钥文件，用最新的密钥重新加密所有API密钥
+func RotateDataSourceKeys(dataSourceService *services.DataSourceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rotation, err := dataSourceService.RotateKeys(c.Request.Context())
+		if err != nil {
+			respondDataSourceError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"data": rotation,
+		})
 	}
 }
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/tracing"
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
@@ -47,7 +49,8 @@ func NewProducer(brokers []string) (*Producer, error) {
 	return producer, nil
 }
 
-func (p *Producer) PublishMessage(topic string, key string, message interface{}) error {
+// PublishMessage 序列化并发送消息，ctx中的trace上下文写入消息头，消费方据此延续同一个trace
+func (p *Producer) PublishMessage(ctx context.Context, topic string, key string, message interface{}) error {
 	writer, exists := p.writers[topic]
 	if !exists {
 		// 动态创建writer
@@ -77,10 +80,15 @@ func (p *Producer) PublishMessage(topic string, key string, message interface{})
 		Time:  time.Now(),
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	_, span := tracing.StartPublish(ctx, topic, &kafkaMessage)
+	// 调用方ctx只用于传递trace上下文，发送超时单独控制
+	writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if err := writer.WriteMessages(ctx, kafkaMessage); err != nil {
+	err = writer.WriteMessages(writeCtx, kafkaMessage)
+	tracing.EndSpan(span, err)
+	metrics.ObservePublish(topic, err)
+	if err != nil {
 		p.logger.Errorf("Failed to write message to topic %s: %v", topic, err)
 		return err
 	}
@@ -98,7 +106,11 @@ func (p *Producer) PublishBatch(topic string, messages []kafka.Message) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := writer.WriteMessages(ctx, messages...); err != nil {
+	err := writer.WriteMessages(ctx, messages...)
+	for range messages {
+		metrics.ObservePublish(topic, err)
+	}
+	if err != nil {
 		p.logger.Errorf("Failed to write batch messages to topic %s: %v", topic, err)
 		return err
 	}
@@ -168,7 +180,13 @@ func (c *Consumer) StartConsumer(ctx context.Context, handler MessageHandler) {
 				continue
 			}
 
-			if err := handler.HandleMessage(ctx, message); err != nil {
+			config := c.reader.Config()
+			messageCtx, span := tracing.StartConsume(ctx, message, config.GroupID)
+			err = handler.HandleMessage(messageCtx, message)
+			tracing.EndSpan(span, err)
+			metrics.ObserveConsume(message.Topic, config.GroupID, err)
+			metrics.SetConsumerLag(message.Topic, message.Partition, config.GroupID, c.reader.Lag())
+			if err != nil {
 				c.logger.Errorf("Failed to handle message: %v", err)
 				continue
 			}
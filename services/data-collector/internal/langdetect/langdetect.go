@@ -0,0 +1,142 @@
+// Package langdetect 离线识别新闻文本的语言，返回ISO 639-1代码
+//
+// 先按文字系统区分中文、日文、韩文和俄文：日文与中文都使用汉字，以假名的占比区分。
+// 拉丁字母文本按各语言高频虚词的出现次数打分，并参考特有字母（如德文的ß、法文的ç）
+package langdetect
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// minLetters 字母少于该数量时不做判断
+	minLetters = 12
+	// minStopwords 拉丁字母文本至少命中的虚词数
+	minStopwords = 2
+)
+
+// Result 识别结果，Language为空表示无法判断
+type Result struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"`
+}
+
+// stopwords 各语言最常见的虚词，只收录在其他候选语言中不常见的词
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "for", "with", "on", "as", "by", "are", "was", "from", "has", "have", "its", "this", "will", "be"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "den", "dem", "ein", "eine", "zu", "auf", "für", "sich", "auch", "von", "wird", "wurde", "bei"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "du", "dans", "pour", "qui", "que", "sur", "au", "aux", "par", "pas", "avec", "sont", "ce"},
+	"es": {"el", "los", "las", "y", "del", "es", "una", "por", "con", "para", "que", "su", "al", "como", "más", "pero", "sus", "fue", "está", "entre"},
+	"it": {"il", "di", "che", "è", "per", "una", "della", "con", "del", "non", "sono", "gli", "alla", "anche", "nel", "delle", "dei", "più", "ha", "questo"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "op", "te", "zijn", "voor", "met", "niet", "aan", "ook", "naar", "wordt", "bij", "heeft", "om"},
+	"pt": {"os", "as", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "dos", "das", "mais", "ao", "pelo", "pela", "são", "foi", "seu"},
+}
+
+// letterHints 某些语言特有的字母，每出现一次计为半个虚词
+var letterHints = map[rune]string{
+	'ß': "de", 'ä': "de", 'ö': "de", 'ü': "de",
+	'ç': "fr", 'è': "fr", 'ê': "fr", 'à': "fr", 'œ': "fr",
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ã': "pt", 'õ': "pt",
+}
+
+var stopwordIndex = buildIndex()
+
+func buildIndex() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}
+
+// Detect 识别文本的主要语言
+func Detect(text string) Result {
+	var han, kana, hangul, cyrillic, latin, letters int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		default:
+			continue
+		}
+		letters++
+	}
+	if letters < minLetters {
+		return Result{}
+	}
+
+	// 中日韩文字每个字符即一个词，少量即可判断；混入的英文代码（如USDT）不影响结果
+	cjk := han + kana
+	switch {
+	case cjk > 0 && float64(cjk) >= 0.3*float64(letters):
+		if float64(kana) >= 0.1*float64(cjk) {
+			return Result{Language: "ja", Confidence: ratio(cjk, letters)}
+		}
+		return Result{Language: "zh", Confidence: ratio(cjk, letters)}
+	case float64(hangul) >= 0.3*float64(letters):
+		return Result{Language: "ko", Confidence: ratio(hangul, letters)}
+	case float64(cyrillic) >= 0.5*float64(letters):
+		return Result{Language: "ru", Confidence: ratio(cyrillic, letters)}
+	case latin == 0:
+		return Result{}
+	}
+	return detectLatin(text)
+}
+
+func detectLatin(text string) Result {
+	scores := make(map[string]float64)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		// 一个词同时是多种语言的虚词时平分
+		languages := stopwordIndex[word]
+		for _, language := range languages {
+			scores[language] += 1 / float64(len(languages))
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		if language, ok := letterHints[r]; ok {
+			scores[language] += 0.5
+		}
+	}
+
+	var best, second string
+	for language, score := range scores {
+		switch {
+		case best == "" || score > scores[best] || score == scores[best] && language < best:
+			best, second = language, best
+		case second == "" || score > scores[second] || score == scores[second] && language < second:
+			second = language
+		}
+	}
+	if best == "" || scores[best] < minStopwords {
+		return Result{}
+	}
+
+	confidence := 1.0
+	if second != "" {
+		confidence = scores[best] / (scores[best] + scores[second])
+	}
+	return Result{Language: best, Confidence: round(confidence)}
+}
+
+func ratio(part, total int) float64 {
+	return round(float64(part) / float64(total))
+}
+
+func round(value float64) float64 {
+	return float64(int(value*100+0.5)) / 100
+}
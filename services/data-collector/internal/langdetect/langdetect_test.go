@@ -0,0 +1,41 @@
+package langdetect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		language string
+		text     string
+	}{
+		{"en", "BlackRock's tokenized treasury fund has crossed $500 million in assets as demand for on-chain yield grows."},
+		{"zh", "贝莱德代币化国债基金BUIDL资产规模突破5亿美元，链上收益需求持续增长。"},
+		{"ja", "ブラックロックのトークン化国債ファンドBUIDLの運用資産が5億ドルを突破した。"},
+		{"ko", "블랙록의 토큰화 국채 펀드 BUIDL 자산 규모가 5억 달러를 돌파했다."},
+		{"de", "Die Tokenisierung von Staatsanleihen wird für institutionelle Anleger immer wichtiger, sagte die Bank."},
+		{"fr", "La tokenisation des obligations d'État est de plus en plus importante pour les investisseurs institutionnels."},
+		{"es", "La tokenización de los bonos del Tesoro es cada vez más importante para los inversores institucionales."},
+		{"ru", "Токенизация государственных облигаций становится всё более важной для институциональных инвесторов."},
+	}
+	for _, tc := range cases {
+		result := Detect(tc.text)
+		assert.Equal(t, tc.language, result.Language, tc.text)
+		assert.Greater(t, result.Confidence, 0.5, tc.text)
+	}
+}
+
+func TestDetectUndetermined(t *testing.T) {
+	assert.Empty(t, Detect("").Language)
+	assert.Empty(t, Detect("USDT").Language)
+	// 只有代码和数字，没有虚词
+	assert.Empty(t, Detect("BUIDL USDY OUSG BENJI 2024 Q3").Language)
+}
+
+func TestDetectMixedScript(t *testing.T) {
+	// 中文报道中常夹带英文代码和机构名
+	result := Detect("Ondo Finance推出USDY代币，面向非美国投资者提供美国国债收益")
+	assert.Equal(t, "zh", result.Language)
+}
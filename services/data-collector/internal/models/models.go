@@ -55,7 +55,7 @@ type BlockchainTransaction struct {
 	GasPrice        *string   `gorm:"type:decimal(78,0)" json:"gas_price"`
 	Status          *uint64   `json:"status"`
 	ContractAddress *string   `json:"contract_address"`
-	Logs            []byte    `gorm:"type:jsonb" json:"logs"`
+	Logs            []byte    `gorm:"type:jsonb;index:idx_blockchain_tx_logs,type:gin" json:"logs"`
 	Timestamp       time.Time `gorm:"not null;index" json:"timestamp"`
 	CreatedAt       time.Time `json:"created_at"`
 }
@@ -63,18 +63,20 @@ type BlockchainTransaction struct {
 // TokenTransfer 代币转账模型
 type TokenTransfer struct {
 	ID              string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Chain           string    `gorm:"not null;index" json:"chain"`
-	TransactionHash string    `gorm:"not null;index" json:"transaction_hash"`
-	LogIndex        uint      `gorm:"not null" json:"log_index"`
+	Chain           string    `gorm:"not null;index;uniqueIndex:idx_token_transfer_unique" json:"chain"`
+	TransactionHash string    `gorm:"not null;index;uniqueIndex:idx_token_transfer_unique" json:"transaction_hash"`
+	LogIndex        uint      `gorm:"not null;uniqueIndex:idx_token_transfer_unique" json:"log_index"`
 	ContractAddress string    `gorm:"not null;index" json:"contract_address"`
-	FromAddress     string    `gorm:"not null;index" json:"from_address"`
-	ToAddress       string    `gorm:"not null;index" json:"to_address"`
+	FromAddress     string    `gorm:"not null;index;index:idx_token_transfer_from_time,priority:1" json:"from_address"`
+	ToAddress       string    `gorm:"not null;index;index:idx_token_transfer_to_time,priority:1" json:"to_address"`
 	Value           string    `gorm:"type:decimal(78,0);not null" json:"value"`
 	TokenSymbol     *string   `json:"token_symbol"`
 	TokenName       *string   `json:"token_name"`
 	TokenDecimals   *uint8    `json:"token_decimals"`
 	BlockNumber     uint64    `gorm:"not null;index" json:"block_number"`
-	Timestamp       time.Time `gorm:"not null;index" json:"timestamp"`
+	Timestamp       time.Time `gorm:"not null;index;index:idx_token_transfer_from_time,priority:2;index:idx_token_transfer_to_time,priority:2" json:"timestamp"`
+	BridgeTransferID *string  `gorm:"type:uuid;index" json:"bridge_transfer_id"`
+	BridgeDirection  *string  `json:"bridge_direction"` // outbound, inbound
 	CreatedAt       time.Time `json:"created_at"`
 
 	// 关联
@@ -96,17 +98,134 @@ type NewsArticle struct {
 	Sentiment   *float64  `gorm:"type:decimal(3,2)" json:"sentiment"` // -1 to 1
 	Relevance   *float64  `gorm:"type:decimal(3,2)" json:"relevance"` // 0 to 1
 	PublishedAt time.Time `gorm:"not null;index" json:"published_at"`
+	StoryID     *string   `gorm:"type:uuid;index" json:"story_id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// SimHash指纹及其分段键，用于识别转载的同一报道
+	Simhash      *int64 `json:"-"`
+	SimhashBands []byte `gorm:"type:jsonb;index:idx_news_simhash_bands,type:gin" json:"-"`
+}
+
+// NewsStory 新闻报道，同一报道被多家媒体转载的文章归为一个报道，CanonicalArticleID为最早采集到的文章
+type NewsStory struct {
+	ID                 string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CanonicalArticleID string    `gorm:"type:uuid;not null" json:"canonical_article_id"`
+	Title              string    `gorm:"not null" json:"title"`
+	Category           *string   `gorm:"index" json:"category"`
+	Sources            []byte    `gorm:"type:jsonb;index:idx_news_story_sources,type:gin" json:"sources"`
+	ArticleCount       int       `gorm:"default:1" json:"article_count"`
+	FirstSeenAt        time.Time `gorm:"not null" json:"first_seen_at"`
+	LastSeenAt         time.Time `gorm:"not null;index" json:"last_seen_at"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// NewsEntity 新闻提及的实体，EntityType为asset时EntityID为资产ID，channel时为渠道ID，issuer时为发行方名称（小写）
+type NewsEntity struct {
+	ID         string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ArticleID  string    `gorm:"type:uuid;not null;uniqueIndex:idx_news_entity_unique" json:"article_id"`
+	EntityType string    `gorm:"not null;uniqueIndex:idx_news_entity_unique;index:idx_news_entity_lookup,priority:1" json:"entity_type"` // asset, issuer, channel
+	EntityID   string    `gorm:"not null;uniqueIndex:idx_news_entity_unique;index:idx_news_entity_lookup,priority:2" json:"entity_id"`
+	Name       string    `json:"name"`
+	MatchType  string    `json:"match_type"` // address, name, alias, symbol, issuer
+	Matched    string    `json:"matched"`
+	Confidence float64   `gorm:"type:decimal(3,2);not null" json:"confidence"`
+	Mentions   int       `gorm:"default:1" json:"mentions"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RegulatoryEvent 从新闻中识别的监管或信用事件，保存时复制文章的标题和链接，文章被清理后仍可追溯
+//
+// 置信度足够且关联到资产或渠道的事件直接发布（published），其余进入分析师复核队列（pending_review）
+type RegulatoryEvent struct {
+	ID                   string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ArticleID            string     `gorm:"type:uuid;not null;uniqueIndex" json:"article_id"`
+	StoryID              *string    `gorm:"type:uuid;index" json:"story_id"`
+	EventType            string     `gorm:"not null;index" json:"event_type"` // enforcement_action, license_revocation, sanctions, trading_halt, issuer_default
+	Severity             string     `gorm:"not null" json:"severity"`         // low, medium, high, critical
+	Title                string     `gorm:"not null" json:"title"`
+	URL                  string     `gorm:"not null" json:"url"`
+	Source               string     `json:"source"`
+	Jurisdictions        []byte     `gorm:"type:jsonb" json:"jurisdictions"`
+	Regulators           []byte     `gorm:"type:jsonb" json:"regulators"`
+	Matched              []byte     `gorm:"type:jsonb" json:"matched"`
+	AssetIDs             []byte     `gorm:"type:jsonb;index:idx_regulatory_event_assets,type:gin" json:"asset_ids"`
+	ChannelIDs           []byte     `gorm:"type:jsonb;index:idx_regulatory_event_channels,type:gin" json:"channel_ids"`
+	Confidence           float64    `gorm:"type:decimal(3,2);not null" json:"confidence"` // 识别置信度与来源可信度之积
+	ClassifierConfidence float64    `gorm:"type:decimal(3,2)" json:"classifier_confidence"`
+	SourceConfidence     float64    `gorm:"type:decimal(3,2)" json:"source_confidence"`
+	Status               string     `gorm:"not null;index" json:"status"` // pending_review, published, rejected
+	ReviewedBy           *string    `json:"reviewed_by"`
+	ReviewNote           *string    `gorm:"type:text" json:"review_note"`
+	ReviewedAt           *time.Time `json:"reviewed_at"`
+	PublishedAt          *time.Time `gorm:"index" json:"published_at"`
+	ArticlePublishedAt   time.Time  `gorm:"not null" json:"article_published_at"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// NewsSubscription NewsAPI检索订阅，Type为keyword时按Keyword检索，为topic时按词典中该分类的关键词检索
+//
+// Origin为asset的订阅由活跃资产的名称和别名生成，资产停用后自动停用。统计字段为累计值，LastFound为最近一次检索返回的文章数
+type NewsSubscription struct {
+	ID              string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type            string     `gorm:"not null;default:'keyword';uniqueIndex:idx_news_subscription_unique,priority:1" json:"type"` // keyword, topic
+	Keyword         string     `gorm:"not null;uniqueIndex:idx_news_subscription_unique,priority:2" json:"keyword"`
+	Language        string     `gorm:"not null;default:'en';uniqueIndex:idx_news_subscription_unique,priority:3" json:"language"`
+	Sources         []byte     `gorm:"type:jsonb" json:"sources"` // NewsAPI来源ID或域名，为空时不限
+	IntervalSeconds int        `gorm:"default:0" json:"interval_seconds"`
+	MinRelevance    float64    `gorm:"type:decimal(3,2);default:0" json:"min_relevance"`
+	Origin          string     `gorm:"not null;default:'manual'" json:"origin"` // manual, config, asset
+	AssetID         *string    `gorm:"type:uuid;index" json:"asset_id"`
+	IsActive        bool       `gorm:"default:true;index" json:"is_active"`
+	LastRunAt       *time.Time `json:"last_run_at"`
+	NextRunAt       *time.Time `gorm:"index" json:"next_run_at"`
+	LastFound       int        `gorm:"default:0" json:"last_found"`
+	ArticlesFound   int64      `gorm:"default:0" json:"articles_found"`
+	ArticlesSaved   int64      `gorm:"default:0" json:"articles_saved"`
+	Duplicates      int64      `gorm:"default:0" json:"duplicates"`
+	Filtered        int64      `gorm:"default:0" json:"filtered"` // 相关性低于MinRelevance未保存的文章
+	ErrorCount      int        `gorm:"default:0" json:"error_count"`
+	LastError       *string    `json:"last_error"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Filing 监管申报（如EDGAR的10-K、N-CSR），Issuer为匹配到的发行方名称（小写），与news_entities中issuer实体的ID一致
+//
+// AUM、NAV等关键数据取自XBRL，数据发布晚于申报索引时在之后的采集中补充
+type Filing struct {
+	ID           string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Accession    string     `gorm:"not null;uniqueIndex" json:"accession"`
+	CIK          string     `gorm:"not null;index" json:"cik"`
+	Company      string     `gorm:"not null" json:"company"`
+	FormType     string     `gorm:"not null;index" json:"form_type"`
+	FiledAt      time.Time  `gorm:"not null;index" json:"filed_at"`
+	URL          string     `gorm:"not null" json:"url"`
+	Issuer       string     `gorm:"index" json:"issuer"`
+	AssetIDs     []byte     `gorm:"type:jsonb;index:idx_filing_assets,type:gin" json:"asset_ids"`
+	PeriodEnd    *time.Time `json:"period_end"`
+	FiscalYear   *int       `json:"fiscal_year"`
+	FiscalPeriod *string    `json:"fiscal_period"`
+	Currency     *string    `json:"currency"`
+	AUM          *float64   `gorm:"type:decimal(30,2)" json:"aum"`
+	NAV          *float64   `gorm:"type:decimal(30,2)" json:"nav"`
+	NAVPerShare  *float64   `gorm:"type:decimal(20,8)" json:"nav_per_share"`
+	Facts        []byte     `gorm:"type:jsonb" json:"facts"` // 提取的全部关键数据
+	Source       string     `gorm:"not null" json:"source"`  // local, remote
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
 // DataSource 数据源模型
 type DataSource struct {
 	ID          string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name        string    `gorm:"not null;uniqueIndex" json:"name"`
-	Type        string    `gorm:"not null" json:"type"` // price, blockchain, news
+	Type        string    `gorm:"not null" json:"type"` // price, blockchain, news, feed, filing
 	URL         string    `gorm:"not null" json:"url"`
-	APIKey      *string   `json:"-"` // 不在JSON中暴露
+	APIKey      *string   `json:"-"` // 不在JSON中暴露，配置密钥文件后加密保存（enc:v1:<密钥ID>:...）
+	AuthHeader  *string   `json:"auth_header"` // 携带API密钥的请求头，为空时使用Authorization: Bearer
 	Config      []byte    `gorm:"type:jsonb" json:"config"`
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 	RateLimit   *int      `json:"rate_limit"` // 每分钟请求数
@@ -121,7 +240,7 @@ type DataSource struct {
 // SyncJob 同步任务模型
 type SyncJob struct {
 	ID           string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Type         string    `gorm:"not null;index" json:"type"` // price, blockchain, news
+	Type         string    `gorm:"not null;index" json:"type"` // price, blockchain, news, scheduled
 	Status       string    `gorm:"not null;index" json:"status"` // pending, running, completed, failed
 	DataSourceID *string   `gorm:"type:uuid" json:"data_source_id"`
 	Config       []byte    `gorm:"type:jsonb" json:"config"`
@@ -136,6 +255,12 @@ type SyncJob struct {
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 
+	// 调度任务的执行记录（Type为scheduled），与shared/scheduler.Run的列一致
+	ScheduledJobID *string    `gorm:"type:uuid;index" json:"scheduled_job_id,omitempty"`
+	Trigger        *string    `json:"trigger,omitempty"` // schedule, manual, catch_up
+	ScheduledAt    *time.Time `json:"scheduled_at,omitempty"`
+	DurationMs     *int64     `json:"duration_ms,omitempty"`
+
 	// 关联
 	DataSource *DataSource `gorm:"foreignKey:DataSourceID" json:"data_source,omitempty"`
 }
@@ -157,6 +282,145 @@ type MetricData struct {
 	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
 }
 
+// ChainCursor 链索引游标，与索引数据在同一事务中持久化。
+// 未初始化（从未写入过）的游标从链头附近开始同步，已初始化的游标即使为0也从该区块之后继续
+type ChainCursor struct {
+	Chain       string    `gorm:"primaryKey" json:"chain"`
+	LastBlock   uint64    `gorm:"not null;default:0" json:"last_block"`
+	Initialized bool      `gorm:"not null;default:false" json:"initialized"`
+	Paused      bool      `gorm:"default:false" json:"paused"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SignatureCursor 按地址查询签名（如Solana的getSignaturesForAddress）时最近记录的已索引签名，
+// 作为下次查询的until参数
+type SignatureCursor struct {
+	Chain     string    `gorm:"primaryKey" json:"chain"`
+	Address   string    `gorm:"primaryKey" json:"address"`
+	Signature string    `gorm:"not null" json:"signature"`
+	Slot      uint64    `gorm:"not null" json:"slot"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchedWallet 用户关联的自托管钱包地址
+type WatchedWallet struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    string    `gorm:"not null;index;uniqueIndex:idx_watched_wallet_user_address" json:"user_id"`
+	Address   string    `gorm:"not null;index;uniqueIndex:idx_watched_wallet_user_address" json:"address"`
+	Label     *string   `json:"label"`
+	IsActive  bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WalletBalance 钱包在某条链上某个代币的已确认余额
+type WalletBalance struct {
+	ID          string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WalletID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_wallet_balance_unique" json:"wallet_id"`
+	UserID      string    `gorm:"not null;index" json:"user_id"`
+	Chain       string    `gorm:"not null;uniqueIndex:idx_wallet_balance_unique" json:"chain"`
+	Token       string    `gorm:"not null;uniqueIndex:idx_wallet_balance_unique" json:"token"`
+	Address     string    `gorm:"not null;index" json:"address"`
+	Balance     string    `gorm:"type:decimal(78,0);not null" json:"balance"`
+	BlockNumber uint64    `gorm:"not null" json:"block_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联
+	Wallet *WatchedWallet `gorm:"foreignKey:WalletID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Chain 链注册表，EVM链的连接与索引参数，可在运行时通过管理接口增删
+type Chain struct {
+	Name           string    `gorm:"primaryKey" json:"name"`
+	ChainID        int64     `gorm:"not null;uniqueIndex" json:"chain_id"`
+	RPCEndpoints   []byte    `gorm:"type:jsonb;not null" json:"rpc_endpoints"` // [{"url": "...", "priority": 0}]，priority小的优先
+	BlockTimeMs    int64     `gorm:"not null;default:0" json:"block_time_ms"`
+	Confirmations  uint64    `gorm:"not null;default:0" json:"confirmations"`
+	NativeSymbol   string    `json:"native_symbol"`
+	NativeDecimals uint8     `gorm:"not null;default:18" json:"native_decimals"`
+	ExplorerURL    string    `json:"explorer_url"`
+	IsActive       bool      `gorm:"default:true;index" json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ContractABI 合约ABI，Address为空时按事件签名匹配跟踪合约的日志
+type ContractABI struct {
+	ID        string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name      string    `json:"name"`
+	Chain     string    `gorm:"not null;default:'';uniqueIndex:idx_contract_abi_address,where:address <> ''" json:"chain"` // 为空时适用于所有EVM链
+	Address   string    `gorm:"not null;default:'';uniqueIndex:idx_contract_abi_address,where:address <> ''" json:"address"`
+	ABI       []byte    `gorm:"column:abi;type:jsonb;not null" json:"abi"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DecodedEvent 按ABI解码后的事件日志，Args为命名参数
+type DecodedEvent struct {
+	ID              string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Chain           string    `gorm:"not null;index;uniqueIndex:idx_decoded_event_unique" json:"chain"`
+	TransactionHash string    `gorm:"not null;index;uniqueIndex:idx_decoded_event_unique" json:"transaction_hash"`
+	LogIndex        uint      `gorm:"not null;uniqueIndex:idx_decoded_event_unique" json:"log_index"`
+	ContractAddress string    `gorm:"not null;index:idx_decoded_event_contract_name,priority:1" json:"contract_address"`
+	EventName       string    `gorm:"not null;index:idx_decoded_event_contract_name,priority:2;index" json:"event_name"`
+	Signature       string    `gorm:"not null" json:"signature"` // Transfer(address,address,uint256)
+	Topic           string    `gorm:"not null;index" json:"topic"`
+	ABIID           *string   `gorm:"column:abi_id;type:uuid;index" json:"abi_id"`
+	Args            []byte    `gorm:"type:jsonb;index:idx_decoded_event_args,type:gin" json:"args"`
+	BlockNumber     uint64    `gorm:"not null;index" json:"block_number"`
+	Timestamp       time.Time `gorm:"not null;index" json:"timestamp"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// BridgeContract 已知的跨链桥合约，按(链, 地址, 事件名)定义哪一侧的事件以及消息ID等参数的位置
+// EventName为空时只作为锁定模式的托管合约，其持有的代币不计入流通量
+type BridgeContract struct {
+	ID             string    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Protocol       string    `gorm:"not null;index" json:"protocol"` // ccip, layerzero, wormhole...
+	Chain          string    `gorm:"not null;uniqueIndex:idx_bridge_contract_event" json:"chain"`
+	Address        string    `gorm:"not null;uniqueIndex:idx_bridge_contract_event" json:"address"`
+	EventName      string    `gorm:"not null;default:'';uniqueIndex:idx_bridge_contract_event" json:"event_name"`
+	Direction      string    `json:"direction"` // outbound（锁定/销毁）, inbound（铸造/释放）
+	MessageIDArg   string    `json:"message_id_arg"`
+	TokenArg       string    `json:"token_arg"`
+	AmountArg      string    `json:"amount_arg"`
+	SenderArg      string    `json:"sender_arg"`
+	RecipientArg   string    `json:"recipient_arg"`
+	RemoteChainArg string    `json:"remote_chain_arg"`
+	Escrow         bool      `gorm:"default:false" json:"escrow"`
+	IsActive       bool      `gorm:"default:true;index" json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BridgeTransfer 按消息ID关联的跨链转账，两侧都观察到后状态为completed
+type BridgeTransfer struct {
+	ID             string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Protocol       string     `gorm:"not null;uniqueIndex:idx_bridge_transfer_message" json:"protocol"`
+	MessageID      string     `gorm:"not null;uniqueIndex:idx_bridge_transfer_message" json:"message_id"`
+	Status         string     `gorm:"not null;index" json:"status"` // pending, completed
+	SourceChain    *string    `json:"source_chain"`
+	SourceTxHash   *string    `gorm:"index" json:"source_tx_hash"`
+	SourceLogIndex *uint      `json:"source_log_index"`
+	SourceToken    *string    `json:"source_token"`
+	DestChain      *string    `json:"dest_chain"`
+	DestTxHash     *string    `gorm:"index" json:"dest_tx_hash"`
+	DestLogIndex   *uint      `json:"dest_log_index"`
+	DestToken      *string    `json:"dest_token"`
+	RemoteChain    *string    `json:"remote_chain"` // 出站事件中的目标链标识（协议自定义，如CCIP chain selector）
+	Sender         *string    `gorm:"index" json:"sender"`
+	Recipient      *string    `gorm:"index" json:"recipient"`
+	Amount         *string    `gorm:"type:decimal(78,0)" json:"amount"` // 源链上的原始数量
+	SentAt         *time.Time `json:"sent_at"`
+	ReceivedAt     *time.Time `json:"received_at"`
+	Timestamp      time.Time  `gorm:"not null;index" json:"timestamp"` // 最早观察到的一侧的时间
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
 // 表名设置
 func (Asset) TableName() string {
 	return "assets"
@@ -189,3 +453,59 @@ func (SyncJob) TableName() string {
 func (MetricData) TableName() string {
 	return "metric_data"
 }
+
+func (ChainCursor) TableName() string {
+	return "chain_cursors"
+}
+
+func (SignatureCursor) TableName() string {
+	return "signature_cursors"
+}
+
+func (WatchedWallet) TableName() string {
+	return "watched_wallets"
+}
+
+func (WalletBalance) TableName() string {
+	return "wallet_balances"
+}
+
+func (Chain) TableName() string {
+	return "chains"
+}
+
+func (ContractABI) TableName() string {
+	return "contract_abis"
+}
+
+func (DecodedEvent) TableName() string {
+	return "decoded_events"
+}
+
+func (BridgeContract) TableName() string {
+	return "bridge_contracts"
+}
+
+func (BridgeTransfer) TableName() string {
+	return "bridge_transfers"
+}
+
+func (NewsStory) TableName() string {
+	return "news_stories"
+}
+
+func (NewsEntity) TableName() string {
+	return "news_entities"
+}
+
+func (RegulatoryEvent) TableName() string {
+	return "regulatory_events"
+}
+
+func (NewsSubscription) TableName() string {
+	return "news_subscriptions"
+}
+
+func (Filing) TableName() string {
+	return "filings"
+}
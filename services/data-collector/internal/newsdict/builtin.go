@@ -0,0 +1,142 @@
+package newsdict
+
+// builtin 内置词典；英文词典与原有的分类和标签规则一致，其他语言只收录本语言的写法，英文写法由英文词典覆盖
+var builtin = []*Dictionary{english, chinese, japanese, german, french}
+
+var english = &Dictionary{
+	Language: "en",
+	Categories: []Terms{
+		{Name: "stablecoin", Terms: []string{"stablecoin", "usdt", "usdc"}},
+		{Name: "treasury", Terms: []string{"treasury", "bond"}},
+		{Name: "rwa", Terms: []string{"rwa", "real world asset"}},
+		{Name: "defi", Terms: []string{"defi", "decentralized finance"}},
+		{Name: "regulation", Terms: []string{"regulation", "regulatory"}},
+	},
+	Tags: []Terms{
+		{Name: "bitcoin", Terms: []string{"bitcoin"}},
+		{Name: "ethereum", Terms: []string{"ethereum"}},
+		{Name: "blockchain", Terms: []string{"blockchain"}},
+		{Name: "crypto", Terms: []string{"crypto"}},
+		{Name: "cryptocurrency", Terms: []string{"cryptocurrency"}},
+		{Name: "stablecoin", Terms: []string{"stablecoin"}},
+		{Name: "defi", Terms: []string{"defi"}},
+		{Name: "rwa", Terms: []string{"rwa"}},
+		{Name: "treasury", Terms: []string{"treasury"}},
+		{Name: "bond", Terms: []string{"bond"}},
+		{Name: "yield", Terms: []string{"yield"}},
+		{Name: "regulation", Terms: []string{"regulation"}},
+		{Name: "sec", Terms: []string{"sec"}},
+		{Name: "fed", Terms: []string{"fed"}},
+		{Name: "central bank", Terms: []string{"central bank"}},
+		{Name: "cbdc", Terms: []string{"cbdc"}},
+	},
+	Relevant: []string{"rwa", "real world assets", "stablecoin", "treasury", "defi"},
+}
+
+var chinese = &Dictionary{
+	Language: "zh",
+	Categories: []Terms{
+		{Name: "stablecoin", Terms: []string{"稳定币", "泰达币"}},
+		{Name: "treasury", Terms: []string{"国债", "美债", "债券"}},
+		{Name: "rwa", Terms: []string{"现实世界资产", "真实世界资产", "资产代币化", "代币化"}},
+		{Name: "defi", Terms: []string{"去中心化金融"}},
+		{Name: "regulation", Terms: []string{"监管", "法规", "证监会", "合规"}},
+	},
+	Tags: []Terms{
+		{Name: "bitcoin", Terms: []string{"比特币"}},
+		{Name: "ethereum", Terms: []string{"以太坊"}},
+		{Name: "blockchain", Terms: []string{"区块链"}},
+		{Name: "crypto", Terms: []string{"加密货币", "加密资产", "数字货币"}},
+		{Name: "stablecoin", Terms: []string{"稳定币"}},
+		{Name: "defi", Terms: []string{"去中心化金融"}},
+		{Name: "rwa", Terms: []string{"现实世界资产", "真实世界资产"}},
+		{Name: "treasury", Terms: []string{"国债", "美债"}},
+		{Name: "bond", Terms: []string{"债券"}},
+		{Name: "yield", Terms: []string{"收益率"}},
+		{Name: "regulation", Terms: []string{"监管"}},
+		{Name: "sec", Terms: []string{"美国证券交易委员会", "美国证监会"}},
+		{Name: "fed", Terms: []string{"美联储"}},
+		{Name: "central bank", Terms: []string{"央行", "中央银行"}},
+		{Name: "cbdc", Terms: []string{"央行数字货币", "数字人民币"}},
+	},
+	Relevant: []string{"现实世界资产", "稳定币", "国债", "去中心化金融", "代币化"},
+}
+
+var japanese = &Dictionary{
+	Language: "ja",
+	Categories: []Terms{
+		{Name: "stablecoin", Terms: []string{"ステーブルコイン"}},
+		{Name: "treasury", Terms: []string{"国債", "米国債", "債券"}},
+		{Name: "rwa", Terms: []string{"現実資産", "実物資産", "トークン化", "セキュリティトークン"}},
+		{Name: "defi", Terms: []string{"分散型金融"}},
+		{Name: "regulation", Terms: []string{"規制", "金融庁"}},
+	},
+	Tags: []Terms{
+		{Name: "bitcoin", Terms: []string{"ビットコイン"}},
+		{Name: "ethereum", Terms: []string{"イーサリアム"}},
+		{Name: "blockchain", Terms: []string{"ブロックチェーン"}},
+		{Name: "crypto", Terms: []string{"暗号資産", "仮想通貨"}},
+		{Name: "stablecoin", Terms: []string{"ステーブルコイン"}},
+		{Name: "defi", Terms: []string{"分散型金融"}},
+		{Name: "rwa", Terms: []string{"現実資産", "実物資産"}},
+		{Name: "treasury", Terms: []string{"国債"}},
+		{Name: "bond", Terms: []string{"債券"}},
+		{Name: "yield", Terms: []string{"利回り"}},
+		{Name: "regulation", Terms: []string{"規制"}},
+		{Name: "sec", Terms: []string{"米証券取引委員会"}},
+		{Name: "fed", Terms: []string{"連邦準備制度", "frb"}},
+		{Name: "central bank", Terms: []string{"中央銀行", "日銀", "日本銀行"}},
+		{Name: "cbdc", Terms: []string{"中央銀行デジタル通貨", "デジタル円"}},
+	},
+	Relevant: []string{"現実資産", "ステーブルコイン", "国債", "分散型金融", "トークン化"},
+}
+
+var german = &Dictionary{
+	Language: "de",
+	Categories: []Terms{
+		{Name: "stablecoin", Terms: []string{"stablecoin", "wertstabile kryptowährung"}},
+		{Name: "treasury", Terms: []string{"staatsanleihe", "bundesanleihe", "anleihe"}},
+		{Name: "rwa", Terms: []string{"tokenisierung", "tokenisierte", "realwerte", "sachwerte"}},
+		{Name: "defi", Terms: []string{"dezentrale finanz"}},
+		{Name: "regulation", Terms: []string{"regulierung", "regulatorisch", "finanzaufsicht", "bafin"}},
+	},
+	Tags: []Terms{
+		{Name: "crypto", Terms: []string{"krypto"}},
+		{Name: "cryptocurrency", Terms: []string{"kryptowährung"}},
+		{Name: "rwa", Terms: []string{"tokenisierung", "realwerte"}},
+		{Name: "defi", Terms: []string{"dezentrale finanz"}},
+		{Name: "treasury", Terms: []string{"staatsanleihe", "bundesanleihe"}},
+		{Name: "bond", Terms: []string{"anleihe"}},
+		{Name: "yield", Terms: []string{"rendite"}},
+		{Name: "regulation", Terms: []string{"regulierung"}},
+		{Name: "fed", Terms: []string{"us-notenbank"}},
+		{Name: "central bank", Terms: []string{"zentralbank", "bundesbank", "ezb"}},
+		{Name: "cbdc", Terms: []string{"digitaler euro", "digitale euro", "digitalen euro"}},
+	},
+	Relevant: []string{"tokenisierung", "staatsanleihe", "realwerte", "dezentrale finanz"},
+}
+
+var french = &Dictionary{
+	Language: "fr",
+	Categories: []Terms{
+		{Name: "stablecoin", Terms: []string{"stablecoin", "cryptomonnaie stable"}},
+		{Name: "treasury", Terms: []string{"bons du trésor", "obligation d'état", "obligations d'état", "obligataire"}},
+		{Name: "rwa", Terms: []string{"actifs réels", "actifs du monde réel", "tokenisation", "tokenisé"}},
+		{Name: "defi", Terms: []string{"finance décentralisée"}},
+		{Name: "regulation", Terms: []string{"réglementation", "régulation", "régulateur", "autorité des marchés financiers"}},
+	},
+	Tags: []Terms{
+		{Name: "blockchain", Terms: []string{"chaîne de blocs"}},
+		{Name: "cryptocurrency", Terms: []string{"cryptomonnaie", "crypto-monnaie"}},
+		{Name: "rwa", Terms: []string{"actifs réels", "tokenisation"}},
+		{Name: "defi", Terms: []string{"finance décentralisée"}},
+		{Name: "treasury", Terms: []string{"bons du trésor"}},
+		{Name: "bond", Terms: []string{"obligataire", "obligations d'état"}},
+		{Name: "yield", Terms: []string{"rendement"}},
+		{Name: "regulation", Terms: []string{"réglementation", "régulation"}},
+		{Name: "fed", Terms: []string{"réserve fédérale"}},
+		{Name: "central bank", Terms: []string{"banque centrale", "bce"}},
+		{Name: "cbdc", Terms: []string{"euro numérique", "monnaie numérique de banque centrale"}},
+	},
+	Relevant: []string{"actifs réels", "tokenisation", "bons du trésor", "finance décentralisée"},
+}
@@ -0,0 +1,170 @@
+// Package newsdict 按语言组织的新闻分类和标签词典
+//
+// 分类和标签使用统一的英文名称（如"稳定币"和"Stablecoin"都归入stablecoin），不同语言的新闻可以一起筛选。
+// 内置英文、中文、日文、德文和法文词典，可以通过Register或LoadDir添加或替换
+package newsdict
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultCategory 没有命中任何分类时使用的分类
+const DefaultCategory = "general"
+
+// Terms 一个分类或标签及其在该语言中的关键词
+type Terms struct {
+	Name  string   `json:"name"`
+	Terms []string `json:"terms"`
+}
+
+// Dictionary 一种语言的词典，Categories按优先级排列，Relevant为提高相关性分数的术语
+type Dictionary struct {
+	Language   string   `json:"language"`
+	Categories []Terms  `json:"categories"`
+	Tags       []Terms  `json:"tags"`
+	Relevant   []string `json:"relevant"`
+}
+
+// Registry 按语言查找词典，可并发使用
+type Registry struct {
+	mu           sync.RWMutex
+	dictionaries map[string]*Dictionary
+}
+
+// NewRegistry 创建包含内置词典的注册表
+func NewRegistry() *Registry {
+	r := &Registry{dictionaries: make(map[string]*Dictionary)}
+	for _, d := range builtin {
+		r.Register(d)
+	}
+	return r
+}
+
+// Register 添加词典，同一语言的词典会被替换
+func (r *Registry) Register(d *Dictionary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dictionaries[strings.ToLower(d.Language)] = d
+}
+
+// LoadDir 加载目录中的*.json词典文件，文件格式与Dictionary一致
+func (r *Registry) LoadDir(dir string) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read dictionary %s: %v", file, err)
+		}
+		var d Dictionary
+		if err := json.Unmarshal(data, &d); err != nil {
+			return 0, fmt.Errorf("failed to parse dictionary %s: %v", file, err)
+		}
+		if d.Language == "" {
+			return 0, fmt.Errorf("dictionary %s has no language", file)
+		}
+		r.Register(&d)
+	}
+	return len(files), nil
+}
+
+// For 返回用于某种语言的词典集合：该语言的词典在前，英文词典在后（非英文报道也常使用英文代码和术语）
+func (r *Registry) For(language string) Set {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var set Set
+	if d, ok := r.dictionaries[strings.ToLower(language)]; ok {
+		set = append(set, d)
+	}
+	if d, ok := r.dictionaries["en"]; ok && !strings.EqualFold(language, "en") {
+		set = append(set, d)
+	}
+	return set
+}
+
+// Set 按顺序使用的一组词典
+type Set []*Dictionary
+
+// Category 返回第一个命中的分类
+func (s Set) Category(text string) string {
+	text = strings.ToLower(text)
+	for _, d := range s {
+		for _, category := range d.Categories {
+			if containsAny(text, category.Terms) {
+				return category.Name
+			}
+		}
+	}
+	return DefaultCategory
+}
+
+// Topic 返回各词典中某个分类的关键词，按词典顺序去重；分类不存在时返回空
+func (s Set) Topic(name string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, d := range s {
+		for _, category := range d.Categories {
+			if category.Name != name {
+				continue
+			}
+			for _, term := range category.Terms {
+				key := strings.ToLower(term)
+				if term != "" && !seen[key] {
+					seen[key] = true
+					terms = append(terms, term)
+				}
+			}
+		}
+	}
+	return terms
+}
+
+// MatchTags 返回所有命中的标签，按词典中的顺序去重
+func (s Set) MatchTags(text string) []string {
+	text = strings.ToLower(text)
+	var tags []string
+	seen := make(map[string]bool)
+	for _, d := range s {
+		for _, tag := range d.Tags {
+			if !seen[tag.Name] && containsAny(text, tag.Terms) {
+				seen[tag.Name] = true
+				tags = append(tags, tag.Name)
+			}
+		}
+	}
+	return tags
+}
+
+// RelevantTerms 返回文本中出现的相关术语数，各词典中相同的术语只计一次
+func (s Set) RelevantTerms(text string) int {
+	text = strings.ToLower(text)
+	seen := make(map[string]bool)
+	for _, d := range s {
+		for _, term := range d.Relevant {
+			term = strings.ToLower(term)
+			if !seen[term] && strings.Contains(text, term) {
+				seen[term] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+// containsAny 按子串匹配，中日文没有词边界，拉丁字母词典与原有的英文规则保持一致
+func containsAny(text string, terms []string) bool {
+	for _, term := range terms {
+		if term != "" && strings.Contains(text, strings.ToLower(term)) {
+			return true
+		}
+	}
+	return false
+}
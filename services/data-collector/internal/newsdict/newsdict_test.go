@@ -0,0 +1,102 @@
+package newsdict
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnglishMatchesOriginalRules(t *testing.T) {
+	set := NewRegistry().For("en")
+	require.Len(t, set, 1)
+
+	text := "SEC approves tokenized Treasury fund as stablecoin yields climb"
+	assert.Equal(t, "stablecoin", set.Category(text))
+	assert.Equal(t, []string{"stablecoin", "treasury", "yield", "sec"}, set.MatchTags(text))
+	assert.Equal(t, 2, set.RelevantTerms(text))
+	assert.Equal(t, DefaultCategory, set.Category("Quarterly earnings beat expectations"))
+}
+
+func TestLanguageDictionaries(t *testing.T) {
+	registry := NewRegistry()
+
+	zh := registry.For("zh")
+	require.Len(t, zh, 2)
+	text := "香港证监会发布代币化国债产品指引，USDT发行方表示欢迎"
+	// 本语言的词典优先
+	assert.Equal(t, "treasury", zh.Category(text))
+	assert.Equal(t, []string{"treasury"}, zh.MatchTags(text))
+	assert.Equal(t, 2, zh.RelevantTerms(text))
+
+	ja := registry.For("ja")
+	assert.Equal(t, "stablecoin", ja.Category("金融庁、ステーブルコインの発行を認可"))
+	assert.Equal(t, []string{"stablecoin", "regulation"}, ja.MatchTags("金融庁、ステーブルコインの発行を規制"))
+
+	de := registry.For("de")
+	assert.Equal(t, "rwa", de.Category("Die Tokenisierung von Realwerten gewinnt an Fahrt"))
+	assert.Equal(t, []string{"rwa", "central bank", "cbdc"}, de.MatchTags("Tokenisierung und der digitale Euro: EZB äußert sich"))
+
+	fr := registry.For("FR")
+	assert.Equal(t, "regulation", fr.Category("Le régulateur publie de nouvelles règles"))
+	assert.Equal(t, []string{"rwa", "central bank", "cbdc"}, fr.MatchTags("La tokenisation et l'euro numérique selon la Banque centrale"))
+
+	// 没有词典的语言只使用英文词典
+	es := registry.For("es")
+	require.Len(t, es, 1)
+	assert.Equal(t, "defi", es.Category("El sector DeFi crece"))
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "es.json"), []byte(`{
+		"language": "es",
+		"categories": [{"name": "stablecoin", "terms": ["moneda estable"]}],
+		"tags": [{"name": "regulation", "terms": ["regulación"]}],
+		"relevant": ["tokenización"]
+	}`), 0o644)
+	require.NoError(t, err)
+
+	registry := NewRegistry()
+	loaded, err := registry.LoadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+
+	es := registry.For("es")
+	require.Len(t, es, 2)
+	text := "Nueva regulación para la moneda estable y la tokenización"
+	assert.Equal(t, "stablecoin", es.Category(text))
+	assert.Equal(t, []string{"regulation"}, es.MatchTags(text))
+	assert.Equal(t, 1, es.RelevantTerms(text))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"categories": []}`), 0o644))
+	_, err = NewRegistry().LoadDir(dir)
+	assert.Error(t, err)
+}
+
+func TestTopic(t *testing.T) {
+	registry := NewRegistry()
+
+	assert.Equal(t, []string{"stablecoin", "usdt", "usdc"}, registry.For("en").Topic("stablecoin"))
+	// 本语言的关键词在前，英文关键词在后，重复的只保留一次
+	assert.Equal(t, []string{"stablecoin", "wertstabile kryptowährung", "usdt", "usdc"}, registry.For("de").Topic("stablecoin"))
+	assert.Empty(t, registry.For("en").Topic("unknown"))
+}
+
+func TestSearchConfig(t *testing.T) {
+	assert.Equal(t, "english", SearchConfig("en"))
+	assert.Equal(t, "german", SearchConfig(" DE "))
+	// 中日韩文没有词干分析配置
+	for _, language := range []string{"zh", "ja", "ko", ""} {
+		assert.Equal(t, SimpleSearchConfig, SearchConfig(language), language)
+	}
+
+	sql := SearchConfigSQL("news_articles.language")
+	assert.Contains(t, sql, "CASE lower(news_articles.language)")
+	assert.Contains(t, sql, "WHEN 'en' THEN 'english'::regconfig")
+	assert.Contains(t, sql, "ELSE 'simple'::regconfig END")
+	// 表达式是确定的，生成列和查询才能保持一致
+	assert.Equal(t, sql, SearchConfigSQL("news_articles.language"))
+}
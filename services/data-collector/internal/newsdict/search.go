@@ -0,0 +1,52 @@
+package newsdict
+
+import (
+	"sort"
+	"strings"
+)
+
+// SimpleSearchConfig 不做词干分析的检索配置，用于中文、日文、韩文等PostgreSQL没有内置配置的语言
+const SimpleSearchConfig = "simple"
+
+// searchConfigs 有内置词干分析配置的语言
+var searchConfigs = map[string]string{
+	"da": "danish",
+	"de": "german",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"it": "italian",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ru": "russian",
+	"sv": "swedish",
+}
+
+// SearchConfig 语言对应的PostgreSQL全文检索配置，未知语言使用simple
+func SearchConfig(language string) string {
+	if config, ok := searchConfigs[strings.ToLower(strings.TrimSpace(language))]; ok {
+		return config
+	}
+	return SimpleSearchConfig
+}
+
+// SearchConfigSQL 按语言列选择检索配置的SQL表达式，结果为regconfig且是IMMUTABLE的，可以用于生成列
+//
+// 生成列和查询必须使用同一个表达式，否则检索词和文章的分词方式不一致
+func SearchConfigSQL(column string) string {
+	languages := make([]string, 0, len(searchConfigs))
+	for language := range searchConfigs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	var b strings.Builder
+	b.WriteString("CASE lower(" + column + ")")
+	for _, language := range languages {
+		b.WriteString(" WHEN '" + language + "' THEN '" + searchConfigs[language] + "'::regconfig")
+	}
+	b.WriteString(" ELSE '" + SimpleSearchConfig + "'::regconfig END")
+	return b.String()
+}
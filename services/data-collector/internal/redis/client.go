@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,6 +25,7 @@ func NewClient(redisURL string) (*Client, error) {
 	}
 
 	client := redis.NewClient(opts)
+	client.AddHook(tracing.RedisHook{})
 	
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -243,3 +247,21 @@ func (c *Client) Ping(ctx context.Context) error {
 func (c *Client) Close() error {
 	return c.client.Close()
 }
+
+// PoolStats 连接池状态，用于metrics.RegisterRedisPool
+func (c *Client) PoolStats() metrics.RedisPoolStats {
+	pool := c.client.PoolStats()
+	return metrics.RedisPoolStats{
+		Hits:       pool.Hits,
+		Misses:     pool.Misses,
+		Timeouts:   pool.Timeouts,
+		TotalConns: pool.TotalConns,
+		IdleConns:  pool.IdleConns,
+		StaleConns: pool.StaleConns,
+	}
+}
+
+// StatsSection Redis的keyspace命中率，用于/admin/stats
+func (c *Client) StatsSection() stats.Section {
+	return stats.RedisSection(c.client)
+}
@@ -0,0 +1,294 @@
+// Package regulatory 按规则识别新闻中的监管和信用事件：执法行动、牌照吊销、制裁、暂停交易和发行方违约
+//
+// 每类事件有强信号和弱信号两组短语，至少命中一个强信号才视为该类事件。"可能"、"据传"等不确定措辞会降低置信度，
+// 提及刑事指控、欺诈等加重措辞时提高严重程度。监管机构的名称决定事件的司法辖区
+package regulatory
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 事件类型
+const (
+	TypeEnforcement       = "enforcement_action"
+	TypeLicenseRevocation = "license_revocation"
+	TypeSanctions         = "sanctions"
+	TypeTradingHalt       = "trading_halt"
+	TypeIssuerDefault     = "issuer_default"
+)
+
+// 严重程度，与渠道服务的风险事件一致
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+const (
+	strongWeight      = 0.6
+	extraStrongWeight = 0.15
+	weakWeight        = 0.1
+	maxWeakWeight     = 0.3
+	regulatorWeight   = 0.1
+	// titleFactor 强信号出现在标题中时的加成
+	titleFactor = 1.2
+	// hedgeFactor 出现不确定措辞时的折扣
+	hedgeFactor = 0.6
+)
+
+// Classification 识别结果，Confidence只反映文本本身，不含来源的可信度
+type Classification struct {
+	Type          string   `json:"type"`
+	Severity      string   `json:"severity"`
+	Confidence    float64  `json:"confidence"`
+	Jurisdictions []string `json:"jurisdictions"`
+	Regulators    []string `json:"regulators"`
+	Matched       []string `json:"matched"`
+}
+
+// rule 一类事件的识别规则，RegulatorBoost表示提及监管机构时提高置信度
+type rule struct {
+	Type           string
+	Severity       string
+	Strong         []*regexp.Regexp
+	Weak           []*regexp.Regexp
+	RegulatorBoost bool
+}
+
+var rules = []rule{
+	{
+		Type:     TypeSanctions,
+		Severity: SeverityCritical,
+		Strong: phrases(
+			`ofac`, `sdn list`, `specially designated nationals`, `sanctions? list`,
+			`(?:imposed|imposes|imposing) sanctions`, `sanctioned`, `asset freeze`, `froze (?:the )?assets`,
+		),
+		Weak:           phrases(`sanctions?`, `designat\w+`, `frozen`, `blacklist\w*`),
+		RegulatorBoost: true,
+	},
+	{
+		Type:     TypeIssuerDefault,
+		Severity: SeverityCritical,
+		Strong: phrases(
+			`default(?:ed|s)? on`, `missed (?:a |an |its )?(?:coupon|interest|principal|redemption|payment)`,
+			`(?:filed|files|filing) for (?:bankruptcy|chapter 11|insolvency)`, `chapter 11`, `insolvency proceedings`,
+			`(?:entered|enters|placed into) (?:into )?(?:liquidation|receivership|administration)`, `declared bankrupt`,
+		),
+		Weak: phrases(`default`, `insolven\w+`, `bankrupt\w*`, `liquidat\w+`, `restructur\w+`, `receivership`),
+	},
+	{
+		Type:     TypeLicenseRevocation,
+		Severity: SeverityHigh,
+		Strong: phrases(
+			`revok\w* (?:its |the |their )?(?:\w+ )?licen[cs]e`, `licen[cs]e (?:was |has been |had been )?(?:revoked|suspended|withdrawn|cancell?ed)`,
+			`suspend\w* (?:its |the |their )?(?:\w+ )?licen[cs]e`, `(?:withdr[ae]w\w*|cancell?\w*) (?:its |the |their )?(?:authori[sz]ation|registration)`,
+			`deregister\w*`, `stripped of (?:its |the )?licen[cs]e`,
+		),
+		Weak:           phrases(`licen[cs]e`, `authori[sz]ation`, `registration`),
+		RegulatorBoost: true,
+	},
+	{
+		Type:     TypeTradingHalt,
+		Severity: SeverityHigh,
+		Strong: phrases(
+			`(?:halt\w*|suspend\w*|paus\w*|froze|freez\w*) (?:all )?(?:trading|withdrawals|redemptions|deposits)`,
+			`(?:trading|withdrawals|redemptions|deposits) (?:were |was |have been |has been |are |is )?(?:halted|suspended|paused|frozen)`,
+			`delist\w*`, `circuit breaker`,
+		),
+		Weak: phrases(`halt\w*`, `suspen\w+`, `paused`),
+	},
+	{
+		Type:     TypeEnforcement,
+		Severity: SeverityMedium,
+		Strong: phrases(
+			`enforcement action`, `cease[- ]and[- ]desist`, `wells notice`, `(?:charged|charges) (?:against|with)`,
+			`(?:sued|sues|suing)`, `lawsuit against`, `(?:fined|fines)`, `civil penalty`, `penalty of`,
+			`settled? (?:charges|with the)`, `subpoena\w*`, `(?:opened|launched|opens|launches) (?:an |a formal )?investigation`,
+		),
+		Weak:           phrases(`alleg\w+`, `violat\w+`, `unregistered securities`, `fraud\w*`, `misleading`, `investigation`),
+		RegulatorBoost: true,
+	},
+}
+
+// hedges 不确定措辞：传闻、提议、可能性和否认；不含may，以免与月份混淆
+var hedges = phrases(
+	`could`, `might`, `reportedly`, `rumou?r\w*`, `consider\w*`, `propos\w+`, `plans? to`,
+	`denies`, `denied`, `unconfirmed`, `speculat\w+`, `whether`,
+)
+
+// escalators 加重措辞，命中时严重程度提高一级
+var escalators = phrases(
+	`criminal`, `indict\w*`, `arrest\w*`, `fraud`, `ponzi`, `billion`, `emergency`, `all (?:trading|withdrawals)`,
+)
+
+// regulator 监管机构，Acronym按大写整词匹配
+type regulator struct {
+	Name         string
+	Jurisdiction string
+	pattern      *regexp.Regexp
+}
+
+var regulators = []regulator{
+	acronym("SEC", "US"), acronym("CFTC", "US"), acronym("OFAC", "US"), acronym("FinCEN", "US"),
+	acronym("DOJ", "US"), acronym("FINRA", "US"), acronym("OCC", "US"), acronym("NYDFS", "US"),
+	named("SEC", "US", `securities and exchange commission`), named("DOJ", "US", `department of justice`),
+	named("Federal Reserve", "US", `federal reserve`), named("NYDFS", "US", `new york department of financial services`),
+	acronym("FCA", "GB"), acronym("PRA", "GB"), named("FCA", "GB", `financial conduct authority`),
+	acronym("BaFin", "DE"), acronym("AMF", "FR"), acronym("ESMA", "EU"), acronym("FINMA", "CH"),
+	acronym("MAS", "SG"), named("MAS", "SG", `monetary authority of singapore`),
+	acronym("SFC", "HK"), acronym("HKMA", "HK"), named("SFC", "HK", `香港证监会`),
+	named("FSA", "JP", `financial services agency`), named("FSA", "JP", `金融庁`),
+	acronym("CSRC", "CN"), named("CSRC", "CN", `中国证监会`),
+	acronym("ASIC", "AU"), acronym("OSC", "CA"), acronym("VARA", "AE"), acronym("CySEC", "CY"),
+	named("Central Bank of Ireland", "IE", `central bank of ireland`),
+}
+
+// Classify 返回置信度最高的事件类型，置信度相同时取rules中靠前（更严重）的类型；没有命中任何强信号时ok为false
+func Classify(title, body string) (result Classification, ok bool) {
+	text := title + "\n" + body
+	lower := strings.ToLower(text)
+	lowerTitle := strings.ToLower(title)
+
+	var found []regulator
+	seen := make(map[string]bool)
+	for _, r := range regulators {
+		if !seen[r.Name] && r.pattern.MatchString(text) {
+			seen[r.Name] = true
+			found = append(found, r)
+		}
+	}
+
+	hedged := anyMatch(hedges, lower)
+	for _, rule := range rules {
+		var matched []string
+		confidence := 0.0
+		inTitle := false
+		for _, pattern := range rule.Strong {
+			if m := pattern.FindString(lower); m != "" {
+				if len(matched) == 0 {
+					confidence += strongWeight
+				} else {
+					confidence += extraStrongWeight
+				}
+				matched = append(matched, strings.TrimSpace(m))
+				inTitle = inTitle || pattern.MatchString(lowerTitle)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		weak := 0.0
+		for _, pattern := range rule.Weak {
+			if pattern.MatchString(lower) {
+				weak += weakWeight
+			}
+		}
+		confidence += math.Min(weak, maxWeakWeight)
+		if rule.RegulatorBoost && len(found) > 0 {
+			confidence += regulatorWeight
+		}
+		if inTitle {
+			confidence *= titleFactor
+		}
+		if hedged {
+			confidence *= hedgeFactor
+		}
+		confidence = round(math.Min(confidence, 1))
+
+		if confidence <= result.Confidence {
+			continue
+		}
+		severity := rule.Severity
+		if anyMatch(escalators, lower) {
+			severity = escalate(severity)
+		}
+		result = Classification{
+			Type:       rule.Type,
+			Severity:   severity,
+			Confidence: confidence,
+			Matched:    matched,
+		}
+		ok = true
+	}
+	if !ok {
+		return Classification{}, false
+	}
+
+	result.Jurisdictions = []string{}
+	result.Regulators = []string{}
+	for _, r := range found {
+		result.Regulators = append(result.Regulators, r.Name)
+		if !contains(result.Jurisdictions, r.Jurisdiction) {
+			result.Jurisdictions = append(result.Jurisdictions, r.Jurisdiction)
+		}
+	}
+	sort.Strings(result.Jurisdictions)
+	return result, true
+}
+
+// SeverityRank 严重程度的序号，low为0，未知取值为-1
+func SeverityRank(severity string) int {
+	for i, s := range severities {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+func escalate(severity string) string {
+	if rank := SeverityRank(severity); rank >= 0 && rank < len(severities)-1 {
+		return severities[rank+1]
+	}
+	return severity
+}
+
+// phrases 编译整词匹配的短语，调用方传入小写文本
+func phrases(patterns ...string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		compiled[i] = regexp.MustCompile(`\b(?:` + pattern + `)\b`)
+	}
+	return compiled
+}
+
+func acronym(name, jurisdiction string) regulator {
+	return regulator{Name: name, Jurisdiction: jurisdiction, pattern: regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)}
+}
+
+func named(name, jurisdiction, phrase string) regulator {
+	pattern := `(?i)` + phrase
+	if phrase[0] < 0x80 {
+		pattern = `(?i)\b` + phrase + `\b`
+	}
+	return regulator{Name: name, Jurisdiction: jurisdiction, pattern: regexp.MustCompile(pattern)}
+}
+
+func anyMatch(patterns []*regexp.Regexp, text string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
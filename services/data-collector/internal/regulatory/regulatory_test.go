@@ -0,0 +1,100 @@
+package regulatory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyEnforcement(t *testing.T) {
+	c, ok := Classify(
+		"SEC charges token issuer with selling unregistered securities",
+		"The Securities and Exchange Commission filed an enforcement action alleging the company misled investors.",
+	)
+	require.True(t, ok)
+	assert.Equal(t, TypeEnforcement, c.Type)
+	assert.Equal(t, SeverityMedium, c.Severity)
+	assert.Equal(t, []string{"US"}, c.Jurisdictions)
+	assert.Equal(t, []string{"SEC"}, c.Regulators)
+	assert.Contains(t, c.Matched, "enforcement action")
+	assert.GreaterOrEqual(t, c.Confidence, 0.9)
+}
+
+func TestClassifyPicksStrongestType(t *testing.T) {
+	c, ok := Classify(
+		"Stablecoin issuer defaults on notes, halts redemptions",
+		"The issuer missed a coupon payment on its notes and said redemptions were suspended. It expects to file for insolvency.",
+	)
+	require.True(t, ok)
+	assert.Equal(t, TypeIssuerDefault, c.Type)
+	assert.Equal(t, SeverityCritical, c.Severity)
+	assert.Empty(t, c.Jurisdictions)
+}
+
+func TestClassifyJurisdictions(t *testing.T) {
+	c, ok := Classify(
+		"BaFin revokes crypto custody licence",
+		"Germany's financial regulator BaFin revoked the licence; ESMA was informed.",
+	)
+	require.True(t, ok)
+	assert.Equal(t, TypeLicenseRevocation, c.Type)
+	assert.Equal(t, []string{"DE", "EU"}, c.Jurisdictions)
+	assert.Equal(t, []string{"BaFin", "ESMA"}, c.Regulators)
+
+	c, ok = Classify("中国证监会对某平台作出处罚", "The CSRC fined the platform and opened an investigation into its token sales.")
+	require.True(t, ok)
+	assert.Equal(t, []string{"CN"}, c.Jurisdictions)
+	assert.Equal(t, []string{"CSRC"}, c.Regulators)
+}
+
+func TestClassifyHedgeLowersConfidence(t *testing.T) {
+	firm, ok := Classify("Exchange halts trading in tokenized bonds", "Trading was halted on Tuesday.")
+	require.True(t, ok)
+	hedged, ok := Classify("Exchange could halt trading in tokenized bonds", "Trading could be halted, people familiar reportedly said.")
+	require.True(t, ok)
+
+	assert.Equal(t, TypeTradingHalt, hedged.Type)
+	assert.Less(t, hedged.Confidence, firm.Confidence)
+	assert.Less(t, hedged.Confidence, strongWeight)
+}
+
+func TestClassifyEscalatesSeverity(t *testing.T) {
+	c, ok := Classify("DOJ charges founders with fraud", "Prosecutors filed criminal charges against the founders of the lending platform.")
+	require.True(t, ok)
+	assert.Equal(t, TypeEnforcement, c.Type)
+	assert.Equal(t, SeverityHigh, c.Severity)
+
+	c, ok = Classify("OFAC adds mixer to SDN list", "The Treasury sanctioned the service, citing billion dollar laundering.")
+	require.True(t, ok)
+	assert.Equal(t, TypeSanctions, c.Type)
+	assert.Equal(t, SeverityCritical, c.Severity, "critical is the highest level")
+}
+
+func TestClassifyRequiresStrongSignal(t *testing.T) {
+	for _, text := range []string{
+		"Regulators discuss new licence framework for stablecoins",
+		"Tokenized treasury fund reaches $1 billion in assets",
+		"Securities regulator publishes guidance on custody registration",
+	} {
+		_, ok := Classify(text, "")
+		assert.False(t, ok, text)
+	}
+}
+
+func TestClassifyAcronymsAreCaseSensitive(t *testing.T) {
+	c, ok := Classify("Lender sued over losses", "The mas of users... second-by-second updates are shown.")
+	require.True(t, ok)
+	assert.Empty(t, c.Regulators)
+}
+
+func TestSourceConfidence(t *testing.T) {
+	assert.Equal(t, 1.0, SourceConfidence("https://www.sec.gov/news/press-release/2024-1"))
+	assert.Equal(t, 1.0, SourceConfidence("home.treasury.gov"))
+	assert.Equal(t, 1.0, SourceConfidence("www.fdic.gov"))
+	assert.Equal(t, 0.9, SourceConfidence("https://www.reuters.com/markets/"))
+	assert.Equal(t, 0.9, SourceConfidence("news.bloomberg.com"))
+	assert.Equal(t, 0.75, SourceConfidence("https://www.coindesk.com/policy/"))
+	assert.Equal(t, DefaultSourceConfidence, SourceConfidence("https://example-blog.io/post"))
+	assert.Equal(t, DefaultSourceConfidence, SourceConfidence(""))
+}
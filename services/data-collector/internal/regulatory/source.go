@@ -0,0 +1,48 @@
+package regulatory
+
+import "strings"
+
+const (
+	// DefaultSourceConfidence 未登记来源的可信度
+	DefaultSourceConfidence = 0.6
+)
+
+// sourceConfidence 按域名登记的来源可信度：监管机构官网最高，其次是主流财经媒体和加密行业媒体
+var sourceConfidence = map[string]float64{
+	"sec.gov": 1, "cftc.gov": 1, "treasury.gov": 1, "justice.gov": 1, "finra.org": 1, "federalreserve.gov": 1,
+	"fca.org.uk": 1, "bankofengland.co.uk": 1, "bafin.de": 1, "amf-france.org": 1, "esma.europa.eu": 1,
+	"finma.ch": 1, "mas.gov.sg": 1, "sfc.hk": 1, "hkma.gov.hk": 1, "fsa.go.jp": 1, "csrc.gov.cn": 1,
+	"asic.gov.au": 1, "osc.ca": 1, "vara.ae": 1, "cysec.gov.cy": 1,
+
+	"reuters.com": 0.9, "bloomberg.com": 0.9, "ft.com": 0.9, "wsj.com": 0.9, "apnews.com": 0.9,
+	"nikkei.com": 0.9, "asia.nikkei.com": 0.9, "cnbc.com": 0.85, "handelsblatt.com": 0.85, "lesechos.fr": 0.85,
+
+	"coindesk.com": 0.75, "theblock.co": 0.75, "decrypt.co": 0.75, "cointelegraph.com": 0.75,
+	"dlnews.com": 0.75, "blockworks.co": 0.75,
+}
+
+// SourceConfidence 返回新闻来源的可信度，host可以是域名或包含域名的URL；政府域名视为官方来源
+func SourceConfidence(host string) float64 {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	if i := strings.IndexAny(host, "/:"); i >= 0 {
+		host = host[:i]
+	}
+	host = strings.TrimPrefix(host, "www.")
+
+	// 逐级去掉子域名，如news.bloomberg.com按bloomberg.com计
+	for domain := host; domain != ""; {
+		if confidence, ok := sourceConfidence[domain]; ok {
+			return confidence
+		}
+		i := strings.Index(domain, ".")
+		if i < 0 {
+			break
+		}
+		domain = domain[i+1:]
+	}
+	if strings.HasSuffix(host, ".gov") || strings.Contains(host, ".gov.") {
+		return 1
+	}
+	return DefaultSourceConfidence
+}
@@ -0,0 +1,172 @@
+// Package reserve 资产储备证明：读取托管方/审计方的证明文件并评估抵押率
+package reserve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attestation 一份储备证明，Reserves以Unit为计价单位（如美元、金衡盎司）
+type Attestation struct {
+	Asset    string    `json:"asset"`
+	Reserves float64   `json:"reserves"`
+	AsOf     time.Time `json:"as_of"`
+	Attestor string    `json:"attestor"`
+	Unit     string    `json:"unit"`
+	File     string    `json:"file"`
+}
+
+// attestationFile 证明文件的原始格式，reserves允许数字或字符串
+type attestationFile struct {
+	Asset    string          `json:"asset"`
+	Reserves json.RawMessage `json:"reserves"`
+	AsOf     time.Time       `json:"as_of"`
+	Attestor string          `json:"attestor"`
+	Unit     string          `json:"unit"`
+}
+
+// LoadAttestations 读取目录下所有.json证明文件（单个对象或数组），按资产（大写）返回as_of最新的一份
+// 格式错误的文件单独报告，不影响其他文件
+func LoadAttestations(dir string) (map[string]*Attestation, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	latest := make(map[string]*Attestation)
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+
+		attestations, err := parseFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", entry.Name(), err))
+			continue
+		}
+		for _, attestation := range attestations {
+			key := strings.ToUpper(attestation.Asset)
+			if current, ok := latest[key]; !ok || attestation.AsOf.After(current.AsOf) {
+				latest[key] = attestation
+			}
+		}
+	}
+
+	return latest, errs
+}
+
+func parseFile(path string) ([]*Attestation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raws []attestationFile
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &raws)
+	} else {
+		var raw attestationFile
+		err = json.Unmarshal(data, &raw)
+		raws = []attestationFile{raw}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attestations := make([]*Attestation, 0, len(raws))
+	for i, raw := range raws {
+		if raw.Asset == "" || raw.AsOf.IsZero() {
+			return nil, fmt.Errorf("entry %d: asset and as_of are required", i)
+		}
+		reserves, err := parseAmount(raw.Reserves)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: invalid reserves: %v", i, err)
+		}
+		attestations = append(attestations, &Attestation{
+			Asset:    strings.TrimSpace(raw.Asset),
+			Reserves: reserves,
+			AsOf:     raw.AsOf,
+			Attestor: raw.Attestor,
+			Unit:     raw.Unit,
+			File:     filepath.Base(path),
+		})
+	}
+	return attestations, nil
+}
+
+func parseAmount(raw json.RawMessage) (float64, error) {
+	value := strings.Trim(strings.TrimSpace(string(raw)), `"`)
+	value = strings.ReplaceAll(value, ",", "")
+	if value == "" {
+		return 0, fmt.Errorf("missing value")
+	}
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if amount < 0 {
+		return 0, fmt.Errorf("negative value %s", value)
+	}
+	return amount, nil
+}
+
+// Source 一个储备数据来源的读数，距AsOf超过MaxAge视为过期
+type Source struct {
+	Name     string        `json:"name"` // 例如 feed:ethereum:0x... 或 attestation:file.json
+	Reserves float64       `json:"reserves"`
+	AsOf     time.Time     `json:"as_of"`
+	MaxAge   time.Duration `json:"-"`
+	Stale    bool          `json:"stale"`
+}
+
+// Assessment 某一时刻的抵押率评估，Liabilities为流通量按锚定价值换算后的负债，与储备同一计价单位
+type Assessment struct {
+	Supply      float64  `json:"supply"`
+	Liabilities float64  `json:"liabilities"`
+	Reserves    float64  `json:"reserves"`
+	Ratio       float64  `json:"ratio"`
+	Shortfall   bool     `json:"shortfall"`
+	Stale       bool     `json:"stale"`
+	Sources     []Source `json:"sources"`
+}
+
+// Assess 以最新的非过期来源作为储备，全部来源过期时仍使用最新的一份但标记为stale
+// peg为单个代币以储备计价单位表示的价值（如锚定美元的稳定币为1），抵押率为储备除以supply*peg
+// 流通量为0时抵押率视为1（没有需要覆盖的负债）
+func Assess(supply, peg float64, sources []Source, now time.Time) Assessment {
+	assessment := Assessment{Supply: supply, Liabilities: supply * peg}
+	if len(sources) == 0 {
+		assessment.Stale = true
+		return assessment
+	}
+
+	for i := range sources {
+		sources[i].Stale = sources[i].MaxAge > 0 && now.Sub(sources[i].AsOf) > sources[i].MaxAge
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		if sources[i].Stale != sources[j].Stale {
+			return !sources[i].Stale
+		}
+		return sources[i].AsOf.After(sources[j].AsOf)
+	})
+
+	chosen := sources[0]
+	assessment.Sources = sources
+	assessment.Reserves = chosen.Reserves
+	assessment.Stale = chosen.Stale
+
+	if assessment.Liabilities <= 0 {
+		assessment.Ratio = 1
+	} else {
+		assessment.Ratio = chosen.Reserves / assessment.Liabilities
+	}
+	assessment.Shortfall = assessment.Ratio < 1
+	return assessment
+}
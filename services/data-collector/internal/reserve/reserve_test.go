@@ -0,0 +1,85 @@
+package reserve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAttestations(t *testing.T) {
+	attestations, errs := LoadAttestations("testdata/attestations")
+
+	// 格式错误的文件单独报告
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "broken.json")
+
+	require.Len(t, attestations, 3)
+
+	// 同一资产取as_of最新的一份，资产名不区分大小写
+	usdy := attestations["USDY"]
+	require.NotNil(t, usdy)
+	assert.Equal(t, 418900000.0, usdy.Reserves)
+	assert.Equal(t, "usdy-2024-05.json", usdy.File)
+
+	paxg := attestations["PAXG"]
+	require.NotNil(t, paxg)
+	assert.Equal(t, 250880.512, paxg.Reserves)
+	assert.Equal(t, "oz", paxg.Unit)
+	assert.Equal(t, "monthly-batch.json", paxg.File)
+}
+
+func TestAssess(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	maxAge := 30 * 24 * time.Hour
+	source := func(name string, reserves float64, age time.Duration) Source {
+		return Source{Name: name, Reserves: reserves, AsOf: now.Add(-age), MaxAge: maxAge}
+	}
+
+	t.Run("fully backed", func(t *testing.T) {
+		assessment := Assess(1000, 1, []Source{source("feed", 1010, time.Hour)}, now)
+		assert.InDelta(t, 1.01, assessment.Ratio, 1e-9)
+		assert.False(t, assessment.Shortfall)
+		assert.False(t, assessment.Stale)
+	})
+
+	t.Run("prefers fresh source over newer stale one", func(t *testing.T) {
+		sources := []Source{
+			source("attestation", 1200, 40*24*time.Hour),
+			source("feed", 990, 2*time.Hour),
+		}
+		assessment := Assess(1000, 1, sources, now)
+		assert.Equal(t, 990.0, assessment.Reserves)
+		assert.True(t, assessment.Shortfall)
+		assert.False(t, assessment.Stale)
+		assert.True(t, assessment.Sources[1].Stale)
+	})
+
+	t.Run("stale attestation", func(t *testing.T) {
+		assessment := Assess(1000, 1, []Source{source("attestation", 1000, 45*24*time.Hour)}, now)
+		assert.True(t, assessment.Stale)
+		assert.False(t, assessment.Shortfall)
+		assert.InDelta(t, 1.0, assessment.Ratio, 1e-9)
+	})
+
+	t.Run("no sources", func(t *testing.T) {
+		assessment := Assess(1000, 1, nil, now)
+		assert.True(t, assessment.Stale)
+		assert.Zero(t, assessment.Ratio)
+	})
+
+	t.Run("pegged above one unit", func(t *testing.T) {
+		// 每个代币对应1.05美元，1000个代币需要1050美元储备
+		assessment := Assess(1000, 1.05, []Source{source("feed", 1020, time.Hour)}, now)
+		assert.Equal(t, 1050.0, assessment.Liabilities)
+		assert.InDelta(t, 1020.0/1050.0, assessment.Ratio, 1e-9)
+		assert.True(t, assessment.Shortfall)
+	})
+
+	t.Run("zero supply", func(t *testing.T) {
+		assessment := Assess(0, 1, []Source{source("feed", 5, 0)}, now)
+		assert.Equal(t, 1.0, assessment.Ratio)
+		assert.False(t, assessment.Shortfall)
+	})
+}
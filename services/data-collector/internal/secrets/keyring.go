@@ -0,0 +1,191 @@
+// Package secrets 使用本地密钥加密（KEK）保存数据源的API密钥。
+//
+// 密钥文件每行一个密钥："<密钥ID> <base64编码的32字节密钥>"，#开头为注释，最后一个密钥用于加密。
+// 轮换时在文件末尾追加新密钥，旧密钥保留到所有密文重新加密之后再删除
+package secrets
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prefix 密文前缀，没有该前缀的值视为尚未加密的明文
+const prefix = "enc:v1:"
+
+var (
+	ErrNoKeys     = errors.New("keyring has no keys")
+	ErrUnknownKey = errors.New("unknown encryption key")
+	ErrCiphertext = errors.New("invalid ciphertext")
+)
+
+// Keyring 加密密钥集合，密钥文件变化后调用Reload生效
+type Keyring struct {
+	path string
+
+	mu      sync.RWMutex
+	keys    map[string]cipher.AEAD
+	primary string
+	modTime time.Time
+}
+
+// LoadKeyring 读取密钥文件
+func LoadKeyring(path string) (*Keyring, error) {
+	k := &Keyring{path: path}
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload 密钥文件修改时间变化时重新读取，返回是否已重新加载；读取失败时保留原有密钥
+func (k *Keyring) Reload() (bool, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return false, err
+	}
+	k.mu.RLock()
+	unchanged := k.keys != nil && info.ModTime().Equal(k.modTime)
+	k.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	file, err := os.Open(k.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	keys, primary, err := parseKeys(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", k.path, err)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.primary = primary
+	k.modTime = info.ModTime()
+	k.mu.Unlock()
+	return true, nil
+}
+
+func parseKeys(r io.Reader) (map[string]cipher.AEAD, string, error) {
+	keys := make(map[string]cipher.AEAD)
+	primary := ""
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 || strings.Contains(fields[0], ":") {
+			return nil, "", fmt.Errorf("line %d: expected \"<key-id> <base64 key>\"", line)
+		}
+		raw, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil || len(raw) != 32 {
+			return nil, "", fmt.Errorf("line %d: key must be 32 bytes encoded as base64", line)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, "", err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, "", err
+		}
+		keys[fields[0]] = aead
+		primary = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", err
+	}
+	if primary == "" {
+		return nil, "", ErrNoKeys
+	}
+	return keys, primary, nil
+}
+
+// Primary 当前用于加密的密钥ID
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Encrypt 使用当前密钥加密，结果为"enc:v1:<密钥ID>:<base64(nonce+密文)>"
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	k.mu.RLock()
+	id, aead := k.primary, k.keys[k.primary]
+	k.mu.RUnlock()
+	if aead == nil {
+		return "", ErrNoKeys
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+	return prefix + id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密Encrypt的结果
+func (k *Keyring) Decrypt(value string) (string, error) {
+	id, payload, err := split(value)
+	if err != nil {
+		return "", err
+	}
+	k.mu.RLock()
+	aead := k.keys[id]
+	k.mu.RUnlock()
+	if aead == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrCiphertext
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", ErrCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted 值是否为密文
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// KeyID 密文使用的密钥ID，明文返回空
+func KeyID(value string) string {
+	id, _, err := split(value)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+func split(value string) (string, string, error) {
+	if !IsEncrypted(value) {
+		return "", "", ErrCiphertext
+	}
+	id, payload, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok || id == "" {
+		return "", "", ErrCiphertext
+	}
+	return id, payload, nil
+}
@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) string {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func writeKeys(t *testing.T, path string, lines ...string) {
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring")
+	writeKeys(t, path, "# data source keys", "k1 "+newKey(t))
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+
+	value, err := keyring.Encrypt("secret-api-key")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(value))
+	assert.Equal(t, "k1", KeyID(value))
+	assert.NotContains(t, value, "secret-api-key")
+
+	plaintext, err := keyring.Decrypt(value)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-api-key", plaintext)
+
+	// 明文和被篡改的密文都无法解密
+	assert.False(t, IsEncrypted("secret-api-key"))
+	assert.Equal(t, "", KeyID("secret-api-key"))
+	_, err = keyring.Decrypt("secret-api-key")
+	assert.True(t, errors.Is(err, ErrCiphertext))
+	_, err = keyring.Decrypt(value[:len(value)-4] + "AAAA")
+	assert.True(t, errors.Is(err, ErrCiphertext))
+}
+
+func TestReloadRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring")
+	k1 := "k1 " + newKey(t)
+	writeKeys(t, path, k1)
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+	old, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+
+	// 文件未变化时不重新加载
+	reloaded, err := keyring.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// 追加新密钥后新密钥成为主密钥，旧密文仍可解密
+	writeKeys(t, path, k1, "k2 "+newKey(t))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	reloaded, err = keyring.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "k2", keyring.Primary())
+
+	plaintext, err := keyring.Decrypt(old)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", plaintext)
+	value, err := keyring.Encrypt("secret")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", KeyID(value))
+
+	// 删除旧密钥后旧密文无法解密
+	writeKeys(t, path, "k2 "+strings.Fields(readLine(t, path, 1))[1])
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(2*time.Second)))
+	_, err = keyring.Reload()
+	require.NoError(t, err)
+	_, err = keyring.Decrypt(old)
+	assert.True(t, errors.Is(err, ErrUnknownKey))
+}
+
+func readLine(t *testing.T, path string, n int) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")[n]
+}
+
+func TestLoadKeyringInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"empty":   "# no keys\n",
+		"short":   "k1 " + base64.StdEncoding.EncodeToString([]byte("too short")) + "\n",
+		"fields":  "k1\n",
+		"colonid": "k:1 " + newKey(t) + "\n",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		_, err := LoadKeyring(path)
+		assert.Error(t, err, name)
+	}
+
+	// 重新加载失败时保留原有密钥
+	path := filepath.Join(dir, "keyring")
+	writeKeys(t, path, "k1 "+newKey(t))
+	keyring, err := LoadKeyring(path)
+	require.NoError(t, err)
+	writeKeys(t, path, "broken")
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Second)))
+	_, err = keyring.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, "k1", keyring.Primary())
+}
@@ -0,0 +1,89 @@
+package sentiment
+
+// financeTerms 金融新闻情绪词典，权重范围约-3.5到3
+//
+// 以Loughran-McDonald金融词表为基础，补充了代币化资产和加密市场的常见用语；
+// "risk"、"volatility"等在金融语境中属于描述性用语的词权重较低
+var financeTerms = map[string]float64{
+	// 正面
+	"gain": 1.5, "gains": 1.5, "gained": 1.5,
+	"growth": 1.5, "grow": 1.2, "grows": 1.2, "grew": 1.2, "growing": 1.2,
+	"surge": 2, "surges": 2, "surged": 2,
+	"soar": 2.2, "soars": 2.2, "soared": 2.2,
+	"rally": 1.8, "rallies": 1.8, "rallied": 1.8,
+	"rise": 1, "rises": 1, "rose": 1, "rising": 1,
+	"increase": 1, "increases": 1, "increased": 1,
+	"higher": 0.8, "boost": 1.5, "boosts": 1.5, "boosted": 1.5,
+	"profit": 1.8, "profits": 1.8, "profitable": 2, "profitability": 1.5,
+	"beat": 1.5, "beats": 1.5, "outperform": 2, "outperforms": 2, "outperformed": 2,
+	"upgrade": 2, "upgrades": 2, "upgraded": 2,
+	"approve": 1.8, "approves": 1.8, "approved": 1.8, "approval": 1.8,
+	"launch": 1.2, "launches": 1.2, "launched": 1.2,
+	"partnership": 1.5, "partners": 1, "partnered": 1.2, "collaboration": 1,
+	"expand": 1.2, "expands": 1.2, "expanded": 1.2, "expansion": 1.2,
+	"adopt": 1.2, "adopts": 1.2, "adoption": 1.5,
+	"inflow": 1.5, "inflows": 1.5,
+	"strong": 1.5, "stronger": 1.5, "robust": 1.8, "resilient": 1.5,
+	"bullish": 2, "upbeat": 1.5, "optimistic": 1.8, "optimism": 1.5,
+	"recover": 1.2, "recovers": 1.2, "recovered": 1.5, "recovery": 1.5,
+	"milestone": 1.5, "breakthrough": 2, "innovative": 1.2, "innovation": 1,
+	"success": 1.8, "successful": 1.8, "successfully": 1.5,
+	"win": 1.5, "wins": 1.5, "won": 1.5,
+	"licensed": 1.5, "license": 1, "compliant": 1.2, "authorized": 1.2,
+	"secure": 1, "secured": 1, "transparent": 1, "transparency": 0.8,
+	"fully backed": 1.5, "overcollateralized": 1.5, "record high": 2.5,
+	"all-time high": 2.5, "all time high": 2.5,
+
+	// 负面
+	"loss": -1.8, "losses": -1.8, "lose": -1.5, "loses": -1.5, "lost": -1.5,
+	"decline": -1.5, "declines": -1.5, "declined": -1.5, "declining": -1.5,
+	"drop": -1.2, "drops": -1.2, "dropped": -1.2,
+	"fall": -1.2, "falls": -1.2, "fell": -1.2, "falling": -1.2,
+	"lower": -0.8, "decrease": -1, "decreased": -1,
+	"plunge": -2.5, "plunges": -2.5, "plunged": -2.5,
+	"crash": -3, "crashes": -3, "crashed": -3, "slump": -2, "slumps": -2,
+	"tumble": -2, "tumbles": -2, "tumbled": -2,
+	"downgrade": -2, "downgrades": -2, "downgraded": -2,
+	"miss": -1.2, "misses": -1.2, "missed": -1.2,
+	"default": -2.8, "defaults": -2.8, "defaulted": -3,
+	"bankrupt": -3.5, "bankruptcy": -3.5, "insolvent": -3.5, "insolvency": -3.5,
+	"collapse": -3, "collapses": -3, "collapsed": -3,
+	"fraud": -3.5, "fraudulent": -3.5, "scam": -3.5, "ponzi": -3.5,
+	"manipulation": -2.5, "misleading": -2.2, "misled": -2.2,
+	"lawsuit": -2, "lawsuits": -2, "sue": -2, "sues": -2, "sued": -2,
+	"charged": -2, "charges": -1.5, "indicted": -3, "penalty": -2, "penalties": -2, "fined": -2,
+	"investigation": -1.5, "investigating": -1.5, "probe": -1.5, "subpoena": -2,
+	"violation": -2, "violations": -2, "violated": -2, "illegal": -2.5, "unregistered": -1.8,
+	"enforcement": -1.2, "crackdown": -2, "sanction": -1.8, "sanctions": -1.8, "sanctioned": -2,
+	"ban": -2, "bans": -2, "banned": -2, "reject": -1.8, "rejects": -1.8, "rejected": -1.8,
+	"hack": -3, "hacked": -3, "exploit": -3, "exploited": -3, "breach": -2.5,
+	"vulnerability": -2, "attack": -2.2, "stolen": -3, "theft": -3,
+	"depeg": -3, "depegs": -3, "depegged": -3, "de-peg": -3, "de-pegged": -3,
+	"halt": -2, "halts": -2, "halted": -2, "suspend": -2, "suspends": -2, "suspended": -2,
+	"freeze": -2, "freezes": -2, "frozen": -2, "delist": -2.2, "delists": -2.2, "delisted": -2.2,
+	"outflow": -1.5, "outflows": -1.5, "withdrawals": -0.8,
+	"liquidation": -2, "liquidations": -2, "liquidated": -2.2,
+	"shortfall": -2.5, "undercollateralized": -2.8, "deficit": -1.5,
+	"warning": -1.5, "warns": -1.5, "warned": -1.5,
+	"concern": -1.2, "concerns": -1.2, "worried": -1.5, "fears": -1.5, "fear": -1.5,
+	"crisis": -2.5, "turmoil": -2.2, "uncertainty": -1.2, "uncertain": -1,
+	"risk": -0.5, "risks": -0.5, "risky": -1.2, "volatile": -0.8, "volatility": -0.5,
+	"bearish": -2, "weak": -1.5, "weaker": -1.5, "weakness": -1.5, "pessimistic": -1.8,
+	"layoffs": -1.8, "shutdown": -2, "shuts down": -2, "wind down": -1.8,
+	"cease and desist": -3, "class action": -2, "going concern": -2,
+	"material weakness": -2.2, "rug pull": -3.5,
+}
+
+// negators 否定词，影响其后negationScope个词
+var negators = map[string]bool{
+	"not": true, "no": true, "never": true, "without": true, "neither": true, "nor": true,
+	"cannot": true, "none": true, "nothing": true, "hardly": true, "barely": true,
+	"fails": true, "failed": true, "lack": true, "lacks": true,
+}
+
+// intensifiers 程度副词，作用于紧随其后的情绪词
+var intensifiers = map[string]float64{
+	"very": 1.3, "highly": 1.3, "extremely": 1.5, "sharply": 1.5, "significantly": 1.4,
+	"substantially": 1.4, "massive": 1.5, "major": 1.3, "severe": 1.5, "severely": 1.5,
+	"record": 1.3, "slightly": 0.6, "modest": 0.7, "modestly": 0.7, "minor": 0.6,
+}
@@ -0,0 +1,63 @@
+package sentiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxModelInput 发送给外部模型的最大字节数，新闻正文过长时截断
+const maxModelInput = 4096
+
+// HTTPModel 通过HTTP调用外部情绪模型（如FinBERT服务）
+//
+// 请求体为{"text": "..."}，响应体为{"score": 0.42}，score范围-1到1
+type HTTPModel struct {
+	URL    string
+	Client *http.Client
+}
+
+// Score 实现Scorer
+func (m HTTPModel) Score(ctx context.Context, text string) (float64, error) {
+	if len(text) > maxModelInput {
+		text = text[:maxModelInput]
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := m.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("sentiment model returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Score *float64 `json:"score"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("invalid sentiment model response: %v", err)
+	}
+	if result.Score == nil {
+		return 0, fmt.Errorf("sentiment model response has no score")
+	}
+	return Clamp(*result.Score), nil
+}
@@ -0,0 +1,54 @@
+package sentiment
+
+import "time"
+
+// Point 单篇文章的情绪分
+type Point struct {
+	Time  time.Time
+	Score float64
+}
+
+// Momentum 情绪动量：近期窗口的平均情绪减去长期窗口的平均情绪
+//
+// 结果为正表示情绪在改善，为负表示在恶化；近期窗口没有文章或长期窗口的文章少于minPoints时返回false
+type Momentum struct {
+	Short     time.Duration
+	Long      time.Duration
+	MinPoints int
+}
+
+// MomentumResult 动量及两个窗口的均值
+type MomentumResult struct {
+	Value      float64 `json:"value"`
+	ShortMean  float64 `json:"short_mean"`
+	LongMean   float64 `json:"long_mean"`
+	ShortCount int     `json:"short_count"`
+	LongCount  int     `json:"long_count"`
+}
+
+// Compute 计算截至now的情绪动量
+func (m Momentum) Compute(points []Point, now time.Time) (MomentumResult, bool) {
+	var result MomentumResult
+	var shortSum, longSum float64
+
+	for _, point := range points {
+		age := now.Sub(point.Time)
+		if age < 0 || age > m.Long {
+			continue
+		}
+		longSum += point.Score
+		result.LongCount++
+		if age <= m.Short {
+			shortSum += point.Score
+			result.ShortCount++
+		}
+	}
+
+	if result.ShortCount == 0 || result.LongCount < m.MinPoints {
+		return result, false
+	}
+	result.ShortMean = shortSum / float64(result.ShortCount)
+	result.LongMean = longSum / float64(result.LongCount)
+	result.Value = result.ShortMean - result.LongMean
+	return result, true
+}
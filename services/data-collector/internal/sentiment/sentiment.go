@@ -0,0 +1,166 @@
+// Package sentiment 为金融新闻打情绪分，范围-1（负面）到1（正面）
+//
+// 默认使用离线的金融词典，支持否定和程度副词；也可以接入外部模型，模型不可用时回退到词典
+package sentiment
+
+import (
+	"context"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Scorer 情绪打分器
+type Scorer interface {
+	Score(ctx context.Context, text string) (float64, error)
+}
+
+// Result 词典打分的明细，Hits为命中的情绪词数量
+type Result struct {
+	Score    float64
+	Hits     int
+	Positive float64
+	Negative float64
+}
+
+const (
+	// normalizeAlpha 归一化平滑系数，词典得分越高越接近±1
+	normalizeAlpha = 15
+	// negationScope 否定词影响其后的词数
+	negationScope = 3
+	// negationFactor 被否定的情绪词反转并减弱（"not strong"弱于"weak"）
+	negationFactor = -0.75
+	// maxPhraseWords 词典短语的最大词数
+	maxPhraseWords = 4
+)
+
+// Lexicon 基于金融词典的打分器
+type Lexicon struct {
+	terms       map[string]float64
+	negators    map[string]bool
+	intensifier map[string]float64
+}
+
+// NewLexicon 创建使用内置金融词典的打分器
+func NewLexicon() *Lexicon {
+	return &Lexicon{
+		terms:       financeTerms,
+		negators:    negators,
+		intensifier: intensifiers,
+	}
+}
+
+// Score 实现Scorer
+func (l *Lexicon) Score(_ context.Context, text string) (float64, error) {
+	return l.Analyze(text).Score, nil
+}
+
+// Analyze 对文本打分，没有命中情绪词时得分为0
+func (l *Lexicon) Analyze(text string) Result {
+	var result Result
+	var sum float64
+
+	for _, clause := range splitClauses(text) {
+		tokens := tokenize(clause)
+		negatedUntil := -1
+		boost := 1.0
+
+		for i := 0; i < len(tokens); {
+			// 优先匹配最长的短语
+			weight, width := 0.0, 0
+			for n := maxPhraseWords; n >= 1; n-- {
+				if i+n > len(tokens) {
+					continue
+				}
+				if w, ok := l.terms[strings.Join(tokens[i:i+n], " ")]; ok {
+					weight, width = w, n
+					break
+				}
+			}
+
+			if width == 0 {
+				token := tokens[i]
+				switch {
+				case l.negators[token] || strings.HasSuffix(token, "n't"):
+					negatedUntil = i + negationScope
+				case l.intensifier[token] != 0:
+					boost = l.intensifier[token]
+				default:
+					boost = 1.0
+				}
+				i++
+				continue
+			}
+
+			weight *= boost
+			boost = 1.0
+			if i <= negatedUntil {
+				weight *= negationFactor
+			}
+			if weight > 0 {
+				result.Positive += weight
+			} else {
+				result.Negative -= weight
+			}
+			sum += weight
+			result.Hits++
+			i += width
+		}
+	}
+
+	if result.Hits > 0 {
+		result.Score = math.Round(sum/math.Sqrt(sum*sum+normalizeAlpha)*100) / 100
+	}
+	return result
+}
+
+// splitClauses 按句子和分句切分，否定的作用范围不跨越分句
+func splitClauses(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		switch r {
+		case '.', ',', ';', ':', '!', '?', '\n', '(', ')', '"', '“', '”':
+			return true
+		}
+		return false
+	})
+}
+
+// tokenize 转小写并按非字母数字切分，保留词内的连字符和撇号
+func tokenize(text string) []string {
+	text = strings.ToLower(strings.NewReplacer("’", "'", "–", " ", "—", " ").Replace(text))
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if field = strings.Trim(field, "-'"); field != "" {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// Fallback 优先使用Primary，出错时使用Secondary
+type Fallback struct {
+	Primary   Scorer
+	Secondary Scorer
+}
+
+// Score 实现Scorer
+func (f Fallback) Score(ctx context.Context, text string) (float64, error) {
+	score, err := f.Primary.Score(ctx, text)
+	if err == nil {
+		return score, nil
+	}
+	return f.Secondary.Score(ctx, text)
+}
+
+// Clamp 将外部模型的输出限制在[-1, 1]并保留两位小数，与news_articles.sentiment的精度一致
+func Clamp(score float64) float64 {
+	if math.IsNaN(score) {
+		return 0
+	}
+	score = math.Max(-1, math.Min(1, score))
+	return math.Round(score*100) / 100
+}
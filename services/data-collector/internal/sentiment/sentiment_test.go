@@ -0,0 +1,148 @@
+package sentiment
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexiconAnalyze(t *testing.T) {
+	lexicon := NewLexicon()
+
+	positive := lexicon.Analyze("Tokenized treasury fund inflows surge to record high as adoption grows")
+	assert.Greater(t, positive.Score, 0.5)
+	assert.Equal(t, 5, positive.Hits)
+
+	negative := lexicon.Analyze("SEC charges issuer with fraud after stablecoin depegged; redemptions halted")
+	assert.Less(t, negative.Score, -0.8)
+	assert.Zero(t, negative.Positive)
+
+	neutral := lexicon.Analyze("The fund publishes its monthly holdings report on Tuesday")
+	assert.Zero(t, neutral.Score)
+	assert.Zero(t, neutral.Hits)
+
+	assert.Zero(t, lexicon.Analyze("").Score)
+}
+
+func TestLexiconNegation(t *testing.T) {
+	lexicon := NewLexicon()
+
+	approved := lexicon.Analyze("Regulator approved the tokenized fund")
+	notApproved := lexicon.Analyze("Regulator has not approved the tokenized fund")
+	assert.Greater(t, approved.Score, 0.0)
+	assert.Less(t, notApproved.Score, 0.0)
+	// 否定后的负面弱于直接的负面词
+	assert.Greater(t, notApproved.Score, lexicon.Analyze("Regulator rejected the tokenized fund").Score)
+
+	// 缩写形式的否定
+	assert.Greater(t, lexicon.Analyze("Issuer says reserves weren't impaired and there is no shortfall").Score, 0.0)
+	assert.Greater(t, lexicon.Analyze("The protocol wasn’t hacked").Score, 0.0)
+
+	// 否定不跨越分句
+	result := lexicon.Analyze("Not a surprise, the bond rally continues")
+	assert.Greater(t, result.Score, 0.0)
+
+	// 否定只影响其后的几个词
+	result = lexicon.Analyze("No changes were made to the custody agreement after the strong quarter")
+	assert.Greater(t, result.Score, 0.0)
+}
+
+func TestLexiconPhrasesAndIntensifiers(t *testing.T) {
+	lexicon := NewLexicon()
+
+	// 短语优先于单词（"class"、"action"本身没有情绪）
+	result := lexicon.Analyze("Investors file class action against the issuer")
+	assert.Equal(t, 1, result.Hits)
+	assert.Equal(t, 2.0, result.Negative)
+
+	result = lexicon.Analyze("Regulator issues cease and desist order")
+	assert.Equal(t, 3.0, result.Negative)
+
+	plain := lexicon.Analyze("Yields declined")
+	sharp := lexicon.Analyze("Yields declined sharply")
+	assert.Equal(t, plain.Score, sharp.Score)
+	assert.Less(t, lexicon.Analyze("Yields sharply declined").Score, plain.Score)
+	assert.Greater(t, lexicon.Analyze("Yields slightly declined").Score, plain.Score)
+}
+
+type failingScorer struct{}
+
+func (failingScorer) Score(context.Context, string) (float64, error) {
+	return 0, assert.AnError
+}
+
+func TestHTTPModelAndFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body.Text {
+		case "broken":
+			http.Error(w, "model unavailable", http.StatusServiceUnavailable)
+		case "overflow":
+			w.Write([]byte(`{"score": 1.7}`))
+		default:
+			w.Write([]byte(`{"score": 0.4213}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	model := HTTPModel{URL: server.URL, Client: server.Client()}
+
+	score, err := model.Score(ctx, "USDY launches on Mantle")
+	require.NoError(t, err)
+	assert.Equal(t, 0.42, score)
+
+	score, err = model.Score(ctx, "overflow")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, score)
+
+	_, err = model.Score(ctx, "broken")
+	assert.Error(t, err)
+
+	scorer := Fallback{Primary: failingScorer{}, Secondary: NewLexicon()}
+	score, err = scorer.Score(ctx, "Issuer defaulted on its notes")
+	require.NoError(t, err)
+	assert.Less(t, score, 0.0)
+}
+
+func TestMomentum(t *testing.T) {
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	momentum := Momentum{Short: 3 * day, Long: 14 * day, MinPoints: 4}
+
+	points := []Point{
+		{Time: now.Add(-12 * day), Score: 0.6},
+		{Time: now.Add(-10 * day), Score: 0.4},
+		{Time: now.Add(-8 * day), Score: 0.5},
+		{Time: now.Add(-2 * day), Score: -0.4},
+		{Time: now.Add(-1 * day), Score: -0.6},
+		// 窗口之外的文章不计入
+		{Time: now.Add(-30 * day), Score: 1},
+		{Time: now.Add(time.Hour), Score: 1},
+	}
+
+	result, ok := momentum.Compute(points, now)
+	require.True(t, ok)
+	assert.Equal(t, 2, result.ShortCount)
+	assert.Equal(t, 5, result.LongCount)
+	assert.InDelta(t, -0.5, result.ShortMean, 1e-9)
+	assert.InDelta(t, 0.1, result.LongMean, 1e-9)
+	assert.InDelta(t, -0.6, result.Value, 1e-9)
+
+	// 近期没有文章
+	_, ok = momentum.Compute(points[:3], now)
+	assert.False(t, ok)
+
+	// 样本太少
+	_, ok = momentum.Compute(points[3:5], now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,529 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/solana"
+	"github.com/rwa-platform/shared/stats"
+)
+
+// ErrAssetNotFound 地址在任何已连接的链上都不是合约，且平台内没有相关记录
+var ErrAssetNotFound = errors.New("asset not found")
+
+// 统计近期转账量的时间窗口
+const assetVolumeWindow = 24 * time.Hour
+
+// 通过AccessControlEnumerable最多列出的管理员数量
+const maxRoleMembers = 10
+
+// EIP-1967代理合约存储槽
+var (
+	eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	eip1967AdminSlot          = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+	eip1967BeaconSlot         = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582cfd8b4a")
+)
+
+// 代币档案、钱包余额和Chainlink兼容储备喂价用到的只读方法
+const tokenProfileABI = `[
+	{"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"string"}]},
+	{"name":"symbol","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"string"}]},
+	{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"uint8"}]},
+	{"name":"totalSupply","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+	{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"account","type":"address"}],"outputs":[{"type":"uint256"}]},
+	{"name":"owner","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"address"}]},
+	{"name":"getRoleMemberCount","type":"function","stateMutability":"view","inputs":[{"name":"role","type":"bytes32"}],"outputs":[{"type":"uint256"}]},
+	{"name":"getRoleMember","type":"function","stateMutability":"view","inputs":[{"name":"role","type":"bytes32"},{"name":"index","type":"uint256"}],"outputs":[{"type":"address"}]},
+	{"name":"latestRoundData","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"roundId","type":"uint80"},{"name":"answer","type":"int256"},{"name":"startedAt","type":"uint256"},{"name":"updatedAt","type":"uint256"},{"name":"answeredInRound","type":"uint80"}]}
+]`
+
+var tokenABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(tokenProfileABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// AssetProfile 代币的链上档案，供上架前审核使用。
+// IndexedHolders根据已索引转账计算，索引起点之前就持有且之后没有转账的地址不在其中
+type AssetProfile struct {
+	Address        string             `json:"address"`
+	Name           string             `json:"name,omitempty"`
+	Symbol         string             `json:"symbol,omitempty"`
+	Decimals       *uint8             `json:"decimals,omitempty"`
+	Chains         []ChainTokenInfo   `json:"chains"`
+	IndexedHolders int64              `json:"indexed_holders"`
+	Volume         AssetTransferStats `json:"recent_volume"`
+	Asset          *models.Asset      `json:"asset"`
+	FetchedAt      time.Time          `json:"fetched_at"`
+}
+
+// ChainTokenInfo 代币在单条链上的信息
+type ChainTokenInfo struct {
+	Chain          string             `json:"chain"`
+	Name           string             `json:"name,omitempty"`
+	Symbol         string             `json:"symbol,omitempty"`
+	Decimals       *uint8             `json:"decimals,omitempty"`
+	TotalSupply    string             `json:"total_supply,omitempty"`
+	IsProxy        bool               `json:"is_proxy"`
+	Implementation *string            `json:"implementation,omitempty"`
+	ProxyAdmin     *string            `json:"proxy_admin,omitempty"`
+	Beacon         *string            `json:"beacon,omitempty"`
+	Owner          *string            `json:"owner,omitempty"`
+	Admins         []string           `json:"admins,omitempty"`
+	IndexedHolders int64              `json:"indexed_holders"`
+	Volume         AssetTransferStats `json:"recent_volume"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// AssetTransferStats 基于已索引转账的统计
+type AssetTransferStats struct {
+	Window        string `json:"window"`
+	TransferCount int64  `json:"transfer_count"`
+	// Volume 按代币精度换算后的转账量；精度未知时为空，且不计入跨链汇总
+	Volume string `json:"volume,omitempty"`
+	// RawVolume 链上最小单位的转账量，只在单条链的统计中返回
+	RawVolume string `json:"raw_volume,omitempty"`
+}
+
+// assetProfileCache 代币档案缓存的命中计数，强制刷新的请求不计入
+var assetProfileCache = stats.NewCacheCounter("asset_profile")
+
+// GetAssetInfo 获取代币在所有已连接链上的档案，结果按BlockchainCacheTTL缓存
+func (s *BlockchainService) GetAssetInfo(ctx context.Context, address string, refresh bool) (*AssetProfile, error) {
+	address = normalizeAddress(address)
+	cacheKey := fmt.Sprintf("asset_profile:%s", address)
+
+	if !refresh {
+		if cached, err := s.redis.Get(ctx, cacheKey).Result(); err == nil {
+			var profile AssetProfile
+			if err := json.Unmarshal([]byte(cached), &profile); err == nil {
+				assetProfileCache.Hit()
+				return &profile, nil
+			}
+		}
+		assetProfileCache.Miss()
+	}
+
+	profile, err := s.buildAssetProfile(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(profile); err == nil {
+		ttl := time.Duration(s.config.BlockchainCacheTTL) * time.Second
+		if err := s.redis.Set(ctx, cacheKey, data, ttl); err != nil {
+			s.logger.Errorf("Failed to cache asset profile for %s: %v", address, err)
+		}
+	}
+
+	return profile, nil
+}
+
+func (s *BlockchainService) buildAssetProfile(ctx context.Context, address string) (*AssetProfile, error) {
+	profile := &AssetProfile{
+		Address:   address,
+		Chains:    []ChainTokenInfo{},
+		FetchedAt: time.Now(),
+	}
+
+	// EVM地址查询各链的链上信息，其他格式（如Solana mint）只使用已索引数据
+	if common.IsHexAddress(address) {
+		profile.Chains = s.readTokenOnChains(ctx, common.HexToAddress(address))
+	}
+
+	indexedChains, err := s.chainsWithTransfers(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, chain := range indexedChains {
+		if !hasChain(profile.Chains, chain) {
+			profile.Chains = append(profile.Chains, ChainTokenInfo{Chain: chain})
+		}
+	}
+
+	since := time.Now().Add(-assetVolumeWindow)
+	for i := range profile.Chains {
+		info := &profile.Chains[i]
+
+		holders, err := s.countHolders(info.Chain, address)
+		if err != nil {
+			return nil, err
+		}
+		stats, err := s.transferStats(info.Chain, address, since)
+		if err != nil {
+			return nil, err
+		}
+		if info.Decimals == nil {
+			if info.Decimals, err = s.indexedDecimals(info.Chain, address); err != nil {
+				return nil, err
+			}
+		}
+
+		info.IndexedHolders = holders
+		info.Volume = stats
+		profile.IndexedHolders += holders
+
+		if profile.Name == "" && info.Name != "" {
+			profile.Name = info.Name
+			profile.Symbol = info.Symbol
+			profile.Decimals = info.Decimals
+		}
+	}
+	profile.Volume = aggregateVolume(profile.Chains)
+
+	asset, err := s.findLinkedAsset(address)
+	if err != nil {
+		return nil, err
+	}
+	profile.Asset = asset
+
+	if len(profile.Chains) == 0 && asset == nil {
+		return nil, ErrAssetNotFound
+	}
+
+	sort.Slice(profile.Chains, func(i, j int) bool { return profile.Chains[i].Chain < profile.Chains[j].Chain })
+	return profile, nil
+}
+
+// readTokenOnChains 并发读取所有EVM链上的合约信息，只返回部署了合约的链
+func (s *BlockchainService) readTokenOnChains(ctx context.Context, token common.Address) []ChainTokenInfo {
+	s.mu.RLock()
+	pools := make([]*evmrpc.Pool, 0, len(s.pools))
+	for _, pool := range s.pools {
+		pools = append(pools, pool)
+	}
+	s.mu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	chains := []ChainTokenInfo{}
+
+	for _, pool := range pools {
+		wg.Add(1)
+		go func(pool *evmrpc.Pool) {
+			defer wg.Done()
+
+			info, deployed := s.readToken(ctx, pool, token)
+			if !deployed {
+				return
+			}
+
+			mu.Lock()
+			chains = append(chains, info)
+			mu.Unlock()
+		}(pool)
+	}
+	wg.Wait()
+
+	return chains
+}
+
+func (s *BlockchainService) readToken(ctx context.Context, pool *evmrpc.Pool, token common.Address) (ChainTokenInfo, bool) {
+	info := ChainTokenInfo{Chain: pool.Chain()}
+
+	code, err := pool.CodeAt(ctx, token, nil)
+	if err != nil {
+		info.Error = err.Error()
+		return info, true
+	}
+	if len(code) == 0 {
+		return info, false
+	}
+
+	// 基础信息通过一次multicall读取
+	adminRole := [32]byte{}
+	calls := []evmrpc.Call{
+		{Target: token, CallData: mustPack("name")},
+		{Target: token, CallData: mustPack("symbol")},
+		{Target: token, CallData: mustPack("decimals")},
+		{Target: token, CallData: mustPack("totalSupply")},
+		{Target: token, CallData: mustPack("owner")},
+		{Target: token, CallData: mustPack("getRoleMemberCount", adminRole)},
+	}
+	results, err := pool.Aggregate(ctx, calls, nil)
+	if err != nil {
+		info.Error = err.Error()
+		return info, true
+	}
+
+	info.Name = decodeStringResult("name", results[0])
+	info.Symbol = decodeStringResult("symbol", results[1])
+	if value, ok := unpackResult("decimals", results[2]); ok {
+		if decimals, ok := value.(uint8); ok {
+			info.Decimals = &decimals
+		}
+	}
+	if value, ok := unpackResult("totalSupply", results[3]); ok {
+		if supply, ok := value.(*big.Int); ok {
+			info.TotalSupply = supply.String()
+		}
+	}
+	if value, ok := unpackResult("owner", results[4]); ok {
+		if owner, ok := value.(common.Address); ok {
+			info.Owner = addressOrNil(owner)
+		}
+	}
+	if value, ok := unpackResult("getRoleMemberCount", results[5]); ok {
+		if count, ok := value.(*big.Int); ok && count.Sign() > 0 {
+			info.Admins = s.readRoleMembers(ctx, pool, token, adminRole, count)
+		}
+	}
+
+	// 代理合约信息
+	slots := []common.Hash{eip1967ImplementationSlot, eip1967AdminSlot, eip1967BeaconSlot}
+	addresses := make([]*string, len(slots))
+	for i, slot := range slots {
+		value, err := pool.StorageAt(ctx, token, slot, nil)
+		if err != nil {
+			info.Error = err.Error()
+			break
+		}
+		addresses[i] = addressOrNil(common.BytesToAddress(value.Bytes()))
+	}
+	info.Implementation, info.ProxyAdmin, info.Beacon = addresses[0], addresses[1], addresses[2]
+	info.IsProxy = info.Implementation != nil || info.Beacon != nil
+
+	return info, true
+}
+
+func (s *BlockchainService) readRoleMembers(ctx context.Context, pool *evmrpc.Pool, token common.Address, role [32]byte, count *big.Int) []string {
+	n := maxRoleMembers
+	if count.IsInt64() && count.Int64() < int64(n) {
+		n = int(count.Int64())
+	}
+
+	calls := make([]evmrpc.Call, n)
+	for i := range calls {
+		calls[i] = evmrpc.Call{Target: token, CallData: mustPack("getRoleMember", role, big.NewInt(int64(i)))}
+	}
+
+	results, err := pool.Aggregate(ctx, calls, nil)
+	if err != nil {
+		s.logger.Warnf("Failed to read admin role members of %s on %s: %v", token.Hex(), pool.Chain(), err)
+		return nil
+	}
+
+	var members []string
+	for _, result := range results {
+		if value, ok := unpackResult("getRoleMember", result); ok {
+			if member, ok := value.(common.Address); ok {
+				members = append(members, member.Hex())
+			}
+		}
+	}
+	return members
+}
+
+func (s *BlockchainService) chainsWithTransfers(address string) ([]string, error) {
+	var chains []string
+	if err := s.db.Model(&models.TokenTransfer{}).
+		Where("contract_address = ?", address).
+		Distinct().Pluck("chain", &chains).Error; err != nil {
+		return nil, fmt.Errorf("failed to query indexed chains: %v", err)
+	}
+	return chains, nil
+}
+
+// countHolders 根据已索引的转账计算余额为正的地址数量。索引从链头附近开始，
+// 结果只反映索引起点之后有转账的地址，因此以indexed_holders返回
+func (s *BlockchainService) countHolders(chain, address string) (int64, error) {
+	var count int64
+	err := s.db.Raw(`
+		SELECT COUNT(*) FROM (
+			SELECT holder FROM (
+				SELECT to_address AS holder, value AS delta FROM token_transfers WHERE chain = ? AND contract_address = ?
+				UNION ALL
+				SELECT from_address AS holder, -value AS delta FROM token_transfers WHERE chain = ? AND contract_address = ?
+			) movements
+			WHERE holder NOT IN (?, ?)
+			GROUP BY holder
+			HAVING SUM(delta) > 0
+		) holders`,
+		chain, address, chain, address, common.Address{}.Hex(), solana.SystemProgramID).Scan(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count holders on %s: %v", chain, err)
+	}
+	return count, nil
+}
+
+func (s *BlockchainService) transferStats(chain, address string, since time.Time) (AssetTransferStats, error) {
+	var row struct {
+		TransferCount int64
+		Volume        string
+	}
+	err := s.db.Model(&models.TokenTransfer{}).
+		Select("COUNT(*) AS transfer_count, COALESCE(SUM(value), 0)::text AS volume").
+		Where("chain = ? AND contract_address = ? AND timestamp >= ?", chain, address, since).
+		Scan(&row).Error
+	if err != nil {
+		return AssetTransferStats{}, fmt.Errorf("failed to aggregate transfers on %s: %v", chain, err)
+	}
+
+	return AssetTransferStats{
+		Window:        assetVolumeWindow.String(),
+		TransferCount: row.TransferCount,
+		RawVolume:     row.Volume,
+	}, nil
+}
+
+// indexedDecimals 链上读取不到精度时（如Solana mint）使用已索引转账中记录的精度
+func (s *BlockchainService) indexedDecimals(chain, address string) (*uint8, error) {
+	var transfers []models.TokenTransfer
+	err := s.db.Select("token_decimals").
+		Where("chain = ? AND contract_address = ? AND token_decimals IS NOT NULL", chain, address).
+		Limit(1).Find(&transfers).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token decimals on %s: %v", chain, err)
+	}
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+	return transfers[0].TokenDecimals, nil
+}
+
+// aggregateVolume 按各链的精度换算转账量后汇总。同一代币在不同链上的精度可能不同
+// （例如USDC在Ethereum上为6位、在BSC上为18位），精度未知的链只计入转账次数
+func aggregateVolume(chains []ChainTokenInfo) AssetTransferStats {
+	total := AssetTransferStats{Window: assetVolumeWindow.String()}
+	sum := new(big.Rat)
+	var precision uint8
+	for i := range chains {
+		info := &chains[i]
+		total.TransferCount += info.Volume.TransferCount
+		if info.Decimals == nil {
+			continue
+		}
+		volume, ok := scaleVolume(info.Volume.RawVolume, *info.Decimals)
+		if !ok {
+			continue
+		}
+		info.Volume.Volume = formatVolume(volume, *info.Decimals)
+		sum.Add(sum, volume)
+		if *info.Decimals > precision {
+			precision = *info.Decimals
+		}
+	}
+	total.Volume = formatVolume(sum, precision)
+	return total
+}
+
+// scaleVolume 将链上最小单位的数量换算为代币单位
+func scaleVolume(raw string, decimals uint8) (*big.Rat, bool) {
+	volume, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return nil, false
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return volume.Quo(volume, new(big.Rat).SetInt(scale)), true
+}
+
+// formatVolume 最多保留precision位小数，去掉末尾的0
+func formatVolume(volume *big.Rat, precision uint8) string {
+	text := volume.FloatString(int(precision))
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
+
+// findLinkedAsset 查找contracts中包含该地址的资产，未关联时返回nil
+func (s *BlockchainService) findLinkedAsset(address string) (*models.Asset, error) {
+	candidates := []string{address}
+	if lower := strings.ToLower(address); lower != address {
+		candidates = append(candidates, lower)
+	}
+
+	query := s.db.Model(&models.Asset{})
+	for i, candidate := range candidates {
+		filter, err := json.Marshal([]map[string]string{{"address": candidate}})
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			query = query.Where("contracts @> ?", string(filter))
+		} else {
+			query = query.Or("contracts @> ?", string(filter))
+		}
+	}
+
+	var assets []models.Asset
+	if err := query.Limit(1).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to query linked asset: %v", err)
+	}
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	return &assets[0], nil
+}
+
+// normalizeAddress EVM地址统一为索引时使用的checksum格式
+func normalizeAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if common.IsHexAddress(address) {
+		return common.HexToAddress(address).Hex()
+	}
+	return address
+}
+
+func hasChain(chains []ChainTokenInfo, chain string) bool {
+	for _, info := range chains {
+		if info.Chain == chain {
+			return true
+		}
+	}
+	return false
+}
+
+func mustPack(method string, args ...interface{}) []byte {
+	data, err := tokenABI.Pack(method, args...)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func unpackResult(method string, result evmrpc.CallResult) (interface{}, bool) {
+	if !result.Success || len(result.ReturnData) == 0 {
+		return nil, false
+	}
+	values, err := tokenABI.Unpack(method, result.ReturnData)
+	if err != nil || len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// decodeStringResult 兼容返回bytes32的早期代币（如MKR）
+func decodeStringResult(method string, result evmrpc.CallResult) string {
+	if value, ok := unpackResult(method, result); ok {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	if result.Success && len(result.ReturnData) == 32 {
+		return string(bytes.TrimRight(result.ReturnData, "\x00"))
+	}
+	return ""
+}
+
+func addressOrNil(address common.Address) *string {
+	if address == (common.Address{}) {
+		return nil
+	}
+	hex := address.Hex()
+	return &hex
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func uint8Ptr(v uint8) *uint8 {
+	return &v
+}
+
+func TestAggregateVolumeScalesByChainDecimals(t *testing.T) {
+	chains := []ChainTokenInfo{
+		// 1.5 USDC（6位精度）
+		{Chain: "ethereum", Decimals: uint8Ptr(6), Volume: AssetTransferStats{TransferCount: 2, RawVolume: "1500000"}},
+		// 2.25 USDC（18位精度）
+		{Chain: "bsc", Decimals: uint8Ptr(18), Volume: AssetTransferStats{TransferCount: 3, RawVolume: "2250000000000000000"}},
+		// 精度未知时只计入转账次数
+		{Chain: "solana", Volume: AssetTransferStats{TransferCount: 1, RawVolume: "42"}},
+	}
+
+	total := aggregateVolume(chains)
+
+	assert.Equal(t, int64(6), total.TransferCount)
+	assert.Equal(t, "3.75", total.Volume)
+	assert.Empty(t, total.RawVolume)
+	assert.Equal(t, assetVolumeWindow.String(), total.Window)
+
+	assert.Equal(t, "1.5", chains[0].Volume.Volume)
+	assert.Equal(t, "2.25", chains[1].Volume.Volume)
+	assert.Empty(t, chains[2].Volume.Volume)
+	assert.Equal(t, "42", chains[2].Volume.RawVolume)
+}
+
+func TestAggregateVolumeWithoutTransfers(t *testing.T) {
+	total := aggregateVolume([]ChainTokenInfo{
+		{Chain: "ethereum", Decimals: uint8Ptr(6), Volume: AssetTransferStats{RawVolume: "0"}},
+	})
+	assert.Equal(t, "0", total.Volume)
+
+	assert.Equal(t, "0", aggregateVolume(nil).Volume)
+}
+
+func TestScaleVolume(t *testing.T) {
+	volume, ok := scaleVolume("123456789", 6)
+	assert.True(t, ok)
+	assert.Equal(t, "123.456789", formatVolume(volume, 6))
+
+	volume, ok = scaleVolume("1000000000000000000000", 18)
+	assert.True(t, ok)
+	assert.Equal(t, "1000", formatVolume(volume, 18))
+
+	volume, ok = scaleVolume("7", 0)
+	assert.True(t, ok)
+	assert.Equal(t, "7", formatVolume(volume, 0))
+
+	_, ok = scaleVolume("", 6)
+	assert.False(t, ok)
+}
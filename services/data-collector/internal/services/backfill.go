@@ -0,0 +1,590 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm"
+)
+
+// SyncJobTypeBackfill 区块回填任务在sync_jobs中的类型
+const SyncJobTypeBackfill = "blockchain_backfill"
+
+var (
+	// ErrBackfillUnsupported 链不支持区块回填（目前只支持EVM链）
+	ErrBackfillUnsupported = errors.New("backfill is only supported for EVM chains")
+	// ErrSyncJobNotFound 同步任务不存在或类型不符
+	ErrSyncJobNotFound = errors.New("sync job not found")
+)
+
+const (
+	defaultBackfillWorkers = 4
+	// 按合约回填时区块范围只用于eth_getLogs，可以比全量回填大得多
+	defaultContractBackfillBatch = 2000
+	backfillAttempts             = 3
+)
+
+// BackfillOptions 区块回填参数，FromTime/ToTime设置时覆盖对应的区块号
+type BackfillOptions struct {
+	Chain     string
+	FromBlock uint64
+	ToBlock   uint64 // 为0时回填到最新的已确认区块
+	FromTime  *time.Time
+	ToTime    *time.Time
+	// Contracts 为空时回填区间内的全部交易，否则只回填与这些合约相关的交易和转账
+	Contracts []string
+	Workers   int
+	BatchSize uint64
+	// DryRun 只获取和解析区块，不写数据库也不创建SyncJob
+	DryRun bool
+	// Publish 将回填的交易和转账发布到Kafka，默认不发布以免下游重复处理历史数据
+	Publish bool
+	// ResumeJobID 从已有回填任务的检查点继续，此时忽略区间和合约参数
+	ResumeJobID string
+}
+
+// BackfillProgress 回填进度快照
+type BackfillProgress struct {
+	JobID        string `json:"job_id,omitempty"`
+	Chain        string `json:"chain"`
+	FromBlock    uint64 `json:"from_block"`
+	ToBlock      uint64 `json:"to_block"`
+	BlocksTotal  uint64 `json:"blocks_total"`
+	BlocksDone   uint64 `json:"blocks_done"`
+	Transactions int    `json:"transactions"`
+	Transfers    int    `json:"transfers"`
+}
+
+// backfillCheckpoint 保存在SyncJob.Config中的回填状态，每个分段记录下一个待处理的区块
+type backfillCheckpoint struct {
+	Chain        string          `json:"chain"`
+	FromBlock    uint64          `json:"from_block"`
+	ToBlock      uint64          `json:"to_block"`
+	Contracts    []string        `json:"contracts,omitempty"`
+	BatchSize    uint64          `json:"batch_size"`
+	Ranges       []backfillRange `json:"ranges"`
+	Transactions int             `json:"transactions"`
+	Transfers    int             `json:"transfers"`
+}
+
+type backfillRange struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	Next uint64 `json:"next"`
+}
+
+func (c *backfillCheckpoint) progress(jobID string) BackfillProgress {
+	progress := BackfillProgress{
+		JobID:        jobID,
+		Chain:        c.Chain,
+		FromBlock:    c.FromBlock,
+		ToBlock:      c.ToBlock,
+		BlocksTotal:  c.ToBlock - c.FromBlock + 1,
+		Transactions: c.Transactions,
+		Transfers:    c.Transfers,
+	}
+	for _, r := range c.Ranges {
+		progress.BlocksDone += r.Next - r.From
+	}
+	return progress
+}
+
+// splitRanges 将[from, to]切分为最多workers个连续分段
+func splitRanges(from, to uint64, workers int) []backfillRange {
+	total := to - from + 1
+	if uint64(workers) > total {
+		workers = int(total)
+	}
+	size := (total + uint64(workers) - 1) / uint64(workers)
+
+	var ranges []backfillRange
+	for start := from; start <= to; start += size {
+		end := start + size - 1
+		if end > to || end < start {
+			end = to
+		}
+		ranges = append(ranges, backfillRange{From: start, To: end, Next: start})
+		if end == to {
+			break
+		}
+	}
+	return ranges
+}
+
+// Backfill 回填指定链在区间内的历史区块，按分段并行处理并在每批完成后写入检查点
+// 写入依赖交易哈希和(交易哈希, 日志索引)的唯一约束，重复执行同一区间不会产生重复数据
+func (s *BlockchainService) Backfill(ctx context.Context, opts BackfillOptions, report func(BackfillProgress)) (*BackfillProgress, error) {
+	if opts.DryRun && opts.ResumeJobID != "" {
+		return nil, fmt.Errorf("dry run cannot resume a job")
+	}
+	if opts.Publish && s.kafka == nil {
+		return nil, fmt.Errorf("kafka producer is required to publish backfilled events")
+	}
+
+	var (
+		job        *models.SyncJob
+		checkpoint *backfillCheckpoint
+		err        error
+	)
+	if opts.ResumeJobID != "" {
+		job, checkpoint, err = s.loadBackfillJob(ctx, opts.ResumeJobID)
+		if err != nil {
+			return nil, err
+		}
+		opts.Chain = checkpoint.Chain
+	}
+
+	s.mu.RLock()
+	pool, isEVM := s.pools[opts.Chain]
+	chain, known := s.chains[opts.Chain]
+	s.mu.RUnlock()
+	if !known {
+		return nil, ErrUnknownChain
+	}
+	if !isEVM {
+		return nil, ErrBackfillUnsupported
+	}
+
+	if checkpoint == nil {
+		checkpoint, err = s.planBackfill(ctx, pool, chain, opts)
+		if err != nil {
+			return nil, err
+		}
+		if !opts.DryRun {
+			job, err = s.createBackfillJob(ctx, checkpoint)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	run := &backfillRun{
+		service:    s,
+		pool:       pool,
+		backend:    &evmBackend{chain: chain.Name, pool: pool, service: s},
+		job:        job,
+		checkpoint: checkpoint,
+		publish:    opts.Publish,
+		report:     report,
+	}
+	for _, contract := range checkpoint.Contracts {
+		run.contracts = append(run.contracts, common.HexToAddress(contract))
+	}
+
+	return run.execute(ctx)
+}
+
+// planBackfill 将时间换算为区块号并按并发数切分区间
+func (s *BlockchainService) planBackfill(ctx context.Context, pool *evmrpc.Pool, chain ChainConfig, opts BackfillOptions) (*backfillCheckpoint, error) {
+	head, err := pool.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %v", err)
+	}
+	// 只回填已确认的区块，未确认部分由索引器处理
+	safeHead := uint64(0)
+	if head > chain.Confirmations {
+		safeHead = head - chain.Confirmations
+	}
+
+	from, to := opts.FromBlock, opts.ToBlock
+	if opts.FromTime != nil {
+		if from, err = pool.BlockAtTime(ctx, *opts.FromTime, head); err != nil {
+			return nil, fmt.Errorf("failed to find block at %s: %v", opts.FromTime.Format(time.RFC3339), err)
+		}
+	}
+	if opts.ToTime != nil {
+		end, err := pool.BlockAtTime(ctx, *opts.ToTime, head)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find block at %s: %v", opts.ToTime.Format(time.RFC3339), err)
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("no blocks before %s", opts.ToTime.Format(time.RFC3339))
+		}
+		// 结束时间不包含在内
+		to = end - 1
+	}
+	if to == 0 || to > safeHead {
+		to = safeHead
+	}
+	if from > to {
+		return nil, fmt.Errorf("empty block range %d-%d on %s (confirmed head %d)", from, to, chain.Name, safeHead)
+	}
+
+	contracts := make([]string, 0, len(opts.Contracts))
+	for _, contract := range opts.Contracts {
+		contract = strings.TrimSpace(contract)
+		if !common.IsHexAddress(contract) {
+			return nil, fmt.Errorf("invalid contract address: %s", contract)
+		}
+		contracts = append(contracts, normalizeAddress(contract))
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = chain.BatchSize
+		if len(contracts) > 0 {
+			batchSize = defaultContractBackfillBatch
+		}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBackfillWorkers
+	}
+
+	return &backfillCheckpoint{
+		Chain:     chain.Name,
+		FromBlock: from,
+		ToBlock:   to,
+		Contracts: contracts,
+		BatchSize: batchSize,
+		Ranges:    splitRanges(from, to, workers),
+	}, nil
+}
+
+func (s *BlockchainService) createBackfillJob(ctx context.Context, checkpoint *backfillCheckpoint) (*models.SyncJob, error) {
+	config, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	total := int(checkpoint.ToBlock - checkpoint.FromBlock + 1)
+	job := &models.SyncJob{
+		Type:         SyncJobTypeBackfill,
+		Status:       "pending",
+		Config:       config,
+		RecordsTotal: &total,
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sync job: %v", err)
+	}
+	return job, nil
+}
+
+func (s *BlockchainService) loadBackfillJob(ctx context.Context, id string) (*models.SyncJob, *backfillCheckpoint, error) {
+	var job models.SyncJob
+	err := s.db.WithContext(ctx).Where("id = ? AND type = ?", id, SyncJobTypeBackfill).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrSyncJobNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load sync job: %v", err)
+	}
+
+	var checkpoint backfillCheckpoint
+	if err := json.Unmarshal(job.Config, &checkpoint); err != nil || len(checkpoint.Ranges) == 0 {
+		return nil, nil, fmt.Errorf("sync job %s has no backfill checkpoint", id)
+	}
+	return &job, &checkpoint, nil
+}
+
+// backfillRun 一次回填执行，检查点的读写都在mu保护下进行
+type backfillRun struct {
+	service    *BlockchainService
+	pool       *evmrpc.Pool
+	backend    *evmBackend
+	job        *models.SyncJob
+	checkpoint *backfillCheckpoint
+	contracts  []common.Address
+	publish    bool
+	report     func(BackfillProgress)
+	mu         sync.Mutex
+}
+
+func (r *backfillRun) jobID() string {
+	if r.job == nil {
+		return ""
+	}
+	return r.job.ID
+}
+
+func (r *backfillRun) execute(ctx context.Context) (*BackfillProgress, error) {
+	logger := r.service.logger
+	progress := r.checkpoint.progress(r.jobID())
+
+	if r.job != nil {
+		now := time.Now()
+		updates := map[string]interface{}{"status": "running", "error_message": nil, "completed_at": nil}
+		if r.job.StartedAt == nil {
+			updates["started_at"] = now
+		}
+		if err := r.updateJob(ctx, updates); err != nil {
+			return nil, err
+		}
+	}
+	logger.Infof("Backfilling %s blocks %d-%d (%d done) with %d workers", progress.Chain, progress.FromBlock, progress.ToBlock, progress.BlocksDone, len(r.checkpoint.Ranges))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make([]error, len(r.checkpoint.Ranges))
+	var wg sync.WaitGroup
+	for i := range r.checkpoint.Ranges {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if errs[i] = r.work(ctx, i); errs[i] != nil {
+				// 任一分段失败时停止其他分段，已完成的批次保留在检查点中
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var runErr error
+	for _, err := range errs {
+		if err != nil && (runErr == nil || errors.Is(runErr, context.Canceled)) {
+			runErr = err
+		}
+	}
+
+	r.mu.Lock()
+	progress = r.checkpoint.progress(r.jobID())
+	r.mu.Unlock()
+
+	if r.job != nil {
+		// 上下文可能已被取消，最终状态使用独立的上下文写入
+		finalCtx, finalCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer finalCancel()
+
+		now := time.Now()
+		updates := map[string]interface{}{"status": "completed", "progress": 100, "completed_at": now}
+		if runErr != nil {
+			message := runErr.Error()
+			updates = map[string]interface{}{"status": "failed", "error_message": message, "records_error": gorm.Expr("records_error + 1")}
+		}
+		if err := r.updateJob(finalCtx, updates); err != nil {
+			logger.Errorf("Failed to update backfill job %s: %v", r.job.ID, err)
+		}
+	}
+
+	if runErr != nil {
+		return &progress, runErr
+	}
+	logger.Infof("Backfill of %s blocks %d-%d completed: %d transactions, %d transfers", progress.Chain, progress.FromBlock, progress.ToBlock, progress.Transactions, progress.Transfers)
+	return &progress, nil
+}
+
+// work 按批处理一个分段，直到分段完成或出错
+func (r *backfillRun) work(ctx context.Context, index int) error {
+	for {
+		r.mu.Lock()
+		segment := r.checkpoint.Ranges[index]
+		r.mu.Unlock()
+
+		if segment.Next > segment.To {
+			return nil
+		}
+		end := segment.Next + r.checkpoint.BatchSize - 1
+		if end > segment.To || end < segment.Next {
+			end = segment.To
+		}
+
+		var blocks []*BlockData
+		var err error
+		for attempt := 1; attempt <= backfillAttempts; attempt++ {
+			if blocks, err = r.fetch(ctx, segment.Next, end); err == nil {
+				break
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			r.service.logger.Warnf("Backfill of %s blocks %d-%d failed (attempt %d/%d): %v", r.checkpoint.Chain, segment.Next, end, attempt, backfillAttempts, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * 2 * time.Second):
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to backfill blocks %d-%d: %v", segment.Next, end, err)
+		}
+
+		transactions, transfers := 0, 0
+		for _, block := range blocks {
+			transactions += len(block.Transactions)
+			transfers += len(block.Transfers)
+		}
+
+		if r.job != nil {
+			if err := r.save(ctx, blocks); err != nil {
+				return fmt.Errorf("failed to save blocks %d-%d: %v", segment.Next, end, err)
+			}
+		}
+		if r.publish {
+			for _, block := range blocks {
+				for _, transaction := range block.Transactions {
+					r.service.publishTransactionEvent(ctx, transaction)
+				}
+				for _, transfer := range block.Transfers {
+					r.service.publishTokenTransferEvent(ctx, transfer)
+				}
+				for _, transfer := range block.Bridges {
+					r.service.publishBridgeTransferEvent(ctx, transfer)
+				}
+			}
+		}
+
+		if err := r.advance(ctx, index, end+1, transactions, transfers); err != nil {
+			return err
+		}
+	}
+}
+
+// fetch 获取[from, to]内需要回填的区块数据；指定合约时先用eth_getLogs定位相关区块
+func (r *backfillRun) fetch(ctx context.Context, from, to uint64) ([]*BlockData, error) {
+	if len(r.contracts) == 0 {
+		blocks, err := r.pool.FetchBlocks(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		data := make([]*BlockData, len(blocks))
+		for i, block := range blocks {
+			data[i] = r.backend.buildBlockData(block)
+		}
+		return data, nil
+	}
+
+	logs, err := r.filterLogs(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只保留合约产生过日志的交易，不产生日志的直接调用不在回填范围内
+	txHashes := make(map[common.Hash]bool)
+	blockSet := make(map[uint64]bool)
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+		txHashes[log.TxHash] = true
+		blockSet[log.BlockNumber] = true
+	}
+	if len(blockSet) == 0 {
+		return nil, nil
+	}
+
+	numbers := make([]uint64, 0, len(blockSet))
+	for number := range blockSet {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+	blocks, err := r.pool.FetchBlocksByNumber(ctx, numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	contracts := make(map[string]bool, len(r.checkpoint.Contracts))
+	for _, contract := range r.checkpoint.Contracts {
+		contracts[contract] = true
+	}
+
+	data := make([]*BlockData, len(blocks))
+	for i, block := range blocks {
+		full := r.backend.buildBlockData(block)
+		filtered := &BlockData{Number: full.Number}
+		for _, transaction := range full.Transactions {
+			if txHashes[common.HexToHash(transaction.Hash)] {
+				filtered.Transactions = append(filtered.Transactions, transaction)
+			}
+		}
+		for _, transfer := range full.Transfers {
+			if contracts[transfer.ContractAddress] {
+				filtered.Transfers = append(filtered.Transfers, transfer)
+			}
+		}
+		for _, event := range full.Events {
+			if contracts[event.ContractAddress] {
+				filtered.Events = append(filtered.Events, event)
+			}
+		}
+		data[i] = filtered
+	}
+	return data, nil
+}
+
+// filterLogs 节点拒绝过大的查询区间时二分后重试
+func (r *backfillRun) filterLogs(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	logs, err := r.pool.FilterLogs(ctx, from, to, r.contracts, nil)
+	if err == nil || from == to {
+		return logs, err
+	}
+
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return nil, err
+	}
+
+	mid := from + (to-from)/2
+	left, err := r.filterLogs(ctx, from, mid)
+	if err != nil {
+		return nil, err
+	}
+	right, err := r.filterLogs(ctx, mid+1, to)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// save 在一个事务中写入一批区块，不推进索引器游标
+func (r *backfillRun) save(ctx context.Context, blocks []*BlockData) error {
+	return r.service.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, block := range blocks {
+			if err := r.service.saveBlockData(tx, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// advance 更新分段检查点并写入SyncJob，写入失败时下次从上一个检查点重做，数据写入是幂等的
+func (r *backfillRun) advance(ctx context.Context, index int, next uint64, transactions, transfers int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkpoint.Ranges[index].Next = next
+	r.checkpoint.Transactions += transactions
+	r.checkpoint.Transfers += transfers
+	progress := r.checkpoint.progress(r.jobID())
+
+	if r.job != nil {
+		config, err := json.Marshal(r.checkpoint)
+		if err != nil {
+			return err
+		}
+		percent := int(progress.BlocksDone * 100 / progress.BlocksTotal)
+		if err := r.updateJob(ctx, map[string]interface{}{
+			"config":            config,
+			"progress":          percent,
+			"records_processed": int(progress.BlocksDone),
+			"records_success":   progress.Transactions + progress.Transfers,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if r.report != nil {
+		r.report(progress)
+	}
+	return nil
+}
+
+func (r *backfillRun) updateJob(ctx context.Context, updates map[string]interface{}) error {
+	if err := r.service.db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", r.job.ID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update sync job %s: %v", r.job.ID, err)
+	}
+	return nil
+}
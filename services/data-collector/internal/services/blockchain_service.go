@@ -5,190 +5,339 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
-	"strings"
+	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/go-redis/redis/v8"
 	"github.com/rwa-platform/data-collector/internal/config"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
 	"github.com/rwa-platform/data-collector/internal/kafka"
 	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/solana"
+	"github.com/rwa-platform/shared/scheduler"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type BlockchainService struct {
-	db      *gorm.DB
-	redis   *redis.Client
-	kafka   *kafka.Producer
-	config  *config.Config
-	clients map[string]*ethclient.Client
-	logger  *logrus.Logger
+	db       *gorm.DB
+	redis    RedisCache
+	kafka    *kafka.Producer
+	config   *config.Config
+	pools    map[string]*evmrpc.Pool
+	solana   *solana.Client
+	chains   map[string]ChainConfig
+	indexers map[string]*ChainIndexer
+	watcher  *WalletWatcher
+	reserves *ReserveMonitor
+	events   *EventDecoder
+	bridges  *BridgeCorrelator
+	jobs     *scheduler.Scheduler
+	mu       sync.RWMutex
+	logger   *logrus.Logger
+
+	// 索引运行期间的上下文，运行时注册的链在其下启动索引器
+	runCtx  context.Context
+	workers map[string]*indexerWorker
+	running sync.WaitGroup
+}
+
+// indexerWorker 运行中的索引器goroutine，done在goroutine退出后关闭
+type indexerWorker struct {
+	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 type ChainConfig struct {
-	Name    string
-	RPC     string
-	ChainID int64
+	Name         string
+	ChainID      int64
+	BlockTime    time.Duration
+	SyncInterval time.Duration
+	BatchSize    uint64
+	// Confirmations 余额变化被视为确认前需要等待的区块数
+	Confirmations  uint64
+	NativeSymbol   string
+	NativeDecimals uint8
+	ExplorerURL    string
 }
 
-func NewBlockchainService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *kafka.Producer, cfg *config.Config) *BlockchainService {
+func NewBlockchainService(db *gorm.DB, redisClient RedisCache, kafkaProducer *kafka.Producer, cfg *config.Config) *BlockchainService {
 	service := &BlockchainService{
-		db:      db,
-		redis:   redisClient,
-		kafka:   kafkaProducer,
-		config:  cfg,
-		clients: make(map[string]*ethclient.Client),
-		logger:  logrus.New(),
+		db:       db,
+		redis:    redisClient,
+		kafka:    kafkaProducer,
+		config:   cfg,
+		pools:    make(map[string]*evmrpc.Pool),
+		chains:   make(map[string]ChainConfig),
+		indexers: make(map[string]*ChainIndexer),
+		workers:  make(map[string]*indexerWorker),
+		logger:   logrus.New(),
 	}
+	service.watcher = newWalletWatcher(service)
+	service.reserves = newReserveMonitor(service)
+	service.events = newEventDecoder(service)
+	service.bridges = newBridgeCorrelator(service)
 
 	// 初始化区块链客户端
 	service.initClients()
+
+	if err := service.events.Reload(context.Background()); err != nil {
+		service.logger.Errorf("Failed to load ABI registry: %v", err)
+	}
+	if err := service.bridges.Reload(context.Background()); err != nil {
+		service.logger.Errorf("Failed to load bridge contracts: %v", err)
+	}
 	
 	return service
 }
 
+// initClients 连接链注册表中启用的EVM链，注册表不可用时退回*_RPC_URL配置
 func (s *BlockchainService) initClients() {
-	chains := []ChainConfig{
-		{"ethereum", s.config.EthereumRPC, 1},
-		{"arbitrum", s.config.ArbitrumRPC, 42161},
-		{"base", s.config.BaseRPC, 8453},
-		{"polygon", s.config.PolygonRPC, 137},
-		{"bsc", s.config.BSCRPC, 56},
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	chains, err := s.loadChainRegistry(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to load chain registry, falling back to RPC URL config: %v", err)
+		chains = s.legacyChains()
 	}
 
 	for _, chain := range chains {
-		if chain.RPC != "" {
-			client, err := ethclient.Dial(chain.RPC)
-			if err != nil {
-				s.logger.Errorf("Failed to connect to %s: %v", chain.Name, err)
-				continue
-			}
-			s.clients[chain.Name] = client
-			s.logger.Infof("Connected to %s blockchain", chain.Name)
+		pool, err := s.dialChain(ctx, chain, false)
+		if err != nil {
+			s.logger.Errorf("Failed to connect to %s: %v", chain.Name, err)
+			continue
 		}
+		s.registerChain(chain, pool)
+		s.logger.Infof("Connected to %s blockchain with %d endpoints", chain.Name, len(chain.RPCEndpoints))
 	}
+
+	s.initSolana()
 }
 
-func (s *BlockchainService) StartBlockchainIndexing(ctx context.Context) {
-	s.logger.Info("Starting blockchain indexing service")
-	
-	ticker := time.NewTicker(time.Duration(s.config.BlockchainSyncInterval) * time.Second)
-	defer ticker.Stop()
-
-	// 立即执行一次
-	s.indexBlockchainData(ctx)
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Blockchain indexing service stopped")
-			return
-		case <-ticker.C:
-			s.indexBlockchainData(ctx)
-		}
+// initSolana 配置了RPC时连接Solana，配置了mint地址时启用索引
+func (s *BlockchainService) initSolana() {
+	if s.config.SolanaRPC == "" {
+		return
+	}
+
+	chain := s.withDefaults(ChainConfig{
+		Name:      "solana",
+		BlockTime: 400 * time.Millisecond,
+		// 出块快，按slot计数的批量需要更大
+		BatchSize:      5000,
+		NativeSymbol:   "SOL",
+		NativeDecimals: 9,
+		ExplorerURL:    "https://solscan.io",
+	})
+
+	s.solana = solana.NewClient(s.config.SolanaRPC, time.Duration(s.config.RequestTimeout)*time.Second)
+	s.chains[chain.Name] = chain
+
+	if len(s.config.SolanaMintAddresses) == 0 {
+		return
 	}
+	s.indexers[chain.Name] = newChainIndexer(s, chain, newSolanaBackend(s.db, chain.Name, s.solana, s.config.SolanaMintAddresses))
+	s.logger.Infof("Solana indexing enabled for %d mints", len(s.config.SolanaMintAddresses))
 }
 
-func (s *BlockchainService) indexBlockchainData(ctx context.Context) {
-	s.logger.Info("Starting blockchain indexing cycle")
+// withDefaults 为未单独配置调度周期和批量大小的链填充全局默认值
+func (s *BlockchainService) withDefaults(chain ChainConfig) ChainConfig {
+	if chain.SyncInterval <= 0 {
+		chain.SyncInterval = time.Duration(s.config.BlockchainSyncInterval) * time.Second
+	}
+	if chain.SyncInterval <= 0 {
+		chain.SyncInterval = 5 * time.Minute
+	}
+	if chain.BatchSize == 0 {
+		chain.BatchSize = uint64(s.config.BlockchainBatchSize)
+	}
+	if chain.BatchSize == 0 {
+		chain.BatchSize = 50
+	}
+	return chain
+}
 
-	for chainName, client := range s.clients {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			s.indexChain(ctx, chainName, client)
+const (
+	// reserveCheckJob 储备证明检查的调度任务名
+	reserveCheckJob = "reserve-check"
+	// abiRedecodeJob 执行ABI重新解码任务的调度任务名
+	abiRedecodeJob = "abi-redecode"
+	// bridgeCorrelateJob 执行跨链转账历史关联任务的调度任务名
+	bridgeCorrelateJob = "bridge-correlate"
+)
+
+// RegisterJobs 注册周期性的储备证明检查和历史回填任务；链索引器按区块持续运行，不经过调度器
+func (s *BlockchainService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	s.jobs = jobs
+
+	interval := time.Duration(s.config.ReserveCheckInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	specs := []scheduler.Spec{
+		{
+			Name:     reserveCheckJob,
+			Schedule: scheduler.Every(interval),
+			Jitter:   time.Minute,
+			Timeout:  30 * time.Minute,
+			Missed:   scheduler.MissedRunOnce,
+			Run:      s.reserves.checkAll,
+		},
+		{
+			// 回填按批写入检查点，超时中断后下一次从检查点继续
+			Name:     abiRedecodeJob,
+			Schedule: scheduler.Every(abiRegistryRefreshInterval),
+			Timeout:  30 * time.Minute,
+			Missed:   scheduler.MissedRunOnce,
+			Run:      s.events.runPendingJobs,
+		},
+		{
+			Name:     bridgeCorrelateJob,
+			Schedule: scheduler.Every(bridgeRefreshInterval),
+			Timeout:  30 * time.Minute,
+			Missed:   scheduler.MissedRunOnce,
+			Run:      s.bridges.runPendingJobs,
+		},
+	}
+	for _, spec := range specs {
+		if err := jobs.Register(spec); err != nil {
+			return err
 		}
 	}
-
-	s.logger.Info("Blockchain indexing cycle completed")
+	return nil
 }
 
-func (s *BlockchainService) indexChain(ctx context.Context, chainName string, client *ethclient.Client) {
-	// 获取最新区块号
-	latestBlock, err := client.BlockNumber(ctx)
-	if err != nil {
-		s.logger.Errorf("Failed to get latest block for %s: %v", chainName, err)
+// triggerJob 请求立即执行任务，失败时任务在下一次计划时间执行
+func (s *BlockchainService) triggerJob(ctx context.Context, name string) {
+	if s.jobs == nil {
 		return
 	}
-
-	// 获取上次同步的区块号
-	lastSyncedBlock := s.getLastSyncedBlock(chainName)
-	
-	// 如果是首次同步，从最近的100个区块开始
-	if lastSyncedBlock == 0 {
-		if latestBlock > 100 {
-			lastSyncedBlock = latestBlock - 100
-		}
+	if err := s.jobs.Trigger(ctx, name); err != nil {
+		s.logger.Warnf("Failed to trigger %s job: %v", name, err)
 	}
+}
+
+// StartBlockchainIndexing 为每条链启动独立的索引器，互不阻塞
+func (s *BlockchainService) StartBlockchainIndexing(ctx context.Context) {
+	s.logger.Info("Starting blockchain indexing service")
 
-	// 限制每次同步的区块数量
-	maxBlocks := uint64(50)
-	endBlock := lastSyncedBlock + maxBlocks
-	if endBlock > latestBlock {
-		endBlock = latestBlock
+	s.mu.Lock()
+	s.runCtx = ctx
+	for _, indexer := range s.indexers {
+		s.startIndexerLocked(indexer)
 	}
 
-	s.logger.Infof("Indexing %s blocks from %d to %d", chainName, lastSyncedBlock+1, endBlock)
+	// 钱包余额监控
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.watcher.Run(ctx)
+	}()
+
+	// ABI注册表刷新，每个实例都需要最新的注册表解码本实例索引的日志
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.events.Run(ctx)
+	}()
+
+	// 桥合约定义刷新
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.bridges.Run(ctx)
+	}()
+	s.mu.Unlock()
+
+	<-ctx.Done()
+	s.running.Wait()
+	s.logger.Info("Blockchain indexing service stopped")
+}
 
-	// 逐个处理区块
-	for blockNum := lastSyncedBlock + 1; blockNum <= endBlock; blockNum++ {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if err := s.processBlock(ctx, chainName, client, blockNum); err != nil {
-				s.logger.Errorf("Failed to process block %d on %s: %v", blockNum, chainName, err)
-				continue
-			}
-		}
+// startIndexerLocked 在可单独取消的上下文中启动索引器，调用方需持有s.mu
+func (s *BlockchainService) startIndexerLocked(indexer *ChainIndexer) {
+	if s.runCtx.Err() != nil {
+		return
 	}
 
-	// 更新最后同步的区块号
-	s.setLastSyncedBlock(chainName, endBlock)
+	ctx, cancel := context.WithCancel(s.runCtx)
+	worker := &indexerWorker{cancel: cancel, done: make(chan struct{})}
+	s.workers[indexer.chain] = worker
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		defer close(worker.done)
+		s.superviseIndexer(ctx, indexer)
+	}()
 }
 
-func (s *BlockchainService) processBlock(ctx context.Context, chainName string, client *ethclient.Client, blockNum uint64) error {
-	// 获取区块信息
-	block, err := client.BlockByNumber(ctx, big.NewInt(int64(blockNum)))
-	if err != nil {
-		return fmt.Errorf("failed to get block %d: %v", blockNum, err)
+// evmBackend 基于批量JSON-RPC的EVM链数据获取实现
+type evmBackend struct {
+	chain   string
+	pool    *evmrpc.Pool
+	service *BlockchainService
+}
+
+func (b *evmBackend) LatestBlock(ctx context.Context) (uint64, error) {
+	return b.pool.BlockNumber(ctx)
+}
+
+func (b *evmBackend) FetchRange(ctx context.Context, from, to uint64) ([]*BlockData, error) {
+	// 出错时只返回连续获取成功的区块，保证游标不会越过失败的区块
+	blocks, fetchErr := b.pool.FetchBlocks(ctx, from, to)
+
+	data := make([]*BlockData, 0, len(blocks))
+	for _, block := range blocks {
+		data = append(data, b.buildBlockData(block))
+	}
+
+	if fetchErr != nil {
+		return data, fmt.Errorf("failed to fetch blocks %d-%d on %s: %v", from, to, b.chain, fetchErr)
 	}
+	return data, nil
+}
+
+func (b *evmBackend) buildBlockData(block *evmrpc.Block) *BlockData {
+	data := &BlockData{Number: block.Number}
 
 	// 处理区块中的交易
-	for _, tx := range block.Transactions() {
-		if err := s.processTransaction(ctx, chainName, client, tx, block); err != nil {
-			s.logger.Errorf("Failed to process transaction %s: %v", tx.Hash().Hex(), err)
+	for i, tx := range block.Transactions {
+		// 无法解码的交易类型（如L2系统交易）不入库
+		if tx == nil {
 			continue
 		}
+
+		receipt := block.Receipts[i]
+		data.Transactions = append(data.Transactions, b.service.buildTransaction(b.chain, tx, block.Senders[i], receipt, block))
+		data.Transfers = append(data.Transfers, b.service.extractTokenTransfers(b.chain, tx, receipt, block)...)
+		data.Events = append(data.Events, b.service.events.decodeLogs(b.chain, receipt.Logs, block.Number, block.Time())...)
 	}
 
-	return nil
+	return data
 }
 
-func (s *BlockchainService) processTransaction(ctx context.Context, chainName string, client *ethclient.Client, tx *types.Transaction, block *types.Block) error {
-	// 获取交易收据
-	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
-	if err != nil {
-		return fmt.Errorf("failed to get transaction receipt: %v", err)
+func (s *BlockchainService) buildTransaction(chainName string, tx *types.Transaction, from common.Address, receipt *types.Receipt, block *evmrpc.Block) *models.BlockchainTransaction {
+	// 节点返回的from优先，缺失时从签名恢复
+	fromAddress := from.Hex()
+	if from == (common.Address{}) {
+		fromAddress = s.getFromAddress(tx)
 	}
 
 	// 创建交易记录
 	transaction := &models.BlockchainTransaction{
 		Chain:            chainName,
 		Hash:             tx.Hash().Hex(),
-		BlockNumber:      block.NumberU64(),
-		BlockHash:        block.Hash().Hex(),
+		BlockNumber:      block.Number,
+		BlockHash:        block.Hash.Hex(),
 		TransactionIndex: receipt.TransactionIndex,
-		FromAddress:      s.getFromAddress(tx),
+		FromAddress:      fromAddress,
 		Value:            tx.Value().String(),
 		GasUsed:          &receipt.GasUsed,
 		Status:           &receipt.Status,
-		Timestamp:        time.Unix(int64(block.Time()), 0),
+		Timestamp:        block.Time(),
 	}
 
 	if tx.To() != nil {
@@ -215,30 +364,18 @@ func (s *BlockchainService) processTransaction(ctx context.Context, chainName st
 		}
 	}
 
-	// 保存交易到数据库
-	if err := s.db.Create(transaction).Error; err != nil {
-		if !strings.Contains(err.Error(), "duplicate key") {
-			return fmt.Errorf("failed to save transaction: %v", err)
-		}
-	}
-
-	// 处理代币转账事件
-	s.processTokenTransfers(chainName, tx, receipt, block)
-
-	// 发布到Kafka
-	s.publishTransactionEvent(transaction)
-
-	return nil
+	return transaction
 }
 
-func (s *BlockchainService) processTokenTransfers(chainName string, tx *types.Transaction, receipt *types.Receipt, block *types.Block) {
+func (s *BlockchainService) extractTokenTransfers(chainName string, tx *types.Transaction, receipt *types.Receipt, block *evmrpc.Block) []*models.TokenTransfer {
 	// ERC-20 Transfer事件的签名
 	transferEventSignature := common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
 
+	var transfers []*models.TokenTransfer
 	for _, log := range receipt.Logs {
 		if len(log.Topics) >= 3 && log.Topics[0] == transferEventSignature {
 			// 解析Transfer事件
-			transfer := &models.TokenTransfer{
+			transfers = append(transfers, &models.TokenTransfer{
 				Chain:           chainName,
 				TransactionHash: tx.Hash().Hex(),
 				LogIndex:        log.Index,
@@ -246,21 +383,13 @@ func (s *BlockchainService) processTokenTransfers(chainName string, tx *types.Tr
 				FromAddress:     common.HexToAddress(log.Topics[1].Hex()).Hex(),
 				ToAddress:       common.HexToAddress(log.Topics[2].Hex()).Hex(),
 				Value:           new(big.Int).SetBytes(log.Data).String(),
-				BlockNumber:     block.NumberU64(),
-				Timestamp:       time.Unix(int64(block.Time()), 0),
-			}
-
-			// 保存代币转账记录
-			if err := s.db.Create(transfer).Error; err != nil {
-				if !strings.Contains(err.Error(), "duplicate key") {
-					s.logger.Errorf("Failed to save token transfer: %v", err)
-				}
-			}
-
-			// 发布代币转账事件
-			s.publishTokenTransferEvent(transfer)
+				BlockNumber:     block.Number,
+				Timestamp:       block.Time(),
+			})
 		}
 	}
+
+	return transfers
 }
 
 func (s *BlockchainService) getFromAddress(tx *types.Transaction) string {
@@ -273,24 +402,7 @@ func (s *BlockchainService) getFromAddress(tx *types.Transaction) string {
 	return from.Hex()
 }
 
-func (s *BlockchainService) getLastSyncedBlock(chainName string) uint64 {
-	key := fmt.Sprintf("last_synced_block:%s", chainName)
-	result, err := s.redis.Get(context.Background(), key).Result()
-	if err != nil {
-		return 0
-	}
-
-	var blockNum uint64
-	fmt.Sscanf(result, "%d", &blockNum)
-	return blockNum
-}
-
-func (s *BlockchainService) setLastSyncedBlock(chainName string, blockNum uint64) {
-	key := fmt.Sprintf("last_synced_block:%s", chainName)
-	s.redis.Set(context.Background(), key, fmt.Sprintf("%d", blockNum), 0)
-}
-
-func (s *BlockchainService) publishTransactionEvent(transaction *models.BlockchainTransaction) {
+func (s *BlockchainService) publishTransactionEvent(ctx context.Context, transaction *models.BlockchainTransaction) {
 	message := map[string]interface{}{
 		"type":         "blockchain_transaction",
 		"chain":        transaction.Chain,
@@ -302,12 +414,12 @@ func (s *BlockchainService) publishTransactionEvent(transaction *models.Blockcha
 		"timestamp":    transaction.Timestamp.Unix(),
 	}
 
-	if err := s.kafka.PublishMessage("blockchain-events", transaction.Hash, message); err != nil {
+	if err := s.kafka.PublishMessage(ctx, "blockchain-events", transaction.Hash, message); err != nil {
 		s.logger.Errorf("Failed to publish transaction event: %v", err)
 	}
 }
 
-func (s *BlockchainService) publishTokenTransferEvent(transfer *models.TokenTransfer) {
+func (s *BlockchainService) publishTokenTransferEvent(ctx context.Context, transfer *models.TokenTransfer) {
 	message := map[string]interface{}{
 		"type":             "token_transfer",
 		"chain":            transfer.Chain,
@@ -320,19 +432,11 @@ func (s *BlockchainService) publishTokenTransferEvent(transfer *models.TokenTran
 		"timestamp":        transfer.Timestamp.Unix(),
 	}
 
-	if err := s.kafka.PublishMessage("token-transfers", transfer.TransactionHash, message); err != nil {
+	if err := s.kafka.PublishMessage(ctx, "token-transfers", transfer.TransactionHash, message); err != nil {
 		s.logger.Errorf("Failed to publish token transfer event: %v", err)
 	}
 }
 
-func (s *BlockchainService) GetAssetInfo(contractAddress string) (*models.Asset, error) {
-	var asset models.Asset
-	if err := s.db.Where("contracts @> ?", fmt.Sprintf(`[{"address": "%s"}]`, contractAddress)).First(&asset).Error; err != nil {
-		return nil, err
-	}
-	return &asset, nil
-}
-
 func (s *BlockchainService) GetTransaction(hash string) (*models.BlockchainTransaction, error) {
 	var transaction models.BlockchainTransaction
 	if err := s.db.Where("hash = ?", hash).First(&transaction).Error; err != nil {
@@ -342,6 +446,11 @@ func (s *BlockchainService) GetTransaction(hash string) (*models.BlockchainTrans
 }
 
 func (s *BlockchainService) TriggerSync() error {
-	go s.indexBlockchainData(context.Background())
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, indexer := range s.indexers {
+		indexer.Trigger()
+	}
 	return nil
 }
@@ -0,0 +1,612 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rwa-platform/data-collector/internal/bridge"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SyncJobTypeBridgeCorrelate 添加桥合约后关联历史事件的任务在sync_jobs中的类型
+const SyncJobTypeBridgeCorrelate = "bridge_correlate"
+
+// 跨链转账状态
+const (
+	BridgeStatusPending   = "pending"
+	BridgeStatusCompleted = "completed"
+)
+
+const (
+	bridgeRefreshInterval = 5 * time.Minute
+	bridgeCorrelateBatch  = 500
+)
+
+var (
+	// ErrInvalidBridge 桥合约定义不合法
+	ErrInvalidBridge = errors.New("invalid bridge contract")
+	// ErrBridgeNotFound 桥合约不存在
+	ErrBridgeNotFound = errors.New("bridge contract not found")
+)
+
+var zeroAddress = common.Address{}.Hex()
+
+// bridgeTransferUpsert 合并同一消息ID的两侧：每侧的字段取新观察到的值，共有字段保留最先观察到的值
+var bridgeTransferUpsert = func() clause.OnConflict {
+	var set clause.Set
+	for _, column := range []string{
+		"source_chain", "source_tx_hash", "source_log_index", "source_token", "sent_at", "remote_chain",
+		"dest_chain", "dest_tx_hash", "dest_log_index", "dest_token", "received_at",
+	} {
+		set = append(set, clause.Assignment{Column: clause.Column{Name: column}, Value: gorm.Expr(fmt.Sprintf("COALESCE(EXCLUDED.%s, bridge_transfers.%s)", column, column))})
+	}
+	for _, column := range []string{"sender", "recipient", "amount"} {
+		set = append(set, clause.Assignment{Column: clause.Column{Name: column}, Value: gorm.Expr(fmt.Sprintf("COALESCE(bridge_transfers.%s, EXCLUDED.%s)", column, column))})
+	}
+	set = append(set,
+		clause.Assignment{Column: clause.Column{Name: "timestamp"}, Value: gorm.Expr("LEAST(bridge_transfers.timestamp, EXCLUDED.timestamp)")},
+		clause.Assignment{Column: clause.Column{Name: "status"}, Value: gorm.Expr(
+			"CASE WHEN COALESCE(EXCLUDED.source_tx_hash, bridge_transfers.source_tx_hash) IS NOT NULL AND COALESCE(EXCLUDED.dest_tx_hash, bridge_transfers.dest_tx_hash) IS NOT NULL THEN ? ELSE ? END",
+			BridgeStatusCompleted, BridgeStatusPending)},
+		clause.Assignment{Column: clause.Column{Name: "updated_at"}, Value: gorm.Expr("EXCLUDED.updated_at")},
+	)
+	return clause.OnConflict{
+		Columns:   []clause.Column{{Name: "protocol"}, {Name: "message_id"}},
+		DoUpdates: set,
+	}
+}()
+
+// BridgeContractInput 添加桥合约的请求
+type BridgeContractInput struct {
+	Protocol       string `json:"protocol" binding:"required"`
+	Chain          string `json:"chain" binding:"required"`
+	Address        string `json:"address" binding:"required"`
+	EventName      string `json:"event_name"`
+	Direction      string `json:"direction"`
+	MessageIDArg   string `json:"message_id_arg"`
+	TokenArg       string `json:"token_arg"`
+	AmountArg      string `json:"amount_arg"`
+	SenderArg      string `json:"sender_arg"`
+	RecipientArg   string `json:"recipient_arg"`
+	RemoteChainArg string `json:"remote_chain_arg"`
+	Escrow         bool   `json:"escrow"`
+}
+
+// BridgeTransferQuery 跨链转账查询条件，Address匹配发送方或接收方
+type BridgeTransferQuery struct {
+	Address  string
+	Protocol string
+	Status   string
+	Cursor   string
+	Limit    int
+}
+
+// bridgeCorrelateCheckpoint 保存在SyncJob.Config中的历史关联状态，按(block_number, id)顺序扫描解码事件
+type bridgeCorrelateCheckpoint struct {
+	BridgeContractID string `json:"bridge_contract_id"`
+	Chain            string `json:"chain"`
+	Address          string `json:"address"`
+	EventName        string `json:"event_name"`
+	AfterBlock       uint64 `json:"after_block"`
+	AfterID          string `json:"after_id,omitempty"`
+	Completed        int    `json:"completed"`
+}
+
+type bridgeKey struct {
+	chain   string
+	address string
+	event   string
+}
+
+// BridgeCorrelator 将桥合约的出站和入站事件按消息ID关联为跨链转账
+type BridgeCorrelator struct {
+	service     *BlockchainService
+	mu          sync.RWMutex
+	definitions map[bridgeKey]*models.BridgeContract
+	escrows     map[string][]string
+	logger      *logrus.Logger
+}
+
+func newBridgeCorrelator(service *BlockchainService) *BridgeCorrelator {
+	return &BridgeCorrelator{
+		service:     service,
+		definitions: make(map[bridgeKey]*models.BridgeContract),
+		escrows:     make(map[string][]string),
+		logger:      service.logger,
+	}
+}
+
+// Run 定期刷新桥合约定义；定义在每个实例的内存中，不经过只在一个实例执行的调度器
+func (c *BridgeCorrelator) Run(ctx context.Context) {
+	ticker := time.NewTicker(bridgeRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Reload(ctx); err != nil {
+			c.logger.Errorf("Failed to load bridge contracts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reload 从数据库加载启用的桥合约
+func (c *BridgeCorrelator) Reload(ctx context.Context) error {
+	var contracts []models.BridgeContract
+	if err := c.service.db.WithContext(ctx).Where("is_active = ?", true).Find(&contracts).Error; err != nil {
+		return err
+	}
+
+	definitions := make(map[bridgeKey]*models.BridgeContract)
+	escrows := make(map[string][]string)
+	for i := range contracts {
+		contract := &contracts[i]
+		if contract.Escrow {
+			escrows[contract.Chain] = append(escrows[contract.Chain], contract.Address)
+		}
+		if contract.EventName != "" {
+			definitions[bridgeKey{contract.Chain, contract.Address, contract.EventName}] = contract
+		}
+	}
+
+	c.mu.Lock()
+	c.definitions = definitions
+	c.escrows = escrows
+	c.mu.Unlock()
+	return nil
+}
+
+// escrowsOn 返回链上的托管合约地址
+func (c *BridgeCorrelator) escrowsOn(chain string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.escrows[chain]
+}
+
+func (c *BridgeCorrelator) definition(event *models.DecodedEvent) *models.BridgeContract {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.definitions[bridgeKey{event.Chain, event.ContractAddress, event.EventName}]
+}
+
+// record 在写入区块数据的事务中关联桥合约事件，返回本次变为completed的跨链转账
+func (c *BridgeCorrelator) record(tx *gorm.DB, events []*models.DecodedEvent) ([]*models.BridgeTransfer, error) {
+	var completed []*models.BridgeTransfer
+	for _, event := range events {
+		contract := c.definition(event)
+		if contract == nil {
+			continue
+		}
+
+		var args map[string]interface{}
+		if err := json.Unmarshal(event.Args, &args); err != nil {
+			continue
+		}
+		leg, err := bridge.Extract(bridgeDefinition(contract), args)
+		if err != nil {
+			c.logger.Debugf("Skipping %s event %s:%d: %v", contract.Protocol, event.TransactionHash, event.LogIndex, err)
+			continue
+		}
+
+		transfer, err := c.recordLeg(tx, event, leg)
+		if err != nil {
+			return nil, err
+		}
+		if transfer.Status == BridgeStatusCompleted {
+			completed = append(completed, transfer)
+		}
+	}
+	return completed, nil
+}
+
+// recordLeg 写入转账的一侧，并将同一交易中对应的代币转账关联到跨链转账
+func (c *BridgeCorrelator) recordLeg(tx *gorm.DB, event *models.DecodedEvent, leg *bridge.Leg) (*models.BridgeTransfer, error) {
+	tokenTransfer, err := c.matchTokenTransfer(tx, event, leg)
+	if err != nil {
+		return nil, err
+	}
+	// 桥事件中没有的字段从对应的代币转账补全
+	if tokenTransfer != nil {
+		if leg.Token == "" {
+			leg.Token = tokenTransfer.ContractAddress
+		}
+		if leg.Amount == "" {
+			leg.Amount = tokenTransfer.Value
+		}
+		if leg.Direction == bridge.Outbound && leg.Sender == "" {
+			leg.Sender = tokenTransfer.FromAddress
+		}
+		if leg.Direction == bridge.Inbound && leg.Recipient == "" {
+			leg.Recipient = tokenTransfer.ToAddress
+		}
+	}
+
+	chain, txHash, logIndex, timestamp := event.Chain, event.TransactionHash, event.LogIndex, event.Timestamp
+	transfer := &models.BridgeTransfer{
+		Protocol:  leg.Protocol,
+		MessageID: leg.MessageID,
+		Status:    BridgeStatusPending,
+		Sender:    optionalAddress(leg.Sender),
+		Recipient: optionalAddress(leg.Recipient),
+		Amount:    optionalString(leg.Amount),
+		Timestamp: timestamp,
+	}
+	if leg.Direction == bridge.Outbound {
+		transfer.SourceChain = &chain
+		transfer.SourceTxHash = &txHash
+		transfer.SourceLogIndex = &logIndex
+		transfer.SourceToken = optionalAddress(leg.Token)
+		transfer.RemoteChain = optionalString(leg.RemoteChain)
+		transfer.SentAt = &timestamp
+	} else {
+		transfer.DestChain = &chain
+		transfer.DestTxHash = &txHash
+		transfer.DestLogIndex = &logIndex
+		transfer.DestToken = optionalAddress(leg.Token)
+		transfer.ReceivedAt = &timestamp
+	}
+
+	if err := tx.Clauses(bridgeTransferUpsert, clause.Returning{}).Create(transfer).Error; err != nil {
+		return nil, fmt.Errorf("failed to save bridge transfer: %v", err)
+	}
+
+	if tokenTransfer != nil {
+		if err := tx.Model(&models.TokenTransfer{}).Where("id = ?", tokenTransfer.ID).Updates(map[string]interface{}{
+			"bridge_transfer_id": transfer.ID,
+			"bridge_direction":   leg.Direction,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to link token transfer: %v", err)
+		}
+	}
+	return transfer, nil
+}
+
+// matchTokenTransfer 查找桥事件所在交易中被锁定/销毁或铸造/释放的代币转账
+// 事件中有发送方（出站）或接收方（入站）时按地址匹配，否则匹配与零地址或托管合约之间的转账
+func (c *BridgeCorrelator) matchTokenTransfer(tx *gorm.DB, event *models.DecodedEvent, leg *bridge.Leg) (*models.TokenTransfer, error) {
+	query := tx.Model(&models.TokenTransfer{}).Where("chain = ? AND transaction_hash = ?", event.Chain, event.TransactionHash)
+	if leg.Token != "" {
+		query = query.Where("contract_address = ?", normalizeAddress(leg.Token))
+	}
+
+	counterparties := append([]string{zeroAddress}, c.escrowsOn(event.Chain)...)
+	if leg.Direction == bridge.Outbound {
+		if leg.Sender != "" {
+			query = query.Where("from_address = ?", normalizeAddress(leg.Sender))
+		} else {
+			query = query.Where("to_address IN ?", counterparties)
+		}
+	} else {
+		if leg.Recipient != "" {
+			query = query.Where("to_address = ?", normalizeAddress(leg.Recipient))
+		} else {
+			query = query.Where("from_address IN ?", counterparties)
+		}
+	}
+
+	var transfers []models.TokenTransfer
+	if err := query.Order("log_index").Limit(1).Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("failed to match token transfer: %v", err)
+	}
+	if len(transfers) == 0 {
+		return nil, nil
+	}
+	return &transfers[0], nil
+}
+
+func bridgeDefinition(contract *models.BridgeContract) bridge.Definition {
+	return bridge.Definition{
+		Protocol:       contract.Protocol,
+		Direction:      contract.Direction,
+		MessageIDArg:   contract.MessageIDArg,
+		TokenArg:       contract.TokenArg,
+		AmountArg:      contract.AmountArg,
+		SenderArg:      contract.SenderArg,
+		RecipientArg:   contract.RecipientArg,
+		RemoteChainArg: contract.RemoteChainArg,
+	}
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+func optionalAddress(value string) *string {
+	if value == "" {
+		return nil
+	}
+	normalized := normalizeAddress(value)
+	return &normalized
+}
+
+// runPendingJobs 依次执行待处理的历史关联任务，执行前刷新桥合约定义
+func (c *BridgeCorrelator) runPendingJobs(ctx context.Context) error {
+	if err := c.Reload(ctx); err != nil {
+		return fmt.Errorf("failed to load bridge contracts: %v", err)
+	}
+
+	var jobs []models.SyncJob
+	if err := c.service.db.WithContext(ctx).
+		Where("type = ? AND status IN ?", SyncJobTypeBridgeCorrelate, []string{"pending", "running"}).
+		Order("created_at").
+		Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to load bridge correlation jobs: %v", err)
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := c.correlateHistory(ctx, &jobs[i]); err != nil && ctx.Err() == nil {
+			c.logger.Errorf("Bridge correlation job %s failed: %v", jobs[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// correlateHistory 关联桥合约在添加前已解码的事件，每批完成后写入检查点
+func (c *BridgeCorrelator) correlateHistory(ctx context.Context, job *models.SyncJob) error {
+	db := c.service.db
+	var checkpoint bridgeCorrelateCheckpoint
+	if err := json.Unmarshal(job.Config, &checkpoint); err != nil || checkpoint.BridgeContractID == "" {
+		return c.service.events.finishJob(job, fmt.Errorf("sync job has no bridge correlation checkpoint"))
+	}
+
+	scan := func() *gorm.DB {
+		return db.WithContext(ctx).Model(&models.DecodedEvent{}).
+			Where("chain = ? AND contract_address = ? AND event_name = ?", checkpoint.Chain, checkpoint.Address, checkpoint.EventName)
+	}
+
+	updates := map[string]interface{}{"status": "running", "error_message": nil, "completed_at": nil}
+	if job.StartedAt == nil {
+		updates["started_at"] = time.Now()
+	}
+	if job.RecordsTotal == nil {
+		var total int64
+		if err := scan().Count(&total).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return c.service.events.finishJob(job, fmt.Errorf("failed to count events: %v", err))
+		}
+		count := int(total)
+		job.RecordsTotal = &count
+		updates["records_total"] = count
+	}
+	if err := db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	processed := job.RecordsProcessed
+	for {
+		query := scan()
+		if checkpoint.AfterID != "" {
+			query = query.Where("block_number > ? OR (block_number = ? AND id > ?)", checkpoint.AfterBlock, checkpoint.AfterBlock, checkpoint.AfterID)
+		}
+		var events []*models.DecodedEvent
+		if err := query.Order("block_number, id").Limit(bridgeCorrelateBatch).Find(&events).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return c.service.events.finishJob(job, fmt.Errorf("failed to scan events: %v", err))
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		last := events[len(events)-1]
+		checkpoint.AfterBlock = last.BlockNumber
+		checkpoint.AfterID = last.ID
+		processed += len(events)
+
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			completed, err := c.record(tx, events)
+			if err != nil {
+				return err
+			}
+			checkpoint.Completed += len(completed)
+
+			config, err := json.Marshal(checkpoint)
+			if err != nil {
+				return err
+			}
+			progress := 100
+			if *job.RecordsTotal > 0 && processed < *job.RecordsTotal {
+				progress = processed * 100 / *job.RecordsTotal
+			}
+			return tx.Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"config":            config,
+				"progress":          progress,
+				"records_processed": processed,
+				"records_success":   checkpoint.Completed,
+			}).Error
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return c.service.events.finishJob(job, err)
+		}
+	}
+
+	c.logger.Infof("Bridge correlation for %s %s on %s completed: %d events, %d transfers completed",
+		checkpoint.EventName, checkpoint.Address, checkpoint.Chain, processed, checkpoint.Completed)
+	return c.service.events.finishJob(job, nil)
+}
+
+// AddBridgeContract 添加或更新桥合约，并为已解码的历史事件创建关联任务
+func (s *BlockchainService) AddBridgeContract(ctx context.Context, input BridgeContractInput) (*models.BridgeContract, *models.SyncJob, error) {
+	contract := models.BridgeContract{
+		Protocol:       strings.ToLower(strings.TrimSpace(input.Protocol)),
+		Chain:          strings.TrimSpace(input.Chain),
+		EventName:      strings.TrimSpace(input.EventName),
+		Direction:      input.Direction,
+		MessageIDArg:   input.MessageIDArg,
+		TokenArg:       input.TokenArg,
+		AmountArg:      input.AmountArg,
+		SenderArg:      input.SenderArg,
+		RecipientArg:   input.RecipientArg,
+		RemoteChainArg: input.RemoteChainArg,
+		Escrow:         input.Escrow,
+		IsActive:       true,
+	}
+
+	s.mu.RLock()
+	_, isEVM := s.pools[contract.Chain]
+	s.mu.RUnlock()
+	if !isEVM {
+		return nil, nil, fmt.Errorf("%w: %s is not a registered EVM chain", ErrInvalidBridge, contract.Chain)
+	}
+	if !common.IsHexAddress(input.Address) {
+		return nil, nil, fmt.Errorf("%w: invalid contract address %s", ErrInvalidBridge, input.Address)
+	}
+	contract.Address = normalizeAddress(input.Address)
+	if contract.EventName == "" && !contract.Escrow {
+		return nil, nil, fmt.Errorf("%w: event_name is required unless the contract is an escrow", ErrInvalidBridge)
+	}
+	if contract.EventName != "" {
+		if err := bridgeDefinition(&contract).Validate(); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidBridge, err)
+		}
+	}
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chain"}, {Name: "address"}, {Name: "event_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"protocol", "direction", "message_id_arg", "token_arg", "amount_arg", "sender_arg",
+			"recipient_arg", "remote_chain_arg", "escrow", "is_active", "updated_at",
+		}),
+	}, clause.Returning{}).Create(&contract).Error
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save bridge contract: %v", err)
+	}
+
+	if err := s.bridges.Reload(ctx); err != nil {
+		s.logger.Errorf("Failed to reload bridge contracts: %v", err)
+	}
+	// 桥合约也在事件解码的跟踪范围内
+	if err := s.events.Reload(ctx); err != nil {
+		s.logger.Errorf("Failed to reload ABI registry: %v", err)
+	}
+
+	if contract.EventName == "" {
+		return &contract, nil, nil
+	}
+
+	config, err := json.Marshal(bridgeCorrelateCheckpoint{
+		BridgeContractID: contract.ID,
+		Chain:            contract.Chain,
+		Address:          contract.Address,
+		EventName:        contract.EventName,
+	})
+	if err != nil {
+		return &contract, nil, err
+	}
+	job := &models.SyncJob{
+		Type:   SyncJobTypeBridgeCorrelate,
+		Status: "pending",
+		Config: config,
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return &contract, nil, fmt.Errorf("failed to create sync job: %v", err)
+	}
+
+	s.triggerJob(ctx, bridgeCorrelateJob)
+	return &contract, job, nil
+}
+
+// ListBridgeContracts 列出桥合约
+func (s *BlockchainService) ListBridgeContracts(ctx context.Context) ([]models.BridgeContract, error) {
+	var contracts []models.BridgeContract
+	if err := s.db.WithContext(ctx).Order("protocol, chain, address").Find(&contracts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list bridge contracts: %v", err)
+	}
+	return contracts, nil
+}
+
+// RemoveBridgeContract 停用桥合约，已关联的跨链转账保留
+func (s *BlockchainService) RemoveBridgeContract(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Model(&models.BridgeContract{}).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove bridge contract: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrBridgeNotFound
+	}
+	if err := s.bridges.Reload(ctx); err != nil {
+		s.logger.Errorf("Failed to reload bridge contracts: %v", err)
+	}
+	return nil
+}
+
+// GetBridgeTransfers 查询跨链转账，按时间倒序分页
+func (s *BlockchainService) GetBridgeTransfers(ctx context.Context, q BridgeTransferQuery) ([]models.BridgeTransfer, string, error) {
+	query := s.db.Model(&models.BridgeTransfer{})
+	if q.Address != "" {
+		address := normalizeAddress(q.Address)
+		query = query.Where("sender = ? OR recipient = ?", address, address)
+	}
+	if q.Protocol != "" {
+		query = query.Where("protocol = ?", strings.ToLower(q.Protocol))
+	}
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	query, err := applyActivityFilters(query, WalletActivityQuery{Cursor: q.Cursor, Limit: q.Limit})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var transfers []models.BridgeTransfer
+	if err := query.WithContext(ctx).Find(&transfers).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to query bridge transfers: %v", err)
+	}
+
+	nextCursor := ""
+	if len(transfers) > q.Limit {
+		transfers = transfers[:q.Limit]
+		last := transfers[len(transfers)-1]
+		nextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+	return transfers, nextCursor, nil
+}
+
+func (s *BlockchainService) publishBridgeTransferEvent(ctx context.Context, transfer *models.BridgeTransfer) {
+	message := map[string]interface{}{
+		"type":         "bridge_transfer",
+		"id":           transfer.ID,
+		"protocol":     transfer.Protocol,
+		"message_id":   transfer.MessageID,
+		"source_chain": transfer.SourceChain,
+		"source_tx":    transfer.SourceTxHash,
+		"source_token": transfer.SourceToken,
+		"dest_chain":   transfer.DestChain,
+		"dest_tx":      transfer.DestTxHash,
+		"dest_token":   transfer.DestToken,
+		"sender":       transfer.Sender,
+		"recipient":    transfer.Recipient,
+		"amount":       transfer.Amount,
+		"sent_at":      transfer.SentAt,
+		"received_at":  transfer.ReceivedAt,
+	}
+
+	if err := s.kafka.PublishMessage(ctx, "token-transfers", transfer.MessageID, message); err != nil {
+		s.logger.Errorf("Failed to publish bridge transfer event: %v", err)
+	}
+}
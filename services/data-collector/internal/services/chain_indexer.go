@@ -0,0 +1,525 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 索引器状态
+const (
+	IndexerStateStarting = "starting"
+	IndexerStateRunning  = "running"
+	IndexerStatePaused   = "paused"
+	IndexerStateDegraded = "degraded"
+	IndexerStateStopped  = "stopped"
+)
+
+// 首次同步时回溯的区块数
+const initialSyncLookback = 100
+
+// rewindTimeout 等待索引器完成当前同步周期并执行回退的最长时间
+const rewindTimeout = 2 * time.Minute
+
+var ErrUnknownChain = errors.New("unknown chain")
+
+// ErrIndexerBusy 索引器在rewindTimeout内没有空闲下来执行回退
+var ErrIndexerBusy = errors.New("indexer did not accept the rewind in time")
+
+// BlockData 单个区块（或slot）内需要入库的数据
+type BlockData struct {
+	Number       uint64
+	Transactions []*models.BlockchainTransaction
+	Transfers    []*models.TokenTransfer
+	Events       []*models.DecodedEvent
+	// Bridges 写入时变为completed的跨链转账，由saveBlockData填充
+	Bridges []*models.BridgeTransfer
+}
+
+// chainBackend 链数据获取接口，不同链实现各自的抓取逻辑
+type chainBackend interface {
+	// LatestBlock 返回链上最新的区块号（或slot）
+	LatestBlock(ctx context.Context) (uint64, error)
+	// FetchRange 抓取[from, to]区间内的数据，出错时返回已成功抓取的前缀部分
+	FetchRange(ctx context.Context, from, to uint64) ([]*BlockData, error)
+}
+
+// IndexerStatus 索引器健康状态
+type IndexerStatus struct {
+	Chain               string     `json:"chain"`
+	State               string     `json:"state"`
+	LastBlock           uint64     `json:"last_block"`
+	HeadBlock           uint64     `json:"head_block"`
+	Lag                 uint64     `json:"lag"`
+	BatchSize           uint64     `json:"batch_size"`
+	SyncInterval        string     `json:"sync_interval"`
+	LastRunAt           *time.Time `json:"last_run_at"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Restarts            int        `json:"restarts"`
+}
+
+// ChainIndexer 单条链的索引器，每条链运行在独立的goroutine中
+type ChainIndexer struct {
+	chain     string
+	backend   chainBackend
+	service   *BlockchainService
+	interval  time.Duration
+	batchSize uint64
+	trigger   chan struct{}
+	rewinds   chan rewindRequest
+	logger    *logrus.Logger
+
+	mu     sync.RWMutex
+	status IndexerStatus
+}
+
+func newChainIndexer(service *BlockchainService, chain ChainConfig, backend chainBackend) *ChainIndexer {
+	return &ChainIndexer{
+		chain:     chain.Name,
+		backend:   backend,
+		service:   service,
+		interval:  chain.SyncInterval,
+		batchSize: chain.BatchSize,
+		trigger:   make(chan struct{}, 1),
+		rewinds:   make(chan rewindRequest),
+		logger:    service.logger,
+		status: IndexerStatus{
+			Chain:        chain.Name,
+			State:        IndexerStateStarting,
+			BatchSize:    chain.BatchSize,
+			SyncInterval: chain.SyncInterval.String(),
+		},
+	}
+}
+
+// rewindRequest 游标回退请求，由索引器goroutine在两次同步之间执行，
+// 避免正在进行的同步在回退之后再次推进游标
+type rewindRequest struct {
+	block  uint64
+	result chan error
+}
+
+// Run 按照索引器自己的调度周期运行，直到ctx被取消
+func (i *ChainIndexer) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	// 立即执行一次
+	i.runCycle(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			i.setState(IndexerStateStopped)
+			return
+		case <-ticker.C:
+			i.runCycle(ctx)
+		case <-i.trigger:
+			i.runCycle(ctx)
+		case request := <-i.rewinds:
+			err := i.rewind(ctx, request.block)
+			request.result <- err
+			if err == nil {
+				i.runCycle(ctx)
+			}
+		}
+	}
+}
+
+// rewind 将游标回退到block，只能在Run所在的goroutine中调用
+func (i *ChainIndexer) rewind(ctx context.Context, block uint64) error {
+	cursor, err := i.service.loadCursor(ctx, i.chain)
+	if err != nil {
+		return err
+	}
+	if block > cursor.LastBlock {
+		return fmt.Errorf("cannot rewind %s to %d: cursor is at %d", i.chain, block, cursor.LastBlock)
+	}
+
+	if err := i.service.advanceCursor(i.service.db.WithContext(ctx), i.chain, block); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	i.status.LastBlock = block
+	i.mu.Unlock()
+
+	i.logger.Infof("Rewound %s indexer from block %d to %d", i.chain, cursor.LastBlock, block)
+	return nil
+}
+
+// Trigger 立即触发一次索引，不阻塞
+func (i *ChainIndexer) Trigger() {
+	select {
+	case i.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Status 返回当前状态快照
+func (i *ChainIndexer) Status() IndexerStatus {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.status
+}
+
+func (i *ChainIndexer) runCycle(ctx context.Context) {
+	now := time.Now()
+	i.mu.Lock()
+	i.status.LastRunAt = &now
+	i.mu.Unlock()
+
+	cursor, err := i.service.loadCursor(ctx, i.chain)
+	if err != nil {
+		i.recordFailure(fmt.Errorf("failed to load cursor: %v", err))
+		return
+	}
+
+	if cursor.Paused {
+		i.mu.Lock()
+		i.status.State = IndexerStatePaused
+		i.status.LastBlock = cursor.LastBlock
+		i.mu.Unlock()
+		return
+	}
+
+	// 获取最新区块号
+	latestBlock, err := i.backend.LatestBlock(ctx)
+	if err != nil {
+		i.recordFailure(fmt.Errorf("failed to get latest block: %v", err))
+		return
+	}
+
+	lastSyncedBlock := cursor.LastBlock
+
+	// 如果是首次同步，从最近的100个区块开始
+	if !cursor.Initialized && latestBlock > initialSyncLookback {
+		lastSyncedBlock = latestBlock - initialSyncLookback
+	}
+
+	if lastSyncedBlock >= latestBlock {
+		i.recordSuccess(lastSyncedBlock, latestBlock)
+		return
+	}
+
+	// 限制每次同步的区块数量
+	endBlock := lastSyncedBlock + i.batchSize
+	if endBlock > latestBlock {
+		endBlock = latestBlock
+	}
+
+	i.logger.Infof("Indexing %s blocks from %d to %d", i.chain, lastSyncedBlock+1, endBlock)
+
+	blocks, fetchErr := i.backend.FetchRange(ctx, lastSyncedBlock+1, endBlock)
+
+	// 逐个区块提交，游标与数据在同一事务中推进
+	for _, block := range blocks {
+		if err := i.service.commitBlock(ctx, i.chain, block); err != nil {
+			i.recordFailure(fmt.Errorf("failed to commit block %d: %v", block.Number, err))
+			return
+		}
+		lastSyncedBlock = block.Number
+		i.service.publishBlockEvents(ctx, block)
+		metrics.AddRecords(i.chain+"-indexer", "saved", 1)
+	}
+
+	if fetchErr != nil {
+		i.recordFailure(fetchErr)
+		return
+	}
+
+	// 区间内后段可能没有任何数据（例如Solana的空slot），直接推进游标
+	if lastSyncedBlock < endBlock {
+		if err := i.service.advanceCursor(i.service.db.WithContext(ctx), i.chain, endBlock); err != nil {
+			i.recordFailure(fmt.Errorf("failed to advance cursor: %v", err))
+			return
+		}
+		lastSyncedBlock = endBlock
+	}
+
+	i.recordSuccess(lastSyncedBlock, latestBlock)
+}
+
+func (i *ChainIndexer) recordSuccess(lastBlock, headBlock uint64) {
+	now := time.Now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.status.State = IndexerStateRunning
+	i.status.LastBlock = lastBlock
+	i.status.HeadBlock = headBlock
+	i.status.Lag = 0
+	if headBlock > lastBlock {
+		i.status.Lag = headBlock - lastBlock
+	}
+	i.status.LastSuccessAt = &now
+	i.status.LastError = ""
+	i.status.ConsecutiveFailures = 0
+
+	metrics.SetIndexerLag(i.chain, i.status.Lag)
+	if i.status.LastRunAt != nil {
+		metrics.ObserveCycle(i.chain+"-indexer", *i.status.LastRunAt, nil)
+	}
+}
+
+func (i *ChainIndexer) recordFailure(err error) {
+	i.logger.Errorf("Indexer for %s failed: %v", i.chain, err)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.status.State = IndexerStateDegraded
+	i.status.LastError = err.Error()
+	i.status.ConsecutiveFailures++
+
+	if i.status.LastRunAt != nil {
+		metrics.ObserveCycle(i.chain+"-indexer", *i.status.LastRunAt, err)
+	}
+}
+
+func (i *ChainIndexer) recordRestart(reason interface{}) {
+	i.logger.Errorf("Indexer for %s crashed, restarting: %v", i.chain, reason)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.status.State = IndexerStateDegraded
+	i.status.LastError = fmt.Sprintf("panic: %v", reason)
+	i.status.Restarts++
+}
+
+func (i *ChainIndexer) setState(state string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.status.State = state
+}
+
+// superviseIndexer 监督索引器goroutine，崩溃后按退避时间重启
+func (s *BlockchainService) superviseIndexer(ctx context.Context, indexer *ChainIndexer) {
+	backoff := time.Second
+	maxBackoff := time.Minute
+
+	for {
+		crashed := func() (crashed bool) {
+			defer func() {
+				if r := recover(); r != nil {
+					indexer.recordRestart(r)
+					crashed = true
+				}
+			}()
+			indexer.Run(ctx)
+			return false
+		}()
+
+		if !crashed || ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// loadCursor 读取链游标，不存在时创建（兼容旧的Redis游标）
+func (s *BlockchainService) loadCursor(ctx context.Context, chainName string) (*models.ChainCursor, error) {
+	var cursor models.ChainCursor
+	err := s.db.WithContext(ctx).Where("chain = ?", chainName).First(&cursor).Error
+	if err == nil {
+		return &cursor, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	cursor = models.ChainCursor{Chain: chainName}
+	if legacy := s.getLegacyLastSyncedBlock(ctx, chainName); legacy > 0 {
+		cursor.LastBlock = legacy
+		cursor.Initialized = true
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&cursor).Error; err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
+}
+
+// getLegacyLastSyncedBlock 读取迁移前保存在Redis中的游标
+func (s *BlockchainService) getLegacyLastSyncedBlock(ctx context.Context, chainName string) uint64 {
+	if s.redis == nil {
+		return 0
+	}
+
+	key := fmt.Sprintf("last_synced_block:%s", chainName)
+	result, err := s.redis.Get(ctx, key).Result()
+	if err != nil {
+		return 0
+	}
+
+	var blockNum uint64
+	fmt.Sscanf(result, "%d", &blockNum)
+	return blockNum
+}
+
+// advanceCursor 将游标设置为blockNum并标记为已初始化
+func (s *BlockchainService) advanceCursor(tx *gorm.DB, chainName string, blockNum uint64) error {
+	return tx.Model(&models.ChainCursor{}).
+		Where("chain = ?", chainName).
+		Updates(map[string]interface{}{"last_block": blockNum, "initialized": true}).Error
+}
+
+// commitBlock 在同一事务中写入区块数据并推进游标
+func (s *BlockchainService) commitBlock(ctx context.Context, chainName string, block *BlockData) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.saveBlockData(tx, block); err != nil {
+			return err
+		}
+		return s.advanceCursor(tx, chainName, block.Number)
+	})
+}
+
+// saveBlockData 写入交易、代币转账和解码事件并关联跨链转账，依赖唯一约束保证重复写入幂等
+func (s *BlockchainService) saveBlockData(tx *gorm.DB, block *BlockData) error {
+	if len(block.Transactions) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(block.Transactions, 100).Error; err != nil {
+			return fmt.Errorf("failed to save transactions: %v", err)
+		}
+	}
+
+	if len(block.Transfers) > 0 {
+		if err := tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(block.Transfers, 100).Error; err != nil {
+			return fmt.Errorf("failed to save token transfers: %v", err)
+		}
+	}
+
+	if len(block.Events) > 0 {
+		if err := tx.Clauses(decodedEventUpsert).CreateInBatches(block.Events, 100).Error; err != nil {
+			return fmt.Errorf("failed to save decoded events: %v", err)
+		}
+	}
+
+	bridges, err := s.bridges.record(tx, block.Events)
+	if err != nil {
+		return err
+	}
+	block.Bridges = bridges
+
+	return nil
+}
+
+func (s *BlockchainService) publishBlockEvents(ctx context.Context, block *BlockData) {
+	for _, transaction := range block.Transactions {
+		s.publishTransactionEvent(ctx, transaction)
+	}
+	for _, transfer := range block.Transfers {
+		s.publishTokenTransferEvent(ctx, transfer)
+	}
+	for _, transfer := range block.Bridges {
+		s.publishBridgeTransferEvent(ctx, transfer)
+	}
+	s.watcher.ObserveTransfers(block.Transfers)
+}
+
+func (s *BlockchainService) getIndexer(chainName string) (*ChainIndexer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	indexer, exists := s.indexers[chainName]
+	if !exists {
+		return nil, ErrUnknownChain
+	}
+	return indexer, nil
+}
+
+// GetIndexerStatuses 获取所有链索引器的状态
+func (s *BlockchainService) GetIndexerStatuses() []IndexerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]IndexerStatus, 0, len(s.indexers))
+	for _, indexer := range s.indexers {
+		statuses = append(statuses, indexer.Status())
+	}
+	return statuses
+}
+
+// PauseIndexer 暂停指定链的索引，状态持久化在游标表中
+func (s *BlockchainService) PauseIndexer(chainName string) error {
+	indexer, err := s.getIndexer(chainName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.setCursorPaused(chainName, true); err != nil {
+		return err
+	}
+
+	indexer.setState(IndexerStatePaused)
+	return nil
+}
+
+// ResumeIndexer 恢复指定链的索引
+func (s *BlockchainService) ResumeIndexer(chainName string) error {
+	indexer, err := s.getIndexer(chainName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.setCursorPaused(chainName, false); err != nil {
+		return err
+	}
+
+	indexer.Trigger()
+	return nil
+}
+
+// RewindIndexer 将指定链的游标回退到给定区块，之后的区块会被重新索引。
+// 回退由索引器在当前同步周期结束后执行，正在进行的同步不会覆盖回退后的游标
+func (s *BlockchainService) RewindIndexer(ctx context.Context, chainName string, blockNum uint64) error {
+	indexer, err := s.getIndexer(chainName)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rewindTimeout)
+	defer cancel()
+
+	request := rewindRequest{block: blockNum, result: make(chan error, 1)}
+	select {
+	case indexer.rewinds <- request:
+	case <-ctx.Done():
+		return ErrIndexerBusy
+	}
+	return <-request.result
+}
+
+func (s *BlockchainService) setCursorPaused(chainName string, paused bool) error {
+	if _, err := s.loadCursor(context.Background(), chainName); err != nil {
+		return err
+	}
+
+	return s.db.Model(&models.ChainCursor{}).
+		Where("chain = ?", chainName).
+		Update("paused", paused).Error
+}
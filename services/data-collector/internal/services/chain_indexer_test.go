@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/config"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testChain = "testchain"
+
+// newTestBlockchainService 使用内存SQLite的BlockchainService，除游标表外只迁移给定的模型
+func newTestBlockchainService(t *testing.T, tables ...interface{}) *BlockchainService {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	// 每个连接都是独立的内存数据库，只保留一个连接
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, db.AutoMigrate(append([]interface{}{&models.ChainCursor{}}, tables...)...))
+
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+	service := &BlockchainService{
+		db:       db,
+		config:   &config.Config{},
+		pools:    make(map[string]*evmrpc.Pool),
+		chains:   make(map[string]ChainConfig),
+		indexers: make(map[string]*ChainIndexer),
+		workers:  make(map[string]*indexerWorker),
+		logger:   log,
+	}
+	service.watcher = newWalletWatcher(service)
+	service.bridges = newBridgeCorrelator(service)
+	return service
+}
+
+// fakeBackend 按区块号生成空区块的chainBackend
+type fakeBackend struct {
+	mu     sync.Mutex
+	latest uint64
+	// failAt 抓取到该区块时返回错误，0表示不出错
+	failAt uint64
+	// withData 不为空时只返回其中的区块，其余视为没有数据
+	withData map[uint64]bool
+	// panics LatestBlock在返回前panic的次数
+	panics int
+	// gate 不为空时FetchRange等待其关闭后才返回
+	gate   chan struct{}
+	ranges [][2]uint64
+}
+
+func (b *fakeBackend) LatestBlock(ctx context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.panics > 0 {
+		b.panics--
+		panic("rpc client crashed")
+	}
+	return b.latest, nil
+}
+
+func (b *fakeBackend) FetchRange(ctx context.Context, from, to uint64) ([]*BlockData, error) {
+	b.mu.Lock()
+	b.ranges = append(b.ranges, [2]uint64{from, to})
+	gate := b.gate
+	b.mu.Unlock()
+	if gate != nil {
+		<-gate
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var blocks []*BlockData
+	for n := from; n <= to; n++ {
+		if n == b.failAt {
+			return blocks, errors.New("receipt fetch failed")
+		}
+		if b.withData == nil || b.withData[n] {
+			blocks = append(blocks, &BlockData{Number: n})
+		}
+	}
+	return blocks, nil
+}
+
+func (b *fakeBackend) fetched() [][2]uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([][2]uint64(nil), b.ranges...)
+}
+
+func newTestIndexer(service *BlockchainService, backend chainBackend, batchSize uint64) *ChainIndexer {
+	indexer := newChainIndexer(service, ChainConfig{Name: testChain, SyncInterval: time.Hour, BatchSize: batchSize}, backend)
+	service.indexers[testChain] = indexer
+	return indexer
+}
+
+func seedCursor(t *testing.T, service *BlockchainService, lastBlock uint64) {
+	t.Helper()
+	require.NoError(t, service.db.Create(&models.ChainCursor{Chain: testChain, LastBlock: lastBlock, Initialized: true}).Error)
+}
+
+func cursorBlock(t *testing.T, service *BlockchainService) uint64 {
+	t.Helper()
+	cursor, err := service.loadCursor(context.Background(), testChain)
+	require.NoError(t, err)
+	return cursor.LastBlock
+}
+
+func TestIndexerFirstSyncStartsNearHead(t *testing.T) {
+	service := newTestBlockchainService(t)
+	backend := &fakeBackend{latest: 1000}
+	indexer := newTestIndexer(service, backend, 50)
+
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, [][2]uint64{{901, 950}}, backend.fetched())
+	cursor, err := service.loadCursor(context.Background(), testChain)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(950), cursor.LastBlock)
+	assert.True(t, cursor.Initialized)
+
+	status := indexer.Status()
+	assert.Equal(t, IndexerStateRunning, status.State)
+	assert.Equal(t, uint64(50), status.Lag)
+}
+
+func TestIndexerCursorAtZeroIsNotReset(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 0)
+	backend := &fakeBackend{latest: 1000}
+	indexer := newTestIndexer(service, backend, 10)
+
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, [][2]uint64{{1, 10}}, backend.fetched())
+	assert.Equal(t, uint64(10), cursorBlock(t, service))
+}
+
+func TestIndexerKeepsCommittedPrefixOnFetchError(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	backend := &fakeBackend{latest: 200, failAt: 105}
+	indexer := newTestIndexer(service, backend, 10)
+
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, uint64(104), cursorBlock(t, service))
+	status := indexer.Status()
+	assert.Equal(t, IndexerStateDegraded, status.State)
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Contains(t, status.LastError, "receipt fetch failed")
+
+	// 下一次从失败的区块重新开始
+	backend.failAt = 0
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, [][2]uint64{{101, 110}, {105, 114}}, backend.fetched())
+	assert.Equal(t, uint64(114), cursorBlock(t, service))
+	assert.Equal(t, 0, indexer.Status().ConsecutiveFailures)
+}
+
+func TestIndexerAdvancesPastEmptyBlocks(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	backend := &fakeBackend{latest: 200, withData: map[uint64]bool{103: true}}
+	indexer := newTestIndexer(service, backend, 10)
+
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, uint64(110), cursorBlock(t, service))
+	assert.Equal(t, uint64(110), indexer.Status().LastBlock)
+}
+
+func TestIndexerPauseAndResume(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	backend := &fakeBackend{latest: 200}
+	indexer := newTestIndexer(service, backend, 10)
+
+	require.NoError(t, service.PauseIndexer(testChain))
+	indexer.runCycle(context.Background())
+
+	assert.Empty(t, backend.fetched())
+	assert.Equal(t, IndexerStatePaused, indexer.Status().State)
+	assert.Equal(t, uint64(100), cursorBlock(t, service))
+
+	require.NoError(t, service.ResumeIndexer(testChain))
+	select {
+	case <-indexer.trigger:
+	default:
+		t.Fatal("resume did not trigger the indexer")
+	}
+	indexer.runCycle(context.Background())
+
+	assert.Equal(t, [][2]uint64{{101, 110}}, backend.fetched())
+	assert.Equal(t, uint64(110), cursorBlock(t, service))
+
+	assert.ErrorIs(t, service.PauseIndexer("unknown"), ErrUnknownChain)
+}
+
+func TestRewindWaitsForInFlightCycle(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	gate := make(chan struct{})
+	backend := &fakeBackend{latest: 200, gate: gate}
+	indexer := newTestIndexer(service, backend, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		indexer.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// 第一次同步阻塞在抓取中
+	require.Eventually(t, func() bool { return len(backend.fetched()) == 1 }, time.Second, 5*time.Millisecond)
+
+	rewound := make(chan error, 1)
+	go func() {
+		rewound <- service.RewindIndexer(context.Background(), testChain, 50)
+	}()
+
+	select {
+	case err := <-rewound:
+		t.Fatalf("rewind returned before the in-flight cycle finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 放行后正在进行的同步推进到110，随后执行回退并从51重新索引
+	backend.mu.Lock()
+	backend.gate = nil
+	backend.mu.Unlock()
+	close(gate)
+
+	require.NoError(t, <-rewound)
+	require.Eventually(t, func() bool { return len(backend.fetched()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][2]uint64{{101, 110}, {51, 60}}, backend.fetched())
+	require.Eventually(t, func() bool { return cursorBlock(t, service) == 60 }, time.Second, 5*time.Millisecond)
+}
+
+func TestRewindValidation(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	backend := &fakeBackend{latest: 100}
+	indexer := newTestIndexer(service, backend, 10)
+
+	// 索引器没有运行时不会一直等待
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, service.RewindIndexer(ctx, testChain, 50), ErrIndexerBusy)
+	assert.ErrorIs(t, service.RewindIndexer(context.Background(), "unknown", 50), ErrUnknownChain)
+
+	runCtx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		indexer.Run(runCtx)
+	}()
+	defer func() {
+		stop()
+		<-done
+	}()
+
+	assert.Error(t, service.RewindIndexer(context.Background(), testChain, 150))
+	assert.Equal(t, uint64(100), cursorBlock(t, service))
+
+	// 回退到0后从区块1重新索引，而不是跳到链头附近
+	require.NoError(t, service.RewindIndexer(context.Background(), testChain, 0))
+	require.Eventually(t, func() bool { return cursorBlock(t, service) == 10 }, time.Second, 5*time.Millisecond)
+}
+
+func TestSuperviseIndexerRestartsAfterPanic(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	backend := &fakeBackend{latest: 110, panics: 1}
+	indexer := newTestIndexer(service, backend, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.superviseIndexer(ctx, indexer)
+	}()
+
+	require.Eventually(t, func() bool {
+		status := indexer.Status()
+		return status.Restarts == 1 && status.State == IndexerStateRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, uint64(110), cursorBlock(t, service))
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("supervisor did not stop after cancel")
+	}
+	assert.Equal(t, IndexerStateStopped, indexer.Status().State)
+}
@@ -0,0 +1,388 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidChain 链定义不合法
+var ErrInvalidChain = errors.New("invalid chain definition")
+
+var chainNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// RPCEndpoint 链的RPC节点，Priority小的优先使用，相同优先级按定义顺序
+type RPCEndpoint struct {
+	URL      string `json:"url"`
+	Priority int    `json:"priority"`
+}
+
+// ChainDefinition 链注册表中的一条EVM链
+type ChainDefinition struct {
+	Name           string        `json:"name"`
+	ChainID        int64         `json:"chain_id"`
+	RPCEndpoints   []RPCEndpoint `json:"rpc_endpoints"`
+	BlockTimeMs    int64         `json:"block_time_ms"`
+	Confirmations  uint64        `json:"confirmations"`
+	NativeSymbol   string        `json:"native_symbol"`
+	NativeDecimals uint8         `json:"native_decimals"`
+	ExplorerURL    string        `json:"explorer_url"`
+}
+
+// ChainInfo 注册表中的链及其连接状态，RPC地址已脱敏
+type ChainInfo struct {
+	ChainDefinition
+	IsActive  bool                    `json:"is_active"`
+	Connected bool                    `json:"connected"`
+	Endpoints []evmrpc.EndpointStatus `json:"endpoints,omitempty"`
+}
+
+// legacyChains 由*_RPC_URL配置生成的链定义，用于初始化注册表
+func (s *BlockchainService) legacyChains() []ChainDefinition {
+	legacy := []struct {
+		rpc string
+		ChainDefinition
+	}{
+		{s.config.EthereumRPC, ChainDefinition{Name: "ethereum", ChainID: 1, BlockTimeMs: 12000, Confirmations: 12, NativeSymbol: "ETH", ExplorerURL: "https://etherscan.io"}},
+		{s.config.ArbitrumRPC, ChainDefinition{Name: "arbitrum", ChainID: 42161, BlockTimeMs: 250, Confirmations: 20, NativeSymbol: "ETH", ExplorerURL: "https://arbiscan.io"}},
+		{s.config.BaseRPC, ChainDefinition{Name: "base", ChainID: 8453, BlockTimeMs: 2000, Confirmations: 20, NativeSymbol: "ETH", ExplorerURL: "https://basescan.org"}},
+		{s.config.PolygonRPC, ChainDefinition{Name: "polygon", ChainID: 137, BlockTimeMs: 2000, Confirmations: 64, NativeSymbol: "POL", ExplorerURL: "https://polygonscan.com"}},
+		{s.config.BSCRPC, ChainDefinition{Name: "bsc", ChainID: 56, BlockTimeMs: 3000, Confirmations: 15, NativeSymbol: "BNB", ExplorerURL: "https://bscscan.com"}},
+	}
+
+	var chains []ChainDefinition
+	for _, entry := range legacy {
+		urls := evmrpc.ParseURLs(entry.rpc)
+		if len(urls) == 0 {
+			continue
+		}
+		chain := entry.ChainDefinition
+		chain.NativeDecimals = defaultNativeDecimals
+		for i, rpcURL := range urls {
+			chain.RPCEndpoints = append(chain.RPCEndpoints, RPCEndpoint{URL: rpcURL, Priority: i})
+		}
+		chains = append(chains, chain)
+	}
+	return chains
+}
+
+// loadChainRegistry 读取启用的链；配置中有而注册表中没有（包括已删除）的链不会覆盖已有记录
+func (s *BlockchainService) loadChainRegistry(ctx context.Context) ([]ChainDefinition, error) {
+	for _, chain := range s.legacyChains() {
+		record, err := chainRecord(chain)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record).Error; err != nil {
+			return nil, fmt.Errorf("failed to seed chain %s: %v", chain.Name, err)
+		}
+	}
+
+	var records []models.Chain
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Order("name").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	chains := make([]ChainDefinition, 0, len(records))
+	for _, record := range records {
+		chain, err := chainDefinition(record)
+		if err != nil {
+			s.logger.Errorf("Skipping chain %s with invalid registry entry: %v", record.Name, err)
+			continue
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+func chainRecord(chain ChainDefinition) (*models.Chain, error) {
+	endpoints, err := json.Marshal(chain.RPCEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Chain{
+		Name:           chain.Name,
+		ChainID:        chain.ChainID,
+		RPCEndpoints:   endpoints,
+		BlockTimeMs:    chain.BlockTimeMs,
+		Confirmations:  chain.Confirmations,
+		NativeSymbol:   chain.NativeSymbol,
+		NativeDecimals: chain.NativeDecimals,
+		ExplorerURL:    chain.ExplorerURL,
+		IsActive:       true,
+	}, nil
+}
+
+func chainDefinition(record models.Chain) (ChainDefinition, error) {
+	chain := ChainDefinition{
+		Name:           record.Name,
+		ChainID:        record.ChainID,
+		BlockTimeMs:    record.BlockTimeMs,
+		Confirmations:  record.Confirmations,
+		NativeSymbol:   record.NativeSymbol,
+		NativeDecimals: record.NativeDecimals,
+		ExplorerURL:    record.ExplorerURL,
+	}
+	if err := json.Unmarshal(record.RPCEndpoints, &chain.RPCEndpoints); err != nil {
+		return chain, err
+	}
+	return normalizeChain(chain)
+}
+
+// normalizeChain 校验链定义并按优先级排列RPC节点
+func normalizeChain(chain ChainDefinition) (ChainDefinition, error) {
+	chain.Name = strings.ToLower(strings.TrimSpace(chain.Name))
+	if !chainNamePattern.MatchString(chain.Name) {
+		return chain, fmt.Errorf("%w: name must be lowercase letters, digits, '-' or '_'", ErrInvalidChain)
+	}
+	if chain.Name == "solana" {
+		return chain, fmt.Errorf("%w: solana is configured through SOLANA_RPC_URL", ErrInvalidChain)
+	}
+	if chain.ChainID <= 0 {
+		return chain, fmt.Errorf("%w: chain_id is required", ErrInvalidChain)
+	}
+	if chain.BlockTimeMs < 0 {
+		return chain, fmt.Errorf("%w: block_time_ms must not be negative", ErrInvalidChain)
+	}
+
+	endpoints := make([]RPCEndpoint, 0, len(chain.RPCEndpoints))
+	for _, endpoint := range chain.RPCEndpoints {
+		endpoint.URL = strings.TrimSpace(endpoint.URL)
+		parsed, err := url.Parse(endpoint.URL)
+		if err != nil || parsed.Host == "" {
+			return chain, fmt.Errorf("%w: invalid rpc url %q", ErrInvalidChain, evmrpc.RedactURL(endpoint.URL))
+		}
+		switch parsed.Scheme {
+		case "http", "https", "ws", "wss":
+		default:
+			return chain, fmt.Errorf("%w: unsupported rpc url scheme %q", ErrInvalidChain, parsed.Scheme)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return chain, fmt.Errorf("%w: at least one rpc endpoint is required", ErrInvalidChain)
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool { return endpoints[i].Priority < endpoints[j].Priority })
+	chain.RPCEndpoints = endpoints
+
+	chain.NativeSymbol = strings.ToUpper(strings.TrimSpace(chain.NativeSymbol))
+	if chain.NativeDecimals == 0 {
+		chain.NativeDecimals = defaultNativeDecimals
+	}
+	chain.ExplorerURL = strings.TrimRight(strings.TrimSpace(chain.ExplorerURL), "/")
+	return chain, nil
+}
+
+// dialChain 连接链的RPC节点并校验链ID
+// strict为false时（启动阶段）节点暂时不可达只记录警告，链ID不一致始终视为错误
+func (s *BlockchainService) dialChain(ctx context.Context, chain ChainDefinition, strict bool) (*evmrpc.Pool, error) {
+	urls := make([]string, len(chain.RPCEndpoints))
+	for i, endpoint := range chain.RPCEndpoints {
+		urls[i] = endpoint.URL
+	}
+
+	pool, err := evmrpc.Dial(ctx, chain.Name, urls, evmrpc.Options{
+		BatchSize:   s.config.RPCBatchSize,
+		Concurrency: s.config.MaxConcurrentRequests,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	verifyCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := pool.VerifyChainID(verifyCtx, uint64(chain.ChainID)); err != nil {
+		if strict || errors.Is(err, evmrpc.ErrChainIDMismatch) {
+			pool.Close()
+			return nil, err
+		}
+		s.logger.Warnf("Could not verify chain id of %s, indexing will retry: %v", chain.Name, err)
+	}
+	return pool, nil
+}
+
+// registerChain 注册链的连接和索引器，替换同名的旧链；索引已在运行时立即启动新索引器
+func (s *BlockchainService) registerChain(definition ChainDefinition, pool *evmrpc.Pool) {
+	chain := s.withDefaults(ChainConfig{
+		Name:           definition.Name,
+		ChainID:        definition.ChainID,
+		BlockTime:      time.Duration(definition.BlockTimeMs) * time.Millisecond,
+		Confirmations:  definition.Confirmations,
+		NativeSymbol:   definition.NativeSymbol,
+		NativeDecimals: definition.NativeDecimals,
+		ExplorerURL:    definition.ExplorerURL,
+	})
+	indexer := newChainIndexer(s, chain, &evmBackend{
+		chain:   chain.Name,
+		pool:    pool,
+		service: s,
+	})
+	s.replaceIndexer(chain, pool, indexer)
+}
+
+// replaceIndexer 停止同名的旧索引器并等待其退出后再启动新索引器，避免两个索引器同时推进同一游标
+func (s *BlockchainService) replaceIndexer(chain ChainConfig, pool *evmrpc.Pool, indexer *ChainIndexer) {
+	for {
+		s.mu.Lock()
+		_, hasIndexer := s.indexers[chain.Name]
+		_, hasPool := s.pools[chain.Name]
+		if !hasIndexer && !hasPool {
+			break
+		}
+		// 等待期间不持有锁，退出中的索引器可能还需要读取服务状态
+		stop := s.detachChainLocked(chain.Name)
+		s.mu.Unlock()
+		stop()
+	}
+	defer s.mu.Unlock()
+
+	if pool != nil {
+		s.pools[chain.Name] = pool
+	}
+	s.chains[chain.Name] = chain
+	s.indexers[chain.Name] = indexer
+	if s.runCtx != nil {
+		s.startIndexerLocked(indexer)
+	}
+}
+
+// detachChainLocked 移除链并取消其索引器，调用方需持有s.mu
+// 返回的函数需在释放s.mu后调用，等待索引器退出后关闭连接
+func (s *BlockchainService) detachChainLocked(name string) func() {
+	worker := s.workers[name]
+	if worker != nil {
+		worker.cancel()
+		delete(s.workers, name)
+	}
+	pool := s.pools[name]
+	delete(s.pools, name)
+	delete(s.chains, name)
+	delete(s.indexers, name)
+
+	return func() {
+		if worker != nil {
+			<-worker.done
+		}
+		if pool != nil {
+			pool.Close()
+		}
+	}
+}
+
+// AddChain 添加或更新一条链：校验定义和节点链ID后写入注册表并立即开始索引
+func (s *BlockchainService) AddChain(ctx context.Context, chain ChainDefinition) (*ChainInfo, error) {
+	chain, err := normalizeChain(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := s.dialChain(ctx, chain, true)
+	if err != nil {
+		if errors.Is(err, evmrpc.ErrChainIDMismatch) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidChain, err)
+		}
+		return nil, err
+	}
+
+	record, err := chainRecord(chain)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"chain_id", "rpc_endpoints", "block_time_ms", "confirmations",
+			"native_symbol", "native_decimals", "explorer_url", "is_active", "updated_at",
+		}),
+	}).Create(record).Error
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to save chain %s: %v", chain.Name, err)
+	}
+
+	s.registerChain(chain, pool)
+	s.logger.Infof("Registered %s (chain id %d) with %d endpoints", chain.Name, chain.ChainID, len(chain.RPCEndpoints))
+
+	info := s.chainInfo(chain, true)
+	return &info, nil
+}
+
+// RemoveChain 停用一条链并停止其索引，游标保留以便重新添加后继续
+func (s *BlockchainService) RemoveChain(ctx context.Context, name string) error {
+	result := s.db.WithContext(ctx).Model(&models.Chain{}).
+		Where("name = ? AND is_active = ?", name, true).
+		Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove chain %s: %v", name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrUnknownChain
+	}
+
+	s.mu.Lock()
+	stop := s.detachChainLocked(name)
+	s.mu.Unlock()
+	stop()
+
+	s.logger.Infof("Removed %s from the chain registry", name)
+	return nil
+}
+
+// ListChains 列出注册表中的所有链（包括已停用的）
+func (s *BlockchainService) ListChains(ctx context.Context) ([]ChainInfo, error) {
+	var records []models.Chain
+	if err := s.db.WithContext(ctx).Order("name").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list chains: %v", err)
+	}
+
+	chains := make([]ChainInfo, 0, len(records))
+	for _, record := range records {
+		chain, err := chainDefinition(record)
+		if err != nil {
+			chain = ChainDefinition{Name: record.Name, ChainID: record.ChainID}
+		}
+		chains = append(chains, s.chainInfo(chain, record.IsActive))
+	}
+	return chains, nil
+}
+
+func (s *BlockchainService) chainInfo(chain ChainDefinition, active bool) ChainInfo {
+	endpoints := make([]RPCEndpoint, len(chain.RPCEndpoints))
+	for i, endpoint := range chain.RPCEndpoints {
+		endpoints[i] = RPCEndpoint{URL: evmrpc.RedactURL(endpoint.URL), Priority: endpoint.Priority}
+	}
+	chain.RPCEndpoints = endpoints
+
+	info := ChainInfo{ChainDefinition: chain, IsActive: active}
+
+	s.mu.RLock()
+	pool, ok := s.pools[chain.Name]
+	s.mu.RUnlock()
+	if ok {
+		info.Connected = true
+		info.Endpoints = pool.Status()
+	}
+	return info
+}
+
+// nativeDecimals 链原生代币的精度
+func (s *BlockchainService) nativeDecimals(chain string) uint8 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if config, ok := s.chains[chain]; ok && config.NativeDecimals > 0 {
+		return config.NativeDecimals
+	}
+	return defaultNativeDecimals
+}
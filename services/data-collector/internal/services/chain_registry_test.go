@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newChainIDServer 只响应eth_chainId的JSON-RPC节点
+func newChainIDServer(t *testing.T, chainID string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_chainId" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": -32601, "message": "method not found"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": chainID})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNormalizeChain(t *testing.T) {
+	chain, err := normalizeChain(ChainDefinition{
+		Name:         " Optimism ",
+		ChainID:      10,
+		NativeSymbol: "eth",
+		ExplorerURL:  "https://optimistic.etherscan.io/",
+		RPCEndpoints: []RPCEndpoint{
+			{URL: "https://backup.example.com", Priority: 2},
+			{URL: " wss://primary.example.com ", Priority: 0},
+			{URL: "https://secondary.example.com", Priority: 2},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "optimism", chain.Name)
+	assert.Equal(t, "ETH", chain.NativeSymbol)
+	assert.Equal(t, uint8(defaultNativeDecimals), chain.NativeDecimals)
+	assert.Equal(t, "https://optimistic.etherscan.io", chain.ExplorerURL)
+	// 按优先级排列，相同优先级保持定义顺序
+	assert.Equal(t, []RPCEndpoint{
+		{URL: "wss://primary.example.com", Priority: 0},
+		{URL: "https://backup.example.com", Priority: 2},
+		{URL: "https://secondary.example.com", Priority: 2},
+	}, chain.RPCEndpoints)
+
+	valid := ChainDefinition{Name: "optimism", ChainID: 10, RPCEndpoints: []RPCEndpoint{{URL: "https://rpc.example.com"}}}
+	invalid := map[string]func(*ChainDefinition){
+		"bad name":        func(c *ChainDefinition) { c.Name = "op mainnet" },
+		"solana":          func(c *ChainDefinition) { c.Name = "solana" },
+		"missing id":      func(c *ChainDefinition) { c.ChainID = 0 },
+		"negative id":     func(c *ChainDefinition) { c.ChainID = -1 },
+		"negative block":  func(c *ChainDefinition) { c.BlockTimeMs = -1 },
+		"no endpoints":    func(c *ChainDefinition) { c.RPCEndpoints = nil },
+		"relative url":    func(c *ChainDefinition) { c.RPCEndpoints = []RPCEndpoint{{URL: "rpc.example.com"}} },
+		"unsupported url": func(c *ChainDefinition) { c.RPCEndpoints = []RPCEndpoint{{URL: "ftp://rpc.example.com"}} },
+	}
+	for name, mutate := range invalid {
+		chain := valid
+		mutate(&chain)
+		_, err := normalizeChain(chain)
+		assert.ErrorIs(t, err, ErrInvalidChain, name)
+	}
+}
+
+func TestChainRegistryCRUD(t *testing.T) {
+	service := newTestBlockchainService(t, &models.Chain{})
+	ctx := context.Background()
+	server := newChainIDServer(t, "0xa")
+
+	// 节点返回的链ID与定义不一致时拒绝
+	_, err := service.AddChain(ctx, ChainDefinition{Name: "base", ChainID: 8453, RPCEndpoints: []RPCEndpoint{{URL: server.URL}}})
+	assert.ErrorIs(t, err, ErrInvalidChain)
+
+	info, err := service.AddChain(ctx, ChainDefinition{Name: "Optimism", ChainID: 10, RPCEndpoints: []RPCEndpoint{{URL: server.URL + "/?key=secret"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "optimism", info.Name)
+	assert.True(t, info.IsActive)
+	assert.True(t, info.Connected)
+	assert.NotContains(t, info.RPCEndpoints[0].URL, "secret")
+	assert.Contains(t, service.indexers, "optimism")
+
+	chains, err := service.ListChains(ctx)
+	require.NoError(t, err)
+	require.Len(t, chains, 1)
+	assert.Equal(t, int64(10), chains[0].ChainID)
+
+	// 再次添加同名链时更新记录并替换连接
+	_, err = service.AddChain(ctx, ChainDefinition{Name: "optimism", ChainID: 10, Confirmations: 5, RPCEndpoints: []RPCEndpoint{{URL: server.URL}}})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), service.chains["optimism"].Confirmations)
+
+	require.NoError(t, service.RemoveChain(ctx, "optimism"))
+	assert.NotContains(t, service.indexers, "optimism")
+	assert.NotContains(t, service.pools, "optimism")
+	assert.ErrorIs(t, service.RemoveChain(ctx, "optimism"), ErrUnknownChain)
+
+	// 停用的链仍然列出，但不再加载
+	chains, err = service.ListChains(ctx)
+	require.NoError(t, err)
+	require.Len(t, chains, 1)
+	assert.False(t, chains[0].IsActive)
+	assert.False(t, chains[0].Connected)
+
+	active, err := service.loadChainRegistry(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestReplaceIndexerWaitsForOldIndexer(t *testing.T) {
+	service := newTestBlockchainService(t)
+	seedCursor(t, service, 100)
+	gate := make(chan struct{})
+	oldBackend := &fakeBackend{latest: 200, gate: gate}
+	oldIndexer := newTestIndexer(service, oldBackend, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		service.running.Wait()
+	}()
+	service.mu.Lock()
+	service.runCtx = ctx
+	service.startIndexerLocked(oldIndexer)
+	service.mu.Unlock()
+
+	// 旧索引器阻塞在抓取中，不响应取消
+	require.Eventually(t, func() bool { return len(oldBackend.fetched()) == 1 }, time.Second, 5*time.Millisecond)
+
+	newBackend := &fakeBackend{latest: 200}
+	newConfig := ChainConfig{Name: testChain, SyncInterval: time.Hour, BatchSize: 10}
+	newIndexer := newChainIndexer(service, newConfig, newBackend)
+	replaced := make(chan struct{})
+	go func() {
+		defer close(replaced)
+		service.replaceIndexer(newConfig, nil, newIndexer)
+	}()
+
+	select {
+	case <-replaced:
+		t.Fatal("new indexer started before the old one exited")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Empty(t, newBackend.fetched())
+
+	// 旧索引器的上下文已取消，正在进行的批次不会提交，新索引器从已提交的游标继续
+	oldBackend.mu.Lock()
+	oldBackend.gate = nil
+	oldBackend.mu.Unlock()
+	close(gate)
+
+	select {
+	case <-replaced:
+	case <-time.After(time.Second):
+		t.Fatal("replace did not finish after the old indexer exited")
+	}
+	require.Eventually(t, func() bool { return len(newBackend.fetched()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, [][2]uint64{{101, 110}}, newBackend.fetched())
+}
@@ -0,0 +1,739 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/config"
+	"github.com/rwa-platform/data-collector/internal/kafka"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/secrets"
+	"github.com/rwa-platform/data-collector/internal/sourcehealth"
+	"github.com/rwa-platform/shared/tracing"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 代码中直接调用的数据源名称
+const (
+	DataSourceCoinGecko     = "coingecko"
+	DataSourceCoinMarketCap = "coinmarketcap"
+	DataSourceNewsAPI       = "newsapi"
+)
+
+const (
+	// sourceHealthWindow 健康统计的时间窗口，sourceHealthSamples 每个数据源最多保留的样本数
+	sourceHealthWindow  = 15 * time.Minute
+	sourceHealthSamples = 1000
+	// dataSourceTestTimeout 测试连接的超时时间
+	dataSourceTestTimeout = 15 * time.Second
+)
+
+var dataSourceTypes = map[string]bool{
+	"price": true, "blockchain": true, "news": true, "filing": true, DataSourceTypeFeed: true,
+}
+
+var (
+	ErrInvalidDataSource    = errors.New("invalid data source")
+	ErrDataSourceNotFound   = errors.New("data source not found")
+	ErrKeyringNotConfigured = errors.New("data source keyring is not configured")
+)
+
+// builtinDataSource 采集代码直接调用的数据源，启动时不存在则创建；URL为测试连接使用的地址，
+// 也用于按主机名匹配请求。没有通过管理接口设置密钥时使用环境变量中的密钥
+type builtinDataSource struct {
+	Name       string
+	Type       string
+	URL        string
+	AuthHeader string
+	EnvKey     func(cfg *config.Config) string
+}
+
+var builtinDataSources = []builtinDataSource{
+	{
+		Name: DataSourceCoinGecko, Type: "price", URL: "https://api.coingecko.com/api/v3/ping",
+		AuthHeader: "X-CG-Demo-API-Key", EnvKey: func(cfg *config.Config) string { return cfg.CoinGeckoAPIKey },
+	},
+	{
+		Name: DataSourceCoinMarketCap, Type: "price", URL: "https://pro-api.coinmarketcap.com/v1/key/info",
+		AuthHeader: "X-CMC_PRO_API_KEY", EnvKey: func(cfg *config.Config) string { return cfg.CoinMarketCapAPIKey },
+	},
+	{
+		Name: DataSourceNewsAPI, Type: "news", URL: "https://newsapi.org/v2/top-headlines/sources",
+		AuthHeader: "X-API-Key", EnvKey: func(cfg *config.Config) string { return cfg.NewsAPIKey },
+	},
+}
+
+func findBuiltinDataSource(name string) *builtinDataSource {
+	for i := range builtinDataSources {
+		if builtinDataSources[i].Name == name {
+			return &builtinDataSources[i]
+		}
+	}
+	return nil
+}
+
+// DataSourceService 管理data_sources表中的上游配置：API密钥加密保存，
+// 经过Transport的请求按数据源统计健康状况
+type DataSourceService struct {
+	db     *gorm.DB
+	redis  RedisCache
+	kafka  *kafka.Producer
+	config *config.Config
+	client *http.Client
+	logger *logrus.Logger
+
+	keyring *secrets.Keyring
+	health  *sourcehealth.Tracker
+
+	// routes 按主机名匹配请求的活跃数据源，keys 已解密的API密钥；定期及修改后从数据库刷新
+	mu     sync.RWMutex
+	routes []dataSourceRoute
+	keys   map[string]string
+}
+
+type dataSourceRoute struct {
+	name string
+	host string
+	path string
+}
+
+// DataSourceInput 创建数据源的请求
+type DataSourceInput struct {
+	Name       string          `json:"name" binding:"required"`
+	Type       string          `json:"type" binding:"required"`
+	URL        string          `json:"url" binding:"required"`
+	APIKey     *string         `json:"api_key"`
+	AuthHeader *string         `json:"auth_header"`
+	Config     json.RawMessage `json:"config"`
+	IsActive   *bool           `json:"is_active"`
+	RateLimit  *int            `json:"rate_limit"`
+}
+
+// DataSourceUpdate 更新数据源的请求，未提供的字段不修改；api_key为空字符串时清除密钥
+type DataSourceUpdate struct {
+	URL        *string         `json:"url"`
+	APIKey     *string         `json:"api_key"`
+	AuthHeader *string         `json:"auth_header"`
+	Config     json.RawMessage `json:"config"`
+	IsActive   *bool           `json:"is_active"`
+	RateLimit  *int            `json:"rate_limit"`
+}
+
+// DataSourceView 数据源及其健康状况，不包含API密钥本身
+type DataSourceView struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	URL        string              `json:"url"`
+	AuthHeader *string             `json:"auth_header"`
+	Config     json.RawMessage     `json:"config"`
+	IsActive   bool                `json:"is_active"`
+	RateLimit  *int                `json:"rate_limit"`
+	Builtin    bool                `json:"builtin"`
+	APIKeySet  bool                `json:"api_key_set"`
+	KeyID      string              `json:"key_id,omitempty"` // 加密使用的密钥ID，明文保存时为空
+	LastSyncAt *time.Time          `json:"last_sync_at"`
+	ErrorCount int                 `json:"error_count"`
+	LastError  *string             `json:"last_error"`
+	Health     sourcehealth.Health `json:"health"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+// DataSourceTestResult 测试连接的结果
+type DataSourceTestResult struct {
+	Success    bool                    `json:"success"`
+	StatusCode int                     `json:"status_code,omitempty"`
+	LatencyMs  int64                   `json:"latency_ms"`
+	RateLimit  *sourcehealth.RateLimit `json:"rate_limit,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	TestedAt   time.Time               `json:"tested_at"`
+}
+
+// KeyRotation 重新加密API密钥的结果
+type KeyRotation struct {
+	KeyID   string   `json:"key_id"`
+	Rotated int      `json:"rotated"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+func NewDataSourceService(db *gorm.DB, redisClient RedisCache, kafkaProducer *kafka.Producer, cfg *config.Config) *DataSourceService {
+	s := &DataSourceService{
+		db:     db,
+		redis:  redisClient,
+		kafka:  kafkaProducer,
+		config: cfg,
+		logger: logrus.New(),
+		health: sourcehealth.NewTracker(sourceHealthWindow, sourceHealthSamples),
+		keys:   make(map[string]string),
+	}
+	s.client = &http.Client{
+		Timeout:   dataSourceTestTimeout,
+		Transport: s.Transport(tracing.Transport(nil)),
+	}
+
+	if cfg.DataSourceKeyringFile != "" {
+		keyring, err := secrets.LoadKeyring(cfg.DataSourceKeyringFile)
+		if err != nil {
+			s.logger.Errorf("Failed to load data source keyring: %v", err)
+		} else {
+			s.keyring = keyring
+		}
+	}
+	return s
+}
+
+// Transport 统计经过的请求，按主机名和路径前缀匹配数据源
+func (s *DataSourceService) Transport(base http.RoundTripper) http.RoundTripper {
+	return &sourcehealth.Transport{Base: base, Match: s.match, Tracker: s.health}
+}
+
+// APIKey 数据源的API密钥，没有通过管理接口设置时返回fallback（环境变量中的密钥）；
+// 接收者为空时直接返回fallback，未接入数据源管理的服务也可调用
+func (s *DataSourceService) APIKey(name, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if key, ok := s.keys[name]; ok {
+		return key
+	}
+	return fallback
+}
+
+func (s *DataSourceService) match(req *http.Request) string {
+	host := strings.ToLower(req.URL.Host)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	best, bestScore := "", -1
+	for _, route := range s.routes {
+		if route.host != host {
+			continue
+		}
+		score := 0
+		if strings.HasPrefix(req.URL.Path, route.path) {
+			score = len(route.path) + 1
+		}
+		if score > bestScore {
+			best, bestScore = route.name, score
+		}
+	}
+	return best
+}
+
+// Load 创建内置数据源、加密明文保存的密钥并加载数据源配置，需要在采集开始前调用
+func (s *DataSourceService) Load(ctx context.Context) {
+	s.seedBuiltins(ctx)
+	if s.currentKeyring() != nil {
+		if rotation, err := s.RotateKeys(ctx); err != nil {
+			s.logger.Errorf("Failed to encrypt data source API keys: %v", err)
+		} else if rotation.Rotated > 0 {
+			s.logger.Infof("Encrypted %d data source API keys with key %s", rotation.Rotated, rotation.KeyID)
+		}
+	}
+	if err := s.refresh(ctx); err != nil {
+		s.logger.Errorf("Failed to load data sources: %v", err)
+	}
+}
+
+// Start 定期重新加载密钥文件和数据源配置，其他实例修改的密钥也能生效
+func (s *DataSourceService) Start(ctx context.Context) {
+	interval := time.Duration(s.config.DataSourceRefreshInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.reloadKeyring(); err != nil {
+				s.logger.Errorf("Failed to reload data source keyring: %v", err)
+			}
+			if err := s.refresh(ctx); err != nil {
+				s.logger.Errorf("Failed to refresh data sources: %v", err)
+			}
+		}
+	}
+}
+
+func (s *DataSourceService) seedBuiltins(ctx context.Context) {
+	for _, builtin := range builtinDataSources {
+		authHeader := builtin.AuthHeader
+		source := models.DataSource{
+			Name:       builtin.Name,
+			Type:       builtin.Type,
+			URL:        builtin.URL,
+			AuthHeader: &authHeader,
+			IsActive:   true,
+		}
+		err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoNothing: true,
+		}).Create(&source).Error
+		if err != nil {
+			s.logger.Errorf("Failed to create data source %s: %v", builtin.Name, err)
+		}
+	}
+}
+
+// reloadKeyring 密钥文件变化时重新加载；启动时文件不存在的，创建后也会加载
+func (s *DataSourceService) reloadKeyring() error {
+	if s.config.DataSourceKeyringFile == "" {
+		return nil
+	}
+	s.mu.RLock()
+	keyring := s.keyring
+	s.mu.RUnlock()
+
+	if keyring == nil {
+		loaded, err := secrets.LoadKeyring(s.config.DataSourceKeyringFile)
+		if err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.keyring = loaded
+		s.mu.Unlock()
+		s.logger.Infof("Loaded data source keyring, primary key %s", loaded.Primary())
+		return nil
+	}
+
+	reloaded, err := keyring.Reload()
+	if err != nil {
+		return err
+	}
+	if reloaded {
+		s.logger.Infof("Reloaded data source keyring, primary key %s", keyring.Primary())
+	}
+	return nil
+}
+
+func (s *DataSourceService) currentKeyring() *secrets.Keyring {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keyring
+}
+
+// refresh 重新加载活跃数据源的地址和解密后的密钥
+func (s *DataSourceService) refresh(ctx context.Context) error {
+	var sources []models.DataSource
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Order("name ASC").Find(&sources).Error; err != nil {
+		return err
+	}
+
+	keyring := s.currentKeyring()
+	routes := make([]dataSourceRoute, 0, len(sources))
+	keys := make(map[string]string)
+	for _, source := range sources {
+		if parsed, err := url.Parse(source.URL); err == nil && parsed.Host != "" {
+			routes = append(routes, dataSourceRoute{
+				name: source.Name,
+				host: strings.ToLower(parsed.Host),
+				path: parsed.Path,
+			})
+		}
+		if source.APIKey == nil || *source.APIKey == "" {
+			continue
+		}
+		key, err := s.decryptKey(keyring, *source.APIKey)
+		if err != nil {
+			s.logger.Errorf("Failed to decrypt API key of data source %s: %v", source.Name, err)
+			continue
+		}
+		keys[source.Name] = key
+	}
+
+	s.mu.Lock()
+	s.routes = routes
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// decryptKey 尚未加密的旧数据按明文读取
+func (s *DataSourceService) decryptKey(keyring *secrets.Keyring, value string) (string, error) {
+	if !secrets.IsEncrypted(value) {
+		return value, nil
+	}
+	if keyring == nil {
+		return "", ErrKeyringNotConfigured
+	}
+	return keyring.Decrypt(value)
+}
+
+// sealKey 加密新设置的密钥，空字符串表示清除
+func (s *DataSourceService) sealKey(plaintext string) (*string, error) {
+	plaintext = strings.TrimSpace(plaintext)
+	if plaintext == "" {
+		return nil, nil
+	}
+	keyring := s.currentKeyring()
+	if keyring == nil {
+		return nil, ErrKeyringNotConfigured
+	}
+	sealed, err := keyring.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+func (s *DataSourceService) toView(source *models.DataSource) DataSourceView {
+	view := DataSourceView{
+		ID:         source.ID,
+		Name:       source.Name,
+		Type:       source.Type,
+		URL:        source.URL,
+		AuthHeader: source.AuthHeader,
+		Config:     json.RawMessage(source.Config),
+		IsActive:   source.IsActive,
+		RateLimit:  source.RateLimit,
+		Builtin:    findBuiltinDataSource(source.Name) != nil,
+		LastSyncAt: source.LastSyncAt,
+		ErrorCount: source.ErrorCount,
+		LastError:  source.LastError,
+		Health:     s.health.Health(source.Name, source.RateLimit, time.Now()),
+		CreatedAt:  source.CreatedAt,
+		UpdatedAt:  source.UpdatedAt,
+	}
+	if len(view.Config) == 0 {
+		view.Config = nil
+	}
+	if source.APIKey != nil && *source.APIKey != "" {
+		view.APIKeySet = true
+		view.KeyID = secrets.KeyID(*source.APIKey)
+	}
+	return view
+}
+
+// ListDataSources 列出数据源及健康状况，sourceType为空时列出全部
+func (s *DataSourceService) ListDataSources(ctx context.Context, sourceType string) ([]DataSourceView, error) {
+	query := s.db.WithContext(ctx).Order("name ASC")
+	if sourceType != "" {
+		query = query.Where("type = ?", sourceType)
+	}
+	var sources []models.DataSource
+	if err := query.Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data sources: %v", err)
+	}
+
+	views := make([]DataSourceView, 0, len(sources))
+	for i := range sources {
+		views = append(views, s.toView(&sources[i]))
+	}
+	return views, nil
+}
+
+func (s *DataSourceService) findDataSource(ctx context.Context, id string) (*models.DataSource, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, ErrDataSourceNotFound
+	}
+	var source models.DataSource
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&source).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDataSourceNotFound
+		}
+		return nil, fmt.Errorf("failed to query data source: %v", err)
+	}
+	return &source, nil
+}
+
+// GetDataSource 获取数据源及健康状况
+func (s *DataSourceService) GetDataSource(ctx context.Context, id string) (*DataSourceView, error) {
+	source, err := s.findDataSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	view := s.toView(source)
+	return &view, nil
+}
+
+func validateDataSourceURL(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("%w: url must be an absolute http(s) url", ErrInvalidDataSource)
+	}
+	return parsed.String(), nil
+}
+
+func validateDataSourceConfig(raw json.RawMessage) error {
+	var object map[string]interface{}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return fmt.Errorf("%w: config must be a JSON object", ErrInvalidDataSource)
+	}
+	return nil
+}
+
+// CreateDataSource 创建数据源，订阅源需要通过订阅源接口添加以校验内容格式
+func (s *DataSourceService) CreateDataSource(ctx context.Context, input DataSourceInput) (*DataSourceView, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidDataSource)
+	}
+	sourceType := strings.ToLower(strings.TrimSpace(input.Type))
+	if !dataSourceTypes[sourceType] {
+		return nil, fmt.Errorf("%w: unknown type %s", ErrInvalidDataSource, input.Type)
+	}
+	if sourceType == DataSourceTypeFeed {
+		return nil, fmt.Errorf("%w: feeds are managed through the feed api", ErrInvalidDataSource)
+	}
+	sourceURL, err := validateDataSourceURL(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if input.RateLimit != nil && *input.RateLimit < 0 {
+		return nil, fmt.Errorf("%w: rate_limit must not be negative", ErrInvalidDataSource)
+	}
+
+	source := models.DataSource{
+		Name:       name,
+		Type:       sourceType,
+		URL:        sourceURL,
+		AuthHeader: input.AuthHeader,
+		IsActive:   true,
+		RateLimit:  input.RateLimit,
+	}
+	if input.IsActive != nil {
+		source.IsActive = *input.IsActive
+	}
+	if len(input.Config) > 0 {
+		if err := validateDataSourceConfig(input.Config); err != nil {
+			return nil, err
+		}
+		source.Config = input.Config
+	}
+	if input.APIKey != nil {
+		if source.APIKey, err = s.sealKey(*input.APIKey); err != nil {
+			return nil, err
+		}
+	}
+
+	created := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&source)
+	if created.Error != nil {
+		return nil, fmt.Errorf("failed to create data source: %v", created.Error)
+	}
+	if created.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: data source %s already exists", ErrInvalidDataSource, name)
+	}
+
+	s.refreshAfterWrite(ctx)
+	view := s.toView(&source)
+	return &view, nil
+}
+
+// UpdateDataSource 更新数据源，新密钥写入后立即生效
+func (s *DataSourceService) UpdateDataSource(ctx context.Context, id string, update DataSourceUpdate) (*DataSourceView, error) {
+	source, err := s.findDataSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if update.URL != nil || len(update.Config) > 0 {
+		if source.Type == DataSourceTypeFeed {
+			return nil, fmt.Errorf("%w: feed url and config are managed through the feed api", ErrInvalidDataSource)
+		}
+	}
+	if update.URL != nil {
+		sourceURL, err := validateDataSourceURL(*update.URL)
+		if err != nil {
+			return nil, err
+		}
+		updates["url"] = sourceURL
+	}
+	if len(update.Config) > 0 {
+		if err := validateDataSourceConfig(update.Config); err != nil {
+			return nil, err
+		}
+		updates["config"] = []byte(update.Config)
+	}
+	if update.APIKey != nil {
+		sealed, err := s.sealKey(*update.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		updates["api_key"] = sealed
+	}
+	if update.AuthHeader != nil {
+		if header := strings.TrimSpace(*update.AuthHeader); header != "" {
+			updates["auth_header"] = header
+		} else {
+			updates["auth_header"] = nil
+		}
+	}
+	if update.IsActive != nil {
+		updates["is_active"] = *update.IsActive
+	}
+	if update.RateLimit != nil {
+		if *update.RateLimit < 0 {
+			return nil, fmt.Errorf("%w: rate_limit must not be negative", ErrInvalidDataSource)
+		}
+		updates["rate_limit"] = *update.RateLimit
+	}
+	if len(updates) == 0 {
+		view := s.toView(source)
+		return &view, nil
+	}
+
+	if err := s.db.WithContext(ctx).Model(source).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update data source: %v", err)
+	}
+	s.refreshAfterWrite(ctx)
+	return s.GetDataSource(ctx, id)
+}
+
+// DeleteDataSource 删除数据源，关联的同步任务保留但不再指向该数据源；内置数据源只能停用
+func (s *DataSourceService) DeleteDataSource(ctx context.Context, id string) error {
+	source, err := s.findDataSource(ctx, id)
+	if err != nil {
+		return err
+	}
+	if findBuiltinDataSource(source.Name) != nil {
+		return fmt.Errorf("%w: built-in data source %s cannot be deleted, deactivate it instead", ErrInvalidDataSource, source.Name)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SyncJob{}).Where("data_source_id = ?", id).Update("data_source_id", nil).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.DataSource{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDataSourceNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrDataSourceNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete data source: %v", err)
+	}
+
+	s.health.Reset(source.Name)
+	s.refreshAfterWrite(ctx)
+	return nil
+}
+
+func (s *DataSourceService) refreshAfterWrite(ctx context.Context) {
+	if err := s.refresh(ctx); err != nil {
+		s.logger.Errorf("Failed to refresh data sources: %v", err)
+	}
+}
+
+// TestDataSource 带上API密钥请求数据源地址，活跃数据源的结果同时计入健康统计
+func (s *DataSourceService) TestDataSource(ctx context.Context, id string) (*DataSourceTestResult, error) {
+	source, err := s.findDataSource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fallback := ""
+	if builtin := findBuiltinDataSource(source.Name); builtin != nil {
+		fallback = builtin.EnvKey(s.config)
+	}
+	apiKey := fallback
+	if source.APIKey != nil && *source.APIKey != "" {
+		if apiKey, err = s.decryptKey(s.currentKeyring(), *source.APIKey); err != nil {
+			return nil, fmt.Errorf("failed to decrypt API key: %v", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDataSource, err)
+	}
+	if apiKey != "" {
+		if source.AuthHeader != nil && *source.AuthHeader != "" {
+			req.Header.Set(*source.AuthHeader, apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+		}
+	}
+
+	result := &DataSourceTestResult{TestedAt: time.Now()}
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	result.StatusCode = resp.StatusCode
+	result.Success = resp.StatusCode < 400
+	result.RateLimit = sourcehealth.ParseRateLimit(resp.Header, resp.StatusCode, time.Now())
+	if !result.Success {
+		result.Error = fmt.Sprintf("upstream returned status %d", resp.StatusCode)
+	}
+	return result, nil
+}
+
+// RotateKeys 重新加载密钥文件，用当前主密钥重新加密其他密钥加密的或明文保存的API密钥
+func (s *DataSourceService) RotateKeys(ctx context.Context) (*KeyRotation, error) {
+	if err := s.reloadKeyring(); err != nil {
+		return nil, fmt.Errorf("failed to reload keyring: %v", err)
+	}
+	keyring := s.currentKeyring()
+	if keyring == nil {
+		return nil, ErrKeyringNotConfigured
+	}
+
+	var sources []models.DataSource
+	if err := s.db.WithContext(ctx).Where("api_key IS NOT NULL AND api_key <> ''").Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("failed to list data sources: %v", err)
+	}
+
+	rotation := &KeyRotation{KeyID: keyring.Primary()}
+	for _, source := range sources {
+		current := *source.APIKey
+		if secrets.KeyID(current) == rotation.KeyID {
+			continue
+		}
+		plaintext, err := s.decryptKey(keyring, current)
+		if err != nil {
+			s.logger.Errorf("Failed to decrypt API key of data source %s: %v", source.Name, err)
+			rotation.Failed = append(rotation.Failed, source.Name)
+			continue
+		}
+		sealed, err := keyring.Encrypt(plaintext)
+		if err != nil {
+			return nil, err
+		}
+		// 只在密钥未被同时修改时替换
+		err = s.db.WithContext(ctx).Model(&models.DataSource{}).
+			Where("id = ? AND api_key = ?", source.ID, current).
+			Update("api_key", sealed).Error
+		if err != nil {
+			s.logger.Errorf("Failed to re-encrypt API key of data source %s: %v", source.Name, err)
+			rotation.Failed = append(rotation.Failed, source.Name)
+			continue
+		}
+		rotation.Rotated++
+	}
+
+	s.refreshAfterWrite(ctx)
+	return rotation, nil
+}
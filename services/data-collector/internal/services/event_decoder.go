@@ -0,0 +1,568 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rwa-platform/data-collector/internal/abidecode"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SyncJobTypeABIRedecode 上传ABI后重新解码历史日志的任务在sync_jobs中的类型
+const SyncJobTypeABIRedecode = "abi_redecode"
+
+const (
+	// 定期重新加载ABI和资产合约，新上架资产的合约无需重启即可解码
+	abiRegistryRefreshInterval = 5 * time.Minute
+	redecodeBatchSize          = 500
+)
+
+var (
+	// ErrInvalidABI 上传的ABI无法解析或参数不合法
+	ErrInvalidABI = errors.New("invalid abi")
+	// ErrABINotFound ABI不存在
+	ErrABINotFound = errors.New("abi not found")
+)
+
+// decodedEventUpsert 重新解码时用新ABI的结果覆盖已有记录
+var decodedEventUpsert = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "chain"}, {Name: "transaction_hash"}, {Name: "log_index"}},
+	DoUpdates: clause.AssignmentColumns([]string{"event_name", "signature", "topic", "abi_id", "args", "updated_at"}),
+}
+
+// ABIUpload 上传ABI的请求，Address为空时按事件签名匹配所有跟踪合约
+type ABIUpload struct {
+	Name    string          `json:"name"`
+	Chain   string          `json:"chain"`
+	Address string          `json:"address"`
+	ABI     json.RawMessage `json:"abi" binding:"required"`
+}
+
+// DecodedEventQuery 解码事件查询条件，Args按参数值精确匹配
+type DecodedEventQuery struct {
+	Chain     string
+	Contract  string
+	Event     string
+	Args      map[string]string
+	FromBlock *uint64
+	ToBlock   *uint64
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+// redecodeCheckpoint 保存在SyncJob.Config中的重新解码状态，按(block_number, id)顺序扫描交易
+type redecodeCheckpoint struct {
+	ABIID      string   `json:"abi_id"`
+	Chain      string   `json:"chain,omitempty"`
+	Address    string   `json:"address,omitempty"`
+	Topics     []string `json:"topics,omitempty"`
+	AfterBlock uint64   `json:"after_block"`
+	AfterID    string   `json:"after_id,omitempty"`
+	Events     int      `json:"events"`
+}
+
+// EventDecoder 维护ABI注册表，解码新索引的日志并执行重新解码任务
+type EventDecoder struct {
+	service  *BlockchainService
+	registry *abidecode.Registry
+	logger   *logrus.Logger
+}
+
+func newEventDecoder(service *BlockchainService) *EventDecoder {
+	return &EventDecoder{
+		service:  service,
+		registry: abidecode.NewRegistry(),
+		logger:   service.logger,
+	}
+}
+
+// Run 定期刷新注册表；注册表在每个实例的内存中，不经过只在一个实例执行的调度器
+func (d *EventDecoder) Run(ctx context.Context) {
+	ticker := time.NewTicker(abiRegistryRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Reload(ctx); err != nil {
+			d.logger.Errorf("Failed to load ABI registry: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reload 从数据库加载ABI、资产合约和桥合约，新上传的ABI优先于同签名的旧ABI
+func (d *EventDecoder) Reload(ctx context.Context) error {
+	var records []models.ContractABI
+	if err := d.service.db.WithContext(ctx).Order("created_at DESC").Find(&records).Error; err != nil {
+		return err
+	}
+
+	sources := make([]abidecode.Source, 0, len(records))
+	for _, record := range records {
+		parsed, err := abidecode.ParseABI(record.ABI)
+		if err != nil {
+			d.logger.Warnf("Skipping invalid ABI %s: %v", record.ID, err)
+			continue
+		}
+		sources = append(sources, abidecode.Source{ID: record.ID, Chain: record.Chain, Address: record.Address, ABI: parsed})
+	}
+
+	var assets []models.Asset
+	if err := d.service.db.WithContext(ctx).Select("contracts").Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return err
+	}
+	var tracked []abidecode.Contract
+	for _, asset := range assets {
+		if len(asset.Contracts) == 0 {
+			continue
+		}
+		var contracts []assetContract
+		if err := json.Unmarshal(asset.Contracts, &contracts); err != nil {
+			continue
+		}
+		for _, contract := range contracts {
+			tracked = append(tracked, abidecode.Contract{Chain: contract.Chain, Address: contract.Address})
+		}
+	}
+
+	// 桥合约的事件用于关联跨链转账
+	var bridges []models.BridgeContract
+	if err := d.service.db.WithContext(ctx).Where("is_active = ? AND event_name <> ''", true).Find(&bridges).Error; err != nil {
+		return err
+	}
+	for _, contract := range bridges {
+		tracked = append(tracked, abidecode.Contract{Chain: contract.Chain, Address: contract.Address})
+	}
+
+	d.registry.Load(sources, tracked)
+	return nil
+}
+
+// decodeLogs 解码交易回执中跟踪合约的日志，无法解码的日志记录后跳过
+func (d *EventDecoder) decodeLogs(chain string, logs []*types.Log, blockNumber uint64, timestamp time.Time) []*models.DecodedEvent {
+	var events []*models.DecodedEvent
+	for _, log := range logs {
+		event, err := d.registry.Decode(chain, log)
+		if err != nil {
+			d.logger.Debugf("Failed to decode log %d of %s on %s: %v", log.Index, log.TxHash.Hex(), chain, err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		args, err := json.Marshal(event.Args)
+		if err != nil {
+			continue
+		}
+		decoded := &models.DecodedEvent{
+			Chain:           chain,
+			TransactionHash: log.TxHash.Hex(),
+			LogIndex:        log.Index,
+			ContractAddress: log.Address.Hex(),
+			EventName:       event.Name,
+			Signature:       event.Signature,
+			Topic:           event.Topic.Hex(),
+			Args:            args,
+			BlockNumber:     blockNumber,
+			Timestamp:       timestamp,
+		}
+		if event.ABIID != "" {
+			abiID := event.ABIID
+			decoded.ABIID = &abiID
+		}
+		events = append(events, decoded)
+	}
+	return events
+}
+
+// runPendingJobs 依次执行待处理的重新解码任务，中断的任务下次从检查点继续；
+// 任务可能由其他实例上传的ABI创建，执行前先刷新注册表
+func (d *EventDecoder) runPendingJobs(ctx context.Context) error {
+	if err := d.Reload(ctx); err != nil {
+		return fmt.Errorf("failed to load ABI registry: %v", err)
+	}
+
+	var jobs []models.SyncJob
+	if err := d.service.db.WithContext(ctx).
+		Where("type = ? AND status IN ?", SyncJobTypeABIRedecode, []string{"pending", "running"}).
+		Order("created_at").
+		Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to load ABI redecode jobs: %v", err)
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := d.redecode(ctx, &jobs[i]); err != nil && ctx.Err() == nil {
+			d.logger.Errorf("ABI redecode job %s failed: %v", jobs[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// redecode 扫描包含ABI相关日志的历史交易并重新解码，每批完成后写入检查点
+func (d *EventDecoder) redecode(ctx context.Context, job *models.SyncJob) error {
+	db := d.service.db
+	var checkpoint redecodeCheckpoint
+	if err := json.Unmarshal(job.Config, &checkpoint); err != nil || checkpoint.ABIID == "" {
+		return d.finishJob(job, fmt.Errorf("sync job has no redecode checkpoint"))
+	}
+
+	updates := map[string]interface{}{"status": "running", "error_message": nil, "completed_at": nil}
+	if job.StartedAt == nil {
+		updates["started_at"] = time.Now()
+	}
+	if job.RecordsTotal == nil {
+		var total int64
+		if err := d.scanQuery(ctx, checkpoint).Count(&total).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return d.finishJob(job, fmt.Errorf("failed to count transactions: %v", err))
+		}
+		count := int(total)
+		job.RecordsTotal = &count
+		updates["records_total"] = count
+	}
+	if err := db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	d.logger.Infof("Redecoding %d transactions for ABI %s", *job.RecordsTotal, checkpoint.ABIID)
+
+	topics := make(map[common.Hash]bool, len(checkpoint.Topics))
+	for _, topic := range checkpoint.Topics {
+		topics[common.HexToHash(topic)] = true
+	}
+	address := common.HexToAddress(checkpoint.Address)
+	processed := job.RecordsProcessed
+
+	for {
+		query := d.scanQuery(ctx, checkpoint)
+		if checkpoint.AfterID != "" {
+			query = query.Where("block_number > ? OR (block_number = ? AND id > ?)", checkpoint.AfterBlock, checkpoint.AfterBlock, checkpoint.AfterID)
+		}
+		var transactions []models.BlockchainTransaction
+		if err := query.Order("block_number, id").Limit(redecodeBatchSize).Find(&transactions).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return d.finishJob(job, fmt.Errorf("failed to scan transactions: %v", err))
+		}
+		if len(transactions) == 0 {
+			break
+		}
+
+		var events []*models.DecodedEvent
+		for _, transaction := range transactions {
+			var logs []*types.Log
+			if err := json.Unmarshal(transaction.Logs, &logs); err != nil {
+				continue
+			}
+			matching := logs[:0]
+			for _, log := range logs {
+				if checkpoint.Address != "" && log.Address != address {
+					continue
+				}
+				if len(topics) > 0 && (len(log.Topics) == 0 || !topics[log.Topics[0]]) {
+					continue
+				}
+				matching = append(matching, log)
+			}
+			events = append(events, d.decodeLogs(transaction.Chain, matching, transaction.BlockNumber, transaction.Timestamp)...)
+		}
+
+		last := transactions[len(transactions)-1]
+		checkpoint.AfterBlock = last.BlockNumber
+		checkpoint.AfterID = last.ID
+		checkpoint.Events += len(events)
+		processed += len(transactions)
+
+		config, err := json.Marshal(checkpoint)
+		if err != nil {
+			return err
+		}
+		progress := 100
+		if *job.RecordsTotal > 0 && processed < *job.RecordsTotal {
+			progress = processed * 100 / *job.RecordsTotal
+		}
+		err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if len(events) > 0 {
+				if err := tx.Clauses(decodedEventUpsert).CreateInBatches(events, 100).Error; err != nil {
+					return fmt.Errorf("failed to save decoded events: %v", err)
+				}
+				if _, err := d.service.bridges.record(tx, events); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"config":            config,
+				"progress":          progress,
+				"records_processed": processed,
+				"records_success":   checkpoint.Events,
+			}).Error
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return d.finishJob(job, err)
+		}
+	}
+
+	d.logger.Infof("ABI %s redecode completed: %d events from %d transactions", checkpoint.ABIID, checkpoint.Events, processed)
+	return d.finishJob(job, nil)
+}
+
+// scanQuery 按ABI的合约地址或事件签名筛选交易，日志中的地址和topic是小写十六进制
+func (d *EventDecoder) scanQuery(ctx context.Context, checkpoint redecodeCheckpoint) *gorm.DB {
+	db := d.service.db.WithContext(ctx)
+	query := db.Model(&models.BlockchainTransaction{}).Where("logs IS NOT NULL")
+	if checkpoint.Chain != "" {
+		query = query.Where("chain = ?", checkpoint.Chain)
+	}
+
+	if checkpoint.Address != "" {
+		filter, _ := json.Marshal([]map[string]string{{"address": strings.ToLower(checkpoint.Address)}})
+		return query.Where("logs @> ?", string(filter))
+	}
+
+	conditions := db.Where("false")
+	for _, topic := range checkpoint.Topics {
+		filter, _ := json.Marshal([]map[string][]string{{"topics": {strings.ToLower(topic)}}})
+		conditions = conditions.Or("logs @> ?", string(filter))
+	}
+	return query.Where(conditions)
+}
+
+// finishJob 写入任务最终状态，使用独立的上下文以免服务停止时状态丢失
+func (d *EventDecoder) finishJob(job *models.SyncJob, runErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updates := map[string]interface{}{"status": "completed", "progress": 100, "completed_at": time.Now()}
+	if runErr != nil {
+		updates = map[string]interface{}{"status": "failed", "error_message": runErr.Error(), "records_error": gorm.Expr("records_error + 1")}
+	}
+	if err := d.service.db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		d.logger.Errorf("Failed to update ABI redecode job %s: %v", job.ID, err)
+	}
+	return runErr
+}
+
+// UploadABI 保存ABI并创建重新解码任务；同一合约重复上传时替换原有ABI
+func (s *BlockchainService) UploadABI(ctx context.Context, upload ABIUpload) (*models.ContractABI, *models.SyncJob, error) {
+	raw, err := abidecode.ExtractABI(upload.ABI)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+	parsed, err := abidecode.ParseABI(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+
+	record := models.ContractABI{
+		Name:  strings.TrimSpace(upload.Name),
+		Chain: strings.TrimSpace(upload.Chain),
+		ABI:   raw,
+	}
+	if record.Chain != "" {
+		s.mu.RLock()
+		_, isEVM := s.pools[record.Chain]
+		s.mu.RUnlock()
+		if !isEVM {
+			return nil, nil, fmt.Errorf("%w: %s is not a registered EVM chain", ErrInvalidABI, record.Chain)
+		}
+	}
+	if address := strings.TrimSpace(upload.Address); address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, nil, fmt.Errorf("%w: invalid contract address %s", ErrInvalidABI, address)
+		}
+		record.Address = normalizeAddress(address)
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if record.Address != "" {
+			var existing models.ContractABI
+			err := tx.Where("chain = ? AND address = ?", record.Chain, record.Address).First(&existing).Error
+			if err == nil {
+				record.ID = existing.ID
+				record.CreatedAt = existing.CreatedAt
+				return tx.Save(&record).Error
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+		return tx.Create(&record).Error
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save abi: %v", err)
+	}
+
+	if err := s.events.Reload(ctx); err != nil {
+		s.logger.Errorf("Failed to reload ABI registry: %v", err)
+	}
+
+	job, err := s.createRedecodeJob(ctx, &record, parsed.Events)
+	if err != nil {
+		return &record, nil, err
+	}
+	return &record, job, nil
+}
+
+// RedecodeABI 为已有ABI重新创建解码任务
+func (s *BlockchainService) RedecodeABI(ctx context.Context, id string) (*models.SyncJob, error) {
+	var record models.ContractABI
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrABINotFound
+		}
+		return nil, fmt.Errorf("failed to query abi: %v", err)
+	}
+	parsed, err := abidecode.ParseABI(record.ABI)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+	return s.createRedecodeJob(ctx, &record, parsed.Events)
+}
+
+func (s *BlockchainService) createRedecodeJob(ctx context.Context, record *models.ContractABI, events map[string]abi.Event) (*models.SyncJob, error) {
+	checkpoint := redecodeCheckpoint{ABIID: record.ID, Chain: record.Chain, Address: record.Address}
+	if record.Address == "" {
+		for _, event := range events {
+			if !event.Anonymous {
+				checkpoint.Topics = append(checkpoint.Topics, event.ID.Hex())
+			}
+		}
+	}
+	config, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.SyncJob{
+		Type:   SyncJobTypeABIRedecode,
+		Status: "pending",
+		Config: config,
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sync job: %v", err)
+	}
+
+	s.triggerJob(ctx, abiRedecodeJob)
+	return job, nil
+}
+
+// ListABIs 列出已上传的ABI
+func (s *BlockchainService) ListABIs(ctx context.Context) ([]models.ContractABI, error) {
+	var records []models.ContractABI
+	if err := s.db.WithContext(ctx).Order("created_at DESC").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to list abis: %v", err)
+	}
+	return records, nil
+}
+
+// DeleteABI 删除ABI，已解码的事件保留
+func (s *BlockchainService) DeleteABI(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Where("id = ?", id).Delete(&models.ContractABI{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete abi: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrABINotFound
+	}
+	if err := s.events.Reload(ctx); err != nil {
+		s.logger.Errorf("Failed to reload ABI registry: %v", err)
+	}
+	return nil
+}
+
+// GetSyncJob 获取同步任务状态
+func (s *BlockchainService) GetSyncJob(ctx context.Context, id string) (*models.SyncJob, error) {
+	var job models.SyncJob
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSyncJobNotFound
+		}
+		return nil, fmt.Errorf("failed to query sync job: %v", err)
+	}
+	return &job, nil
+}
+
+// GetDecodedEvents 按合约、事件名和参数值查询解码事件，按时间倒序分页
+func (s *BlockchainService) GetDecodedEvents(ctx context.Context, q DecodedEventQuery) ([]models.DecodedEvent, string, error) {
+	query := s.db.Model(&models.DecodedEvent{})
+	if q.Contract != "" {
+		query = query.Where("contract_address = ?", normalizeAddress(q.Contract))
+	}
+	if q.Event != "" {
+		query = query.Where("event_name = ?", q.Event)
+	}
+	if q.FromBlock != nil {
+		query = query.Where("block_number >= ?", *q.FromBlock)
+	}
+	if q.ToBlock != nil {
+		query = query.Where("block_number <= ?", *q.ToBlock)
+	}
+	for name, value := range q.Args {
+		filter, err := json.Marshal(map[string]string{name: normalizeArgValue(value)})
+		if err != nil {
+			return nil, "", err
+		}
+		// 解码结果中只有bool不是字符串
+		if value == "true" || value == "false" {
+			boolFilter, _ := json.Marshal(map[string]bool{name: value == "true"})
+			query = query.Where("args @> ? OR args @> ?", string(filter), string(boolFilter))
+			continue
+		}
+		query = query.Where("args @> ?", string(filter))
+	}
+
+	query, err := applyActivityFilters(query, WalletActivityQuery{Chain: q.Chain, From: q.From, To: q.To, Cursor: q.Cursor, Limit: q.Limit})
+	if err != nil {
+		return nil, "", err
+	}
+
+	var events []models.DecodedEvent
+	if err := query.WithContext(ctx).Find(&events).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to query decoded events: %v", err)
+	}
+
+	nextCursor := ""
+	if len(events) > q.Limit {
+		events = events[:q.Limit]
+		last := events[len(events)-1]
+		nextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+	return events, nextCursor, nil
+}
+
+// normalizeArgValue 将查询值转换为解码结果中的格式：地址为校验和格式，其他十六进制为小写
+func normalizeArgValue(value string) string {
+	value = strings.TrimSpace(value)
+	if common.IsHexAddress(value) {
+		return common.HexToAddress(value).Hex()
+	}
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return strings.ToLower(value)
+	}
+	return value
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/config"
+	"github.com/rwa-platform/data-collector/internal/filings"
+	"github.com/rwa-platform/data-collector/internal/kafka"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/tracing"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// 申报的数据来源
+const (
+	FilingSourceLocal  = "local"
+	FilingSourceRemote = "remote"
+)
+
+var (
+	ErrFilingNotFound = errors.New("filing not found")
+	// ErrFilingsDisabled 本地目录和远程地址都未配置
+	ErrFilingsDisabled = errors.New("filing collection is not configured")
+)
+
+// errFilingNotPublished 远程文件不存在，周末和节假日没有每日索引，新发行方可能还没有companyfacts
+var errFilingNotPublished = errors.New("filing document not published")
+
+type FilingService struct {
+	db     *gorm.DB
+	redis  RedisCache
+	kafka  *kafka.Producer
+	config *config.Config
+	client *http.Client
+	logger *logrus.Logger
+	jobs   *scheduler.Scheduler
+}
+
+// FilingQuery 申报筛选条件，Issuer为发行方名称（不区分大小写）
+type FilingQuery struct {
+	AssetID  string
+	Issuer   string
+	FormType string
+	Page     int
+	Limit    int
+}
+
+// filingIssuer 资产元数据中的发行方，ID与news_entities中issuer实体的ID一致
+type filingIssuer struct {
+	ID       string
+	AssetIDs []string
+}
+
+// filingIssuers 按CIK和规范化名称查找发行方，元数据中有CIK时优先按CIK匹配
+type filingIssuers struct {
+	byCIK  map[string]*filingIssuer
+	byName map[string]*filingIssuer
+}
+
+func (i *filingIssuers) match(entry filings.Entry) *filingIssuer {
+	if issuer, ok := i.byCIK[entry.CIK]; ok {
+		return issuer
+	}
+	return i.byName[filings.NormalizeCompany(entry.Company)]
+}
+
+func NewFilingService(db *gorm.DB, redisClient RedisCache, kafkaProducer *kafka.Producer, cfg *config.Config) *FilingService {
+	return &FilingService{
+		db:     db,
+		redis:  redisClient,
+		kafka:  kafkaProducer,
+		config: cfg,
+		client: &http.Client{
+			Timeout:   time.Duration(cfg.RequestTimeout) * time.Second,
+			Transport: tracing.Transport(nil),
+		},
+		logger: logrus.New(),
+	}
+}
+
+// UseDataSources 申报请求计入数据源健康统计
+func (s *FilingService) UseDataSources(sources *DataSourceService) {
+	s.client.Transport = sources.Transport(s.client.Transport)
+}
+
+// filingCollectionJob 监管申报采集的调度任务名
+const filingCollectionJob = "filing-collection"
+
+// RegisterJobs 注册申报采集任务，本地目录和远程地址都未配置时不注册
+func (s *FilingService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	if s.config.FilingsDir == "" && s.config.FilingsBaseURL == "" {
+		s.logger.Info("Filing collection disabled: neither FILINGS_DIR nor FILINGS_BASE_URL is set")
+		return nil
+	}
+	s.jobs = jobs
+
+	interval := time.Duration(s.config.FilingsInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return jobs.Register(scheduler.Spec{
+		Name:     filingCollectionJob,
+		Schedule: scheduler.Every(interval),
+		// 多个部署同时请求EDGAR时错开时间
+		Jitter:  5 * time.Minute,
+		Timeout: 30 * time.Minute,
+		// 每次采集覆盖最近几天的索引，错过的执行合并为一次即可
+		Missed: scheduler.MissedRunOnce,
+		Run:    s.collectFilings,
+	})
+}
+
+// TriggerSync 手动触发申报采集
+func (s *FilingService) TriggerSync() error {
+	if s.jobs == nil {
+		return ErrFilingsDisabled
+	}
+	return s.jobs.Trigger(context.Background(), filingCollectionJob)
+}
+
+func (s *FilingService) collectFilings(ctx context.Context) error {
+	issuers, err := s.loadIssuers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load filing issuers: %v", err)
+	}
+	if len(issuers.byCIK) == 0 && len(issuers.byName) == 0 {
+		s.logger.Debug("No asset issuers to match filings against")
+		return nil
+	}
+
+	forms := make(map[string]bool)
+	for _, form := range s.config.FilingsForms {
+		if form = filings.BaseForm(form); form != "" {
+			forms[form] = true
+		}
+	}
+
+	saved := 0
+	if s.config.FilingsDir != "" {
+		saved += s.collectLocal(ctx, issuers, forms)
+	}
+	if s.config.FilingsBaseURL != "" && ctx.Err() == nil {
+		saved += s.collectRemote(ctx, issuers, forms)
+	}
+	if saved > 0 {
+		s.logger.Infof("Saved %d filings", saved)
+	}
+	return nil
+}
+
+// loadIssuers 从资产元数据加载发行方
+func (s *FilingService) loadIssuers(ctx context.Context) (*filingIssuers, error) {
+	var assets []models.Asset
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to query assets: %v", err)
+	}
+
+	issuers := &filingIssuers{
+		byCIK:  make(map[string]*filingIssuer),
+		byName: make(map[string]*filingIssuer),
+	}
+	byID := make(map[string]*filingIssuer)
+	for _, asset := range assets {
+		var metadata assetEntityMetadata
+		if len(asset.Metadata) > 0 {
+			json.Unmarshal(asset.Metadata, &metadata)
+		}
+		name := strings.TrimSpace(metadata.Issuer)
+		if name == "" {
+			continue
+		}
+
+		id := strings.ToLower(name)
+		issuer, ok := byID[id]
+		if !ok {
+			issuer = &filingIssuer{ID: id}
+			byID[id] = issuer
+			if normalized := filings.NormalizeCompany(name); normalized != "" {
+				issuers.byName[normalized] = issuer
+			}
+		}
+		issuer.AssetIDs = append(issuer.AssetIDs, asset.ID)
+		if cik := strings.TrimLeft(strings.TrimSpace(metadata.CIK), "0"); cik != "" {
+			issuers.byCIK[cik] = issuer
+		}
+	}
+	return issuers, nil
+}
+
+// collectLocal 处理目录中的索引文件（*.idx）和companyfacts文件（*.json）
+func (s *FilingService) collectLocal(ctx context.Context, issuers *filingIssuers, forms map[string]bool) int {
+	paths, err := filepath.Glob(filepath.Join(s.config.FilingsDir, "*.idx"))
+	if err != nil {
+		s.logger.Errorf("Failed to list filing indexes: %v", err)
+		return 0
+	}
+
+	var entries []filings.Entry
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			s.logger.Errorf("Failed to open filing index %s: %v", path, err)
+			continue
+		}
+		parsed, err := filings.ParseIndex(file)
+		file.Close()
+		if err != nil {
+			s.logger.Errorf("Failed to parse filing index %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, parsed...)
+	}
+
+	companies, errs := filings.LoadCompanyFacts(s.config.FilingsDir)
+	for _, err := range errs {
+		s.logger.Errorf("Failed to load company facts: %v", err)
+	}
+	return s.saveFilings(ctx, entries, issuers, forms, FilingSourceLocal, func(cik string) *filings.CompanyFacts {
+		return companies[cik]
+	})
+}
+
+// collectRemote 抓取最近几天的每日索引，匹配到的发行方每次采集只请求一次companyfacts
+func (s *FilingService) collectRemote(ctx context.Context, issuers *filingIssuers, forms map[string]bool) int {
+	days := s.config.FilingsLookbackDays
+	if days <= 0 {
+		days = 1
+	}
+	base := strings.TrimRight(s.config.FilingsBaseURL, "/")
+
+	var entries []filings.Entry
+	today := time.Now().UTC()
+	for d := 0; d < days; d++ {
+		path := filings.DailyIndexPath(today.AddDate(0, 0, -d))
+		parsed, err := s.fetchIndex(ctx, base+"/"+path)
+		if err != nil {
+			if !errors.Is(err, errFilingNotPublished) && ctx.Err() == nil {
+				s.logger.Errorf("Failed to fetch filing index %s: %v", path, err)
+			}
+			continue
+		}
+		entries = append(entries, parsed...)
+	}
+
+	companies := make(map[string]*filings.CompanyFacts)
+	return s.saveFilings(ctx, entries, issuers, forms, FilingSourceRemote, func(cik string) *filings.CompanyFacts {
+		if facts, ok := companies[cik]; ok {
+			return facts
+		}
+		facts, err := s.fetchCompanyFacts(ctx, cik)
+		if err != nil && !errors.Is(err, errFilingNotPublished) && ctx.Err() == nil {
+			s.logger.Errorf("Failed to fetch company facts for CIK %s: %v", cik, err)
+		}
+		companies[cik] = facts
+		return facts
+	})
+}
+
+func (s *FilingService) fetchIndex(ctx context.Context, url string) ([]filings.Entry, error) {
+	body, err := s.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return filings.ParseIndex(body)
+}
+
+func (s *FilingService) fetchCompanyFacts(ctx context.Context, cik string) (*filings.CompanyFacts, error) {
+	number, err := strconv.ParseUint(cik, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cik %s", cik)
+	}
+	url := fmt.Sprintf("%s/CIK%010d.json", strings.TrimRight(s.config.FilingsFactsURL, "/"), number)
+	body, err := s.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return filings.ParseCompanyFacts(body)
+}
+
+// fetch 请求归档文件，EDGAR拒绝没有User-Agent的请求
+func (s *FilingService) fetch(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.FilingsUserAgent != "" {
+		req.Header.Set("User-Agent", s.config.FilingsUserAgent)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errFilingNotPublished
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("filing archive returned status %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// saveFilings 保存匹配到发行方的申报，返回新增或补充了关键数据的数量
+func (s *FilingService) saveFilings(ctx context.Context, entries []filings.Entry, issuers *filingIssuers, forms map[string]bool, source string, companyFacts func(cik string) *filings.CompanyFacts) int {
+	saved := 0
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if len(forms) > 0 && !forms[filings.BaseForm(entry.FormType)] {
+			continue
+		}
+		accession := entry.Accession()
+		if accession == "" || seen[accession] {
+			continue
+		}
+		seen[accession] = true
+		issuer := issuers.match(entry)
+		if issuer == nil {
+			continue
+		}
+
+		// 已有关键数据的申报不再请求companyfacts
+		var existing models.Filing
+		err := s.db.WithContext(ctx).Where("accession = ?", accession).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Errorf("Failed to query filing %s: %v", accession, err)
+			continue
+		}
+		found := err == nil
+		if found && len(existing.Facts) > 0 {
+			continue
+		}
+
+		var key filings.KeyFacts
+		if facts := companyFacts(entry.CIK); facts != nil {
+			key = facts.KeyFacts(accession)
+		}
+		if found && key.Empty() {
+			continue
+		}
+		var previous *models.Filing
+		if found {
+			previous = &existing
+		}
+		if err := s.saveFiling(ctx, entry, accession, issuer, key, source, previous); err != nil {
+			s.logger.Errorf("Failed to save filing %s: %v", accession, err)
+			continue
+		}
+		saved++
+	}
+	return saved
+}
+
+// saveFiling 新申报入库（existing为nil）；已有申报只在首次取得关键数据时更新，XBRL数据通常晚于索引发布
+func (s *FilingService) saveFiling(ctx context.Context, entry filings.Entry, accession string, issuer *filingIssuer, key filings.KeyFacts, source string, existing *models.Filing) error {
+	filing := existing
+	if filing == nil {
+		assetIDs, _ := json.Marshal(issuer.AssetIDs)
+		filing = &models.Filing{
+			Accession: accession,
+			CIK:       entry.CIK,
+			Company:   entry.Company,
+			FormType:  entry.FormType,
+			FiledAt:   entry.FiledAt,
+			URL:       s.filingURL(entry.Path),
+			Issuer:    issuer.ID,
+			AssetIDs:  assetIDs,
+			Source:    source,
+		}
+	}
+	if !key.Empty() {
+		facts, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		filing.Facts = facts
+		filing.PeriodEnd = key.PeriodEnd
+		filing.AUM = key.AUM
+		filing.NAV = key.NAV
+		filing.NAVPerShare = key.NAVPerShare
+		if key.FiscalYear > 0 {
+			filing.FiscalYear = &key.FiscalYear
+		}
+		if key.FiscalPeriod != "" {
+			filing.FiscalPeriod = &key.FiscalPeriod
+		}
+		if key.Currency != "" {
+			filing.Currency = &key.Currency
+		}
+	}
+
+	var err error
+	if existing != nil {
+		err = s.db.WithContext(ctx).Save(filing).Error
+	} else {
+		err = s.db.WithContext(ctx).Create(filing).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	s.publishFiling(ctx, filing, issuer, existing != nil)
+	return nil
+}
+
+// filingURL 申报在归档中的地址，只使用本地文件时为相对路径
+func (s *FilingService) filingURL(path string) string {
+	if s.config.FilingsBaseURL == "" {
+		return path
+	}
+	return strings.TrimRight(s.config.FilingsBaseURL, "/") + "/" + path
+}
+
+// publishFiling 发送到filing-updates，updated表示已有申报补充了关键数据
+func (s *FilingService) publishFiling(ctx context.Context, filing *models.Filing, issuer *filingIssuer, updated bool) {
+	message := map[string]interface{}{
+		"type":          "filing_update",
+		"filing_id":     filing.ID,
+		"accession":     filing.Accession,
+		"cik":           filing.CIK,
+		"company":       filing.Company,
+		"form_type":     filing.FormType,
+		"issuer":        filing.Issuer,
+		"asset_ids":     issuer.AssetIDs,
+		"url":           filing.URL,
+		"filed_at":      filing.FiledAt.Unix(),
+		"aum":           filing.AUM,
+		"nav":           filing.NAV,
+		"nav_per_share": filing.NAVPerShare,
+		"currency":      filing.Currency,
+		"updated":       updated,
+	}
+	if filing.PeriodEnd != nil {
+		message["period_end"] = filing.PeriodEnd.Unix()
+	}
+	if err := s.kafka.PublishMessage(ctx, "filing-updates", filing.CIK, message); err != nil {
+		s.logger.Errorf("Failed to publish filing update: %v", err)
+	}
+}
+
+// ListFilings 按资产、发行方和申报类型筛选申报，按申报日期倒序；申报类型包含修订版
+func (s *FilingService) ListFilings(ctx context.Context, q FilingQuery) ([]models.Filing, int, error) {
+	if q.AssetID != "" && !uuidPattern.MatchString(q.AssetID) {
+		return nil, 0, ErrInvalidAssetID
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Filing{})
+	if q.AssetID != "" {
+		assetIDs, _ := json.Marshal([]string{q.AssetID})
+		query = query.Where("asset_ids @> ?", string(assetIDs))
+	}
+	if q.Issuer != "" {
+		query = query.Where("issuer = ?", strings.ToLower(strings.TrimSpace(q.Issuer)))
+	}
+	if q.FormType != "" {
+		form := filings.BaseForm(q.FormType)
+		query = query.Where("form_type IN ?", []string{form, form + "/A"})
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count filings: %v", err)
+	}
+	results := []models.Filing{}
+	err := query.Order("filed_at DESC, created_at DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&results).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query filings: %v", err)
+	}
+	return results, int(total), nil
+}
+
+// GetFiling 获取申报详情
+func (s *FilingService) GetFiling(ctx context.Context, id string) (*models.Filing, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, ErrFilingNotFound
+	}
+	var filing models.Filing
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&filing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFilingNotFound
+		}
+		return nil, fmt.Errorf("failed to query filing: %v", err)
+	}
+	return &filing, nil
+}
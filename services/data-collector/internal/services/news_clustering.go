@@ -0,0 +1,254 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/simhash"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// storyWindow 只与该时间内采集的文章比较，转载通常在首发后一两天内完成
+const storyWindow = 72 * time.Hour
+
+// ErrStoryNotFound 新闻报道不存在
+var ErrStoryNotFound = errors.New("story not found")
+
+// NewsStorySummary 新闻报道及其首发文章，Articles只在查询单个报道时返回
+type NewsStorySummary struct {
+	ID           string               `json:"id"`
+	Title        string               `json:"title"`
+	Category     *string              `json:"category"`
+	Sources      []string             `json:"sources"`
+	ArticleCount int                  `json:"article_count"`
+	FirstSeenAt  time.Time            `json:"first_seen_at"`
+	LastSeenAt   time.Time            `json:"last_seen_at"`
+	Canonical    *models.NewsArticle  `json:"canonical"`
+	Articles     []models.NewsArticle `json:"articles,omitempty"`
+}
+
+// assignStory 将新文章归入相似文章所在的报道，没有相似文章时创建新报道；joined表示加入了已有报道
+//
+// 使用事务级咨询锁串行化聚类，避免并发采集的转载文章各自创建报道
+func (s *NewsService) assignStory(tx *gorm.DB, article *models.NewsArticle) (story *models.NewsStory, joined bool, err error) {
+	fingerprint := simhash.Fingerprint(article.Title, stringValue(article.Summary))
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('news_stories'))").Error; err != nil {
+		return nil, false, fmt.Errorf("failed to lock news stories: %v", err)
+	}
+
+	now := time.Now()
+	var match *models.NewsArticle
+	if fingerprint != 0 {
+		bands := simhash.Bands(fingerprint)
+		conditions := tx.Where("false")
+		for _, band := range bands {
+			filter, _ := json.Marshal([]string{band})
+			conditions = conditions.Or("simhash_bands @> ?", string(filter))
+		}
+
+		var candidates []models.NewsArticle
+		err := tx.Model(&models.NewsArticle{}).
+			Select("id, story_id, simhash").
+			Where("story_id IS NOT NULL AND id <> ? AND created_at > ?", article.ID, now.Add(-storyWindow)).
+			Where(conditions).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to find similar articles: %v", err)
+		}
+
+		best := simhash.Threshold + 1
+		for i := range candidates {
+			if candidates[i].Simhash == nil {
+				continue
+			}
+			if distance := simhash.Distance(fingerprint, uint64(*candidates[i].Simhash)); distance < best {
+				best = distance
+				match = &candidates[i]
+			}
+		}
+
+		signed := int64(fingerprint)
+		article.Simhash = &signed
+		article.SimhashBands, _ = json.Marshal(bands)
+	}
+
+	if match != nil {
+		story = &models.NewsStory{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", *match.StoryID).First(story).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, false, fmt.Errorf("failed to load story: %v", err)
+			}
+			// 报道已被清理，作为新报道处理
+			story = nil
+		}
+	}
+
+	if story != nil {
+		var sources []string
+		json.Unmarshal(story.Sources, &sources)
+		if !contains(sources, article.Source) {
+			sources = append(sources, article.Source)
+		}
+		story.Sources, _ = json.Marshal(sources)
+		story.ArticleCount++
+		story.LastSeenAt = now
+		if err := tx.Model(story).Updates(map[string]interface{}{
+			"sources":       story.Sources,
+			"article_count": story.ArticleCount,
+			"last_seen_at":  story.LastSeenAt,
+		}).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to update story: %v", err)
+		}
+		joined = true
+	} else {
+		sources, _ := json.Marshal([]string{article.Source})
+		story = &models.NewsStory{
+			CanonicalArticleID: article.ID,
+			Title:              article.Title,
+			Category:           article.Category,
+			Sources:            sources,
+			ArticleCount:       1,
+			FirstSeenAt:        now,
+			LastSeenAt:         now,
+		}
+		if err := tx.Create(story).Error; err != nil {
+			return nil, false, fmt.Errorf("failed to create story: %v", err)
+		}
+	}
+
+	article.StoryID = &story.ID
+	if err := tx.Model(article).Updates(map[string]interface{}{
+		"story_id":      story.ID,
+		"simhash":       article.Simhash,
+		"simhash_bands": article.SimhashBands,
+	}).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to link article to story: %v", err)
+	}
+	return story, joined, nil
+}
+
+// publishStoryUpdate 有新的媒体转载已有报道时发布更新，而不是再发一条news_update
+func (s *NewsService) publishStoryUpdate(ctx context.Context, story *models.NewsStory, article *models.NewsArticle, links []models.NewsEntity) {
+	var sources []string
+	json.Unmarshal(story.Sources, &sources)
+
+	message := map[string]interface{}{
+		"type":                 "story_update",
+		"story_id":             story.ID,
+		"canonical_article_id": story.CanonicalArticleID,
+		"title":                story.Title,
+		"category":             story.Category,
+		"sources":              sources,
+		"article_count":        story.ArticleCount,
+		"article_id":           article.ID,
+		"url":                  article.URL,
+		"source":               article.Source,
+		"asset_ids":            linkedAssetIDs(links),
+		"last_seen_at":         story.LastSeenAt.Unix(),
+	}
+
+	if err := s.kafka.PublishMessage(ctx, "news-updates", story.ID, message); err != nil {
+		s.logger.Errorf("Failed to publish story update: %v", err)
+	}
+}
+
+// GetNewsStories 按最近更新时间分页列出新闻报道，分类和来源作用于报道，语言作用于首发文章，资产作用于报道中的任一文章
+func (s *NewsService) GetNewsStories(page, limit int, category, source, language, assetID string) ([]NewsStorySummary, int, error) {
+	if assetID != "" && !uuidPattern.MatchString(assetID) {
+		return nil, 0, ErrInvalidAssetID
+	}
+
+	var stories []models.NewsStory
+	var total int64
+
+	query := s.db.Model(&models.NewsStory{})
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if source != "" {
+		filter, _ := json.Marshal([]string{source})
+		query = query.Where("sources @> ?", string(filter))
+	}
+	if language != "" {
+		query = query.Where("canonical_article_id IN (?)", s.db.Model(&models.NewsArticle{}).Select("id").Where("language = ?", language))
+	}
+	if assetID != "" {
+		query = query.Where("id IN (?)", s.db.Model(&models.NewsArticle{}).Select("story_id").Where("id IN (?)", s.assetArticles(assetID)))
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	if err := query.Order("last_seen_at DESC").Offset(offset).Limit(limit).Find(&stories).Error; err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]string, 0, len(stories))
+	for _, story := range stories {
+		ids = append(ids, story.CanonicalArticleID)
+	}
+	var articles []models.NewsArticle
+	if len(ids) > 0 {
+		if err := s.db.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return nil, 0, err
+		}
+	}
+	canonical := make(map[string]*models.NewsArticle, len(articles))
+	for i := range articles {
+		canonical[articles[i].ID] = &articles[i]
+	}
+
+	summaries := make([]NewsStorySummary, 0, len(stories))
+	for i := range stories {
+		summaries = append(summaries, toStorySummary(&stories[i], canonical[stories[i].CanonicalArticleID]))
+	}
+	return summaries, int(total), nil
+}
+
+// GetNewsStory 获取报道及其所有文章
+func (s *NewsService) GetNewsStory(ctx context.Context, id string) (*NewsStorySummary, error) {
+	var story models.NewsStory
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&story).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStoryNotFound
+		}
+		return nil, fmt.Errorf("failed to query story: %v", err)
+	}
+
+	var articles []models.NewsArticle
+	if err := s.db.WithContext(ctx).Where("story_id = ?", story.ID).Order("published_at ASC").Find(&articles).Error; err != nil {
+		return nil, fmt.Errorf("failed to query story articles: %v", err)
+	}
+
+	var canonical *models.NewsArticle
+	for i := range articles {
+		if articles[i].ID == story.CanonicalArticleID {
+			canonical = &articles[i]
+		}
+	}
+	summary := toStorySummary(&story, canonical)
+	summary.Articles = articles
+	return &summary, nil
+}
+
+func toStorySummary(story *models.NewsStory, canonical *models.NewsArticle) NewsStorySummary {
+	sources := []string{}
+	json.Unmarshal(story.Sources, &sources)
+	return NewsStorySummary{
+		ID:           story.ID,
+		Title:        story.Title,
+		Category:     story.Category,
+		Sources:      sources,
+		ArticleCount: story.ArticleCount,
+		FirstSeenAt:  story.FirstSeenAt,
+		LastSeenAt:   story.LastSeenAt,
+		Canonical:    canonical,
+	}
+}
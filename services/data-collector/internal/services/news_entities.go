@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/entity"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm"
+)
+
+// SyncJobTypeNewsEntities 按当前的资产和渠道重新关联历史新闻的任务
+const SyncJobTypeNewsEntities = "news_entities"
+
+const (
+	// entityRefreshInterval 重新加载资产和渠道、同步资产订阅的间隔，新增资产最迟在该时间后开始关联
+	entityRefreshInterval = 10 * time.Minute
+	// minEntityConfidence 低于该置信度的匹配不保存
+	minEntityConfidence = 0.5
+	// assetNewsConfidence 按资产筛选新闻、计算资产情绪时使用的最低置信度
+	assetNewsConfidence = 0.6
+	// negativeNewsThreshold 情绪分不高于该值的新报道向关联资产发送negative_news事件
+	negativeNewsThreshold = -0.5
+)
+
+// ErrInvalidAssetID 按资产筛选新闻时资产ID不是UUID
+var ErrInvalidAssetID = errors.New("invalid asset id")
+
+// assetEntityMetadata assets.metadata中用于新闻和申报关联的字段
+type assetEntityMetadata struct {
+	Issuer  string   `json:"issuer"`
+	Aliases []string `json:"aliases"`
+	// CIK 发行方在EDGAR的编号，用于关联监管申报
+	CIK string `json:"cik"`
+}
+
+// entityCheckpoint 重新关联任务的检查点，Since为空时处理所有文章
+type entityCheckpoint struct {
+	Since   *time.Time `json:"since,omitempty"`
+	AfterID string     `json:"after_id,omitempty"`
+	Linked  int        `json:"linked"`
+}
+
+// refreshEntities 从资产和渠道重建实体识别器
+func (s *NewsService) refreshEntities(ctx context.Context) {
+	entities, err := s.loadEntities(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to load news entities: %v", err)
+		return
+	}
+
+	linker := entity.NewLinker(entities)
+	s.linkerMu.Lock()
+	s.linker = linker
+	s.linkerLoadedAt = time.Now()
+	s.linkerMu.Unlock()
+	s.logger.Debugf("Loaded %d news entities", len(entities))
+}
+
+// ensureEntities 识别器超过entityRefreshInterval未重建时重新加载，在采集文章前调用
+func (s *NewsService) ensureEntities(ctx context.Context) {
+	s.linkerMu.RLock()
+	stale := time.Since(s.linkerLoadedAt) >= entityRefreshInterval
+	s.linkerMu.RUnlock()
+	if stale {
+		s.refreshEntities(ctx)
+	}
+}
+
+// loadEntities 资产按名称、代码、别名和合约地址识别，发行方来自资产元数据，渠道来自渠道服务的channels表
+func (s *NewsService) loadEntities(ctx context.Context) ([]entity.Entity, error) {
+	var assets []models.Asset
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to query assets: %v", err)
+	}
+
+	var entities []entity.Entity
+	issuers := make(map[string]int)
+	for _, asset := range assets {
+		var metadata assetEntityMetadata
+		if len(asset.Metadata) > 0 {
+			json.Unmarshal(asset.Metadata, &metadata)
+		}
+		var contracts []assetContract
+		if len(asset.Contracts) > 0 {
+			json.Unmarshal(asset.Contracts, &contracts)
+		}
+
+		e := entity.Entity{
+			Type:    entity.TypeAsset,
+			ID:      asset.ID,
+			Name:    asset.Name,
+			Aliases: metadata.Aliases,
+			Symbols: []string{asset.Symbol},
+		}
+		for _, contract := range contracts {
+			e.Addresses = append(e.Addresses, contract.Address)
+		}
+		entities = append(entities, e)
+
+		issuer := strings.TrimSpace(metadata.Issuer)
+		if issuer == "" {
+			continue
+		}
+		key := strings.ToLower(issuer)
+		if i, ok := issuers[key]; ok {
+			entities[i].Assets = append(entities[i].Assets, asset.ID)
+			continue
+		}
+		issuers[key] = len(entities)
+		entities = append(entities, entity.Entity{
+			Type:   entity.TypeIssuer,
+			ID:     key,
+			Name:   issuer,
+			Assets: []string{asset.ID},
+		})
+	}
+
+	var channels []struct {
+		ID          string
+		Name        string
+		DisplayName string
+	}
+	err := s.db.WithContext(ctx).Table("channels").
+		Select("id, name, display_name").
+		Where("is_active = ?", true).
+		Scan(&channels).Error
+	if err != nil {
+		// 渠道服务尚未初始化数据库时只关联资产和发行方
+		s.logger.Warnf("Failed to query channels for news entities: %v", err)
+	}
+	for _, channel := range channels {
+		e := entity.Entity{Type: entity.TypeChannel, ID: channel.ID, Name: channel.DisplayName}
+		if e.Name == "" {
+			e.Name = channel.Name
+		} else if !strings.EqualFold(channel.Name, channel.DisplayName) {
+			e.Aliases = []string{channel.Name}
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+// linkEntities 识别文章提及的实体，识别器尚未加载时返回空
+func (s *NewsService) linkEntities(article *models.NewsArticle) []models.NewsEntity {
+	s.linkerMu.RLock()
+	linker := s.linker
+	s.linkerMu.RUnlock()
+	if linker == nil {
+		return nil
+	}
+
+	body := strings.Join([]string{stringValue(article.Summary), stringValue(article.Content)}, "\n")
+	var links []models.NewsEntity
+	for _, link := range linker.Link(article.Title, body) {
+		if link.Confidence < minEntityConfidence {
+			continue
+		}
+		links = append(links, models.NewsEntity{
+			ArticleID:  article.ID,
+			EntityType: link.Type,
+			EntityID:   link.ID,
+			Name:       link.Name,
+			MatchType:  link.MatchType,
+			Matched:    link.Matched,
+			Confidence: link.Confidence,
+			Mentions:   link.Mentions,
+		})
+	}
+	return links
+}
+
+// linkedAssetIDs 置信度足够用于资产维度统计的资产ID
+func linkedAssetIDs(links []models.NewsEntity) []string {
+	ids := []string{}
+	for _, link := range links {
+		if link.EntityType == entity.TypeAsset && link.Confidence >= assetNewsConfidence {
+			ids = append(ids, link.EntityID)
+		}
+	}
+	return ids
+}
+
+// assetArticles 关联到资产的文章ID子查询
+func (s *NewsService) assetArticles(assetID string) *gorm.DB {
+	return s.db.Model(&models.NewsEntity{}).
+		Select("article_id").
+		Where("entity_type = ? AND entity_id = ? AND confidence >= ?", entity.TypeAsset, assetID, assetNewsConfidence)
+}
+
+// publishNegativeNews 负面的新报道按关联资产发送到asset-events，风险引擎据此重新评级
+func (s *NewsService) publishNegativeNews(ctx context.Context, article *models.NewsArticle, story *models.NewsStory, links []models.NewsEntity) {
+	if article.Sentiment == nil || *article.Sentiment > negativeNewsThreshold {
+		return
+	}
+
+	for _, link := range links {
+		if link.EntityType != entity.TypeAsset || link.Confidence < assetNewsConfidence {
+			continue
+		}
+		message := map[string]interface{}{
+			"type":         "negative_news",
+			"asset_id":     link.EntityID,
+			"article_id":   article.ID,
+			"story_id":     story.ID,
+			"title":        article.Title,
+			"url":          article.URL,
+			"source":       article.Source,
+			"sentiment":    *article.Sentiment,
+			"confidence":   link.Confidence,
+			"published_at": article.PublishedAt.Unix(),
+		}
+		if err := s.kafka.PublishMessage(ctx, "asset-events", link.EntityID, message); err != nil {
+			s.logger.Errorf("Failed to publish negative news event: %v", err)
+		}
+	}
+}
+
+// GetNewsEntities 获取文章关联的实体
+func (s *NewsService) GetNewsEntities(ctx context.Context, articleID string) ([]models.NewsEntity, error) {
+	var links []models.NewsEntity
+	if err := s.db.WithContext(ctx).Where("article_id = ?", articleID).Order("confidence DESC").Find(&links).Error; err != nil {
+		return nil, fmt.Errorf("failed to query news entities: %v", err)
+	}
+	return links, nil
+}
+
+// RelinkEntities 创建重新关联任务，days大于0时只处理最近days天发布的文章
+func (s *NewsService) RelinkEntities(ctx context.Context, days int) (*models.SyncJob, error) {
+	var checkpoint entityCheckpoint
+	if days > 0 {
+		since := time.Now().AddDate(0, 0, -days)
+		checkpoint.Since = &since
+	}
+	config, err := json.Marshal(checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	job := &models.SyncJob{
+		Type:   SyncJobTypeNewsEntities,
+		Status: "pending",
+		Config: config,
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create news entity job: %v", err)
+	}
+
+	s.triggerJob(ctx, newsBackfillJob)
+	return job, nil
+}
+
+// relinkEntities 按ID顺序扫描文章，替换已有的关联，每批完成后写入检查点
+func (s *NewsService) relinkEntities(ctx context.Context, job *models.SyncJob) error {
+	var checkpoint entityCheckpoint
+	if len(job.Config) > 0 {
+		if err := json.Unmarshal(job.Config, &checkpoint); err != nil {
+			return s.finishNewsJob(job, fmt.Errorf("invalid news entity checkpoint: %v", err))
+		}
+	}
+
+	// 使用最新的资产和渠道
+	s.refreshEntities(ctx)
+
+	scan := func() *gorm.DB {
+		query := s.db.WithContext(ctx).Model(&models.NewsArticle{})
+		if checkpoint.Since != nil {
+			query = query.Where("published_at >= ?", *checkpoint.Since)
+		}
+		return query
+	}
+
+	updates := map[string]interface{}{"status": "running", "error_message": nil, "completed_at": nil}
+	if job.StartedAt == nil {
+		updates["started_at"] = time.Now()
+	}
+	if job.RecordsTotal == nil {
+		var total int64
+		if err := scan().Count(&total).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, fmt.Errorf("failed to count news articles: %v", err))
+		}
+		count := int(total)
+		job.RecordsTotal = &count
+		updates["records_total"] = count
+	}
+	if err := s.db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	s.logger.Infof("Linking entities for %d news articles", *job.RecordsTotal)
+
+	processed := job.RecordsProcessed
+	for {
+		query := scan()
+		if checkpoint.AfterID != "" {
+			query = query.Where("id > ?", checkpoint.AfterID)
+		}
+		var articles []models.NewsArticle
+		if err := query.Select("id, title, summary, content").Order("id").Limit(newsJobBatchSize).Find(&articles).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, fmt.Errorf("failed to scan news articles: %v", err))
+		}
+		if len(articles) == 0 {
+			break
+		}
+
+		ids := make([]string, 0, len(articles))
+		var links []models.NewsEntity
+		for i := range articles {
+			ids = append(ids, articles[i].ID)
+			articleLinks := s.linkEntities(&articles[i])
+			if len(articleLinks) > 0 {
+				checkpoint.Linked++
+			}
+			links = append(links, articleLinks...)
+		}
+
+		checkpoint.AfterID = articles[len(articles)-1].ID
+		processed += len(articles)
+		config, err := json.Marshal(checkpoint)
+		if err != nil {
+			return err
+		}
+		progress := 100
+		if *job.RecordsTotal > 0 && processed < *job.RecordsTotal {
+			progress = processed * 100 / *job.RecordsTotal
+		}
+
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("article_id IN ?", ids).Delete(&models.NewsEntity{}).Error; err != nil {
+				return fmt.Errorf("failed to delete news entities: %v", err)
+			}
+			if len(links) > 0 {
+				if err := tx.Create(&links).Error; err != nil {
+					return fmt.Errorf("failed to save news entities: %v", err)
+				}
+			}
+			return tx.Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"config":            config,
+				"progress":          progress,
+				"records_processed": processed,
+				"records_success":   checkpoint.Linked,
+			}).Error
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, err)
+		}
+	}
+
+	s.logger.Infof("News entity job %s completed: %d of %d articles linked", job.ID, checkpoint.Linked, processed)
+	return s.finishNewsJob(job, nil)
+}
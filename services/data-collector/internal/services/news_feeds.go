@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/feed"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DataSourceTypeFeed RSS/Atom订阅源在data_sources表中的类型
+const DataSourceTypeFeed = "feed"
+
+const (
+	// feedPollInterval 检查到期订阅源的间隔
+	feedPollInterval = time.Minute
+	// feedWorkers 同时抓取的订阅源数量
+	feedWorkers = 4
+	// feedMaxBackoff 连续失败时的最长重试间隔
+	feedMaxBackoff = 24 * time.Hour
+)
+
+var (
+	ErrInvalidFeed  = errors.New("invalid feed")
+	ErrFeedNotFound = errors.New("feed not found")
+)
+
+// FeedConfig 订阅源配置，保存在DataSource.Config中；ETag和LastModified是上次响应的校验值
+type FeedConfig struct {
+	feed.Rules
+	Language        string `json:"language,omitempty"`
+	IntervalSeconds int    `json:"interval_seconds,omitempty"`
+	ETag            string `json:"etag,omitempty"`
+	LastModified    string `json:"last_modified,omitempty"`
+}
+
+// FeedInput 添加或更新订阅源的请求，同名订阅源会被覆盖
+type FeedInput struct {
+	feed.Rules
+	Name            string `json:"name" binding:"required"`
+	URL             string `json:"url" binding:"required"`
+	Language        string `json:"language"`
+	IntervalSeconds int    `json:"interval_seconds"`
+}
+
+// FeedSource 订阅源及其采集状态
+type FeedSource struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	URL        string     `json:"url"`
+	IsActive   bool       `json:"is_active"`
+	Config     FeedConfig `json:"config"`
+	LastSyncAt *time.Time `json:"last_sync_at"`
+	NextSyncAt *time.Time `json:"next_sync_at"`
+	ErrorCount int        `json:"error_count"`
+	LastError  *string    `json:"last_error"`
+}
+
+// collectFromRSSFeeds 抓取所有到期的订阅源
+func (s *NewsService) collectFromRSSFeeds(ctx context.Context) error {
+	var sources []models.DataSource
+	err := s.db.WithContext(ctx).
+		Where("type = ? AND is_active = ?", DataSourceTypeFeed, true).
+		Where("next_sync_at IS NULL OR next_sync_at <= ?", time.Now()).
+		Order("next_sync_at ASC NULLS FIRST").
+		Find(&sources).Error
+	if err != nil {
+		return fmt.Errorf("failed to load news feeds: %v", err)
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	s.ensureEntities(ctx)
+
+	sem := make(chan struct{}, feedWorkers)
+	var wg sync.WaitGroup
+	for i := range sources {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(source *models.DataSource) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.collectFeed(ctx, source)
+		}(&sources[i])
+	}
+	wg.Wait()
+	return nil
+}
+
+// collectFeed 条件请求抓取单个订阅源，按规则筛选后进入新闻处理流程
+func (s *NewsService) collectFeed(ctx context.Context, source *models.DataSource) {
+	cfg, err := decodeFeedConfig(source.Config)
+	if err != nil {
+		s.recordFeedError(ctx, source, err)
+		return
+	}
+
+	result, err := feed.Fetch(ctx, s.client, source.URL, cfg.ETag, cfg.LastModified)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.recordFeedError(ctx, source, err)
+		}
+		return
+	}
+
+	matched := 0
+	if !result.NotModified {
+		language := cfg.Language
+		if language == "" {
+			language = result.Feed.Language
+		}
+
+		for _, item := range result.Feed.Items {
+			if item.Link == "" || item.Title == "" {
+				continue
+			}
+			keyword, category, ok := cfg.Match(item)
+			if !ok {
+				continue
+			}
+
+			s.processNewsArticle(ctx, newsItem{
+				Source:      source.Name,
+				Author:      item.Author,
+				Title:       item.Title,
+				Description: item.Summary,
+				URL:         item.Link,
+				Content:     item.Content,
+				Language:    language,
+				Category:    category,
+				PublishedAt: item.Published,
+			}, keyword)
+			matched++
+		}
+	}
+
+	validators, err := json.Marshal(map[string]string{
+		"etag":          result.ETag,
+		"last_modified": result.LastModified,
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to encode validators for feed %s: %v", source.Name, err)
+		return
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&models.DataSource{}).Where("id = ?", source.ID).Updates(map[string]interface{}{
+		"config":       gorm.Expr("COALESCE(config, '{}'::jsonb) || ?::jsonb", string(validators)),
+		"last_sync_at": now,
+		"next_sync_at": now.Add(s.feedInterval(cfg)),
+		"error_count":  0,
+		"last_error":   nil,
+	}).Error
+	if err != nil {
+		s.logger.Errorf("Failed to update feed %s: %v", source.Name, err)
+		return
+	}
+
+	if result.NotModified {
+		s.logger.Debugf("Feed %s not modified", source.Name)
+	} else {
+		s.logger.Debugf("Matched %d of %d items from feed %s", matched, len(result.Feed.Items), source.Name)
+	}
+}
+
+// recordFeedError 记录抓取失败，按连续失败次数指数退避
+func (s *NewsService) recordFeedError(ctx context.Context, source *models.DataSource, cause error) {
+	s.logger.Errorf("Failed to collect feed %s: %v", source.Name, cause)
+
+	cfg, _ := decodeFeedConfig(source.Config)
+	backoff := s.feedInterval(cfg)
+	for i := 0; i < source.ErrorCount && backoff < feedMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > feedMaxBackoff {
+		backoff = feedMaxBackoff
+	}
+
+	message := cause.Error()
+	err := s.db.WithContext(ctx).Model(&models.DataSource{}).Where("id = ?", source.ID).Updates(map[string]interface{}{
+		"error_count":  gorm.Expr("error_count + 1"),
+		"last_error":   message,
+		"next_sync_at": time.Now().Add(backoff),
+	}).Error
+	if err != nil {
+		s.logger.Errorf("Failed to record error for feed %s: %v", source.Name, err)
+	}
+}
+
+func (s *NewsService) feedInterval(cfg FeedConfig) time.Duration {
+	if cfg.IntervalSeconds > 0 {
+		return time.Duration(cfg.IntervalSeconds) * time.Second
+	}
+	return time.Duration(s.config.NewsFeedInterval) * time.Second
+}
+
+func decodeFeedConfig(raw []byte) (FeedConfig, error) {
+	var cfg FeedConfig
+	if len(raw) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid feed config: %v", err)
+	}
+	return cfg, nil
+}
+
+func toFeedSource(source *models.DataSource) FeedSource {
+	cfg, _ := decodeFeedConfig(source.Config)
+	return FeedSource{
+		ID:         source.ID,
+		Name:       source.Name,
+		URL:        source.URL,
+		IsActive:   source.IsActive,
+		Config:     cfg,
+		LastSyncAt: source.LastSyncAt,
+		NextSyncAt: source.NextSyncAt,
+		ErrorCount: source.ErrorCount,
+		LastError:  source.LastError,
+	}
+}
+
+// AddFeed 添加或更新订阅源，先抓取一次确认地址返回的是RSS或Atom
+func (s *NewsService) AddFeed(ctx context.Context, input FeedInput) (*FeedSource, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidFeed)
+	}
+	parsed, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) url", ErrInvalidFeed)
+	}
+	if input.IntervalSeconds < 0 {
+		return nil, fmt.Errorf("%w: interval_seconds must not be negative", ErrInvalidFeed)
+	}
+
+	result, err := feed.Fetch(ctx, s.client, parsed.String(), "", "")
+	if err != nil {
+		if errors.Is(err, feed.ErrUnknownFormat) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFeed, err)
+		}
+		return nil, fmt.Errorf("failed to fetch feed: %v", err)
+	}
+
+	// 规则或地址变化后需要完整抓取一次，因此不保存本次的校验值
+	cfg := FeedConfig{
+		Rules:           input.Rules,
+		Language:        strings.ToLower(strings.TrimSpace(input.Language)),
+		IntervalSeconds: input.IntervalSeconds,
+	}
+	if cfg.Language == "" {
+		cfg.Language = result.Feed.Language
+	}
+	config, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	source := models.DataSource{
+		Name:     name,
+		Type:     DataSourceTypeFeed,
+		URL:      parsed.String(),
+		Config:   config,
+		IsActive: true,
+	}
+	// 只覆盖同名的订阅源，不改动其他类型的数据源
+	saved := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "data_sources", Name: "type"}, Value: DataSourceTypeFeed},
+		}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"url":          gorm.Expr("EXCLUDED.url"),
+			"config":       gorm.Expr("EXCLUDED.config"),
+			"is_active":    true,
+			"next_sync_at": nil,
+			"error_count":  0,
+			"last_error":   nil,
+			"updated_at":   time.Now(),
+		}),
+	}, clause.Returning{}).Create(&source)
+	if saved.Error != nil {
+		return nil, fmt.Errorf("failed to save feed: %v", saved.Error)
+	}
+	if saved.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: data source %s already exists", ErrInvalidFeed, name)
+	}
+
+	s.triggerJob(ctx, newsFeedsJob)
+
+	view := toFeedSource(&source)
+	return &view, nil
+}
+
+// ListFeeds 列出所有订阅源及采集状态
+func (s *NewsService) ListFeeds(ctx context.Context) ([]FeedSource, error) {
+	var sources []models.DataSource
+	if err := s.db.WithContext(ctx).Where("type = ?", DataSourceTypeFeed).Order("name ASC").Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feeds: %v", err)
+	}
+
+	feeds := make([]FeedSource, 0, len(sources))
+	for i := range sources {
+		feeds = append(feeds, toFeedSource(&sources[i]))
+	}
+	return feeds, nil
+}
+
+// RemoveFeed 停用订阅源，已采集的文章保留
+func (s *NewsService) RemoveFeed(ctx context.Context, id string) error {
+	result := s.db.WithContext(ctx).Model(&models.DataSource{}).
+		Where("id = ? AND type = ?", id, DataSourceTypeFeed).
+		Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove feed: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrFeedNotFound
+	}
+	return nil
+}
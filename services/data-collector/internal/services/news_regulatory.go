@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/entity"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/regulatory"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 监管事件状态
+const (
+	RegulatoryStatusPending   = "pending_review"
+	RegulatoryStatusPublished = "published"
+	RegulatoryStatusRejected  = "rejected"
+)
+
+const (
+	// regulatoryTopic 监管事件的Kafka主题，由风险引擎和渠道服务消费
+	regulatoryTopic = "regulatory-events"
+	// regulatoryPublishConfidence 综合置信度不低于该值且关联到资产或渠道的事件直接发布
+	regulatoryPublishConfidence = 0.7
+	// regulatoryReviewConfidence 综合置信度低于该值的事件不保存
+	regulatoryReviewConfidence = 0.35
+)
+
+var (
+	ErrRegulatoryEventNotFound = errors.New("regulatory event not found")
+	ErrInvalidRegulatoryQuery  = errors.New("invalid regulatory event query")
+	ErrInvalidRegulatoryReview = errors.New("invalid regulatory review")
+)
+
+// RegulatoryEventQuery 监管事件筛选条件
+type RegulatoryEventQuery struct {
+	Status    string
+	EventType string
+	AssetID   string
+	Page      int
+	Limit     int
+}
+
+// RegulatoryReview 分析师的复核结果，批准时可以修正严重程度和关联的资产、渠道
+type RegulatoryReview struct {
+	Action     string   `json:"action" binding:"required"` // approve, reject
+	Reviewer   string   `json:"reviewer" binding:"required"`
+	Note       string   `json:"note"`
+	Severity   string   `json:"severity"`
+	AssetIDs   []string `json:"asset_ids"`
+	ChannelIDs []string `json:"channel_ids"`
+}
+
+// detectRegulatoryEvent 识别新文章中的监管和信用事件
+//
+// 不只处理regulation分类的文章：违约、暂停赎回等事件常被归入stablecoin或treasury分类。
+// 同一报道的同类事件只保留一条，转载的置信度更高时更新待复核的事件
+func (s *NewsService) detectRegulatoryEvent(ctx context.Context, article *models.NewsArticle, story *models.NewsStory, links []models.NewsEntity) {
+	body := strings.Join([]string{stringValue(article.Summary), stringValue(article.Content)}, "\n")
+	classification, ok := regulatory.Classify(article.Title, body)
+	if !ok {
+		return
+	}
+
+	sourceConfidence := regulatory.SourceConfidence(article.URL)
+	confidence := math.Round(classification.Confidence*sourceConfidence*100) / 100
+	if confidence < regulatoryReviewConfidence {
+		s.logger.Debugf("Ignoring %s signal in %s (confidence %.2f)", classification.Type, article.URL, confidence)
+		return
+	}
+
+	assetIDs := linkedAssetIDs(links)
+	channelIDs := linkedChannelIDs(links)
+	status := RegulatoryStatusPending
+	if confidence >= regulatoryPublishConfidence && len(assetIDs)+len(channelIDs) > 0 {
+		status = RegulatoryStatusPublished
+	}
+
+	var existing models.RegulatoryEvent
+	err := s.db.WithContext(ctx).
+		Where("story_id = ? AND event_type = ?", story.ID, classification.Type).
+		First(&existing).Error
+	switch {
+	case err == nil:
+		s.upgradeRegulatoryEvent(ctx, &existing, article, classification, sourceConfidence, confidence, assetIDs, channelIDs, status)
+		return
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		s.logger.Errorf("Failed to query regulatory events: %v", err)
+		return
+	}
+
+	event := &models.RegulatoryEvent{
+		ArticleID: article.ID,
+		StoryID:   &story.ID,
+		EventType: classification.Type,
+		Severity:  classification.Severity,
+		Status:    status,
+	}
+	setRegulatoryArticle(event, article, classification, sourceConfidence, confidence)
+	event.AssetIDs, _ = json.Marshal(assetIDs)
+	event.ChannelIDs, _ = json.Marshal(channelIDs)
+	if status == RegulatoryStatusPublished {
+		now := time.Now()
+		event.PublishedAt = &now
+	}
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		s.logger.Errorf("Failed to save regulatory event: %v", err)
+		return
+	}
+
+	s.logger.Infof("Detected %s %s event (%s, confidence %.2f): %s", classification.Severity, classification.Type, status, confidence, article.Title)
+	if status == RegulatoryStatusPublished {
+		s.publishRegulatoryEvent(ctx, event)
+	}
+}
+
+// upgradeRegulatoryEvent 同一报道的转载置信度更高时替换待复核事件的来源文章，并合并关联的资产和渠道；
+// 已发布或已驳回的事件不再变更
+func (s *NewsService) upgradeRegulatoryEvent(ctx context.Context, event *models.RegulatoryEvent, article *models.NewsArticle, classification regulatory.Classification, sourceConfidence, confidence float64, assetIDs, channelIDs []string, status string) {
+	if event.Status != RegulatoryStatusPending || confidence <= event.Confidence {
+		return
+	}
+
+	assetIDs = mergeIDs(event.AssetIDs, assetIDs)
+	channelIDs = mergeIDs(event.ChannelIDs, channelIDs)
+	if confidence >= regulatoryPublishConfidence && len(assetIDs)+len(channelIDs) > 0 {
+		status = RegulatoryStatusPublished
+	}
+
+	event.ArticleID = article.ID
+	if regulatory.SeverityRank(classification.Severity) > regulatory.SeverityRank(event.Severity) {
+		event.Severity = classification.Severity
+	}
+	setRegulatoryArticle(event, article, classification, sourceConfidence, confidence)
+	event.AssetIDs, _ = json.Marshal(assetIDs)
+	event.ChannelIDs, _ = json.Marshal(channelIDs)
+	event.Status = status
+	if status == RegulatoryStatusPublished {
+		now := time.Now()
+		event.PublishedAt = &now
+	}
+	if err := s.db.WithContext(ctx).Save(event).Error; err != nil {
+		s.logger.Errorf("Failed to update regulatory event: %v", err)
+		return
+	}
+
+	if status == RegulatoryStatusPublished {
+		s.publishRegulatoryEvent(ctx, event)
+	}
+}
+
+// setRegulatoryArticle 记录事件的来源文章和识别结果
+func setRegulatoryArticle(event *models.RegulatoryEvent, article *models.NewsArticle, classification regulatory.Classification, sourceConfidence, confidence float64) {
+	event.Title = article.Title
+	event.URL = article.URL
+	event.Source = article.Source
+	event.ArticlePublishedAt = article.PublishedAt
+	event.Jurisdictions, _ = json.Marshal(classification.Jurisdictions)
+	event.Regulators, _ = json.Marshal(classification.Regulators)
+	event.Matched, _ = json.Marshal(classification.Matched)
+	event.ClassifierConfidence = classification.Confidence
+	event.SourceConfidence = sourceConfidence
+	event.Confidence = confidence
+}
+
+// linkedChannelIDs 置信度足够的渠道ID
+func linkedChannelIDs(links []models.NewsEntity) []string {
+	ids := []string{}
+	for _, link := range links {
+		if link.EntityType == entity.TypeChannel && link.Confidence >= assetNewsConfidence {
+			ids = append(ids, link.EntityID)
+		}
+	}
+	return ids
+}
+
+// mergeIDs 合并jsonb数组中已有的ID和新的ID
+func mergeIDs(existing []byte, ids []string) []string {
+	merged := []string{}
+	if len(existing) > 0 {
+		json.Unmarshal(existing, &merged)
+	}
+	for _, id := range ids {
+		if !contains(merged, id) {
+			merged = append(merged, id)
+		}
+	}
+	return merged
+}
+
+// publishRegulatoryEvent 发送regulatory_event，以事件ID为键
+func (s *NewsService) publishRegulatoryEvent(ctx context.Context, event *models.RegulatoryEvent) {
+	var jurisdictions, regulators, assetIDs, channelIDs []string
+	json.Unmarshal(event.Jurisdictions, &jurisdictions)
+	json.Unmarshal(event.Regulators, &regulators)
+	json.Unmarshal(event.AssetIDs, &assetIDs)
+	json.Unmarshal(event.ChannelIDs, &channelIDs)
+
+	message := map[string]interface{}{
+		"type":              "regulatory_event",
+		"id":                event.ID,
+		"event_type":        event.EventType,
+		"severity":          event.Severity,
+		"confidence":        event.Confidence,
+		"source_confidence": event.SourceConfidence,
+		"jurisdictions":     jurisdictions,
+		"regulators":        regulators,
+		"asset_ids":         assetIDs,
+		"channel_ids":       channelIDs,
+		"article_id":        event.ArticleID,
+		"story_id":          event.StoryID,
+		"title":             event.Title,
+		"url":               event.URL,
+		"source":            event.Source,
+		"reviewed":          event.ReviewedAt != nil,
+		"published_at":      event.ArticlePublishedAt.Unix(),
+	}
+	if err := s.kafka.PublishMessage(ctx, regulatoryTopic, event.ID, message); err != nil {
+		s.logger.Errorf("Failed to publish regulatory event: %v", err)
+	}
+}
+
+// ListRegulatoryEvents 按状态、事件类型和资产筛选监管事件，按识别时间倒序
+func (s *NewsService) ListRegulatoryEvents(ctx context.Context, q RegulatoryEventQuery) ([]models.RegulatoryEvent, int, error) {
+	switch q.Status {
+	case "", RegulatoryStatusPending, RegulatoryStatusPublished, RegulatoryStatusRejected:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown status %s", ErrInvalidRegulatoryQuery, q.Status)
+	}
+	if q.AssetID != "" && !uuidPattern.MatchString(q.AssetID) {
+		return nil, 0, ErrInvalidAssetID
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.RegulatoryEvent{})
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.EventType != "" {
+		query = query.Where("event_type = ?", q.EventType)
+	}
+	if q.AssetID != "" {
+		assetIDs, _ := json.Marshal([]string{q.AssetID})
+		query = query.Where("asset_ids @> ?", string(assetIDs))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count regulatory events: %v", err)
+	}
+	events := []models.RegulatoryEvent{}
+	err := query.Order("created_at DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&events).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query regulatory events: %v", err)
+	}
+	return events, int(total), nil
+}
+
+// ReviewRegulatoryEvent 复核待处理的监管事件，批准后立即发布
+func (s *NewsService) ReviewRegulatoryEvent(ctx context.Context, id string, review RegulatoryReview) (*models.RegulatoryEvent, error) {
+	switch {
+	case review.Action != "approve" && review.Action != "reject":
+		return nil, fmt.Errorf("%w: action must be approve or reject", ErrInvalidRegulatoryReview)
+	case strings.TrimSpace(review.Reviewer) == "":
+		return nil, fmt.Errorf("%w: reviewer is required", ErrInvalidRegulatoryReview)
+	case review.Severity != "" && regulatory.SeverityRank(review.Severity) < 0:
+		return nil, fmt.Errorf("%w: unknown severity %s", ErrInvalidRegulatoryReview, review.Severity)
+	}
+	for _, assetID := range review.AssetIDs {
+		if !uuidPattern.MatchString(assetID) {
+			return nil, ErrInvalidAssetID
+		}
+	}
+	if !uuidPattern.MatchString(id) {
+		return nil, ErrRegulatoryEventNotFound
+	}
+
+	var event models.RegulatoryEvent
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&event).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRegulatoryEventNotFound
+			}
+			return fmt.Errorf("failed to query regulatory event: %v", err)
+		}
+		if event.Status != RegulatoryStatusPending {
+			return fmt.Errorf("%w: event is already %s", ErrInvalidRegulatoryReview, event.Status)
+		}
+
+		now := time.Now()
+		reviewer := strings.TrimSpace(review.Reviewer)
+		event.ReviewedBy = &reviewer
+		event.ReviewedAt = &now
+		if review.Note != "" {
+			event.ReviewNote = &review.Note
+		}
+		if review.Action == "reject" {
+			event.Status = RegulatoryStatusRejected
+			return tx.Save(&event).Error
+		}
+
+		if review.Severity != "" {
+			event.Severity = review.Severity
+		}
+		if review.AssetIDs != nil {
+			event.AssetIDs, _ = json.Marshal(review.AssetIDs)
+		}
+		if review.ChannelIDs != nil {
+			event.ChannelIDs, _ = json.Marshal(review.ChannelIDs)
+		}
+		event.Status = RegulatoryStatusPublished
+		event.PublishedAt = &now
+		return tx.Save(&event).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Status == RegulatoryStatusPublished {
+		s.publishRegulatoryEvent(ctx, &event)
+	}
+	s.logger.Infof("Regulatory event %s %s by %s", event.ID, event.Status, *event.ReviewedBy)
+	return &event, nil
+}
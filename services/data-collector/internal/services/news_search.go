@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/newsdict"
+	"gorm.io/gorm"
+)
+
+const (
+	// searchMaxQueryLength 检索词的最大长度
+	searchMaxQueryLength = 256
+	// searchFacetLimit 每个分面最多返回的取值数
+	searchFacetLimit = 20
+
+	// 命中词用<mark>标出，正文片段最多取两段
+	searchTitleHeadline   = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	searchSnippetHeadline = `StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=35, MinWords=15, FragmentDelimiter=" ... "`
+)
+
+// ErrInvalidSearchQuery 新闻检索参数无效
+var ErrInvalidSearchQuery = errors.New("invalid search query")
+
+// NewsSearchQuery 新闻检索条件
+//
+// Text使用websearch语法：空格分隔的词须同时出现，"引号"内为短语，or表示或，-前缀表示排除
+type NewsSearchQuery struct {
+	Text         string
+	Category     string
+	Source       string
+	Language     string
+	AssetID      string
+	From         *time.Time
+	To           *time.Time
+	MinRelevance float64
+	Sort         string // relevance（默认）, date
+	Page         int
+	Limit        int
+}
+
+// NewsSearchHit 检索命中的文章，不含正文；Rank为0到1之间的匹配度
+type NewsSearchHit struct {
+	models.NewsArticle
+	Rank           float64 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+// SearchFacet 分面的一个取值及命中文章数
+type SearchFacet struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// NewsSearchResult 检索结果，分面统计基于全部命中文章而非当前页
+type NewsSearchResult struct {
+	Hits   []NewsSearchHit          `json:"hits"`
+	Total  int                      `json:"total"`
+	Facets map[string][]SearchFacet `json:"facets"`
+}
+
+// SearchNews 按migrateNewsSearch创建的search_vector全文检索新闻
+func (s *NewsService) SearchNews(ctx context.Context, q NewsSearchQuery) (*NewsSearchResult, error) {
+	q, order, err := validateSearchQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	search := func() *gorm.DB {
+		return s.searchFilter(s.db.WithContext(ctx), q)
+	}
+
+	var total int64
+	if err := search().Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %v", err)
+	}
+
+	result := &NewsSearchResult{Hits: []NewsSearchHit{}, Total: int(total), Facets: map[string][]SearchFacet{}}
+	if total == 0 {
+		result.Facets["category"] = []SearchFacet{}
+		result.Facets["source"] = []SearchFacet{}
+		return result, nil
+	}
+
+	if err := searchHits(search(), q, order).Scan(&result.Hits).Error; err != nil {
+		return nil, fmt.Errorf("failed to search news: %v", err)
+	}
+
+	facets := map[string]string{
+		"category": "COALESCE(news_articles.category, 'general')",
+		"source":   "news_articles.source",
+	}
+	for name, column := range facets {
+		values := []SearchFacet{}
+		if err := searchFacet(search(), column).Scan(&values).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s facet: %v", name, err)
+		}
+		result.Facets[name] = values
+	}
+	return result, nil
+}
+
+// validateSearchQuery 校验检索条件，返回去除首尾空白后的条件和排序子句
+func validateSearchQuery(q NewsSearchQuery) (NewsSearchQuery, string, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	switch {
+	case q.Text == "":
+		return q, "", fmt.Errorf("%w: q is required", ErrInvalidSearchQuery)
+	case len(q.Text) > searchMaxQueryLength:
+		return q, "", fmt.Errorf("%w: q must be at most %d characters", ErrInvalidSearchQuery, searchMaxQueryLength)
+	case q.From != nil && q.To != nil && !q.From.Before(*q.To):
+		return q, "", fmt.Errorf("%w: from must be before to", ErrInvalidSearchQuery)
+	case q.MinRelevance < 0 || q.MinRelevance > 1:
+		return q, "", fmt.Errorf("%w: min_relevance must be between 0 and 1", ErrInvalidSearchQuery)
+	case q.AssetID != "" && !uuidPattern.MatchString(q.AssetID):
+		return q, "", ErrInvalidAssetID
+	}
+
+	switch q.Sort {
+	case "", "relevance":
+		return q, "rank DESC, news_articles.published_at DESC", nil
+	case "date":
+		return q, "news_articles.published_at DESC", nil
+	default:
+		return q, "", fmt.Errorf("%w: sort must be relevance or date", ErrInvalidSearchQuery)
+	}
+}
+
+// searchFilter 检索词和筛选条件，计数、分页和分面统计共用
+func (s *NewsService) searchFilter(db *gorm.DB, q NewsSearchQuery) *gorm.DB {
+	query := db.
+		Table(fmt.Sprintf("news_articles, websearch_to_tsquery(%s, ?) AS query", searchConfig(q)), q.Text).
+		Where("news_articles.search_vector @@ query")
+	if q.Category != "" {
+		query = query.Where("news_articles.category = ?", q.Category)
+	}
+	if q.Source != "" {
+		query = query.Where("news_articles.source = ?", q.Source)
+	}
+	if q.Language != "" {
+		query = query.Where("news_articles.language = ?", q.Language)
+	}
+	if q.AssetID != "" {
+		query = query.Where("news_articles.id IN (?)", s.assetArticles(q.AssetID))
+	}
+	if q.From != nil {
+		query = query.Where("news_articles.published_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("news_articles.published_at < ?", *q.To)
+	}
+	if q.MinRelevance > 0 {
+		query = query.Where("news_articles.relevance >= ?", q.MinRelevance)
+	}
+	return query
+}
+
+// searchConfig 检索词使用的全文检索配置，须与search_vector生成列一致
+// 指定语言时使用该语言的配置，检索词只解析一次，可以使用GIN索引；否则每篇文章按自身语言解析检索词
+func searchConfig(q NewsSearchQuery) string {
+	if q.Language != "" {
+		return "'" + newsdict.SearchConfig(q.Language) + "'::regconfig"
+	}
+	return newsdict.SearchConfigSQL("news_articles.language")
+}
+
+// searchHits 当前页的命中文章
+// ts_headline不参与排序，PostgreSQL在分页之后才计算，只处理当前页的文章
+func searchHits(query *gorm.DB, q NewsSearchQuery, order string) *gorm.DB {
+	config := searchConfig(q)
+	return query.
+		Select(`news_articles.id, news_articles.title, news_articles.summary, news_articles.url, news_articles.source,
+			news_articles.author, news_articles.category, news_articles.tags, news_articles.language, news_articles.sentiment,
+			news_articles.relevance, news_articles.published_at, news_articles.story_id, news_articles.created_at, news_articles.updated_at,
+			ts_rank_cd(news_articles.search_vector, query, 32) AS rank,
+			ts_headline(`+config+`, news_articles.title, query, ?) AS title_highlight,
+			ts_headline(`+config+`, COALESCE(news_articles.summary, '') || ' ' || COALESCE(news_articles.content, ''), query, ?) AS snippet`,
+			searchTitleHeadline, searchSnippetHeadline).
+		Order(order).
+		Offset((q.Page - 1) * q.Limit).
+		Limit(q.Limit)
+}
+
+// searchFacet 按列统计全部命中文章，取数量最多的取值
+func searchFacet(query *gorm.DB, column string) *gorm.DB {
+	return query.
+		Select(column + " AS value, COUNT(*) AS count").
+		Group(column).
+		Order("count DESC, value").
+		Limit(searchFacetLimit)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/newsdict"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDryRunNewsService 只生成SQL不执行的NewsService，检索语句依赖PostgreSQL全文检索，无法在SQLite中运行
+func newDryRunNewsService(t *testing.T) *NewsService {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true, Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return &NewsService{db: db}
+}
+
+// buildSQL 返回查询生成的SQL和参数
+func buildSQL(query *gorm.DB) (string, []interface{}) {
+	statement := query.Find(&[]NewsSearchHit{}).Statement
+	return statement.SQL.String(), statement.Vars
+}
+
+func TestValidateSearchQuery(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	q, order, err := validateSearchQuery(NewsSearchQuery{Text: "  tokenized treasury  ", From: &from, To: &to, MinRelevance: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "tokenized treasury", q.Text)
+	assert.Equal(t, "rank DESC, news_articles.published_at DESC", order)
+
+	_, order, err = validateSearchQuery(NewsSearchQuery{Text: "rwa", Sort: "date"})
+	require.NoError(t, err)
+	assert.Equal(t, "news_articles.published_at DESC", order)
+
+	invalid := map[string]NewsSearchQuery{
+		"empty text":         {Text: "   "},
+		"long text":          {Text: strings.Repeat("a", searchMaxQueryLength+1)},
+		"from equals to":     {Text: "rwa", From: &from, To: &from},
+		"from after to":      {Text: "rwa", From: &to, To: &from},
+		"negative relevance": {Text: "rwa", MinRelevance: -0.1},
+		"relevance above 1":  {Text: "rwa", MinRelevance: 1.5},
+		"unknown sort":       {Text: "rwa", Sort: "popularity"},
+	}
+	for name, query := range invalid {
+		_, _, err := validateSearchQuery(query)
+		assert.ErrorIs(t, err, ErrInvalidSearchQuery, name)
+	}
+
+	_, _, err = validateSearchQuery(NewsSearchQuery{Text: "rwa", AssetID: "not-a-uuid"})
+	assert.ErrorIs(t, err, ErrInvalidAssetID)
+}
+
+func TestSearchFilterParameters(t *testing.T) {
+	service := newDryRunNewsService(t)
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	sql, vars := buildSQL(service.searchFilter(service.db, NewsSearchQuery{
+		Text:         `"tokenized treasury" -bitcoin`,
+		Category:     "regulation",
+		Language:     "en",
+		From:         &from,
+		To:           &to,
+		MinRelevance: 0.4,
+	}))
+
+	// 指定语言时使用该语言的检索配置
+	assert.Contains(t, sql, "websearch_to_tsquery('english'::regconfig, ?) AS query")
+	assert.Contains(t, sql, "news_articles.search_vector @@ query")
+	// 起始时间包含，结束时间不包含
+	assert.Contains(t, sql, "news_articles.published_at >= ?")
+	assert.Contains(t, sql, "news_articles.published_at < ?")
+	assert.Contains(t, sql, "news_articles.relevance >= ?")
+	assert.NotContains(t, sql, "news_articles.source")
+	assert.Equal(t, []interface{}{`"tokenized treasury" -bitcoin`, "regulation", "en", from, to, 0.4}, vars)
+}
+
+func TestSearchFilterOmitsUnsetBounds(t *testing.T) {
+	service := newDryRunNewsService(t)
+
+	sql, vars := buildSQL(service.searchFilter(service.db, NewsSearchQuery{Text: "rwa"}))
+
+	assert.NotContains(t, sql, "published_at")
+	assert.NotContains(t, sql, "relevance")
+	assert.Equal(t, []interface{}{"rwa"}, vars)
+
+	// 未指定语言时按每篇文章的语言解析检索词
+	assert.Contains(t, sql, "websearch_to_tsquery("+newsdict.SearchConfigSQL("news_articles.language")+", ?) AS query")
+}
+
+func TestSearchConfigFollowsLanguage(t *testing.T) {
+	service := newDryRunNewsService(t)
+	q := NewsSearchQuery{Text: "代币化 国债", Language: "zh", Page: 1, Limit: 10}
+
+	sql, _ := buildSQL(searchHits(service.searchFilter(service.db, q), q, "rank DESC"))
+
+	// 中文没有词干分析配置，检索词和高亮都使用simple
+	assert.Contains(t, sql, "websearch_to_tsquery('simple'::regconfig, ?) AS query")
+	assert.Contains(t, sql, "ts_headline('simple'::regconfig, news_articles.title, query, ?)")
+	assert.NotContains(t, sql, "english")
+}
+
+func TestSearchHitsPagination(t *testing.T) {
+	service := newDryRunNewsService(t)
+	q := NewsSearchQuery{Text: "rwa", Page: 3, Limit: 20}
+
+	sql, vars := buildSQL(searchHits(service.searchFilter(service.db, q), q, "news_articles.published_at DESC"))
+
+	assert.Contains(t, sql, "ORDER BY news_articles.published_at DESC")
+	assert.Contains(t, sql, "LIMIT 20 OFFSET 40")
+	assert.Contains(t, sql, "ts_rank_cd(news_articles.search_vector, query, 32) AS rank")
+	assert.Equal(t, []interface{}{searchTitleHeadline, searchSnippetHeadline, "rwa"}, vars)
+
+	// 第一页不需要偏移
+	q.Page = 1
+	sql, _ = buildSQL(searchHits(service.searchFilter(service.db, q), q, "news_articles.published_at DESC"))
+	assert.Contains(t, sql, "LIMIT 20")
+	assert.NotContains(t, sql, "OFFSET")
+}
+
+func TestSearchFacetGroupsAllHits(t *testing.T) {
+	service := newDryRunNewsService(t)
+
+	sql, _ := buildSQL(searchFacet(service.searchFilter(service.db, NewsSearchQuery{Text: "rwa"}), "COALESCE(news_articles.category, 'general')"))
+	assert.Contains(t, sql, "GROUP BY COALESCE(news_articles.category, 'general')")
+
+	sql, vars := buildSQL(searchFacet(service.searchFilter(service.db, NewsSearchQuery{Text: "rwa", Source: "coindesk"}), "news_articles.source"))
+
+	assert.Contains(t, sql, "news_articles.source AS value, COUNT(*) AS count")
+	// 按列分组，GROUP BY "1"会被当作列名
+	assert.Contains(t, sql, "GROUP BY `news_articles`.`source`")
+	assert.Contains(t, sql, "ORDER BY count DESC, value")
+	assert.Contains(t, sql, "LIMIT 20")
+	assert.Equal(t, []interface{}{"rwa", "coindesk"}, vars)
+}
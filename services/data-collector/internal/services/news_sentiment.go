@@ -0,0 +1,405 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/sentiment"
+	"gorm.io/gorm"
+)
+
+// SyncJobTypeNewsSentiment 为历史新闻补充或重新计算情绪分的任务
+const SyncJobTypeNewsSentiment = "news_sentiment"
+
+// MetricSentimentMomentum 资产新闻情绪动量，写入metric_data供风险引擎使用
+const MetricSentimentMomentum = "news_sentiment_momentum"
+
+const (
+	sentimentMetricSource = "news_sentiment"
+
+	// newsJobBatchSize 回填任务每批处理的文章数
+	newsJobBatchSize = 200
+	// sentimentMetricsInterval 计算资产情绪动量的间隔
+	sentimentMetricsInterval = time.Hour
+	// newsJobPollInterval 检查待处理回填任务的间隔
+	newsJobPollInterval = 5 * time.Minute
+)
+
+// sentimentMomentum 近3天相对近14天的情绪变化，样本少于5篇时不计算
+var sentimentMomentum = sentiment.Momentum{Short: 3 * 24 * time.Hour, Long: 14 * 24 * time.Hour, MinPoints: 5}
+
+// ErrInvalidSentimentQuery 情绪聚合查询参数无效
+var ErrInvalidSentimentQuery = errors.New("invalid sentiment query")
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// sentimentCheckpoint 回填任务的检查点，All为false时只处理没有情绪分的文章
+type sentimentCheckpoint struct {
+	All     bool   `json:"all"`
+	AfterID string `json:"after_id,omitempty"`
+	Scored  int    `json:"scored"`
+}
+
+// SentimentQuery 情绪聚合查询条件，Asset为资产ID或代码
+type SentimentQuery struct {
+	Asset    string
+	Category string
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+// SentimentBucket 一个时间段内某分类新闻的情绪统计
+type SentimentBucket struct {
+	Bucket   time.Time `json:"bucket"`
+	Category string    `json:"category"`
+	Articles int       `json:"articles"`
+	Average  float64   `json:"average"`
+	Positive int       `json:"positive"`
+	Negative int       `json:"negative"`
+}
+
+// SentimentSummary 情绪聚合结果，Momentum基于查询截止时间之前的文章计算
+type SentimentSummary struct {
+	Asset    *models.Asset             `json:"asset,omitempty"`
+	Category string                    `json:"category,omitempty"`
+	Interval string                    `json:"interval"`
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Buckets  []SentimentBucket         `json:"buckets"`
+	Momentum *sentiment.MomentumResult `json:"momentum"`
+}
+
+// newSentimentScorer 配置了外部模型时优先使用模型，模型不可用时回退到金融词典
+func newSentimentScorer(modelURL string, client *http.Client) sentiment.Scorer {
+	lexicon := sentiment.NewLexicon()
+	if modelURL == "" {
+		return lexicon
+	}
+	return sentiment.Fallback{
+		Primary:   sentiment.HTTPModel{URL: modelURL, Client: client},
+		Secondary: lexicon,
+	}
+}
+
+// scoreSentiment 对标题、摘要和正文整体打分
+func (s *NewsService) scoreSentiment(ctx context.Context, title, summary, content string) *float64 {
+	text := strings.Join([]string{title, summary, content}, ".\n")
+	score, err := s.sentiment.Score(ctx, text)
+	if err != nil {
+		s.logger.Warnf("Failed to score news sentiment: %v", err)
+		return nil
+	}
+	score = sentiment.Clamp(score)
+	return &score
+}
+
+// runNewsJobs 依次执行情绪和实体关联的回填任务，中断的任务下次从检查点继续
+func (s *NewsService) runNewsJobs(ctx context.Context) error {
+	var jobs []models.SyncJob
+	if err := s.db.WithContext(ctx).
+		Where("type IN ? AND status IN ?", []string{SyncJobTypeNewsSentiment, SyncJobTypeNewsEntities}, []string{"pending", "running"}).
+		Order("created_at").
+		Find(&jobs).Error; err != nil {
+		return fmt.Errorf("failed to load news jobs: %v", err)
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var err error
+		switch jobs[i].Type {
+		case SyncJobTypeNewsSentiment:
+			err = s.rescoreSentiment(ctx, &jobs[i])
+		case SyncJobTypeNewsEntities:
+			err = s.relinkEntities(ctx, &jobs[i])
+		}
+		if err != nil && ctx.Err() == nil {
+			s.logger.Errorf("News job %s (%s) failed: %v", jobs[i].ID, jobs[i].Type, err)
+		}
+	}
+	return nil
+}
+
+// rescoreSentiment 按ID顺序扫描文章并写入情绪分，每批完成后写入检查点
+func (s *NewsService) rescoreSentiment(ctx context.Context, job *models.SyncJob) error {
+	var checkpoint sentimentCheckpoint
+	if len(job.Config) > 0 {
+		if err := json.Unmarshal(job.Config, &checkpoint); err != nil {
+			return s.finishNewsJob(job, fmt.Errorf("invalid sentiment checkpoint: %v", err))
+		}
+	}
+
+	scan := func() *gorm.DB {
+		query := s.db.WithContext(ctx).Model(&models.NewsArticle{})
+		if !checkpoint.All {
+			query = query.Where("sentiment IS NULL")
+		}
+		return query
+	}
+
+	updates := map[string]interface{}{"status": "running", "error_message": nil, "completed_at": nil}
+	if job.StartedAt == nil {
+		updates["started_at"] = time.Now()
+	}
+	if job.RecordsTotal == nil {
+		var total int64
+		if err := scan().Count(&total).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, fmt.Errorf("failed to count news articles: %v", err))
+		}
+		count := int(total)
+		job.RecordsTotal = &count
+		updates["records_total"] = count
+	}
+	if err := s.db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		return err
+	}
+	s.logger.Infof("Scoring sentiment for %d news articles", *job.RecordsTotal)
+
+	processed := job.RecordsProcessed
+	for {
+		query := scan()
+		if checkpoint.AfterID != "" {
+			query = query.Where("id > ?", checkpoint.AfterID)
+		}
+		var articles []models.NewsArticle
+		if err := query.Select("id, title, summary, content").Order("id").Limit(newsJobBatchSize).Find(&articles).Error; err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, fmt.Errorf("failed to scan news articles: %v", err))
+		}
+		if len(articles) == 0 {
+			break
+		}
+
+		scores := make(map[string]float64, len(articles))
+		for _, article := range articles {
+			score := s.scoreSentiment(ctx, article.Title, stringValue(article.Summary), stringValue(article.Content))
+			if score != nil {
+				scores[article.ID] = *score
+			}
+		}
+
+		checkpoint.AfterID = articles[len(articles)-1].ID
+		checkpoint.Scored += len(scores)
+		processed += len(articles)
+		config, err := json.Marshal(checkpoint)
+		if err != nil {
+			return err
+		}
+		progress := 100
+		if *job.RecordsTotal > 0 && processed < *job.RecordsTotal {
+			progress = processed * 100 / *job.RecordsTotal
+		}
+
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for id, score := range scores {
+				if err := tx.Model(&models.NewsArticle{}).Where("id = ?", id).Update("sentiment", score).Error; err != nil {
+					return fmt.Errorf("failed to save sentiment: %v", err)
+				}
+			}
+			return tx.Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+				"config":            config,
+				"progress":          progress,
+				"records_processed": processed,
+				"records_success":   checkpoint.Scored,
+			}).Error
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.finishNewsJob(job, err)
+		}
+	}
+
+	s.logger.Infof("Sentiment job %s completed: %d of %d articles scored", job.ID, checkpoint.Scored, processed)
+	return s.finishNewsJob(job, nil)
+}
+
+// finishNewsJob 写入任务最终状态，使用独立的上下文以免服务停止时状态丢失
+func (s *NewsService) finishNewsJob(job *models.SyncJob, runErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	updates := map[string]interface{}{"status": "completed", "progress": 100, "completed_at": time.Now()}
+	if runErr != nil {
+		updates = map[string]interface{}{"status": "failed", "error_message": runErr.Error(), "records_error": gorm.Expr("records_error + 1")}
+	}
+	if err := s.db.WithContext(ctx).Model(&models.SyncJob{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		s.logger.Errorf("Failed to update news job %s: %v", job.ID, err)
+	}
+	return runErr
+}
+
+// RescoreSentiment 创建情绪回填任务，all为true时重新计算所有文章（如更换了模型）
+func (s *NewsService) RescoreSentiment(ctx context.Context, all bool) (*models.SyncJob, error) {
+	config, err := json.Marshal(sentimentCheckpoint{All: all})
+	if err != nil {
+		return nil, err
+	}
+	job := &models.SyncJob{
+		Type:   SyncJobTypeNewsSentiment,
+		Status: "pending",
+		Config: config,
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create sentiment job: %v", err)
+	}
+
+	s.triggerJob(ctx, newsBackfillJob)
+	return job, nil
+}
+
+// updateSentimentMetrics 计算每个资产的新闻情绪动量并写入metric_data
+func (s *NewsService) updateSentimentMetrics(ctx context.Context) error {
+	var assets []models.Asset
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return fmt.Errorf("failed to load assets for sentiment metrics: %v", err)
+	}
+
+	now := time.Now()
+	var metrics []*models.MetricData
+	for i := range assets {
+		asset := &assets[i]
+		points, err := s.sentimentPoints(ctx, asset, "", now)
+		if err != nil {
+			s.logger.Errorf("Failed to load sentiment for %s: %v", asset.Symbol, err)
+			continue
+		}
+		result, ok := sentimentMomentum.Compute(points, now)
+		if !ok {
+			continue
+		}
+
+		metadata, err := json.Marshal(result)
+		if err != nil {
+			continue
+		}
+		metrics = append(metrics, &models.MetricData{
+			AssetID:    &asset.ID,
+			MetricType: MetricSentimentMomentum,
+			Value:      result.Value,
+			Unit:       "score",
+			Source:     sentimentMetricSource,
+			Metadata:   metadata,
+			Timestamp:  now,
+		})
+	}
+
+	if len(metrics) == 0 {
+		return nil
+	}
+	if err := s.db.WithContext(ctx).Create(metrics).Error; err != nil {
+		return fmt.Errorf("failed to save sentiment metrics: %v", err)
+	}
+	s.logger.Debugf("Updated sentiment momentum for %d assets", len(metrics))
+	return nil
+}
+
+// sentimentPoints 读取动量窗口内关联到资产的文章情绪分
+func (s *NewsService) sentimentPoints(ctx context.Context, asset *models.Asset, category string, now time.Time) ([]sentiment.Point, error) {
+	query := s.db.WithContext(ctx).Model(&models.NewsArticle{}).
+		Select("published_at AS time, sentiment AS score").
+		Where("sentiment IS NOT NULL AND published_at > ? AND published_at <= ?", now.Add(-sentimentMomentum.Long), now)
+	if asset != nil {
+		query = query.Where("id IN (?)", s.assetArticles(asset.ID))
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var points []sentiment.Point
+	if err := query.Scan(&points).Error; err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// GetSentiment 按时间段和分类聚合新闻情绪，可按资产筛选
+func (s *NewsService) GetSentiment(ctx context.Context, q SentimentQuery) (*SentimentSummary, error) {
+	switch q.Interval {
+	case "":
+		q.Interval = "day"
+	case "hour", "day", "week", "month":
+	default:
+		return nil, fmt.Errorf("%w: interval must be hour, day, week or month", ErrInvalidSentimentQuery)
+	}
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidSentimentQuery)
+	}
+
+	summary := &SentimentSummary{Category: q.Category, Interval: q.Interval, From: q.From, To: q.To, Buckets: []SentimentBucket{}}
+	if q.Asset != "" {
+		asset, err := s.findAsset(ctx, q.Asset)
+		if err != nil {
+			return nil, err
+		}
+		summary.Asset = asset
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.NewsArticle{}).
+		Select(`date_trunc(?, published_at) AS bucket,
+			COALESCE(category, 'general') AS category,
+			COUNT(*) AS articles,
+			ROUND(AVG(sentiment), 4) AS average,
+			COUNT(*) FILTER (WHERE sentiment > 0) AS positive,
+			COUNT(*) FILTER (WHERE sentiment < 0) AS negative`, q.Interval).
+		Where("sentiment IS NOT NULL AND published_at >= ? AND published_at < ?", q.From, q.To)
+	if summary.Asset != nil {
+		query = query.Where("id IN (?)", s.assetArticles(summary.Asset.ID))
+	}
+	if q.Category != "" {
+		query = query.Where("category = ?", q.Category)
+	}
+	if err := query.Group("1, 2").Order("1, 2").Scan(&summary.Buckets).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment: %v", err)
+	}
+
+	points, err := s.sentimentPoints(ctx, summary.Asset, q.Category, q.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sentiment: %v", err)
+	}
+	if result, ok := sentimentMomentum.Compute(points, q.To); ok {
+		summary.Momentum = &result
+	}
+	return summary, nil
+}
+
+// findAsset 按ID或代码查找资产
+func (s *NewsService) findAsset(ctx context.Context, key string) (*models.Asset, error) {
+	query := s.db.WithContext(ctx)
+	if uuidPattern.MatchString(key) {
+		query = query.Where("id = ?", key)
+	} else {
+		query = query.Where("UPPER(symbol) = ?", strings.ToUpper(key))
+	}
+
+	var asset models.Asset
+	if err := query.First(&asset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAssetNotFound
+		}
+		return nil, fmt.Errorf("failed to query asset: %v", err)
+	}
+	return &asset, nil
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -6,190 +6,384 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/rwa-platform/data-collector/internal/config"
+	"github.com/rwa-platform/data-collector/internal/entity"
 	"github.com/rwa-platform/data-collector/internal/kafka"
+	"github.com/rwa-platform/data-collector/internal/langdetect"
 	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/newsdict"
+	"github.com/rwa-platform/data-collector/internal/sentiment"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/tracing"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type NewsService struct {
 	db     *gorm.DB
-	redis  *redis.Client
+	redis  RedisCache
 	kafka  *kafka.Producer
 	config *config.Config
 	client *http.Client
 	logger *logrus.Logger
+
+	// sources 数据源管理，提供管理接口设置的API密钥
+	sources *DataSourceService
+
+	sentiment sentiment.Scorer
+
+	// keywords 配置中的检索关键词，没有任何检索订阅时导入；dictionaries 各语言的分类和标签词典
+	keywords     []newsKeyword
+	dictionaries *newsdict.Registry
+
+	// linker 按当前资产和渠道构建的实体识别器，linkerLoadedAt 为构建时间；
+	// 采集任务可能在任一实例执行，识别器过期时在采集前重建
+	linkerMu       sync.RWMutex
+	linker         *entity.Linker
+	linkerLoadedAt time.Time
+
+	// jobs 采集和回填任务的调度器，订阅变更或创建回填任务后立即触发对应任务
+	jobs *scheduler.Scheduler
 }
 
 type NewsAPIResponse struct {
-	Status       string `json:"status"`
-	TotalResults int    `json:"totalResults"`
-	Articles     []struct {
-		Source struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"source"`
-		Author      string    `json:"author"`
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		URL         string    `json:"url"`
-		URLToImage  string    `json:"urlToImage"`
-		PublishedAt time.Time `json:"publishedAt"`
-		Content     string    `json:"content"`
-	} `json:"articles"`
+	Status       string           `json:"status"`
+	TotalResults int              `json:"totalResults"`
+	Articles     []NewsAPIArticle `json:"articles"`
 }
 
-func NewNewsService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *kafka.Producer, cfg *config.Config) *NewsService {
-	return &NewsService{
-		db:     db,
-		redis:  redisClient,
-		kafka:  kafkaProducer,
-		config: cfg,
-		client: &http.Client{
-			Timeout: time.Duration(cfg.RequestTimeout) * time.Second,
-		},
-		logger: logrus.New(),
+type NewsAPIArticle struct {
+	Source struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"source"`
+	Author      string    `json:"author"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	URL         string    `json:"url"`
+	URLToImage  string    `json:"urlToImage"`
+	PublishedAt time.Time `json:"publishedAt"`
+	Content     string    `json:"content"`
+}
+
+// newsItem 各新闻源统一后的文章，Category为空时按内容自动分类，Language为来源声明的语言，入库时以识别结果为准；
+// 相关性低于MinRelevance的文章不保存
+type newsItem struct {
+	Source       string
+	Author       string
+	Title        string
+	Description  string
+	URL          string
+	Content      string
+	Language     string
+	Category     string
+	PublishedAt  time.Time
+	MinRelevance float64
+}
+
+// newsOutcome processNewsArticle的处理结果，用于检索订阅的统计
+type newsOutcome int
+
+const (
+	newsSaved newsOutcome = iota
+	newsDuplicate
+	newsFiltered
+	newsFailed
+)
+
+// String 用作采集记录数指标的result标签
+func (o newsOutcome) String() string {
+	switch o {
+	case newsSaved:
+		return "saved"
+	case newsDuplicate:
+		return "duplicate"
+	case newsFiltered:
+		return "skipped"
+	default:
+		return "failed"
 	}
 }
 
-func (s *NewsService) StartNewsCollection(ctx context.Context) {
-	s.logger.Info("Starting news collection service")
-	
-	ticker := time.NewTicker(time.Duration(s.config.NewsCollectionInterval) * time.Second)
-	defer ticker.Stop()
-
-	// 立即执行一次
-	s.collectNews(ctx)
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("News collection service stopped")
-			return
-		case <-ticker.C:
-			s.collectNews(ctx)
+// newsKeyword NewsAPI检索的关键词，每种语言分别检索
+type newsKeyword struct {
+	Keyword   string
+	Languages []string
+}
+
+const (
+	// minLanguageConfidence 识别结果低于该置信度时使用来源声明的语言
+	minLanguageConfidence = 0.6
+)
+
+// newsAPILanguages NewsAPI支持的语言，其他语言（如日文）的新闻通过订阅源采集
+var newsAPILanguages = map[string]bool{
+	"ar": true, "de": true, "en": true, "es": true, "fr": true, "he": true, "it": true,
+	"nl": true, "no": true, "pt": true, "ru": true, "sv": true, "ud": true, "zh": true,
+}
+
+func NewNewsService(db *gorm.DB, redisClient RedisCache, kafkaProducer *kafka.Producer, cfg *config.Config) *NewsService {
+	client := &http.Client{
+		Timeout:   time.Duration(cfg.RequestTimeout) * time.Second,
+		Transport: tracing.Transport(nil),
+	}
+	s := &NewsService{
+		db:           db,
+		redis:        redisClient,
+		kafka:        kafkaProducer,
+		config:       cfg,
+		client:       client,
+		logger:       logrus.New(),
+		sentiment:    newSentimentScorer(cfg.SentimentModelURL, client),
+		dictionaries: newsdict.NewRegistry(),
+	}
+
+	if cfg.NewsDictionaryDir != "" {
+		loaded, err := s.dictionaries.LoadDir(cfg.NewsDictionaryDir)
+		if err != nil {
+			s.logger.Errorf("Failed to load news dictionaries: %v", err)
+		} else {
+			s.logger.Infof("Loaded %d news dictionaries from %s", loaded, cfg.NewsDictionaryDir)
+		}
+	}
+
+	s.keywords = parseNewsKeywords(cfg.NewsKeywords, cfg.NewsLanguages)
+	for _, keyword := range s.keywords {
+		for _, language := range keyword.Languages {
+			if !newsAPILanguages[language] {
+				s.logger.Warnf("NewsAPI does not support language %s for keyword %s, use a feed instead", language, keyword.Keyword)
+			}
 		}
 	}
+	return s
 }
 
-func (s *NewsService) collectNews(ctx context.Context) {
-	s.logger.Info("Starting news collection cycle")
-
-	// 收集不同类型的新闻
-	keywords := []string{
-		"stablecoin",
-		"treasury",
-		"RWA",
-		"real world assets",
-		"USDT",
-		"USDC",
-		"DAI",
-		"government bonds",
-		"money market",
-		"defi",
-	}
-
-	for _, keyword := range keywords {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			s.collectNewsForKeyword(ctx, keyword)
-			time.Sleep(2 * time.Second) // 避免API限制
+// parseNewsKeywords 解析逗号分隔的关键词，关键词后可用冒号指定语言（以|分隔），如"stablecoin:en|de,稳定币:zh"
+func parseNewsKeywords(spec string, defaultLanguages []string) []newsKeyword {
+	if len(defaultLanguages) == 0 {
+		defaultLanguages = []string{"en"}
+	}
+
+	var keywords []newsKeyword
+	for _, entry := range strings.Split(spec, ",") {
+		keyword, languages, found := strings.Cut(entry, ":")
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+
+		parsed := newsKeyword{Keyword: keyword}
+		if found {
+			for _, language := range strings.Split(languages, "|") {
+				if language = strings.ToLower(strings.TrimSpace(language)); language != "" {
+					parsed.Languages = append(parsed.Languages, language)
+				}
+			}
 		}
+		if len(parsed.Languages) == 0 {
+			parsed.Languages = defaultLanguages
+		}
+		keywords = append(keywords, parsed)
 	}
+	return keywords
+}
 
-	s.logger.Info("News collection cycle completed")
+// UseDataSources 采集请求（含订阅源和情绪模型）计入数据源健康统计，NewsAPI密钥优先使用管理接口设置的密钥
+func (s *NewsService) UseDataSources(sources *DataSourceService) {
+	s.sources = sources
+	s.client.Transport = sources.Transport(s.client.Transport)
+}
+
+const (
+	// newsSubscriptionsJob 检索订阅采集的调度任务名
+	newsSubscriptionsJob = "news-subscriptions"
+	// newsFeedsJob 订阅源采集的调度任务名
+	newsFeedsJob = "news-feeds"
+	// newsAssetSubscriptionsJob 资产订阅同步的调度任务名
+	newsAssetSubscriptionsJob = "news-asset-subscriptions"
+	// newsBackfillJob 情绪和实体关联回填的调度任务名
+	newsBackfillJob = "news-backfill"
+	// newsSentimentMetricsJob 资产情绪动量计算的调度任务名
+	newsSentimentMetricsJob = "news-sentiment-metrics"
+)
+
+// RegisterJobs 注册新闻采集、资产订阅同步、回填和情绪动量任务；
+// 检索订阅和订阅源各有采集间隔，任务只按固定间隔检查到期的订阅
+func (s *NewsService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	s.jobs = jobs
+
+	specs := []scheduler.Spec{
+		{
+			Name:     newsSubscriptionsJob,
+			Schedule: scheduler.Every(subscriptionPollInterval),
+			Timeout:  30 * time.Minute,
+			// 到期的订阅在下一次检查时采集，错过的检查不需要补
+			Missed: scheduler.MissedSkip,
+			Run:    s.collectNews,
+		},
+		{
+			Name:     newsFeedsJob,
+			Schedule: scheduler.Every(feedPollInterval),
+			Timeout:  30 * time.Minute,
+			Missed:   scheduler.MissedSkip,
+			Run:      s.collectFromRSSFeeds,
+		},
+		{
+			Name:     newsBackfillJob,
+			Schedule: scheduler.Every(newsJobPollInterval),
+			// 回填按批写入检查点，超时中断后下一次从检查点继续
+			Timeout: 30 * time.Minute,
+			Missed:  scheduler.MissedRunOnce,
+			Run:     s.runNewsJobs,
+		},
+		{
+			Name:     newsSentimentMetricsJob,
+			Schedule: scheduler.Every(sentimentMetricsInterval),
+			Timeout:  10 * time.Minute,
+			Missed:   scheduler.MissedRunOnce,
+			Run:      s.updateSentimentMetrics,
+		},
+	}
+	if s.config.NewsAssetSubscriptions {
+		specs = append(specs, scheduler.Spec{
+			Name:     newsAssetSubscriptionsJob,
+			Schedule: scheduler.Every(entityRefreshInterval),
+			Timeout:  5 * time.Minute,
+			Missed:   scheduler.MissedRunOnce,
+			Run:      s.syncAssetSubscriptions,
+		})
+	}
+	for _, spec := range specs {
+		if err := jobs.Register(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrepareNewsCollection 采集任务开始前加载资产和渠道，并在没有任何检索订阅时导入配置的关键词
+func (s *NewsService) PrepareNewsCollection(ctx context.Context) {
+	s.logger.Info("Preparing news collection")
+	s.refreshEntities(ctx)
+	s.seedSubscriptions(ctx)
+}
+
+// triggerJob 请求立即执行任务，失败时任务在下一次计划时间执行
+func (s *NewsService) triggerJob(ctx context.Context, name string) {
+	if s.jobs == nil {
+		return
+	}
+	if err := s.jobs.Trigger(ctx, name); err != nil {
+		s.logger.Warnf("Failed to trigger %s job: %v", name, err)
+	}
 }
 
-func (s *NewsService) collectNewsForKeyword(ctx context.Context, keyword string) {
-	// 使用NewsAPI收集新闻
-	if err := s.collectFromNewsAPI(ctx, keyword); err != nil {
-		s.logger.Errorf("Failed to collect news from NewsAPI for keyword %s: %v", keyword, err)
+// collectNews 按到期的检索订阅从NewsAPI采集
+func (s *NewsService) collectNews(ctx context.Context) error {
+	var subscriptions []models.NewsSubscription
+	err := s.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Where("next_run_at IS NULL OR next_run_at <= ?", time.Now()).
+		Order("next_run_at ASC NULLS FIRST").
+		Find(&subscriptions).Error
+	if err != nil {
+		return fmt.Errorf("failed to load news subscriptions: %v", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+	s.ensureEntities(ctx)
+
+	s.logger.Infof("Starting news collection cycle for %d subscriptions", len(subscriptions))
+	for i := range subscriptions {
+		if i > 0 {
+			// 避免API限制
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(2 * time.Second):
+			}
+		}
+		s.runSubscription(ctx, &subscriptions[i])
 	}
+	s.logger.Info("News collection cycle completed")
+	return nil
+}
 
-	// 可以添加其他新闻源
-	// s.collectFromCryptoNews(ctx, keyword)
-	// s.collectFromRSSFeeds(ctx, keyword)
+// newsAPIQuery NewsAPI everything接口的检索条件
+type newsAPIQuery struct {
+	Query    string
+	Language string
+	Sources  []string
+	Domains  []string
 }
 
-func (s *NewsService) collectFromNewsAPI(ctx context.Context, keyword string) error {
+// collectFromNewsAPI 检索最近1天的新闻
+func (s *NewsService) collectFromNewsAPI(ctx context.Context, query newsAPIQuery) ([]NewsAPIArticle, error) {
 	// 构建API请求
 	baseURL := "https://newsapi.org/v2/everything"
 	
 	req, err := http.NewRequestWithContext(ctx, "GET", baseURL, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 设置查询参数
 	q := req.URL.Query()
-	q.Add("q", keyword)
-	q.Add("language", "en")
+	q.Add("q", query.Query)
+	q.Add("language", query.Language)
 	q.Add("sortBy", "publishedAt")
 	q.Add("pageSize", "50")
 	q.Add("from", time.Now().AddDate(0, 0, -1).Format("2006-01-02")) // 最近1天
+	if len(query.Sources) > 0 {
+		q.Add("sources", strings.Join(query.Sources, ","))
+	}
+	if len(query.Domains) > 0 {
+		q.Add("domains", strings.Join(query.Domains, ","))
+	}
 	req.URL.RawQuery = q.Encode()
 
 	// 设置API密钥（如果有的话）
-	if apiKey := s.config.NewsAPIKey; apiKey != "" {
+	if apiKey := s.sources.APIKey(DataSourceNewsAPI, s.config.NewsAPIKey); apiKey != "" {
 		req.Header.Set("X-API-Key", apiKey)
 	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("NewsAPI returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("NewsAPI returned status %d", resp.StatusCode)
 	}
 
 	var newsResponse NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsResponse); err != nil {
-		return err
-	}
-
-	// 处理新闻文章
-	for _, article := range newsResponse.Articles {
-		s.processNewsArticle(article, keyword)
+		return nil, err
 	}
-
-	s.logger.Debugf("Collected %d news articles for keyword: %s", len(newsResponse.Articles), keyword)
-	return nil
+	return newsResponse.Articles, nil
 }
 
-func (s *NewsService) processNewsArticle(article struct {
-	Source struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	} `json:"source"`
-	Author      string    `json:"author"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	URL         string    `json:"url"`
-	URLToImage  string    `json:"urlToImage"`
-	PublishedAt time.Time `json:"publishedAt"`
-	Content     string    `json:"content"`
-}, keyword string) {
-	
+func (s *NewsService) processNewsArticle(ctx context.Context, article newsItem, keyword string) (outcome newsOutcome) {
+	defer func() { metrics.AddRecords("news", outcome.String(), 1) }()
+
 	// 检查文章是否已存在
 	var existingArticle models.NewsArticle
-	if err := s.db.Where("url = ?", article.URL).First(&existingArticle).Error; err == nil {
-		return // 文章已存在
+	if err := s.db.WithContext(ctx).Where("url = ?", article.URL).First(&existingArticle).Error; err == nil {
+		return newsDuplicate // 文章已存在
 	}
 
 	// 创建新闻文章记录
 	newsArticle := &models.NewsArticle{
 		Title:       article.Title,
 		URL:         article.URL,
-		Source:      article.Source.Name,
-		Language:    "en",
+		Source:      article.Source,
+		Language:    s.detectLanguage(article),
 		PublishedAt: article.PublishedAt,
 	}
 
@@ -205,100 +399,121 @@ func (s *NewsService) processNewsArticle(article struct {
 		newsArticle.Content = &article.Content
 	}
 
+	if newsArticle.PublishedAt.IsZero() {
+		newsArticle.PublishedAt = time.Now()
+	}
+
 	// 设置分类
-	category := s.categorizeNews(article.Title, article.Description, keyword)
+	category := article.Category
+	if category == "" {
+		category = s.categorizeNews(newsArticle.Language, article.Title, article.Description, keyword)
+	}
 	if category != "" {
 		newsArticle.Category = &category
 	}
 
 	// 设置标签
-	tags := s.extractTags(article.Title, article.Description, keyword)
+	tags := s.extractTags(newsArticle.Language, article.Title, article.Description, keyword)
 	if len(tags) > 0 {
 		tagsJSON, _ := json.Marshal(tags)
 		newsArticle.Tags = tagsJSON
 	}
 
 	// 计算相关性分数
-	relevance := s.calculateRelevance(article.Title, article.Description, keyword)
+	relevance := s.calculateRelevance(newsArticle.Language, article.Title, article.Description, keyword)
 	newsArticle.Relevance = &relevance
+	if relevance < article.MinRelevance {
+		return newsFiltered
+	}
 
-	// 保存到数据库
-	if err := s.db.Create(newsArticle).Error; err != nil {
+	// 计算情绪分
+	newsArticle.Sentiment = s.scoreSentiment(ctx, article.Title, article.Description, article.Content)
+
+	// 保存到数据库，关联提及的实体，并归入转载的同一报道
+	var story *models.NewsStory
+	var joined bool
+	var links []models.NewsEntity
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newsArticle).Error; err != nil {
+			return err
+		}
+		if links = s.linkEntities(newsArticle); len(links) > 0 {
+			if err := tx.Create(&links).Error; err != nil {
+				return fmt.Errorf("failed to save news entities: %v", err)
+			}
+		}
+		var err error
+		story, joined, err = s.assignStory(tx, newsArticle)
+		return err
+	})
+	if err != nil {
 		s.logger.Errorf("Failed to save news article: %v", err)
-		return
+		return newsFailed
 	}
 
-	// 发布到Kafka
-	s.publishNewsUpdate(newsArticle)
+	// 发布到Kafka，每个报道只发布一次news_update，之后的转载发布story_update
+	if joined {
+		s.publishStoryUpdate(ctx, story, newsArticle, links)
+	} else {
+		s.publishNewsUpdate(ctx, newsArticle, story, links)
+		s.publishNegativeNews(ctx, newsArticle, story, links)
+	}
+	s.detectRegulatoryEvent(ctx, newsArticle, story, links)
 
 	s.logger.Debugf("Saved news article: %s", article.Title)
+	return newsSaved
 }
 
-func (s *NewsService) categorizeNews(title, description, keyword string) string {
-	content := strings.ToLower(title + " " + description)
-	
-	if strings.Contains(content, "stablecoin") || strings.Contains(content, "usdt") || strings.Contains(content, "usdc") {
-		return "stablecoin"
-	}
-	if strings.Contains(content, "treasury") || strings.Contains(content, "bond") {
-		return "treasury"
-	}
-	if strings.Contains(content, "rwa") || strings.Contains(content, "real world asset") {
-		return "rwa"
+// detectLanguage 识别文章语言，无法可靠识别时使用来源声明的语言，都没有时默认英文
+func (s *NewsService) detectLanguage(article newsItem) string {
+	result := langdetect.Detect(strings.Join([]string{article.Title, article.Description, article.Content}, "\n"))
+	if result.Language != "" && result.Confidence >= minLanguageConfidence {
+		return result.Language
 	}
-	if strings.Contains(content, "defi") || strings.Contains(content, "decentralized finance") {
-		return "defi"
+	if article.Language != "" {
+		return strings.ToLower(article.Language)
 	}
-	if strings.Contains(content, "regulation") || strings.Contains(content, "regulatory") {
-		return "regulation"
-	}
-	
-	return "general"
+	return "en"
 }
 
-func (s *NewsService) extractTags(title, description, keyword string) []string {
-	content := strings.ToLower(title + " " + description)
-	tags := []string{keyword}
-	
-	// 常见标签
-	commonTags := []string{
-		"bitcoin", "ethereum", "blockchain", "crypto", "cryptocurrency",
-		"stablecoin", "defi", "rwa", "treasury", "bond", "yield",
-		"regulation", "sec", "fed", "central bank", "cbdc",
+func (s *NewsService) categorizeNews(language, title, description, keyword string) string {
+	return s.dictionaries.For(language).Category(title + " " + description)
+}
+
+func (s *NewsService) extractTags(language, title, description, keyword string) []string {
+	var tags []string
+	if keyword != "" {
+		tags = append(tags, keyword)
 	}
-	
-	for _, tag := range commonTags {
-		if strings.Contains(content, tag) && !contains(tags, tag) {
+
+	// 常见标签，按文章语言的词典匹配
+	for _, tag := range s.dictionaries.For(language).MatchTags(title + " " + description) {
+		if !contains(tags, tag) {
 			tags = append(tags, tag)
 		}
 	}
-	
+
 	return tags
 }
 
-func (s *NewsService) calculateRelevance(title, description, keyword string) float64 {
+func (s *NewsService) calculateRelevance(language, title, description, keyword string) float64 {
 	content := strings.ToLower(title + " " + description)
 	keyword = strings.ToLower(keyword)
 	
 	score := 0.0
 	
 	// 标题中包含关键词
-	if strings.Contains(strings.ToLower(title), keyword) {
+	if keyword != "" && strings.Contains(strings.ToLower(title), keyword) {
 		score += 0.5
 	}
 	
 	// 描述中包含关键词
-	if strings.Contains(strings.ToLower(description), keyword) {
+	if keyword != "" && strings.Contains(strings.ToLower(description), keyword) {
 		score += 0.3
 	}
 	
 	// 包含相关术语
-	relevantTerms := []string{"rwa", "real world assets", "stablecoin", "treasury", "defi"}
-	for _, term := range relevantTerms {
-		if strings.Contains(content, term) {
-			score += 0.1
-		}
-	}
+	score += 0.1 * float64(s.dictionaries.For(language).RelevantTerms(content))
 	
 	if score > 1.0 {
 		score = 1.0
@@ -307,25 +522,34 @@ func (s *NewsService) calculateRelevance(title, description, keyword string) flo
 	return score
 }
 
-func (s *NewsService) publishNewsUpdate(article *models.NewsArticle) {
+func (s *NewsService) publishNewsUpdate(ctx context.Context, article *models.NewsArticle, story *models.NewsStory, links []models.NewsEntity) {
 	message := map[string]interface{}{
 		"type":         "news_update",
 		"id":           article.ID,
+		"story_id":     story.ID,
 		"title":        article.Title,
 		"url":          article.URL,
 		"source":       article.Source,
+		"sources":      []string{article.Source},
 		"category":     article.Category,
 		"relevance":    article.Relevance,
+		"sentiment":    article.Sentiment,
+		"asset_ids":    linkedAssetIDs(links),
 		"published_at": article.PublishedAt.Unix(),
 		"created_at":   article.CreatedAt.Unix(),
 	}
 
-	if err := s.kafka.PublishMessage("news-updates", article.ID, message); err != nil {
+	// 以报道ID为键，同一报道的后续更新进入同一分区
+	if err := s.kafka.PublishMessage(ctx, "news-updates", story.ID, message); err != nil {
 		s.logger.Errorf("Failed to publish news update: %v", err)
 	}
 }
 
-func (s *NewsService) GetNews(page, limit int, category, source, language string) ([]models.NewsArticle, int, error) {
+func (s *NewsService) GetNews(page, limit int, category, source, language, assetID string) ([]models.NewsArticle, int, error) {
+	if assetID != "" && !uuidPattern.MatchString(assetID) {
+		return nil, 0, ErrInvalidAssetID
+	}
+
 	var news []models.NewsArticle
 	var total int64
 
@@ -341,6 +565,9 @@ func (s *NewsService) GetNews(page, limit int, category, source, language string
 	if language != "" {
 		query = query.Where("language = ?", language)
 	}
+	if assetID != "" {
+		query = query.Where("id IN (?)", s.assetArticles(assetID))
+	}
 
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
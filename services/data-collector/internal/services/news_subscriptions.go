@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 检索订阅的类型和来源
+const (
+	SubscriptionTypeKeyword = "keyword"
+	SubscriptionTypeTopic   = "topic"
+
+	SubscriptionOriginManual = "manual"
+	SubscriptionOriginConfig = "config"
+	SubscriptionOriginAsset  = "asset"
+)
+
+const (
+	// subscriptionPollInterval 检查到期检索订阅的间隔
+	subscriptionPollInterval = time.Minute
+	// subscriptionMinInterval 单个订阅允许的最短检索间隔，避免耗尽NewsAPI配额
+	subscriptionMinInterval = 5 * time.Minute
+	// subscriptionMaxKeyword 关键词的最大长度，NewsAPI的q参数最长500字符
+	subscriptionMaxKeyword = 200
+	// assetKeywordMinLength 短于该长度的资产名称或别名不生成订阅，过短的词检索结果噪声太大
+	assetKeywordMinLength = 4
+)
+
+var (
+	ErrInvalidSubscription  = errors.New("invalid news subscription")
+	ErrSubscriptionNotFound = errors.New("news subscription not found")
+)
+
+// SubscriptionInput 添加或更新检索订阅的请求，类型、关键词和语言相同的订阅会被覆盖
+type SubscriptionInput struct {
+	Type            string   `json:"type"` // keyword（默认）, topic
+	Keyword         string   `json:"keyword" binding:"required"`
+	Language        string   `json:"language"`
+	Sources         []string `json:"sources"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	MinRelevance    float64  `json:"min_relevance"`
+}
+
+// AssetSubscriptionSync 按资产同步检索订阅的结果
+type AssetSubscriptionSync struct {
+	Created     int `json:"created"`
+	Deactivated int `json:"deactivated"`
+}
+
+// subscriptionRun 一次检索的文章统计
+type subscriptionRun struct {
+	Found      int
+	Saved      int
+	Duplicates int
+	Filtered   int
+}
+
+// runSubscription 执行一次检索并累计统计，失败时按连续失败次数指数退避
+func (s *NewsService) runSubscription(ctx context.Context, sub *models.NewsSubscription) {
+	query, err := s.subscriptionQuery(sub)
+	if err != nil {
+		s.recordSubscriptionError(ctx, sub, err)
+		return
+	}
+	articles, err := s.collectFromNewsAPI(ctx, query)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.recordSubscriptionError(ctx, sub, err)
+		}
+		return
+	}
+
+	var topicTerms []string
+	if sub.Type == SubscriptionTypeTopic {
+		topicTerms = s.dictionaries.For(sub.Language).Topic(sub.Keyword)
+	}
+
+	run := subscriptionRun{Found: len(articles)}
+	for _, article := range articles {
+		item := newsItem{
+			Source:       article.Source.Name,
+			Author:       article.Author,
+			Title:        article.Title,
+			Description:  article.Description,
+			URL:          article.URL,
+			Content:      article.Content,
+			Language:     sub.Language,
+			PublishedAt:  article.PublishedAt,
+			MinRelevance: sub.MinRelevance,
+		}
+		keyword := sub.Keyword
+		if sub.Type == SubscriptionTypeTopic {
+			// 主题订阅的文章归入该分类，以命中的关键词计算相关性
+			item.Category = sub.Keyword
+			keyword = firstTerm(topicTerms, article.Title+" "+article.Description)
+		}
+
+		switch s.processNewsArticle(ctx, item, keyword) {
+		case newsSaved:
+			run.Saved++
+		case newsDuplicate:
+			run.Duplicates++
+		case newsFiltered:
+			run.Filtered++
+		}
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&models.NewsSubscription{}).Where("id = ?", sub.ID).Updates(map[string]interface{}{
+		"last_run_at":    now,
+		"next_run_at":    now.Add(s.subscriptionInterval(sub)),
+		"last_found":     run.Found,
+		"articles_found": gorm.Expr("articles_found + ?", run.Found),
+		"articles_saved": gorm.Expr("articles_saved + ?", run.Saved),
+		"duplicates":     gorm.Expr("duplicates + ?", run.Duplicates),
+		"filtered":       gorm.Expr("filtered + ?", run.Filtered),
+		"error_count":    0,
+		"last_error":     nil,
+	}).Error
+	if err != nil {
+		s.logger.Errorf("Failed to update news subscription %s: %v", sub.Keyword, err)
+		return
+	}
+	s.logger.Debugf("Subscription %s (%s): %d found, %d saved, %d duplicates, %d filtered",
+		sub.Keyword, sub.Language, run.Found, run.Saved, run.Duplicates, run.Filtered)
+}
+
+// subscriptionQuery 关键词订阅直接检索关键词（资产订阅按短语检索），主题订阅检索词典中该分类的任一关键词
+func (s *NewsService) subscriptionQuery(sub *models.NewsSubscription) (newsAPIQuery, error) {
+	query := newsAPIQuery{Query: sub.Keyword, Language: sub.Language}
+	switch {
+	case sub.Type == SubscriptionTypeTopic:
+		terms := s.dictionaries.For(sub.Language).Topic(sub.Keyword)
+		if len(terms) == 0 {
+			return query, fmt.Errorf("no dictionary terms for topic %s", sub.Keyword)
+		}
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + term + `"`
+		}
+		query.Query = strings.Join(quoted, " OR ")
+	case sub.Origin == SubscriptionOriginAsset && strings.Contains(sub.Keyword, " "):
+		query.Query = `"` + sub.Keyword + `"`
+	}
+
+	var sources []string
+	if len(sub.Sources) > 0 {
+		if err := json.Unmarshal(sub.Sources, &sources); err != nil {
+			return query, fmt.Errorf("invalid subscription sources: %v", err)
+		}
+	}
+	// 含点的视为域名，其余为NewsAPI来源ID
+	for _, source := range sources {
+		if strings.Contains(source, ".") {
+			query.Domains = append(query.Domains, source)
+		} else {
+			query.Sources = append(query.Sources, source)
+		}
+	}
+	return query, nil
+}
+
+// recordSubscriptionError 记录检索失败，按连续失败次数指数退避
+func (s *NewsService) recordSubscriptionError(ctx context.Context, sub *models.NewsSubscription, cause error) {
+	s.logger.Errorf("Failed to collect %s news for subscription %s: %v", sub.Language, sub.Keyword, cause)
+
+	backoff := s.subscriptionInterval(sub)
+	for i := 0; i < sub.ErrorCount && backoff < feedMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > feedMaxBackoff {
+		backoff = feedMaxBackoff
+	}
+
+	now := time.Now()
+	err := s.db.WithContext(ctx).Model(&models.NewsSubscription{}).Where("id = ?", sub.ID).Updates(map[string]interface{}{
+		"last_run_at": now,
+		"next_run_at": now.Add(backoff),
+		"error_count": gorm.Expr("error_count + 1"),
+		"last_error":  cause.Error(),
+	}).Error
+	if err != nil {
+		s.logger.Errorf("Failed to record error for news subscription %s: %v", sub.Keyword, err)
+	}
+}
+
+func (s *NewsService) subscriptionInterval(sub *models.NewsSubscription) time.Duration {
+	if sub.IntervalSeconds > 0 {
+		return time.Duration(sub.IntervalSeconds) * time.Second
+	}
+	return time.Duration(s.config.NewsCollectionInterval) * time.Second
+}
+
+// firstTerm 返回文本中出现的第一个关键词，都没有出现时返回空
+func firstTerm(terms []string, text string) string {
+	text = strings.ToLower(text)
+	for _, term := range terms {
+		if strings.Contains(text, strings.ToLower(term)) {
+			return term
+		}
+	}
+	return ""
+}
+
+// seedSubscriptions 没有任何检索订阅时导入NEWS_KEYWORDS，之后以数据库为准
+func (s *NewsService) seedSubscriptions(ctx context.Context) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.NewsSubscription{}).Count(&count).Error; err != nil {
+		s.logger.Errorf("Failed to count news subscriptions: %v", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	var subscriptions []models.NewsSubscription
+	for _, keyword := range s.keywords {
+		for _, language := range keyword.Languages {
+			if !newsAPILanguages[language] {
+				continue
+			}
+			subscriptions = append(subscriptions, models.NewsSubscription{
+				Type:     SubscriptionTypeKeyword,
+				Keyword:  keyword.Keyword,
+				Language: language,
+				Origin:   SubscriptionOriginConfig,
+				IsActive: true,
+			})
+		}
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&subscriptions).Error
+	if err != nil {
+		s.logger.Errorf("Failed to import news keywords: %v", err)
+		return
+	}
+	s.logger.Infof("Imported %d news subscriptions from NEWS_KEYWORDS", len(subscriptions))
+}
+
+// syncAssetSubscriptions 定时同步资产订阅
+func (s *NewsService) syncAssetSubscriptions(ctx context.Context) error {
+	result, err := s.SyncAssetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	if result.Created > 0 || result.Deactivated > 0 {
+		s.logger.Infof("Asset news subscriptions synced: %d created, %d deactivated", result.Created, result.Deactivated)
+	}
+	return nil
+}
+
+// SyncAssetSubscriptions 按活跃资产的名称和别名生成检索订阅，语言为NEWS_LANGUAGES；
+// 资产停用或别名移除后停用对应的订阅。已有同名的手动订阅时不重复创建
+func (s *NewsService) SyncAssetSubscriptions(ctx context.Context) (*AssetSubscriptionSync, error) {
+	var assets []models.Asset
+	if err := s.db.WithContext(ctx).Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return nil, fmt.Errorf("failed to query assets: %v", err)
+	}
+
+	var languages []string
+	for _, language := range s.config.NewsLanguages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if newsAPILanguages[language] && !contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	if len(languages) == 0 {
+		languages = []string{"en"}
+	}
+
+	desired := make(map[string]bool)
+	var subscriptions []models.NewsSubscription
+	for _, asset := range assets {
+		var metadata assetEntityMetadata
+		if len(asset.Metadata) > 0 {
+			json.Unmarshal(asset.Metadata, &metadata)
+		}
+
+		assetID := asset.ID
+		seen := make(map[string]bool)
+		for _, name := range append([]string{asset.Name}, metadata.Aliases...) {
+			name = strings.TrimSpace(name)
+			key := strings.ToLower(name)
+			if len([]rune(name)) < assetKeywordMinLength || len(name) > subscriptionMaxKeyword || seen[key] {
+				continue
+			}
+			seen[key] = true
+			for _, language := range languages {
+				desired[subscriptionKey(SubscriptionTypeKeyword, name, language)] = true
+				subscriptions = append(subscriptions, models.NewsSubscription{
+					Type:     SubscriptionTypeKeyword,
+					Keyword:  name,
+					Language: language,
+					Origin:   SubscriptionOriginAsset,
+					AssetID:  &assetID,
+					IsActive: true,
+				})
+			}
+		}
+	}
+
+	result := &AssetSubscriptionSync{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(subscriptions) > 0 {
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&subscriptions)
+			if created.Error != nil {
+				return fmt.Errorf("failed to create asset subscriptions: %v", created.Error)
+			}
+			result.Created = int(created.RowsAffected)
+		}
+
+		var existing []models.NewsSubscription
+		if err := tx.Where("origin = ? AND is_active = ?", SubscriptionOriginAsset, true).Find(&existing).Error; err != nil {
+			return fmt.Errorf("failed to query asset subscriptions: %v", err)
+		}
+		var stale []string
+		for _, sub := range existing {
+			if !desired[subscriptionKey(sub.Type, sub.Keyword, sub.Language)] {
+				stale = append(stale, sub.ID)
+			}
+		}
+		if len(stale) > 0 {
+			if err := tx.Model(&models.NewsSubscription{}).Where("id IN ?", stale).Update("is_active", false).Error; err != nil {
+				return fmt.Errorf("failed to deactivate asset subscriptions: %v", err)
+			}
+			result.Deactivated = len(stale)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Created > 0 {
+		s.triggerJob(ctx, newsSubscriptionsJob)
+	}
+	return result, nil
+}
+
+func subscriptionKey(subscriptionType, keyword, language string) string {
+	return subscriptionType + "\x00" + keyword + "\x00" + language
+}
+
+// SaveSubscription 添加或更新检索订阅，保存后立即检索一次；接管的配置或资产订阅改为手动订阅
+func (s *NewsService) SaveSubscription(ctx context.Context, input SubscriptionInput) (*models.NewsSubscription, error) {
+	sub := models.NewsSubscription{
+		Type:            strings.ToLower(strings.TrimSpace(input.Type)),
+		Keyword:         strings.TrimSpace(input.Keyword),
+		Language:        strings.ToLower(strings.TrimSpace(input.Language)),
+		IntervalSeconds: input.IntervalSeconds,
+		MinRelevance:    input.MinRelevance,
+		Origin:          SubscriptionOriginManual,
+		IsActive:        true,
+	}
+	if sub.Type == "" {
+		sub.Type = SubscriptionTypeKeyword
+	}
+	if sub.Language == "" {
+		sub.Language = "en"
+		if len(s.config.NewsLanguages) > 0 {
+			sub.Language = strings.ToLower(strings.TrimSpace(s.config.NewsLanguages[0]))
+		}
+	}
+
+	switch {
+	case sub.Type != SubscriptionTypeKeyword && sub.Type != SubscriptionTypeTopic:
+		return nil, fmt.Errorf("%w: type must be keyword or topic", ErrInvalidSubscription)
+	case sub.Keyword == "":
+		return nil, fmt.Errorf("%w: keyword is required", ErrInvalidSubscription)
+	case len(sub.Keyword) > subscriptionMaxKeyword:
+		return nil, fmt.Errorf("%w: keyword must be at most %d characters", ErrInvalidSubscription, subscriptionMaxKeyword)
+	case !newsAPILanguages[sub.Language]:
+		return nil, fmt.Errorf("%w: NewsAPI does not support language %s", ErrInvalidSubscription, sub.Language)
+	case sub.IntervalSeconds < 0:
+		return nil, fmt.Errorf("%w: interval_seconds must not be negative", ErrInvalidSubscription)
+	case sub.IntervalSeconds > 0 && time.Duration(sub.IntervalSeconds)*time.Second < subscriptionMinInterval:
+		return nil, fmt.Errorf("%w: interval_seconds must be at least %d", ErrInvalidSubscription, int(subscriptionMinInterval.Seconds()))
+	case sub.MinRelevance < 0 || sub.MinRelevance > 1:
+		return nil, fmt.Errorf("%w: min_relevance must be between 0 and 1", ErrInvalidSubscription)
+	}
+	if sub.Type == SubscriptionTypeTopic {
+		sub.Keyword = strings.ToLower(sub.Keyword)
+		if len(s.dictionaries.For(sub.Language).Topic(sub.Keyword)) == 0 {
+			return nil, fmt.Errorf("%w: unknown topic %s", ErrInvalidSubscription, sub.Keyword)
+		}
+	}
+
+	sources := []string{}
+	for _, source := range input.Sources {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source != "" && !contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	sub.Sources, _ = json.Marshal(sources)
+
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "type"}, {Name: "keyword"}, {Name: "language"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"sources":          gorm.Expr("EXCLUDED.sources"),
+			"interval_seconds": gorm.Expr("EXCLUDED.interval_seconds"),
+			"min_relevance":    gorm.Expr("EXCLUDED.min_relevance"),
+			"origin":           SubscriptionOriginManual,
+			"is_active":        true,
+			"next_run_at":      nil,
+			"error_count":      0,
+			"last_error":       nil,
+			"updated_at":       time.Now(),
+		}),
+	}, clause.Returning{}).Create(&sub).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save news subscription: %v", err)
+	}
+
+	s.triggerJob(ctx, newsSubscriptionsJob)
+	return &sub, nil
+}
+
+// ListSubscriptions 列出检索订阅及统计，origin不为空时按来源筛选
+func (s *NewsService) ListSubscriptions(ctx context.Context, origin string) ([]models.NewsSubscription, error) {
+	query := s.db.WithContext(ctx).Model(&models.NewsSubscription{})
+	if origin != "" {
+		query = query.Where("origin = ?", origin)
+	}
+
+	subscriptions := []models.NewsSubscription{}
+	if err := query.Order("type, keyword, language").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list news subscriptions: %v", err)
+	}
+	return subscriptions, nil
+}
+
+// RemoveSubscription 停用检索订阅，保留统计；资产订阅在下次同步时不会被重新启用
+func (s *NewsService) RemoveSubscription(ctx context.Context, id string) error {
+	if !uuidPattern.MatchString(id) {
+		return ErrSubscriptionNotFound
+	}
+	result := s.db.WithContext(ctx).Model(&models.NewsSubscription{}).Where("id = ?", id).Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove news subscription: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
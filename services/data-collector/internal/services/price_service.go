@@ -9,21 +9,26 @@ import (
 	"sync"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/rwa-platform/data-collector/internal/config"
 	"github.com/rwa-platform/data-collector/internal/kafka"
 	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type PriceService struct {
 	db       *gorm.DB
-	redis    *redis.Client
-	kafka    *kafka.Producer
+	redis    RedisCache
+	kafka    messagePublisher
 	config   *config.Config
 	client   *http.Client
 	logger   *logrus.Logger
+	jobs     *scheduler.Scheduler
+	sources  *DataSourceService
 }
 
 type CoinGeckoResponse struct {
@@ -44,63 +49,102 @@ type CoinGeckoPrice struct {
 	LastUpdated       string  `json:"last_updated"`
 }
 
-func NewPriceService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *kafka.Producer, cfg *config.Config) *PriceService {
+func NewPriceService(db *gorm.DB, redisClient RedisCache, kafkaProducer *kafka.Producer, cfg *config.Config) *PriceService {
 	return &PriceService{
 		db:     db,
 		redis:  redisClient,
 		kafka:  kafkaProducer,
 		config: cfg,
 		client: &http.Client{
-			Timeout: time.Duration(cfg.RequestTimeout) * time.Second,
+			Timeout:   time.Duration(cfg.RequestTimeout) * time.Second,
+			Transport: tracing.Transport(nil),
 		},
 		logger: logrus.New(),
 	}
 }
 
-func (s *PriceService) StartPriceCollection(ctx context.Context) {
-	s.logger.Info("Starting price collection service")
-	
-	ticker := time.NewTicker(time.Duration(s.config.PriceCollectionInterval) * time.Second)
-	defer ticker.Stop()
-
-	// 立即执行一次
-	s.collectPrices(ctx)
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Price collection service stopped")
-			return
-		case <-ticker.C:
-			s.collectPrices(ctx)
-		}
-	}
+// priceCollectionJob 价格采集的调度任务名
+const priceCollectionJob = "price-collection"
+
+// RegisterJobs 注册价格采集任务，按PRICE_COLLECTION_INTERVAL固定间隔执行
+func (s *PriceService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	s.jobs = jobs
+
+	interval := time.Duration(s.config.PriceCollectionInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return jobs.Register(scheduler.Spec{
+		Name:     priceCollectionJob,
+		Schedule: scheduler.Every(interval),
+		Timeout:  5 * time.Minute,
+		// 停机期间错过的价格无法补采，恢复后采集一次即可
+		Missed: scheduler.MissedRunOnce,
+		Run:    s.collectPrices,
+	})
 }
 
-func (s *PriceService) collectPrices(ctx context.Context) {
+// UseDataSources 采集请求计入数据源健康统计，API密钥优先使用管理接口设置的密钥
+func (s *PriceService) UseDataSources(sources *DataSourceService) {
+	s.sources = sources
+	s.client.Transport = sources.Transport(s.client.Transport)
+}
+
+func (s *PriceService) collectPrices(ctx context.Context) error {
 	s.logger.Info("Starting price collection cycle")
 
 	// 获取需要采集价格的资产列表
 	var assets []models.Asset
 	if err := s.db.Where("is_active = ?", true).Find(&assets).Error; err != nil {
-		s.logger.Errorf("Failed to fetch assets: %v", err)
-		return
+		return fmt.Errorf("failed to fetch assets: %v", err)
 	}
 
 	if len(assets) == 0 {
 		s.logger.Warn("No active assets found for price collection")
-		return
+		return nil
 	}
 
+	s.seedPriceStaleness(ctx, assets)
+
 	// 按数据源分组采集
 	s.collectFromCoinGecko(ctx, assets)
 	s.collectFromCoinMarketCap(ctx, assets)
 
 	s.logger.Infof("Price collection cycle completed for %d assets", len(assets))
+	return nil
+}
+
+// seedPriceStaleness 服务重启后从数据库读取尚未跟踪资产的最近价格时间，避免价格陈旧指标在下次成功采集前缺失
+func (s *PriceService) seedPriceStaleness(ctx context.Context, assets []models.Asset) {
+	symbols := make([]string, 0)
+	for _, asset := range assets {
+		if !metrics.PriceStaleness.Tracked(asset.Symbol) {
+			symbols = append(symbols, asset.Symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		return
+	}
+
+	var latest []struct {
+		Symbol    string
+		Timestamp time.Time
+	}
+	if err := s.db.WithContext(ctx).Model(&models.PriceData{}).
+		Select("symbol, MAX(timestamp) AS timestamp").
+		Where("symbol IN ?", symbols).
+		Group("symbol").
+		Scan(&latest).Error; err != nil {
+		s.logger.Warnf("Failed to load latest price timestamps: %v", err)
+		return
+	}
+	for _, row := range latest {
+		metrics.PriceStaleness.Touch(row.Symbol, row.Timestamp)
+	}
 }
 
 func (s *PriceService) collectFromCoinGecko(ctx context.Context, assets []models.Asset) {
-	if s.config.CoinGeckoAPIKey == "" {
+	if s.sources.APIKey(DataSourceCoinGecko, s.config.CoinGeckoAPIKey) == "" {
 		s.logger.Debug("CoinGecko API key not configured, skipping")
 		return
 	}
@@ -140,8 +184,8 @@ func (s *PriceService) fetchCoinGeckoPrices(ctx context.Context, symbols []strin
 		return
 	}
 
-	if s.config.CoinGeckoAPIKey != "" {
-		req.Header.Set("X-CG-Demo-API-Key", s.config.CoinGeckoAPIKey)
+	if apiKey := s.sources.APIKey(DataSourceCoinGecko, s.config.CoinGeckoAPIKey); apiKey != "" {
+		req.Header.Set("X-CG-Demo-API-Key", apiKey)
 	}
 
 	resp, err := s.client.Do(req)
@@ -173,14 +217,14 @@ func (s *PriceService) fetchCoinGeckoPrices(ctx context.Context, symbols []strin
 			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
-			s.processPriceData(symbol, data, assetMap, "coingecko")
+			s.processPriceData(ctx, symbol, data, assetMap, "coingecko")
 		}(symbol, data)
 	}
 
 	wg.Wait()
 }
 
-func (s *PriceService) processPriceData(symbol string, data map[string]interface{}, assetMap map[string]models.Asset, source string) {
+func (s *PriceService) processPriceData(ctx context.Context, symbol string, data map[string]interface{}, assetMap map[string]models.Asset, source string) {
 	asset, exists := assetMap[symbol]
 	if !exists {
 		return
@@ -216,14 +260,17 @@ func (s *PriceService) processPriceData(symbol string, data map[string]interface
 	// 保存到数据库
 	if err := s.db.Create(priceData).Error; err != nil {
 		s.logger.Errorf("Failed to save price data for %s: %v", symbol, err)
+		metrics.AddRecords("prices", "failed", 1)
 		return
 	}
+	metrics.AddRecords("prices", "saved", 1)
+	metrics.PriceStaleness.Touch(asset.Symbol, priceData.Timestamp)
 
 	// 更新缓存
 	s.updatePriceCache(asset.Symbol, priceData)
 
 	// 发送到Kafka
-	s.publishPriceUpdate(priceData)
+	s.publishPriceUpdate(ctx, priceData)
 
 	s.logger.Debugf("Updated price for %s: $%.4f", asset.Symbol, price)
 }
@@ -237,12 +284,12 @@ func (s *PriceService) updatePriceCache(symbol string, priceData *models.PriceDa
 		return
 	}
 
-	if err := s.redis.Set(context.Background(), cacheKey, data, time.Duration(s.config.PriceCacheTTL)*time.Second).Err(); err != nil {
+	if err := s.redis.Set(context.Background(), cacheKey, data, time.Duration(s.config.PriceCacheTTL)*time.Second); err != nil {
 		s.logger.Errorf("Failed to update price cache for %s: %v", symbol, err)
 	}
 }
 
-func (s *PriceService) publishPriceUpdate(priceData *models.PriceData) {
+func (s *PriceService) publishPriceUpdate(ctx context.Context, priceData *models.PriceData) {
 	message := map[string]interface{}{
 		"type":      "price_update",
 		"asset_id":  priceData.AssetID,
@@ -257,13 +304,14 @@ func (s *PriceService) publishPriceUpdate(priceData *models.PriceData) {
 		message["change_24h"] = *priceData.Change24h
 	}
 
-	if err := s.kafka.PublishMessage("price-updates", priceData.Symbol, message); err != nil {
+	if err := s.kafka.PublishMessage(ctx, "price-updates", priceData.Symbol, message); err != nil {
 		s.logger.Errorf("Failed to publish price update for %s: %v", priceData.Symbol, err)
 	}
 }
 
 func (s *PriceService) collectFromCoinMarketCap(ctx context.Context, assets []models.Asset) {
-	if s.config.CoinMarketCapAPIKey == "" {
+	apiKey := s.sources.APIKey(DataSourceCoinMarketCap, s.config.CoinMarketCapAPIKey)
+	if apiKey == "" {
 		s.logger.Debug("CoinMarketCap API key not configured, skipping")
 		return
 	}
@@ -292,7 +340,7 @@ func (s *PriceService) collectFromCoinMarketCap(ctx context.Context, assets []mo
 	q.Add("convert", "USD")
 	req.URL.RawQuery = q.Encode()
 
-	req.Header.Set("X-CMC_PRO_API_KEY", s.config.CoinMarketCapAPIKey)
+	req.Header.Set("X-CMC_PRO_API_KEY", apiKey)
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := s.client.Do(req)
@@ -337,7 +385,7 @@ func (s *PriceService) collectFromCoinMarketCap(ctx context.Context, assets []mo
 				"usd_7d_change":      usdQuote.PercentChange7d,
 				"usd_30d_change":     usdQuote.PercentChange30d,
 			}
-			s.processPriceData(strings.ToLower(data.Symbol), priceData, assetMap, "coinmarketcap")
+			s.processPriceData(ctx, strings.ToLower(data.Symbol), priceData, assetMap, "coinmarketcap")
 		}
 	}
 }
@@ -349,6 +397,9 @@ func min(a, b int) int {
 	return b
 }
 
+// priceCache 价格缓存的命中计数，在/admin/stats中输出
+var priceCache = stats.NewCacheCounter("price")
+
 func (s *PriceService) GetPrice(symbol string) (*models.PriceData, error) {
 	// 先从缓存获取
 	cacheKey := fmt.Sprintf("price:%s", symbol)
@@ -356,9 +407,11 @@ func (s *PriceService) GetPrice(symbol string) (*models.PriceData, error) {
 	if err == nil {
 		var priceData models.PriceData
 		if err := json.Unmarshal([]byte(cached), &priceData); err == nil {
+			priceCache.Hit()
 			return &priceData, nil
 		}
 	}
+	priceCache.Miss()
 
 	// 从数据库获取最新价格
 	var priceData models.PriceData
@@ -381,7 +434,7 @@ func (s *PriceService) GetPriceHistory(symbol string, from, to time.Time) ([]mod
 	return priceHistory, nil
 }
 
+// TriggerSync 手动触发价格采集，执行记录与定时执行一起保存
 func (s *PriceService) TriggerSync() error {
-	go s.collectPrices(context.Background())
-	return nil
+	return s.jobs.Trigger(context.Background(), priceCollectionJob)
 }
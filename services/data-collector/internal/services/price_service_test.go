@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/rwa-platform/data-collector/internal/config"
 	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/newsdict"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/driver/sqlite"
@@ -23,9 +26,9 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}
 	return args.Error(0)
 }
 
-func (m *MockRedisClient) Get(ctx context.Context, key string) (string, error) {
+func (m *MockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
 	args := m.Called(ctx, key)
-	return args.String(0), args.Error(1)
+	return redis.NewStringResult(args.String(0), args.Error(1))
 }
 
 // MockKafkaProducer 模拟Kafka生产者
@@ -33,8 +36,8 @@ type MockKafkaProducer struct {
 	mock.Mock
 }
 
-func (m *MockKafkaProducer) PublishMessage(topic string, key string, message interface{}) error {
-	args := m.Called(topic, key, message)
+func (m *MockKafkaProducer) PublishMessage(ctx context.Context, topic string, key string, message interface{}) error {
+	args := m.Called(ctx, topic, key, message)
 	return args.Error(0)
 }
 
@@ -49,8 +52,21 @@ func setupTestDB() *gorm.DB {
 		panic("failed to connect database")
 	}
 
-	// 自动迁移
-	db.AutoMigrate(&models.Asset{}, &models.PriceData{})
+	// 模型中的gen_random_uuid()默认值在SQLite中无法迁移，直接建表
+	for _, ddl := range []string{
+		`CREATE TABLE assets (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), symbol TEXT NOT NULL UNIQUE, name TEXT NOT NULL,
+			type TEXT NOT NULL, contracts BLOB, metadata BLOB, is_active NUMERIC DEFAULT true,
+			created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)`,
+		`CREATE TABLE price_data (
+			id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), asset_id TEXT, symbol TEXT NOT NULL,
+			price REAL NOT NULL, currency TEXT DEFAULT 'USD', volume24h REAL, change24h REAL, change7d REAL,
+			change30d REAL, market_cap REAL, source TEXT NOT NULL, timestamp DATETIME NOT NULL, created_at DATETIME)`,
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			panic("failed to create test tables: " + err.Error())
+		}
+	}
 
 	return db
 }
@@ -69,6 +85,7 @@ func TestPriceService_GetPrice(t *testing.T) {
 		redis:  mockRedis,
 		kafka:  mockKafka,
 		config: cfg,
+		logger: logrus.New(),
 	}
 
 	// 创建测试资产
@@ -117,6 +134,7 @@ func TestPriceService_ProcessPriceData(t *testing.T) {
 		redis:  mockRedis,
 		kafka:  mockKafka,
 		config: cfg,
+		logger: logrus.New(),
 	}
 
 	// 创建测试资产
@@ -142,10 +160,10 @@ func TestPriceService_ProcessPriceData(t *testing.T) {
 
 	// 设置mock期望
 	mockRedis.On("Set", mock.Anything, "price:TEST", mock.Anything, time.Duration(300)*time.Second).Return(nil)
-	mockKafka.On("PublishMessage", "price-updates", "TEST", mock.Anything).Return(nil)
+	mockKafka.On("PublishMessage", mock.Anything, "price-updates", "TEST", mock.Anything).Return(nil)
 
 	// 执行测试
-	service.processPriceData("test", data, assetMap, "test-source")
+	service.processPriceData(context.Background(), "test", data, assetMap, "test-source")
 
 	// 验证数据库中的记录
 	var savedPriceData models.PriceData
@@ -170,6 +188,7 @@ func TestPriceService_GetPriceHistory(t *testing.T) {
 		redis:  mockRedis,
 		kafka:  mockKafka,
 		config: cfg,
+		logger: logrus.New(),
 	}
 
 	// 创建测试价格历史数据
@@ -214,7 +233,7 @@ func TestPriceService_GetPriceHistory(t *testing.T) {
 }
 
 func TestPriceService_CategorizeNews(t *testing.T) {
-	newsService := &NewsService{}
+	newsService := &NewsService{dictionaries: newsdict.NewRegistry()}
 
 	tests := []struct {
 		title       string
@@ -249,13 +268,13 @@ func TestPriceService_CategorizeNews(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := newsService.categorizeNews(test.title, test.description, test.keyword)
+		result := newsService.categorizeNews("en", test.title, test.description, test.keyword)
 		assert.Equal(t, test.expected, result, "Failed for title: %s", test.title)
 	}
 }
 
 func TestPriceService_CalculateRelevance(t *testing.T) {
-	newsService := &NewsService{}
+	newsService := &NewsService{dictionaries: newsdict.NewRegistry()}
 
 	tests := []struct {
 		title       string
@@ -267,7 +286,7 @@ func TestPriceService_CalculateRelevance(t *testing.T) {
 			title:       "RWA Token Launch",
 			description: "New real world assets token",
 			keyword:     "rwa",
-			expected:    0.9, // 标题+描述+相关术语
+			expected:    0.7, // 标题+两个相关术语，描述中没有关键词本身
 		},
 		{
 			title:       "Stablecoin News",
@@ -284,7 +303,7 @@ func TestPriceService_CalculateRelevance(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		result := newsService.calculateRelevance(test.title, test.description, test.keyword)
+		result := newsService.calculateRelevance("en", test.title, test.description, test.keyword)
 		assert.InDelta(t, test.expected, result, 0.1, "Failed for title: %s", test.title)
 	}
 }
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisCache 服务使用的Redis缓存操作，生产环境为internal/redis.Client，测试中可替换
+type RedisCache interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
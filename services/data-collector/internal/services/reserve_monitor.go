@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/reserve"
+	"github.com/sirupsen/logrus"
+)
+
+// 储备证明指标类型，写入metric_data
+const (
+	MetricCollateralizationRatio = "collateralization_ratio"
+	MetricCirculatingSupply      = "circulating_supply"
+	MetricReportedReserves       = "reported_reserves"
+
+	reserveMetricSource = "proof_of_reserve"
+
+	// 未配置时按锚定美元的稳定币计算
+	defaultReserveUnit = "USD"
+	defaultReservePeg  = 1.0
+)
+
+// 发送到asset-events的储备告警类型
+const (
+	// EventReserveShortfall 储备不足以覆盖流通量或储备证明过期，reasons说明原因
+	EventReserveShortfall = "reserve_shortfall"
+)
+
+// reserveConfig assets.metadata中proof_of_reserve字段的配置
+type reserveConfig struct {
+	// Feeds Chainlink兼容（latestRoundData）的链上储备喂价合约，读数以Unit计价
+	Feeds []assetContract `json:"feeds"`
+	// Unit 储备的计价单位，默认USD；证明文件的unit与之不一致时不使用该证明
+	Unit string `json:"unit"`
+	// Peg 单个代币以Unit表示的价值，默认1（如黄金代币按盎司计价时为每个代币对应的盎司数）
+	Peg float64 `json:"peg"`
+	// SupplyChains 计算流通量时统计的链，为空时统计assets.contracts中的所有EVM合约
+	SupplyChains []string `json:"supply_chains"`
+	// 覆盖全局的过期时间（小时）
+	FeedMaxAgeHours        int `json:"feed_max_age_hours"`
+	AttestationMaxAgeHours int `json:"attestation_max_age_hours"`
+}
+
+// ReserveMonitor 比较资产的链上流通量与报告的储备，记录抵押率并在不足或证明过期时发出告警
+type ReserveMonitor struct {
+	service   *BlockchainService
+	logger    *logrus.Logger
+	publisher messagePublisher
+
+	mu      sync.Mutex
+	alerted map[string]string // 资产ID -> 已告警的原因，状态变化时才重新告警
+}
+
+func newReserveMonitor(service *BlockchainService) *ReserveMonitor {
+	return &ReserveMonitor{
+		service:   service,
+		logger:    service.logger,
+		publisher: service.kafka,
+		alerted:   make(map[string]string),
+	}
+}
+
+// checkAll 检查所有配置了储备证明的资产，由reserve-check任务按RESERVE_CHECK_INTERVAL调度
+func (m *ReserveMonitor) checkAll(ctx context.Context) error {
+	var assets []models.Asset
+	if err := m.service.db.WithContext(ctx).Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return fmt.Errorf("failed to load assets for reserve monitoring: %v", err)
+	}
+
+	var attestations map[string]*reserve.Attestation
+	if dir := m.service.config.ReserveAttestationDir; dir != "" {
+		var errs []error
+		attestations, errs = reserve.LoadAttestations(dir)
+		for _, err := range errs {
+			m.logger.Warnf("Skipping reserve attestation: %v", err)
+		}
+	}
+
+	for i := range assets {
+		asset := &assets[i]
+		config := assetReserveConfig(asset)
+		attestation := attestations[strings.ToUpper(asset.Symbol)]
+		if attestation == nil {
+			attestation = attestations[strings.ToUpper(asset.ID)]
+		}
+		if config == nil && attestation == nil {
+			continue
+		}
+		if config == nil {
+			config = &reserveConfig{}
+		}
+
+		if err := m.checkAsset(ctx, asset, config, attestation); err != nil {
+			m.logger.Errorf("Failed to check reserves of %s: %v", asset.Symbol, err)
+		}
+	}
+	return nil
+}
+
+func assetReserveConfig(asset *models.Asset) *reserveConfig {
+	if len(asset.Metadata) == 0 {
+		return nil
+	}
+	var metadata struct {
+		ProofOfReserve *reserveConfig `json:"proof_of_reserve"`
+	}
+	if err := json.Unmarshal(asset.Metadata, &metadata); err != nil {
+		return nil
+	}
+	return metadata.ProofOfReserve
+}
+
+func (m *ReserveMonitor) checkAsset(ctx context.Context, asset *models.Asset, config *reserveConfig, attestation *reserve.Attestation) error {
+	supply, supplyByChain, err := m.circulatingSupply(ctx, asset, config)
+	if err != nil {
+		return err
+	}
+
+	feedMaxAge := hoursOr(config.FeedMaxAgeHours, m.service.config.ReserveFeedMaxAge)
+	attestationMaxAge := hoursOr(config.AttestationMaxAgeHours, m.service.config.ReserveAttestationMaxAge)
+
+	unit := config.Unit
+	if unit == "" {
+		unit = defaultReserveUnit
+	}
+	peg := config.Peg
+	if peg <= 0 {
+		peg = defaultReservePeg
+	}
+
+	// failed 读取失败或无法使用的来源，随告警一起发送
+	var sources []reserve.Source
+	var failed []string
+	for _, feed := range config.Feeds {
+		source, err := m.readFeed(ctx, feed)
+		if err != nil {
+			m.logger.Warnf("Failed to read reserve feed %s on %s for %s: %v", feed.Address, feed.Chain, asset.Symbol, err)
+			failed = append(failed, source.Name)
+			continue
+		}
+		source.MaxAge = feedMaxAge
+		sources = append(sources, source)
+	}
+	if attestation != nil {
+		source, err := attestationSource(attestation, unit, attestationMaxAge)
+		if err != nil {
+			m.logger.Warnf("Ignoring reserve attestation for %s: %v", asset.Symbol, err)
+			failed = append(failed, source.Name)
+		} else {
+			sources = append(sources, source)
+		}
+	}
+
+	now := time.Now()
+	assessment := reserve.Assess(supply, peg, sources, now)
+	if err := m.saveMetrics(ctx, asset, assessment, supplyByChain, unit, peg, failed, now); err != nil {
+		return err
+	}
+
+	m.updateAlert(ctx, asset, assessment, failed, now)
+	return nil
+}
+
+// attestationSource 将证明文件转换为储备来源，证明的计价单位必须与资产配置的单位一致
+func attestationSource(attestation *reserve.Attestation, unit string, maxAge time.Duration) (reserve.Source, error) {
+	source := reserve.Source{
+		Name:     "attestation:" + attestation.File,
+		Reserves: attestation.Reserves,
+		AsOf:     attestation.AsOf,
+		MaxAge:   maxAge,
+	}
+	if attestation.Unit != "" && !strings.EqualFold(attestation.Unit, unit) {
+		return source, fmt.Errorf("%s is denominated in %s, expected %s", attestation.File, attestation.Unit, unit)
+	}
+	return source, nil
+}
+
+func hoursOr(hours, fallback int) time.Duration {
+	if hours <= 0 {
+		hours = fallback
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// circulatingSupply 汇总资产在各EVM链上合约的totalSupply（按精度换算），任一链读取失败时返回错误而不是偏低的流通量
+// 锁定模式的跨链桥托管合约持有的代币已在目标链上铸造，从源链流通量中扣除以免重复计算
+func (m *ReserveMonitor) circulatingSupply(ctx context.Context, asset *models.Asset, config *reserveConfig) (float64, map[string]float64, error) {
+	var contracts []assetContract
+	if len(asset.Contracts) > 0 {
+		if err := json.Unmarshal(asset.Contracts, &contracts); err != nil {
+			return 0, nil, fmt.Errorf("invalid contracts: %v", err)
+		}
+	}
+
+	included := make(map[string]bool, len(config.SupplyChains))
+	for _, chain := range config.SupplyChains {
+		included[chain] = true
+	}
+
+	total := 0.0
+	byChain := make(map[string]float64)
+	for _, contract := range contracts {
+		if contract.Chain == "" || !common.IsHexAddress(contract.Address) {
+			continue
+		}
+		if len(included) > 0 && !included[contract.Chain] {
+			continue
+		}
+
+		m.service.mu.RLock()
+		pool, ok := m.service.pools[contract.Chain]
+		m.service.mu.RUnlock()
+		if !ok {
+			return 0, nil, fmt.Errorf("chain %s is not connected", contract.Chain)
+		}
+
+		supply, err := readCirculatingSupply(ctx, pool, common.HexToAddress(contract.Address), m.service.bridges.escrowsOn(contract.Chain))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read supply on %s: %v", contract.Chain, err)
+		}
+		byChain[contract.Chain] += supply
+		total += supply
+	}
+
+	if len(byChain) == 0 {
+		return 0, nil, fmt.Errorf("no EVM contracts to measure supply")
+	}
+	return total, byChain, nil
+}
+
+func readCirculatingSupply(ctx context.Context, pool *evmrpc.Pool, token common.Address, escrows []string) (float64, error) {
+	calls := []evmrpc.Call{
+		{Target: token, CallData: mustPack("decimals")},
+		{Target: token, CallData: mustPack("totalSupply")},
+	}
+	for _, escrow := range escrows {
+		calls = append(calls, evmrpc.Call{Target: token, CallData: mustPack("balanceOf", common.HexToAddress(escrow))})
+	}
+	results, err := pool.Aggregate(ctx, calls, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	decimals, ok := unpackResult("decimals", results[0])
+	if !ok {
+		return 0, fmt.Errorf("decimals() failed")
+	}
+	supply, ok := unpackResult("totalSupply", results[1])
+	if !ok {
+		return 0, fmt.Errorf("totalSupply() failed")
+	}
+
+	circulating := new(big.Int).Set(supply.(*big.Int))
+	for i, escrow := range escrows {
+		balance, ok := unpackResult("balanceOf", results[2+i])
+		if !ok {
+			return 0, fmt.Errorf("balanceOf(%s) failed", escrow)
+		}
+		circulating.Sub(circulating, balance.(*big.Int))
+	}
+	if circulating.Sign() < 0 {
+		circulating.SetInt64(0)
+	}
+	return scaleAmount(circulating, decimals.(uint8)), nil
+}
+
+// readFeed 读取Chainlink兼容储备喂价的最新一轮数据
+func (m *ReserveMonitor) readFeed(ctx context.Context, feed assetContract) (reserve.Source, error) {
+	source := reserve.Source{Name: fmt.Sprintf("feed:%s:%s", feed.Chain, feed.Address)}
+
+	m.service.mu.RLock()
+	pool, ok := m.service.pools[feed.Chain]
+	m.service.mu.RUnlock()
+	if !ok {
+		return source, fmt.Errorf("chain %s is not connected", feed.Chain)
+	}
+	if !common.IsHexAddress(feed.Address) {
+		return source, fmt.Errorf("invalid feed address")
+	}
+
+	address := common.HexToAddress(feed.Address)
+	results, err := pool.Aggregate(ctx, []evmrpc.Call{
+		{Target: address, CallData: mustPack("decimals")},
+		{Target: address, CallData: mustPack("latestRoundData")},
+	}, nil)
+	if err != nil {
+		return source, err
+	}
+
+	decimals, ok := unpackResult("decimals", results[0])
+	if !ok {
+		return source, fmt.Errorf("decimals() failed")
+	}
+	if !results[1].Success {
+		return source, fmt.Errorf("latestRoundData() failed")
+	}
+	round, err := tokenABI.Unpack("latestRoundData", results[1].ReturnData)
+	if err != nil || len(round) != 5 {
+		return source, fmt.Errorf("invalid latestRoundData() result: %v", err)
+	}
+
+	answer := round[1].(*big.Int)
+	if answer.Sign() < 0 {
+		return source, fmt.Errorf("negative reserve answer %s", answer)
+	}
+	source.Reserves = scaleAmount(answer, decimals.(uint8))
+	source.AsOf = time.Unix(round[3].(*big.Int).Int64(), 0)
+	return source, nil
+}
+
+// scaleAmount 按精度将整数金额换算为浮点数，仅用于比率计算
+func scaleAmount(amount *big.Int, decimals uint8) float64 {
+	value, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(amount),
+		new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)),
+	).Float64()
+	return value
+}
+
+// saveMetrics 写入流通量、储备和抵押率，没有任何储备来源时只记录流通量
+func (m *ReserveMonitor) saveMetrics(ctx context.Context, asset *models.Asset, assessment reserve.Assessment, supplyByChain map[string]float64, unit string, peg float64, failed []string, now time.Time) error {
+	metadata, err := json.Marshal(map[string]interface{}{
+		"supply_by_chain": supplyByChain,
+		"peg":             peg,
+		"liabilities":     assessment.Liabilities,
+		"sources":         assessment.Sources,
+		"failed_sources":  failed,
+		"stale":           assessment.Stale,
+		"shortfall":       assessment.Shortfall,
+	})
+	if err != nil {
+		return err
+	}
+
+	metrics := []*models.MetricData{{
+		AssetID:    &asset.ID,
+		MetricType: MetricCirculatingSupply,
+		Value:      assessment.Supply,
+		Unit:       asset.Symbol,
+		Source:     reserveMetricSource,
+		Metadata:   metadata,
+		Timestamp:  now,
+	}}
+	if len(assessment.Sources) > 0 {
+		metrics = append(metrics,
+			&models.MetricData{
+				AssetID:    &asset.ID,
+				MetricType: MetricReportedReserves,
+				Value:      assessment.Reserves,
+				Unit:       unit,
+				Source:     reserveMetricSource,
+				Metadata:   metadata,
+				Timestamp:  now,
+			},
+			&models.MetricData{
+				AssetID:    &asset.ID,
+				MetricType: MetricCollateralizationRatio,
+				Value:      assessment.Ratio,
+				Unit:       "ratio",
+				Source:     reserveMetricSource,
+				Metadata:   metadata,
+				Timestamp:  now,
+			},
+		)
+	}
+
+	if err := m.service.db.WithContext(ctx).Create(metrics).Error; err != nil {
+		return fmt.Errorf("failed to save reserve metrics: %v", err)
+	}
+	return nil
+}
+
+// updateAlert 抵押不足或没有可用的新鲜储备数据时发送reserve_shortfall，reasons为undercollateralized和/或stale_reserves；
+// 同一状态只告警一次，恢复后重置
+func (m *ReserveMonitor) updateAlert(ctx context.Context, asset *models.Asset, assessment reserve.Assessment, failed []string, now time.Time) {
+	var reasons []string
+	if assessment.Shortfall {
+		reasons = append(reasons, "undercollateralized")
+	}
+	if assessment.Stale {
+		reasons = append(reasons, "stale_reserves")
+	}
+	sort.Strings(reasons)
+	state := strings.Join(reasons, ",")
+
+	m.mu.Lock()
+	previous := m.alerted[asset.ID]
+	m.mu.Unlock()
+
+	if state == "" {
+		if previous != "" {
+			m.setAlerted(asset.ID, "")
+			m.logger.Infof("Reserves of %s recovered (ratio %.4f)", asset.Symbol, assessment.Ratio)
+		}
+		return
+	}
+	if state == previous {
+		return
+	}
+
+	// 所有来源都读取失败时没有储备读数，抵押率和储备为null
+	var ratio, reserves interface{}
+	var reservesAsOf *time.Time
+	if len(assessment.Sources) > 0 {
+		ratio = assessment.Ratio
+		reserves = assessment.Reserves
+		reservesAsOf = &assessment.Sources[0].AsOf
+	}
+
+	message := map[string]interface{}{
+		"type":           EventReserveShortfall,
+		"asset_id":       asset.ID,
+		"symbol":         asset.Symbol,
+		"reasons":        reasons,
+		"ratio":          ratio,
+		"supply":         assessment.Supply,
+		"liabilities":    assessment.Liabilities,
+		"reserves":       reserves,
+		"reserves_as_of": reservesAsOf,
+		"failed_sources": failed,
+		"timestamp":      now.Unix(),
+	}
+	// 发送失败时不记录状态，下一轮重试
+	if err := m.publisher.PublishMessage(ctx, "asset-events", asset.ID, message); err != nil {
+		m.logger.Errorf("Failed to publish %s for %s: %v", EventReserveShortfall, asset.Symbol, err)
+		return
+	}
+	m.setAlerted(asset.ID, state)
+	m.logger.Warnf("Reserve alert %s for %s: %s (ratio %.4f)", EventReserveShortfall, asset.Symbol, state, assessment.Ratio)
+}
+
+func (m *ReserveMonitor) setAlerted(assetID, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state == "" {
+		delete(m.alerted, assetID)
+		return
+	}
+	m.alerted[assetID] = state
+}
+
+// GetReserveHistory 获取资产的抵押率时间序列，按时间正序
+func (s *BlockchainService) GetReserveHistory(ctx context.Context, assetID string, from, to time.Time) ([]models.MetricData, error) {
+	var metrics []models.MetricData
+	err := s.db.WithContext(ctx).
+		Where("asset_id = ? AND metric_type = ? AND timestamp >= ? AND timestamp < ?", assetID, MetricCollateralizationRatio, from, to).
+		Order("timestamp ASC").
+		Find(&metrics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reserve history: %v", err)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/reserve"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttestationSourceRequiresMatchingUnit(t *testing.T) {
+	asOf := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	attestation := &reserve.Attestation{Asset: "PAXG", Reserves: 250880.5, AsOf: asOf, Unit: "oz", File: "paxg.json"}
+
+	source, err := attestationSource(attestation, "OZ", time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "attestation:paxg.json", source.Name)
+	assert.Equal(t, 250880.5, source.Reserves)
+	assert.Equal(t, time.Hour, source.MaxAge)
+
+	// 以盎司计价的证明不能当作美元储备
+	_, err = attestationSource(attestation, defaultReserveUnit, time.Hour)
+	assert.Error(t, err)
+
+	// 未注明单位的证明视为与配置一致
+	attestation.Unit = ""
+	_, err = attestationSource(attestation, defaultReserveUnit, time.Hour)
+	assert.NoError(t, err)
+}
+
+func TestReserveAlerts(t *testing.T) {
+	service := newTestBlockchainService(t)
+	monitor := newReserveMonitor(service)
+	publisher := &fakePublisher{}
+	monitor.publisher = publisher
+
+	ctx := context.Background()
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	asset := &models.Asset{ID: "asset-1", Symbol: "USDY"}
+	feed := func(reserves float64) []reserve.Source {
+		return []reserve.Source{{Name: "feed:ethereum:0x1", Reserves: reserves, AsOf: now.Add(-time.Hour), MaxAge: 24 * time.Hour}}
+	}
+
+	// 所有喂价都读取失败时按储备过期告警
+	failed := []string{"feed:ethereum:0x1"}
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1, nil, now), failed, now)
+	messages := publisher.published()
+	require.Len(t, messages, 1)
+	assert.Equal(t, EventReserveShortfall, messages[0]["type"])
+	assert.Equal(t, []string{"stale_reserves"}, messages[0]["reasons"])
+	assert.Nil(t, messages[0]["ratio"])
+	assert.Equal(t, failed, messages[0]["failed_sources"])
+
+	// 同一状态不重复告警
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1, nil, now), failed, now)
+	assert.Len(t, publisher.published(), 1)
+
+	// 按锚定价值计算负债后储备不足
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1.05, feed(1020), now), nil, now)
+	messages = publisher.published()
+	require.Len(t, messages, 2)
+	assert.Equal(t, EventReserveShortfall, messages[1]["type"])
+	assert.Equal(t, []string{"undercollateralized"}, messages[1]["reasons"])
+	assert.Equal(t, 1050.0, messages[1]["liabilities"])
+
+	// 恢复后重置，再次不足时重新告警
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1, feed(1020), now), nil, now)
+	assert.Len(t, publisher.published(), 2)
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1, feed(900), now), nil, now)
+	messages = publisher.published()
+	require.Len(t, messages, 3)
+	assert.Equal(t, EventReserveShortfall, messages[2]["type"])
+
+	// 储备证明过期时即使抵押充足也发送reserve_shortfall
+	stale := []reserve.Source{{Name: "attestation:usdy.json", Reserves: 1200, AsOf: now.Add(-48 * time.Hour), MaxAge: 24 * time.Hour}}
+	monitor.updateAlert(ctx, asset, reserve.Assess(1000, 1, stale, now), nil, now)
+	messages = publisher.published()
+	require.Len(t, messages, 4)
+	assert.Equal(t, EventReserveShortfall, messages[3]["type"])
+	assert.Equal(t, []string{"stale_reserves"}, messages[3]["reasons"])
+	assert.Equal(t, 1.2, messages[3]["ratio"])
+}
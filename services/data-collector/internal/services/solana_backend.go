@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/solana"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 单次getSignaturesForAddress最多返回的签名数
+const solanaSignaturePageSize = 1000
+
+// solanaBackend 基于Solana JSON-RPC的SPL代币索引实现
+// 以配置的mint地址为入口查询签名，覆盖transferChecked、mint和burn指令；
+// 未携带mint账户的transfer指令只有在同一交易同时引用了mint时才能被发现
+type solanaBackend struct {
+	db     *gorm.DB
+	chain  string
+	client *solana.Client
+	mints  []string
+}
+
+func newSolanaBackend(db *gorm.DB, chain string, client *solana.Client, mints []string) *solanaBackend {
+	return &solanaBackend{
+		db:     db,
+		chain:  chain,
+		client: client,
+		mints:  mints,
+	}
+}
+
+func (b *solanaBackend) LatestBlock(ctx context.Context) (uint64, error) {
+	return b.client.GetSlot(ctx)
+}
+
+func (b *solanaBackend) FetchRange(ctx context.Context, from, to uint64) ([]*BlockData, error) {
+	// 收集区间内所有mint相关的签名，按签名去重
+	signatures := make(map[string]solana.SignatureInfo)
+	for _, mint := range b.mints {
+		if err := b.collectSignatures(ctx, mint, from, to, signatures); err != nil {
+			return nil, fmt.Errorf("failed to list signatures for mint %s: %v", mint, err)
+		}
+	}
+
+	// 按slot升序分组
+	bySlot := make(map[uint64][]solana.SignatureInfo)
+	for _, info := range signatures {
+		if info.Failed() {
+			continue
+		}
+		bySlot[info.Slot] = append(bySlot[info.Slot], info)
+	}
+
+	slots := make([]uint64, 0, len(bySlot))
+	for slot := range bySlot {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	mintSet := make(map[string]bool, len(b.mints))
+	for _, mint := range b.mints {
+		mintSet[mint] = true
+	}
+
+	var blocks []*BlockData
+	for _, slot := range slots {
+		if err := ctx.Err(); err != nil {
+			return blocks, err
+		}
+
+		block, err := b.fetchSlot(ctx, slot, bySlot[slot], mintSet)
+		if err != nil {
+			return blocks, fmt.Errorf("failed to process slot %d on %s: %v", slot, b.chain, err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// collectSignatures 从最新签名向前翻页，直到越过区间起点或到达上次记录的签名。
+// 越过起点时记录遇到的第一个签名（其slot已被索引），下次查询以它为until，翻页量只与新增签名数有关
+func (b *solanaBackend) collectSignatures(ctx context.Context, address string, from, to uint64, out map[string]solana.SignatureInfo) error {
+	until, err := b.loadSignatureCursor(ctx, address, from)
+	if err != nil {
+		return err
+	}
+
+	before := ""
+	for {
+		page, err := b.client.GetSignaturesForAddress(ctx, address, before, until, solanaSignaturePageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range page {
+			if info.Slot < from {
+				return b.saveSignatureCursor(ctx, address, info)
+			}
+			if info.Slot <= to {
+				out[info.Signature] = info
+			}
+		}
+
+		if len(page) < solanaSignaturePageSize {
+			return nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+// loadSignatureCursor 读取地址上次记录的签名；游标回退到该签名的slot之前时不能使用，返回空
+func (b *solanaBackend) loadSignatureCursor(ctx context.Context, address string, from uint64) (string, error) {
+	var cursor models.SignatureCursor
+	err := b.db.WithContext(ctx).Where("chain = ? AND address = ?", b.chain, address).Limit(1).Find(&cursor).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to load signature cursor: %v", err)
+	}
+	if cursor.Signature == "" || cursor.Slot >= from {
+		return "", nil
+	}
+	return cursor.Signature, nil
+}
+
+func (b *solanaBackend) saveSignatureCursor(ctx context.Context, address string, info solana.SignatureInfo) error {
+	cursor := models.SignatureCursor{Chain: b.chain, Address: address, Signature: info.Signature, Slot: info.Slot}
+	err := b.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"signature", "slot", "updated_at"}),
+	}).Create(&cursor).Error
+	if err != nil {
+		return fmt.Errorf("failed to save signature cursor: %v", err)
+	}
+	return nil
+}
+
+func (b *solanaBackend) fetchSlot(ctx context.Context, slot uint64, signatures []solana.SignatureInfo, mints map[string]bool) (*BlockData, error) {
+	block, err := b.client.GetBlock(ctx, slot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block: %v", err)
+	}
+
+	// 保证同一slot内的处理顺序稳定
+	sort.Slice(signatures, func(i, j int) bool { return signatures[i].Signature < signatures[j].Signature })
+
+	data := &BlockData{Number: slot}
+	for _, info := range signatures {
+		tx, err := b.client.GetTransaction(ctx, info.Signature)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction %s: %v", info.Signature, err)
+		}
+
+		events := solana.ExtractTokenEvents(tx, mints)
+		if len(events) == 0 {
+			continue
+		}
+
+		data.Transactions = append(data.Transactions, b.buildTransaction(tx, block, events))
+		for _, event := range events {
+			data.Transfers = append(data.Transfers, b.buildTransfer(event))
+		}
+	}
+
+	return data, nil
+}
+
+func (b *solanaBackend) buildTransaction(tx *solana.Transaction, block *solana.BlockInfo, events []solana.TokenEvent) *models.BlockchainTransaction {
+	status := uint64(1)
+	transaction := &models.BlockchainTransaction{
+		Chain:       b.chain,
+		Hash:        tx.Signature(),
+		BlockNumber: tx.Slot,
+		BlockHash:   block.Blockhash,
+		FromAddress: tx.FeePayer(),
+		Value:       "0",
+		Status:      &status,
+		Timestamp:   tx.Time(),
+	}
+
+	// Solana没有gas的概念，使用消耗的计算单元和手续费（lamports）填充
+	if tx.Meta.ComputeUnitsConsumed != nil {
+		transaction.GasUsed = tx.Meta.ComputeUnitsConsumed
+	}
+	fee := fmt.Sprintf("%d", tx.Meta.Fee)
+	transaction.GasPrice = &fee
+
+	// 保存解析后的代币事件，便于排查
+	if logsData, err := json.Marshal(events); err == nil {
+		transaction.Logs = logsData
+	}
+
+	if transaction.Timestamp.IsZero() && block.BlockTime != nil {
+		transaction.Timestamp = time.Unix(*block.BlockTime, 0)
+	}
+
+	return transaction
+}
+
+func (b *solanaBackend) buildTransfer(event solana.TokenEvent) *models.TokenTransfer {
+	return &models.TokenTransfer{
+		Chain:           b.chain,
+		TransactionHash: event.Signature,
+		LogIndex:        event.Index,
+		ContractAddress: event.Mint,
+		FromAddress:     event.From,
+		ToAddress:       event.To,
+		Value:           event.Amount,
+		TokenDecimals:   event.Decimals,
+		BlockNumber:     event.Slot,
+		Timestamp:       event.BlockTime,
+	}
+}
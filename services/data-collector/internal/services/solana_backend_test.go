@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/solana"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+// signatureServer 模拟getSignaturesForAddress，每个slot一个签名，按before/until截取
+type signatureServer struct {
+	mu     sync.Mutex
+	slots  []uint64 // 降序
+	untils []string
+}
+
+func signatureFor(slot uint64) string {
+	return fmt.Sprintf("sig-%d", slot)
+}
+
+func (s *signatureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var options struct {
+		Before string `json:"before"`
+		Until  string `json:"until"`
+	}
+	_ = json.Unmarshal(req.Params[1], &options)
+
+	s.mu.Lock()
+	s.untils = append(s.untils, options.Until)
+	s.mu.Unlock()
+
+	result := []solana.SignatureInfo{}
+	started := options.Before == ""
+	for _, slot := range s.slots {
+		signature := signatureFor(slot)
+		if signature == options.Until {
+			break
+		}
+		if started {
+			result = append(result, solana.SignatureInfo{Signature: signature, Slot: slot})
+		}
+		if signature == options.Before {
+			started = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+}
+
+func (s *signatureServer) lastUntil() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.untils[len(s.untils)-1]
+}
+
+func TestSolanaCollectSignaturesUsesUntil(t *testing.T) {
+	service := newTestBlockchainService(t, &models.SignatureCursor{})
+	rpc := &signatureServer{}
+	for slot := uint64(130); slot >= 100; slot-- {
+		rpc.slots = append(rpc.slots, slot)
+	}
+	server := httptest.NewServer(rpc)
+	defer server.Close()
+
+	backend := newSolanaBackend(service.db, "solana", solana.NewClient(server.URL, 5*time.Second), []string{testMint})
+	ctx := context.Background()
+
+	// 第一次没有游标，翻页到区间起点之前并记录该签名
+	out := make(map[string]solana.SignatureInfo)
+	require.NoError(t, backend.collectSignatures(ctx, testMint, 120, 125, out))
+	assert.Len(t, out, 6)
+	assert.Equal(t, "", rpc.lastUntil())
+
+	// 下一个区间只查询记录的签名之后的部分
+	out = make(map[string]solana.SignatureInfo)
+	require.NoError(t, backend.collectSignatures(ctx, testMint, 126, 130, out))
+	assert.Len(t, out, 5)
+	assert.Contains(t, out, signatureFor(126))
+	assert.Equal(t, signatureFor(119), rpc.lastUntil())
+
+	var cursor models.SignatureCursor
+	require.NoError(t, service.db.Where("chain = ? AND address = ?", "solana", testMint).First(&cursor).Error)
+	assert.Equal(t, uint64(125), cursor.Slot)
+
+	// 游标回退到记录的签名之前时不使用until
+	out = make(map[string]solana.SignatureInfo)
+	require.NoError(t, backend.collectSignatures(ctx, testMint, 110, 112, out))
+	assert.Len(t, out, 3)
+	assert.Equal(t, "", rpc.lastUntil())
+}
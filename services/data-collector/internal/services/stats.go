@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/shared/stats"
+)
+
+// FillStats 价格数据按来源的写入量
+func (s *PriceService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.IngestionSection(s.db, "price_data", "source", "created_at")(ctx, report)
+}
+
+// FillStats 新闻按来源的写入量、待复核的监管事件、到期未执行的订阅以及订阅源的同步情况
+func (s *NewsService) FillStats(ctx context.Context, report *stats.Report) error {
+	sections := []stats.Section{
+		stats.IngestionSection(s.db, "news_articles", "source", "created_at"),
+		stats.PendingSection("regulatory_events:pending_review", s.db, &models.RegulatoryEvent{},
+			"status = ?", RegulatoryStatusPending),
+		stats.PendingSection("news_subscriptions:due", s.db, &models.NewsSubscription{},
+			"is_active = ? AND (next_run_at IS NULL OR next_run_at <= ?)", true, report.GeneratedAt),
+	}
+	for _, section := range sections {
+		if err := section(ctx, report); err != nil {
+			return err
+		}
+	}
+
+	var feeds []models.DataSource
+	if err := s.db.WithContext(ctx).
+		Where("type = ? AND is_active = ?", DataSourceTypeFeed, true).
+		Order("name").
+		Find(&feeds).Error; err != nil {
+		return fmt.Errorf("failed to get feed sources: %v", err)
+	}
+	for _, feed := range feeds {
+		activity := stats.Activity{
+			Name:          "feed:" + feed.Name,
+			Kind:          stats.ActivityKindCollector,
+			LastSuccessAt: feed.LastSyncAt,
+		}
+		if feed.LastError != nil {
+			activity.LastError = *feed.LastError
+		}
+		report.Activity = append(report.Activity, activity)
+	}
+	return nil
+}
+
+// FillStats 链上交易和代币转账按链的写入量、待匹配的跨链转账以及各链索引器的进度
+func (s *BlockchainService) FillStats(ctx context.Context, report *stats.Report) error {
+	sections := []stats.Section{
+		stats.IngestionSection(s.db, "blockchain_transactions", "chain", "created_at"),
+		stats.IngestionSection(s.db, "token_transfers", "chain", "created_at"),
+		stats.PendingSection("bridge_transfers:pending", s.db, &models.BridgeTransfer{},
+			"status = ?", BridgeStatusPending),
+	}
+	for _, section := range sections {
+		if err := section(ctx, report); err != nil {
+			return err
+		}
+	}
+
+	statuses := s.GetIndexerStatuses()
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Chain < statuses[j].Chain })
+	for _, status := range statuses {
+		name := status.Chain + "-indexer"
+		report.Activity = append(report.Activity, stats.Activity{
+			Name:          name,
+			Kind:          stats.ActivityKindCollector,
+			LastSuccessAt: status.LastSuccessAt,
+			LastError:     status.LastError,
+		})
+		report.Queues = append(report.Queues, stats.Queue{
+			Name:  name,
+			Kind:  stats.QueueKindBlocks,
+			Depth: int64(status.Lag),
+		})
+	}
+	return nil
+}
+
+// FillStats 监管文件按来源的写入量
+func (s *FilingService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.IngestionSection(s.db, "filings", "source", "created_at")(ctx, report)
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rwa-platform/data-collector/internal/bridge"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor 分页游标无法解析
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// 钱包活动查询的转账方向
+const (
+	DirectionIn   = "in"
+	DirectionOut  = "out"
+	DirectionSelf = "self"
+	// DirectionBridge 同一钱包在两条链之间的跨链转账，只显示出站一侧
+	DirectionBridge = "bridge"
+)
+
+// 链注册表未指定时原生代币的精度
+const defaultNativeDecimals = 18
+
+// WalletActivityQuery 钱包活动查询条件
+type WalletActivityQuery struct {
+	Address   string
+	Chain     string
+	Token     string
+	Direction string
+	From      *time.Time
+	To        *time.Time
+	Cursor    string
+	Limit     int
+}
+
+// WalletTransfer 钱包视角的代币转账，Amount已按代币精度换算，Bridge为关联的跨链转账
+type WalletTransfer struct {
+	models.TokenTransfer
+	Direction string                 `json:"direction"`
+	Amount    *string                `json:"amount"`
+	Bridge    *models.BridgeTransfer `json:"bridge,omitempty"`
+}
+
+// WalletTransaction 钱包视角的交易，Amount为按原生代币精度换算后的金额
+type WalletTransaction struct {
+	models.BlockchainTransaction
+	Direction string `json:"direction"`
+	Amount    string `json:"amount"`
+}
+
+// activityCursor 按(timestamp, id)倒序分页的位置
+type activityCursor struct {
+	Timestamp time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeCursor(timestamp time.Time, id string) string {
+	data, _ := json.Marshal(activityCursor{Timestamp: timestamp, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (*activityCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor activityCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// applyActivityFilters 添加链、时间范围和游标条件，并按时间倒序取limit+1条判断是否还有下一页
+func applyActivityFilters(query *gorm.DB, q WalletActivityQuery) (*gorm.DB, error) {
+	if q.Chain != "" {
+		query = query.Where("chain = ?", q.Chain)
+	}
+	if q.From != nil {
+		query = query.Where("timestamp >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("timestamp < ?", *q.To)
+	}
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(timestamp, id) < (?, ?)", cursor.Timestamp, cursor.ID)
+	}
+
+	return query.Order("timestamp DESC, id DESC").Limit(q.Limit + 1), nil
+}
+
+func walletDirectionFilter(query *gorm.DB, address, direction string) *gorm.DB {
+	switch direction {
+	case DirectionIn:
+		return query.Where("to_address = ?", address)
+	case DirectionOut:
+		return query.Where("from_address = ?", address)
+	default:
+		return query.Where("from_address = ? OR to_address = ?", address, address)
+	}
+}
+
+// GetWalletTransfers 获取钱包的代币转账记录，返回结果和下一页游标
+func (s *BlockchainService) GetWalletTransfers(ctx context.Context, q WalletActivityQuery) ([]WalletTransfer, string, error) {
+	address := normalizeAddress(q.Address)
+
+	query := walletDirectionFilter(s.db.Model(&models.TokenTransfer{}), address, q.Direction)
+	if q.Token != "" {
+		query = query.Where("contract_address = ?", normalizeAddress(q.Token))
+	}
+	// 钱包自己发起并已完成的跨链转账，目标链上的入站一侧不再单独显示
+	query = query.Where("bridge_transfer_id IS NULL OR bridge_direction <> ? OR bridge_transfer_id NOT IN (?)",
+		bridge.Inbound,
+		s.db.Model(&models.BridgeTransfer{}).Select("id").Where("status = ? AND sender = ? AND recipient = ?", BridgeStatusCompleted, address, address))
+	query, err := applyActivityFilters(query, q)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var transfers []models.TokenTransfer
+	if err := query.WithContext(ctx).Find(&transfers).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to query wallet transfers: %v", err)
+	}
+
+	nextCursor := ""
+	if len(transfers) > q.Limit {
+		transfers = transfers[:q.Limit]
+		last := transfers[len(transfers)-1]
+		nextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+
+	decimals := s.resolveTokenDecimals(ctx, transfers)
+	bridges, err := s.loadBridgeTransfers(ctx, transfers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]WalletTransfer, len(transfers))
+	for i, transfer := range transfers {
+		result[i] = WalletTransfer{
+			TokenTransfer: transfer,
+			Direction:     transferDirection(address, transfer.FromAddress, transfer.ToAddress),
+		}
+		if d, ok := decimals[tokenKey(transfer.Chain, transfer.ContractAddress)]; ok {
+			result[i].TokenDecimals = &d
+			amount := formatUnits(transfer.Value, d)
+			result[i].Amount = &amount
+		}
+		if transfer.BridgeTransferID != nil {
+			bridged := bridges[*transfer.BridgeTransferID]
+			result[i].Bridge = bridged
+			if bridged != nil && bridged.Status == BridgeStatusCompleted &&
+				bridged.Sender != nil && *bridged.Sender == address &&
+				bridged.Recipient != nil && *bridged.Recipient == address {
+				result[i].Direction = DirectionBridge
+			}
+		}
+	}
+
+	return result, nextCursor, nil
+}
+
+// loadBridgeTransfers 加载一页转账关联的跨链转账
+func (s *BlockchainService) loadBridgeTransfers(ctx context.Context, transfers []models.TokenTransfer) (map[string]*models.BridgeTransfer, error) {
+	var ids []string
+	for _, transfer := range transfers {
+		if transfer.BridgeTransferID != nil {
+			ids = append(ids, *transfer.BridgeTransferID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var bridges []models.BridgeTransfer
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Find(&bridges).Error; err != nil {
+		return nil, fmt.Errorf("failed to query bridge transfers: %v", err)
+	}
+	result := make(map[string]*models.BridgeTransfer, len(bridges))
+	for i := range bridges {
+		result[bridges[i].ID] = &bridges[i]
+	}
+	return result, nil
+}
+
+// GetWalletTransactions 获取钱包发起或接收的交易，指定token时只返回产生了该代币转账的交易
+func (s *BlockchainService) GetWalletTransactions(ctx context.Context, q WalletActivityQuery) ([]WalletTransaction, string, error) {
+	address := normalizeAddress(q.Address)
+
+	query := walletDirectionFilter(s.db.Model(&models.BlockchainTransaction{}), address, q.Direction)
+	if q.Token != "" {
+		query = query.Where("hash IN (?)", s.db.Model(&models.TokenTransfer{}).
+			Select("transaction_hash").
+			Where("contract_address = ?", normalizeAddress(q.Token)))
+	}
+	query, err := applyActivityFilters(query, q)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var transactions []models.BlockchainTransaction
+	if err := query.WithContext(ctx).Find(&transactions).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to query wallet transactions: %v", err)
+	}
+
+	nextCursor := ""
+	if len(transactions) > q.Limit {
+		transactions = transactions[:q.Limit]
+		last := transactions[len(transactions)-1]
+		nextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+
+	result := make([]WalletTransaction, len(transactions))
+	for i, transaction := range transactions {
+		to := ""
+		if transaction.ToAddress != nil {
+			to = *transaction.ToAddress
+		}
+
+		result[i] = WalletTransaction{
+			BlockchainTransaction: transaction,
+			Direction:             transferDirection(address, transaction.FromAddress, to),
+			Amount:                formatUnits(transaction.Value, s.nativeDecimals(transaction.Chain)),
+		}
+	}
+
+	return result, nextCursor, nil
+}
+
+func transferDirection(address, from, to string) string {
+	if strings.EqualFold(from, address) && !strings.EqualFold(to, address) {
+		return DirectionOut
+	}
+	if strings.EqualFold(to, address) && !strings.EqualFold(from, address) {
+		return DirectionIn
+	}
+	return DirectionSelf
+}
+
+func tokenKey(chain, contract string) string {
+	return chain + ":" + contract
+}
+
+// resolveTokenDecimals 获取转账涉及代币的精度：优先使用索引时记录的值，其次缓存，最后链上读取
+func (s *BlockchainService) resolveTokenDecimals(ctx context.Context, transfers []models.TokenTransfer) map[string]uint8 {
+	decimals := make(map[string]uint8)
+	missing := make(map[string][2]string)
+
+	for _, transfer := range transfers {
+		key := tokenKey(transfer.Chain, transfer.ContractAddress)
+		if transfer.TokenDecimals != nil {
+			decimals[key] = *transfer.TokenDecimals
+			continue
+		}
+		if _, ok := decimals[key]; !ok {
+			missing[key] = [2]string{transfer.Chain, transfer.ContractAddress}
+		}
+	}
+
+	for key := range decimals {
+		delete(missing, key)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for key, token := range missing {
+		wg.Add(1)
+		go func(key, chain, contract string) {
+			defer wg.Done()
+
+			d, ok := s.tokenDecimals(ctx, chain, contract)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			decimals[key] = d
+			mu.Unlock()
+		}(key, token[0], token[1])
+	}
+	wg.Wait()
+
+	return decimals
+}
+
+// tokenDecimals 读取EVM代币精度，结果长期缓存在Redis中
+func (s *BlockchainService) tokenDecimals(ctx context.Context, chain, contract string) (uint8, bool) {
+	cacheKey := fmt.Sprintf("token_decimals:%s:%s", chain, contract)
+	if cached, err := s.redis.Get(ctx, cacheKey).Int(); err == nil && cached >= 0 && cached <= 255 {
+		return uint8(cached), true
+	}
+
+	s.mu.RLock()
+	pool, ok := s.pools[chain]
+	s.mu.RUnlock()
+	if !ok || !common.IsHexAddress(contract) {
+		return 0, false
+	}
+
+	output, err := pool.Call(ctx, common.HexToAddress(contract), mustPack("decimals"), nil)
+	if err != nil {
+		s.logger.Warnf("Failed to read decimals of %s on %s: %v", contract, chain, err)
+		return 0, false
+	}
+	value, ok := unpackResult("decimals", evmrpc.CallResult{Success: true, ReturnData: output})
+	if !ok {
+		return 0, false
+	}
+	d, ok := value.(uint8)
+	if !ok {
+		return 0, false
+	}
+
+	// 代币精度不会变化，缓存一周
+	if err := s.redis.Set(ctx, cacheKey, int(d), 7*24*time.Hour); err != nil {
+		s.logger.Warnf("Failed to cache decimals of %s on %s: %v", contract, chain, err)
+	}
+	return d, true
+}
+
+// formatUnits 将最小单位的整数金额按精度换算为十进制字符串，不损失精度
+func formatUnits(value string, decimals uint8) string {
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return value
+	}
+	if decimals == 0 {
+		return amount.String()
+	}
+
+	negative := amount.Sign() < 0
+	digits := new(big.Int).Abs(amount).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-int(decimals)]
+	fraction := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+
+	result := whole
+	if fraction != "" {
+		result += "." + fraction
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWallet = "0x1111111111111111111111111111111111111111"
+
+// newWalletActivityService 建立查询用到的表，模型中的gen_random_uuid()默认值在SQLite中无法迁移
+func newWalletActivityService(t *testing.T) *BlockchainService {
+	t.Helper()
+
+	service := newTestBlockchainService(t)
+	for _, statement := range []string{
+		`CREATE TABLE token_transfers (
+			id TEXT PRIMARY KEY, chain TEXT, transaction_hash TEXT, log_index INTEGER,
+			contract_address TEXT, from_address TEXT, to_address TEXT, value TEXT,
+			token_symbol TEXT, token_name TEXT, token_decimals INTEGER, block_number INTEGER,
+			timestamp DATETIME, bridge_transfer_id TEXT, bridge_direction TEXT, created_at DATETIME)`,
+		`CREATE TABLE bridge_transfers (id TEXT PRIMARY KEY, status TEXT, sender TEXT, recipient TEXT)`,
+	} {
+		require.NoError(t, service.db.Exec(statement).Error)
+	}
+	return service
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 30, 15, 123456789, time.UTC)
+
+	cursor, err := decodeCursor(encodeCursor(timestamp, "transfer-1"))
+	require.NoError(t, err)
+	assert.True(t, cursor.Timestamp.Equal(timestamp))
+	assert.Equal(t, "transfer-1", cursor.ID)
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	cases := map[string]string{
+		"not base64":   "%%%",
+		"not json":     base64.RawURLEncoding.EncodeToString([]byte("timestamp")),
+		"missing id":   base64.RawURLEncoding.EncodeToString([]byte(`{"t":"2024-05-01T00:00:00Z"}`)),
+		"bad time":     base64.RawURLEncoding.EncodeToString([]byte(`{"t":"yesterday","id":"x"}`)),
+		"padded input": base64.URLEncoding.EncodeToString([]byte(`{"t":"2024-05-01T00:00:00Z","id":"x"}`)),
+	}
+	for name, value := range cases {
+		_, err := decodeCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, name)
+	}
+
+	service := newWalletActivityService(t)
+	_, _, err := service.GetWalletTransfers(context.Background(), WalletActivityQuery{Address: testWallet, Cursor: "%%%", Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestWalletTransfersPagesAcrossEqualTimestamps(t *testing.T) {
+	service := newWalletActivityService(t)
+
+	// 5条转账共用同一时间戳，另有一条更早的转账
+	same := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	decimals := uint8(6)
+	for i := 0; i < 6; i++ {
+		timestamp := same
+		if i == 5 {
+			timestamp = same.Add(-time.Minute)
+		}
+		require.NoError(t, service.db.Create(&models.TokenTransfer{
+			ID:              fmt.Sprintf("transfer-%d", i),
+			Chain:           "ethereum",
+			TransactionHash: fmt.Sprintf("0xhash%d", i),
+			ContractAddress: "0xtoken",
+			FromAddress:     testWallet,
+			ToAddress:       "0x2222222222222222222222222222222222222222",
+			Value:           "1000000",
+			TokenDecimals:   &decimals,
+			Timestamp:       timestamp,
+		}).Error)
+	}
+
+	var ids []string
+	cursor := ""
+	pages := 0
+	for {
+		page, next, err := service.GetWalletTransfers(context.Background(), WalletActivityQuery{Address: testWallet, Cursor: cursor, Limit: 2})
+		require.NoError(t, err)
+		pages++
+		for _, transfer := range page {
+			ids = append(ids, transfer.ID)
+			assert.Equal(t, DirectionOut, transfer.Direction)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+		require.Less(t, pages, 10, "pagination did not terminate")
+	}
+
+	// 相同时间戳按id倒序，既不重复也不遗漏
+	assert.Equal(t, []string{"transfer-4", "transfer-3", "transfer-2", "transfer-1", "transfer-0", "transfer-5"}, ids)
+	assert.Equal(t, 3, pages)
+}
+
+func TestWalletTransfersLastPageHasNoCursor(t *testing.T) {
+	service := newWalletActivityService(t)
+	decimals := uint8(6)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, service.db.Create(&models.TokenTransfer{
+			ID:              fmt.Sprintf("transfer-%d", i),
+			Chain:           "ethereum",
+			TransactionHash: fmt.Sprintf("0xhash%d", i),
+			ContractAddress: "0xtoken",
+			FromAddress:     "0x2222222222222222222222222222222222222222",
+			ToAddress:       testWallet,
+			Value:           "2500000",
+			TokenDecimals:   &decimals,
+			Timestamp:       time.Date(2024, 5, 1, 12, 0, i, 0, time.UTC),
+		}).Error)
+	}
+
+	// 恰好取满一页时不返回游标
+	page, next, err := service.GetWalletTransfers(context.Background(), WalletActivityQuery{Address: testWallet, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Empty(t, next)
+	assert.Equal(t, DirectionIn, page[0].Direction)
+	require.NotNil(t, page[0].Amount)
+	assert.Equal(t, "2.5", *page[0].Amount)
+}
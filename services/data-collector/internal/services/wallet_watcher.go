@@ -0,0 +1,588 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rwa-platform/data-collector/internal/evmrpc"
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/rwa-platform/data-collector/internal/solana"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 钱包监控调度参数
+const (
+	walletCheckInterval     = 15 * time.Second
+	walletRegistryRefresh   = time.Minute
+	walletReconcileInterval = time.Hour
+	walletMulticallSize     = 200
+)
+
+var ErrWalletNotFound = errors.New("wallet not found")
+
+// ErrInvalidAddress 钱包地址既不是EVM地址也不是Solana地址
+var ErrInvalidAddress = errors.New("invalid wallet address")
+
+// assetContract assets.contracts中的合约条目，chain为空时视为所有EVM链
+type assetContract struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+}
+
+// balanceCheck 待确认的余额检查项，pending中的值为观察到变化的区块
+type balanceCheck struct {
+	Chain   string
+	Token   string
+	Address string
+}
+
+// messagePublisher 发送事件，生产环境为Kafka生产者，测试中可替换
+type messagePublisher interface {
+	PublishMessage(ctx context.Context, topic string, key string, message interface{}) error
+}
+
+// WalletWatcher 监控用户关联钱包在各链上的代币余额，确认后的变化发送到balance-updates
+type WalletWatcher struct {
+	service   *BlockchainService
+	logger    *logrus.Logger
+	publisher messagePublisher
+
+	mu       sync.Mutex
+	wallets  map[string][]models.WatchedWallet // 地址 -> 关联该地址的钱包
+	tokens   map[string][]string               // 链 -> 跟踪的代币
+	decimals map[string]uint8                  // tokenKey -> 代币精度
+	pending  map[balanceCheck]uint64
+	loadedAt time.Time
+}
+
+func newWalletWatcher(service *BlockchainService) *WalletWatcher {
+	return &WalletWatcher{
+		service:   service,
+		logger:    service.logger,
+		publisher: service.kafka,
+		wallets:   make(map[string][]models.WatchedWallet),
+		tokens:    make(map[string][]string),
+		decimals:  make(map[string]uint8),
+		pending:   make(map[balanceCheck]uint64),
+	}
+}
+
+// Run 周期性处理待确认的余额变化，并定期全量对账
+func (w *WalletWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(walletCheckInterval)
+	defer ticker.Stop()
+
+	lastReconcile := time.Time{}
+	for {
+		if err := w.refreshRegistry(ctx, false); err != nil {
+			w.logger.Errorf("Failed to load wallet watchlist: %v", err)
+		} else if time.Since(lastReconcile) >= walletReconcileInterval {
+			// 对账覆盖服务重启期间丢失的待检查项，以及新上架的代币
+			w.enqueueAll(0)
+			lastReconcile = time.Now()
+		}
+
+		w.processPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ObserveTransfers 记录涉及被监控钱包的转账，等待确认后再读取余额
+func (w *WalletWatcher) ObserveTransfers(transfers []*models.TokenTransfer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, transfer := range transfers {
+		for _, address := range []string{transfer.FromAddress, transfer.ToAddress} {
+			if _, ok := w.wallets[address]; !ok {
+				continue
+			}
+			check := balanceCheck{Chain: transfer.Chain, Token: transfer.ContractAddress, Address: address}
+			if transfer.BlockNumber > w.pending[check] {
+				w.pending[check] = transfer.BlockNumber
+			}
+		}
+	}
+}
+
+// refreshRegistry 从数据库加载监控钱包和跟踪代币
+func (w *WalletWatcher) refreshRegistry(ctx context.Context, force bool) error {
+	w.mu.Lock()
+	fresh := time.Since(w.loadedAt) < walletRegistryRefresh
+	w.mu.Unlock()
+	if fresh && !force {
+		return nil
+	}
+
+	var wallets []models.WatchedWallet
+	if err := w.service.db.WithContext(ctx).Where("is_active = ?", true).Find(&wallets).Error; err != nil {
+		return err
+	}
+
+	var assets []models.Asset
+	if err := w.service.db.WithContext(ctx).Select("contracts").Where("is_active = ?", true).Find(&assets).Error; err != nil {
+		return err
+	}
+
+	byAddress := make(map[string][]models.WatchedWallet)
+	for _, wallet := range wallets {
+		address := normalizeAddress(wallet.Address)
+		byAddress[address] = append(byAddress[address], wallet)
+	}
+
+	tokens := w.trackedTokens(assets)
+
+	w.mu.Lock()
+	w.wallets = byAddress
+	w.tokens = tokens
+	w.loadedAt = time.Now()
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *WalletWatcher) trackedTokens(assets []models.Asset) map[string][]string {
+	w.service.mu.RLock()
+	var evmChains []string
+	for chain := range w.service.pools {
+		evmChains = append(evmChains, chain)
+	}
+	w.service.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	tokens := make(map[string][]string)
+	add := func(chain, token string) {
+		key := tokenKey(chain, token)
+		if !seen[key] {
+			seen[key] = true
+			tokens[chain] = append(tokens[chain], token)
+		}
+	}
+
+	for _, asset := range assets {
+		if len(asset.Contracts) == 0 {
+			continue
+		}
+		var contracts []assetContract
+		if err := json.Unmarshal(asset.Contracts, &contracts); err != nil {
+			continue
+		}
+		for _, contract := range contracts {
+			if contract.Address == "" {
+				continue
+			}
+			if contract.Chain != "" {
+				add(contract.Chain, normalizeAddress(contract.Address))
+				continue
+			}
+			if common.IsHexAddress(contract.Address) {
+				for _, chain := range evmChains {
+					add(chain, normalizeAddress(contract.Address))
+				}
+			}
+		}
+	}
+
+	for _, mint := range w.service.config.SolanaMintAddresses {
+		add("solana", mint)
+	}
+
+	return tokens
+}
+
+// enqueueAll 将所有钱包在所有跟踪代币上的余额加入检查队列，address为空时包含全部钱包
+func (w *WalletWatcher) enqueueAll(block uint64, addresses ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(addresses) == 0 {
+		for address := range w.wallets {
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, address := range addresses {
+		evm := common.IsHexAddress(address)
+		for chain, tokens := range w.tokens {
+			if evm != (chain != "solana") {
+				continue
+			}
+			for _, token := range tokens {
+				check := balanceCheck{Chain: chain, Token: token, Address: address}
+				if _, ok := w.pending[check]; !ok || block > w.pending[check] {
+					w.pending[check] = block
+				}
+			}
+		}
+	}
+}
+
+// processPending 按链处理已达到确认数的检查项
+func (w *WalletWatcher) processPending(ctx context.Context) {
+	w.mu.Lock()
+	byChain := make(map[string]map[balanceCheck]uint64)
+	for check, block := range w.pending {
+		if byChain[check.Chain] == nil {
+			byChain[check.Chain] = make(map[balanceCheck]uint64)
+		}
+		byChain[check.Chain][check] = block
+	}
+	w.mu.Unlock()
+
+	for chain, checks := range byChain {
+		if err := w.processChain(ctx, chain, checks); err != nil {
+			w.logger.Errorf("Failed to check wallet balances on %s: %v", chain, err)
+		}
+	}
+}
+
+func (w *WalletWatcher) processChain(ctx context.Context, chain string, checks map[balanceCheck]uint64) error {
+	w.service.mu.RLock()
+	pool := w.service.pools[chain]
+	chainConfig := w.service.chains[chain]
+	w.service.mu.RUnlock()
+
+	var confirmed uint64
+	var ready []balanceCheck
+	var balances []*big.Int
+	var err error
+
+	switch {
+	case pool != nil:
+		head, err := pool.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if confirmed, ready, ok = confirmedChecks(checks, head, chainConfig.Confirmations); !ok {
+			return nil
+		}
+		balances, err = w.readEVMBalances(ctx, pool, ready, confirmed)
+		if err != nil {
+			return err
+		}
+	case chain == "solana" && w.service.solana != nil:
+		// Solana客户端使用finalized确认级别，读取的余额即为已确认余额
+		if confirmed, err = w.service.solana.GetSlot(ctx); err != nil {
+			return err
+		}
+		for check := range checks {
+			ready = append(ready, check)
+		}
+		balances, err = w.readSolanaBalances(ctx, ready)
+		if err != nil {
+			return err
+		}
+	default:
+		// 链未连接，丢弃检查项
+		w.mu.Lock()
+		for check := range checks {
+			delete(w.pending, check)
+		}
+		w.mu.Unlock()
+		return nil
+	}
+
+	for i, check := range ready {
+		// 读取失败的代币（如非ERC-20合约）直接跳过，等待下一次对账
+		if balances[i] != nil {
+			if err := w.applyBalance(ctx, check, balances[i], confirmed); err != nil {
+				return err
+			}
+		}
+
+		// 处理期间若有更新的变化则保留
+		w.mu.Lock()
+		if w.pending[check] <= confirmed {
+			delete(w.pending, check)
+		}
+		w.mu.Unlock()
+	}
+
+	return nil
+}
+
+// confirmedChecks 返回链头减去确认数后的已确认区块，以及变化发生在该区块及之前的检查项
+func confirmedChecks(checks map[balanceCheck]uint64, head, confirmations uint64) (uint64, []balanceCheck, bool) {
+	if head < confirmations {
+		return 0, nil, false
+	}
+	confirmed := head - confirmations
+
+	var ready []balanceCheck
+	for check, block := range checks {
+		if block <= confirmed {
+			ready = append(ready, check)
+		}
+	}
+	return confirmed, ready, true
+}
+
+func (w *WalletWatcher) readEVMBalances(ctx context.Context, pool *evmrpc.Pool, checks []balanceCheck, block uint64) ([]*big.Int, error) {
+	balances := make([]*big.Int, len(checks))
+	blockNumber := new(big.Int).SetUint64(block)
+
+	for start := 0; start < len(checks); start += walletMulticallSize {
+		end := min(start+walletMulticallSize, len(checks))
+
+		calls := make([]evmrpc.Call, 0, end-start)
+		for _, check := range checks[start:end] {
+			calls = append(calls, evmrpc.Call{
+				Target:   common.HexToAddress(check.Token),
+				CallData: mustPack("balanceOf", common.HexToAddress(check.Address)),
+			})
+		}
+
+		results, err := pool.Aggregate(ctx, calls, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, result := range results {
+			if value, ok := unpackResult("balanceOf", result); ok {
+				if balance, ok := value.(*big.Int); ok {
+					balances[start+i] = balance
+				}
+			}
+		}
+	}
+
+	return balances, nil
+}
+
+func (w *WalletWatcher) readSolanaBalances(ctx context.Context, checks []balanceCheck) ([]*big.Int, error) {
+	balances := make([]*big.Int, len(checks))
+	for i, check := range checks {
+		balance, err := w.service.solana.GetTokenBalance(ctx, check.Address, check.Token)
+		if err != nil {
+			return nil, err
+		}
+		balances[i], _ = new(big.Int).SetString(balance.Amount, 10)
+
+		// 没有代币账户时返回的精度为0，只记录有余额时的精度
+		if balances[i] != nil && balances[i].Sign() > 0 {
+			w.mu.Lock()
+			w.decimals[tokenKey(check.Chain, check.Token)] = balance.Decimals
+			w.mu.Unlock()
+		}
+	}
+	return balances, nil
+}
+
+// tokenDecimals 获取代币精度：EVM链读取合约，其他链使用已索引转账中记录的值，未知时返回nil
+func (w *WalletWatcher) tokenDecimals(ctx context.Context, chain, token string) *uint8 {
+	key := tokenKey(chain, token)
+	w.mu.Lock()
+	d, ok := w.decimals[key]
+	w.mu.Unlock()
+	if ok {
+		return &d
+	}
+
+	w.service.mu.RLock()
+	_, evm := w.service.pools[chain]
+	w.service.mu.RUnlock()
+
+	var decimals *uint8
+	if evm {
+		if d, ok := w.service.tokenDecimals(ctx, chain, token); ok {
+			decimals = &d
+		}
+	} else {
+		var err error
+		if decimals, err = w.service.indexedDecimals(chain, token); err != nil {
+			w.logger.Warnf("Failed to load decimals of %s on %s: %v", token, chain, err)
+		}
+	}
+
+	if decimals != nil {
+		w.mu.Lock()
+		w.decimals[key] = *decimals
+		w.mu.Unlock()
+	}
+	return decimals
+}
+
+// applyBalance 对比并更新关联该地址的每个钱包的余额，余额变化时发送事件
+func (w *WalletWatcher) applyBalance(ctx context.Context, check balanceCheck, balance *big.Int, block uint64) error {
+	w.mu.Lock()
+	wallets := w.wallets[check.Address]
+	w.mu.Unlock()
+
+	for _, wallet := range wallets {
+		var current models.WalletBalance
+		err := w.service.db.WithContext(ctx).
+			Where("wallet_id = ? AND chain = ? AND token = ?", wallet.ID, check.Chain, check.Token).
+			First(&current).Error
+
+		oldBalance := "0"
+		switch {
+		case err == nil:
+			oldBalance = current.Balance
+			if current.BlockNumber > block {
+				continue
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			// 首次记录零余额时不需要保存，也不发送事件
+			if balance.Sign() == 0 {
+				continue
+			}
+		default:
+			return fmt.Errorf("failed to load wallet balance: %v", err)
+		}
+
+		newBalance := balance.String()
+		record := models.WalletBalance{
+			WalletID:    wallet.ID,
+			UserID:      wallet.UserID,
+			Chain:       check.Chain,
+			Token:       check.Token,
+			Address:     check.Address,
+			Balance:     newBalance,
+			BlockNumber: block,
+		}
+		if err := w.service.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "chain"}, {Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"balance", "block_number", "updated_at"}),
+		}).Create(&record).Error; err != nil {
+			return fmt.Errorf("failed to save wallet balance: %v", err)
+		}
+
+		if sameAmount(oldBalance, newBalance) {
+			continue
+		}
+		w.publishBalanceUpdate(ctx, wallet, check, oldBalance, newBalance, block)
+	}
+
+	return nil
+}
+
+// publishBalanceUpdate 余额为最小单位的整数，decimals为null时表示精度未知
+func (w *WalletWatcher) publishBalanceUpdate(ctx context.Context, wallet models.WatchedWallet, check balanceCheck, oldBalance, newBalance string, block uint64) {
+	message := map[string]interface{}{
+		"type":           "balance_update",
+		"user_id":        wallet.UserID,
+		"wallet_id":      wallet.ID,
+		"wallet_address": check.Address,
+		"chain":          check.Chain,
+		"token":          check.Token,
+		"old_balance":    oldBalance,
+		"new_balance":    newBalance,
+		"decimals":       w.tokenDecimals(ctx, check.Chain, check.Token),
+		"block_number":   block,
+		"timestamp":      time.Now().Unix(),
+	}
+
+	if err := w.publisher.PublishMessage(ctx, "balance-updates", wallet.UserID, message); err != nil {
+		w.logger.Errorf("Failed to publish balance update: %v", err)
+	}
+}
+
+func sameAmount(a, b string) bool {
+	x, okX := new(big.Int).SetString(a, 10)
+	y, okY := new(big.Int).SetString(b, 10)
+	if !okX || !okY {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// validateWalletAddress 检查地址是0x开头的EVM地址或32字节的Solana公钥
+func validateWalletAddress(address string) error {
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		if common.IsHexAddress(address) {
+			return nil
+		}
+	} else if solana.IsValidAddress(address) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidAddress, address)
+}
+
+// AddWatchedWallet 为用户关联钱包地址，并立即安排一次余额检查
+func (s *BlockchainService) AddWatchedWallet(ctx context.Context, userID, address string, label *string) (*models.WatchedWallet, error) {
+	address = normalizeAddress(address)
+	if err := validateWalletAddress(address); err != nil {
+		return nil, err
+	}
+
+	wallet := models.WatchedWallet{
+		UserID:   userID,
+		Address:  address,
+		Label:    label,
+		IsActive: true,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "address"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"is_active": true, "label": label, "updated_at": time.Now()}),
+	}).Create(&wallet).Error; err != nil {
+		return nil, fmt.Errorf("failed to save watched wallet: %v", err)
+	}
+
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND address = ?", userID, address).First(&wallet).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.watcher.refreshRegistry(ctx, true); err != nil {
+		s.logger.Errorf("Failed to reload wallet watchlist: %v", err)
+	}
+	s.watcher.enqueueAll(0, address)
+
+	return &wallet, nil
+}
+
+// RemoveWatchedWallet 停止监控钱包，已记录的余额一并删除
+func (s *BlockchainService) RemoveWatchedWallet(ctx context.Context, id string) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.WalletBalance{}, "wallet_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.WatchedWallet{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrWalletNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.watcher.refreshRegistry(ctx, true); err != nil {
+		s.logger.Errorf("Failed to reload wallet watchlist: %v", err)
+	}
+	return nil
+}
+
+// GetWatchedWallets 获取用户关联的钱包
+func (s *BlockchainService) GetWatchedWallets(ctx context.Context, userID string) ([]models.WatchedWallet, error) {
+	var wallets []models.WatchedWallet
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&wallets).Error; err != nil {
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// GetWalletBalances 获取钱包的已确认余额
+func (s *BlockchainService) GetWalletBalances(ctx context.Context, walletID string) ([]models.WalletBalance, error) {
+	var balances []models.WalletBalance
+	if err := s.db.WithContext(ctx).Where("wallet_id = ?", walletID).Order("chain ASC, token ASC").Find(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/rwa-platform/data-collector/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testToken  = "0x2222222222222222222222222222222222222222"
+	testSolana = "BQcdHdAQW1hczDbBi9hiegXAR7A98Q9jx3X3iBBBDiq4"
+)
+
+// fakePublisher 记录发送的消息
+type fakePublisher struct {
+	mu       sync.Mutex
+	messages []map[string]interface{}
+}
+
+func (p *fakePublisher) PublishMessage(ctx context.Context, topic string, key string, message interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.messages = append(p.messages, message.(map[string]interface{}))
+	return nil
+}
+
+func (p *fakePublisher) published() []map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]map[string]interface{}(nil), p.messages...)
+}
+
+// newWatcherService 建立余额表并监控一个钱包，模型中的gen_random_uuid()默认值在SQLite中无法迁移
+func newWatcherService(t *testing.T) (*BlockchainService, *fakePublisher) {
+	t.Helper()
+
+	service := newTestBlockchainService(t)
+	require.NoError(t, service.db.Exec(`CREATE TABLE wallet_balances (
+		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))), wallet_id TEXT, user_id TEXT,
+		chain TEXT, token TEXT, address TEXT, balance TEXT, block_number INTEGER,
+		created_at DATETIME, updated_at DATETIME, UNIQUE (wallet_id, chain, token))`).Error)
+
+	publisher := &fakePublisher{}
+	service.watcher.publisher = publisher
+	service.watcher.wallets[testWallet] = []models.WatchedWallet{{ID: "wallet-1", UserID: "user-1", Address: testWallet}}
+	service.watcher.decimals[tokenKey("ethereum", testToken)] = 6
+	return service, publisher
+}
+
+func storedBalance(t *testing.T, service *BlockchainService) *models.WalletBalance {
+	t.Helper()
+	var balances []models.WalletBalance
+	require.NoError(t, service.db.Where("wallet_id = ?", "wallet-1").Find(&balances).Error)
+	if len(balances) == 0 {
+		return nil
+	}
+	return &balances[0]
+}
+
+func TestObserveTransfersQueuesWatchedWallets(t *testing.T) {
+	service, _ := newWatcherService(t)
+	watcher := service.watcher
+
+	watcher.ObserveTransfers([]*models.TokenTransfer{
+		{Chain: "ethereum", ContractAddress: testToken, FromAddress: testWallet, ToAddress: "0xother", BlockNumber: 120},
+		{Chain: "ethereum", ContractAddress: testToken, FromAddress: "0xother", ToAddress: testWallet, BlockNumber: 110},
+		{Chain: "ethereum", ContractAddress: testToken, FromAddress: "0xother", ToAddress: "0xanother", BlockNumber: 130},
+	})
+
+	// 只记录被监控的地址，并保留观察到变化的最新区块
+	assert.Equal(t, map[balanceCheck]uint64{
+		{Chain: "ethereum", Token: testToken, Address: testWallet}: 120,
+	}, watcher.pending)
+}
+
+func TestConfirmedChecks(t *testing.T) {
+	early := balanceCheck{Chain: "ethereum", Token: testToken, Address: testWallet}
+	late := balanceCheck{Chain: "ethereum", Token: testToken, Address: "0xother"}
+	checks := map[balanceCheck]uint64{early: 100, late: 105}
+
+	confirmed, ready, ok := confirmedChecks(checks, 112, 10)
+	require.True(t, ok)
+	assert.Equal(t, uint64(102), confirmed)
+	assert.Equal(t, []balanceCheck{early}, ready)
+
+	_, ready, ok = confirmedChecks(checks, 115, 10)
+	require.True(t, ok)
+	assert.ElementsMatch(t, []balanceCheck{early, late}, ready)
+
+	// 链头低于确认数时不处理
+	_, _, ok = confirmedChecks(checks, 5, 10)
+	assert.False(t, ok)
+}
+
+func TestApplyBalancePublishesOnlyChanges(t *testing.T) {
+	service, publisher := newWatcherService(t)
+	watcher := service.watcher
+	ctx := context.Background()
+	check := balanceCheck{Chain: "ethereum", Token: testToken, Address: testWallet}
+
+	// 首次读取到零余额时既不保存也不发送
+	require.NoError(t, watcher.applyBalance(ctx, check, big.NewInt(0), 100))
+	assert.Nil(t, storedBalance(t, service))
+	assert.Empty(t, publisher.published())
+
+	require.NoError(t, watcher.applyBalance(ctx, check, big.NewInt(1500000), 101))
+	balance := storedBalance(t, service)
+	require.NotNil(t, balance)
+	assert.Equal(t, "1500000", balance.Balance)
+	assert.Equal(t, uint64(101), balance.BlockNumber)
+
+	messages := publisher.published()
+	require.Len(t, messages, 1)
+	assert.Equal(t, "0", messages[0]["old_balance"])
+	assert.Equal(t, "1500000", messages[0]["new_balance"])
+	assert.Equal(t, uint8(6), *messages[0]["decimals"].(*uint8))
+	assert.Equal(t, uint64(101), messages[0]["block_number"])
+
+	// 余额不变时只更新区块
+	require.NoError(t, watcher.applyBalance(ctx, check, big.NewInt(1500000), 102))
+	assert.Len(t, publisher.published(), 1)
+	assert.Equal(t, uint64(102), storedBalance(t, service).BlockNumber)
+
+	// 比已记录区块更早的读数被忽略
+	require.NoError(t, watcher.applyBalance(ctx, check, big.NewInt(7), 90))
+	assert.Len(t, publisher.published(), 1)
+	assert.Equal(t, "1500000", storedBalance(t, service).Balance)
+
+	require.NoError(t, watcher.applyBalance(ctx, check, big.NewInt(500000), 103))
+	messages = publisher.published()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "1500000", messages[1]["old_balance"])
+	assert.Equal(t, "500000", messages[1]["new_balance"])
+}
+
+func TestValidateWalletAddress(t *testing.T) {
+	assert.NoError(t, validateWalletAddress(testWallet))
+	assert.NoError(t, validateWalletAddress(testSolana))
+
+	for _, address := range []string{"", "0x1234", "0xZZ11111111111111111111111111111111111111", "not-an-address", "1111111111111111111111111111111111111111"} {
+		assert.ErrorIs(t, validateWalletAddress(address), ErrInvalidAddress, address)
+	}
+
+	service, _ := newWatcherService(t)
+	_, err := service.AddWatchedWallet(context.Background(), "user-1", "0x1234", nil)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
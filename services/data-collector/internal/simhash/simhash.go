@@ -0,0 +1,108 @@
+// Package simhash 计算文本的64位SimHash指纹，用于识别被多家媒体转载的同一篇报道
+//
+// 相似文本的指纹只有少数位不同。指纹分成8段，每段8位，汉明距离不超过6的两个指纹至少有两段完全相同，
+// 因此可以先按分段查找候选，再计算完整的距离
+package simhash
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Threshold 视为同一报道的最大汉明距离
+	Threshold = 6
+	// bandCount 指纹分段数，必须大于Threshold
+	bandCount = 8
+	bandBits  = 64 / bandCount
+)
+
+// stopwords 不参与指纹计算的常见词，避免短标题被虚词主导
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "has": true, "have": true, "in": true, "is": true, "it": true, "its": true,
+	"of": true, "on": true, "or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"were": true, "will": true, "with": true, "after": true, "says": true, "said": true, "new": true,
+}
+
+// Fingerprint 计算标题和摘要的指纹，标题的词权重加倍；没有有效词时返回0
+func Fingerprint(title, summary string) uint64 {
+	var weights [64]int
+	features := 0
+
+	add := func(text string, weight int) {
+		tokens := tokenize(text)
+		for i, token := range tokens {
+			// 单词和相邻词对同时作为特征，词序变化只影响部分特征
+			addFeature(&weights, token, weight)
+			if i > 0 {
+				addFeature(&weights, tokens[i-1]+" "+token, weight)
+			}
+			features++
+		}
+	}
+	add(title, 2)
+	add(summary, 1)
+
+	if features == 0 {
+		return 0
+	}
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint
+}
+
+func addFeature(weights *[64]int, feature string, weight int) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	hash := h.Sum64()
+	for bit := 0; bit < 64; bit++ {
+		if hash&(1<<uint(bit)) != 0 {
+			weights[bit] += weight
+		} else {
+			weights[bit] -= weight
+		}
+	}
+}
+
+// Distance 两个指纹的汉明距离
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similar 判断两个指纹是否属于同一报道
+func Similar(a, b uint64) bool {
+	return Distance(a, b) <= Threshold
+}
+
+// Bands 指纹的分段键，格式为"段号:十六进制值"，相似的指纹至少有一个相同的键
+func Bands(fingerprint uint64) []string {
+	bands := make([]string, bandCount)
+	for i := range bands {
+		value := (fingerprint >> uint(i*bandBits)) & (1<<bandBits - 1)
+		bands[i] = fmt.Sprintf("%d:%02x", i, value)
+	}
+	return bands
+}
+
+// tokenize 转小写、去除标点和停用词；媒体常加的"Reuters -"之类前缀也只是普通词，影响有限
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if !stopwords[field] {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
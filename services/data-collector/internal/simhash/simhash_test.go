@@ -0,0 +1,53 @@
+package simhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	wireTitle   = "BlackRock's tokenized treasury fund BUIDL crosses $500 million in assets"
+	wireSummary = "The BlackRock USD Institutional Digital Liquidity Fund, issued on Ethereum through Securitize, has surpassed $500 million in assets under management less than four months after launch."
+)
+
+func TestFingerprintNearDuplicates(t *testing.T) {
+	original := Fingerprint(wireTitle, wireSummary)
+	assert.NotZero(t, original)
+
+	// 同一篇通稿被转载时常见的改动：大小写、标点和个别词
+	republished := Fingerprint(
+		"BlackRock’s Tokenized Treasury Fund BUIDL Crosses $500 Million in Assets",
+		"The BlackRock USD Institutional Digital Liquidity Fund, issued on Ethereum via Securitize, has surpassed $500 million in assets under management less than four months after launch.",
+	)
+	assert.True(t, Similar(original, republished), "distance %d", Distance(original, republished))
+	assert.Equal(t, original, Fingerprint(wireTitle+"!", wireSummary))
+
+	// 同一主题的不同报道
+	different := Fingerprint(
+		"Franklin Templeton expands tokenized money market fund to Avalanche",
+		"Franklin Templeton's OnChain U.S. Government Money Fund, represented by the BENJI token, is now available on Avalanche.",
+	)
+	assert.False(t, Similar(original, different), "distance %d", Distance(original, different))
+	assert.Greater(t, Distance(original, different), 2*Threshold)
+
+	assert.Zero(t, Fingerprint("", ""))
+	assert.Zero(t, Fingerprint("The", "of the"))
+}
+
+func TestBands(t *testing.T) {
+	a := uint64(0x1234_5678_9abc_def0)
+	assert.Equal(t, []string{"0:f0", "1:de", "2:bc", "3:9a", "4:78", "5:56", "6:34", "7:12"}, Bands(a))
+
+	// 距离不超过阈值的指纹至少共享两段
+	b := a ^ (1 << 3) ^ (1 << 12) ^ (1 << 20) ^ (1 << 30) ^ (1 << 40) ^ (1 << 50)
+	assert.Equal(t, 6, Distance(a, b))
+	shared := 0
+	bandsB := Bands(b)
+	for i, band := range Bands(a) {
+		if band == bandsB[i] {
+			shared++
+		}
+	}
+	assert.Equal(t, 2, shared)
+}
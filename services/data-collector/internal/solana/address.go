@@ -0,0 +1,46 @@
+package solana
+
+import "math/big"
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// publicKeyLength Solana公钥（账户地址）的字节数
+const publicKeyLength = 32
+
+// IsValidAddress 判断字符串是否为base58编码的32字节公钥
+func IsValidAddress(address string) bool {
+	// 32字节的base58编码为32到44个字符
+	if len(address) < 32 || len(address) > 44 {
+		return false
+	}
+	decoded, ok := decodeBase58(address)
+	return ok && len(decoded) == publicKeyLength
+}
+
+// decodeBase58 比特币字母表的base58解码，前导的'1'对应前导零字节
+func decodeBase58(value string) ([]byte, bool) {
+	radix := big.NewInt(58)
+	number := new(big.Int)
+	zeros := 0
+	leading := true
+	for i := 0; i < len(value); i++ {
+		digit := -1
+		for j := 0; j < len(base58Alphabet); j++ {
+			if base58Alphabet[j] == value[i] {
+				digit = j
+				break
+			}
+		}
+		if digit < 0 {
+			return nil, false
+		}
+		if leading && digit == 0 {
+			zeros++
+			continue
+		}
+		leading = false
+		number.Mul(number, radix)
+		number.Add(number, big.NewInt(int64(digit)))
+	}
+	return append(make([]byte, zeros), number.Bytes()...), true
+}
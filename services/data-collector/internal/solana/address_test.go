@@ -0,0 +1,26 @@
+package solana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidAddress(t *testing.T) {
+	for _, address := range []string{usdcMint, alice, bob, "11111111111111111111111111111111"} {
+		assert.True(t, IsValidAddress(address), address)
+	}
+
+	for _, address := range []string{
+		"",
+		"0x1111111111111111111111111111111111111111",
+		// 含有base58字母表之外的字符0、O、I、l
+		"0PjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDtlv",
+		// 解码后不是32字节
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1vv",
+		"EPjFWdd5AufqSSqeM2qN1xzybapC8G4w",
+	} {
+		assert.False(t, IsValidAddress(address), address)
+	}
+}
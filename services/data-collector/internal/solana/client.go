@@ -0,0 +1,225 @@
+package solana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// 默认使用finalized确认级别，避免索引被回滚的slot
+const defaultCommitment = "finalized"
+
+// Client Solana JSON-RPC客户端
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	commitment string
+	requestID  uint64
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError JSON-RPC错误
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("solana rpc error %d: %s", e.Code, e.Message)
+}
+
+func NewClient(endpoint string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: timeout},
+		commitment: defaultCommitment,
+	}
+}
+
+func (c *Client) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&c.requestID, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("solana rpc %s returned status %d", method, resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", method, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// GetSlot 获取当前已确认的最新slot
+func (c *Client) GetSlot(ctx context.Context) (uint64, error) {
+	var slot uint64
+	err := c.call(ctx, "getSlot", &slot, map[string]interface{}{
+		"commitment": c.commitment,
+	})
+	return slot, err
+}
+
+// SignatureInfo getSignaturesForAddress返回的签名信息
+type SignatureInfo struct {
+	Signature string          `json:"signature"`
+	Slot      uint64          `json:"slot"`
+	Err       json.RawMessage `json:"err"`
+	BlockTime *int64          `json:"blockTime"`
+}
+
+// Failed 交易是否执行失败
+func (s SignatureInfo) Failed() bool {
+	return len(s.Err) > 0 && string(s.Err) != "null"
+}
+
+// GetSignaturesForAddress 按时间倒序获取与地址相关的交易签名。
+// before不为空时从该签名之前开始，until不为空时到该签名为止（不含）
+func (c *Client) GetSignaturesForAddress(ctx context.Context, address, before, until string, limit int) ([]SignatureInfo, error) {
+	options := map[string]interface{}{
+		"commitment": c.commitment,
+		"limit":      limit,
+	}
+	if before != "" {
+		options["before"] = before
+	}
+	if until != "" {
+		options["until"] = until
+	}
+
+	var signatures []SignatureInfo
+	err := c.call(ctx, "getSignaturesForAddress", &signatures, address, options)
+	return signatures, err
+}
+
+// BlockInfo 区块摘要信息（不含交易明细）
+type BlockInfo struct {
+	Blockhash  string `json:"blockhash"`
+	ParentSlot uint64 `json:"parentSlot"`
+	BlockTime  *int64 `json:"blockTime"`
+}
+
+// GetBlock 获取slot对应的区块摘要
+func (c *Client) GetBlock(ctx context.Context, slot uint64) (*BlockInfo, error) {
+	var block BlockInfo
+	err := c.call(ctx, "getBlock", &block, slot, map[string]interface{}{
+		"commitment":                     c.commitment,
+		"transactionDetails":             "none",
+		"rewards":                        false,
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &block, nil
+}
+
+// GetTransaction 以jsonParsed编码获取交易详情
+func (c *Client) GetTransaction(ctx context.Context, signature string) (*Transaction, error) {
+	var tx *Transaction
+	err := c.call(ctx, "getTransaction", &tx, signature, map[string]interface{}{
+		"commitment":                     c.commitment,
+		"encoding":                       "jsonParsed",
+		"maxSupportedTransactionVersion": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %s not found", signature)
+	}
+	return tx, nil
+}
+
+// TokenAccountBalance 钱包在某个mint下的代币余额
+type TokenAccountBalance struct {
+	Amount   string
+	Decimals uint8
+}
+
+// GetTokenBalance 汇总钱包持有的某个mint的所有代币账户余额
+func (c *Client) GetTokenBalance(ctx context.Context, owner, mint string) (*TokenAccountBalance, error) {
+	var result struct {
+		Value []struct {
+			Account struct {
+				Data struct {
+					Parsed struct {
+						Info struct {
+							TokenAmount tokenAmount `json:"tokenAmount"`
+						} `json:"info"`
+					} `json:"parsed"`
+				} `json:"data"`
+			} `json:"account"`
+		} `json:"value"`
+	}
+
+	err := c.call(ctx, "getTokenAccountsByOwner", &result, owner, map[string]interface{}{
+		"mint": mint,
+	}, map[string]interface{}{
+		"commitment": c.commitment,
+		"encoding":   "jsonParsed",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	balance := &TokenAccountBalance{}
+	for _, account := range result.Value {
+		amount := account.Account.Data.Parsed.Info.TokenAmount
+		value, ok := new(big.Int).SetString(amount.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid token amount %q", amount.Amount)
+		}
+		total.Add(total, value)
+		balance.Decimals = amount.Decimals
+	}
+	balance.Amount = total.String()
+	return balance, nil
+}
@@ -0,0 +1,180 @@
+package solana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usdcMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+
+	sigTransfer = "5h6xBEauJ3PK6SWCZ1PGjBvj8vDdWG3KpwATGy1ARAXFSDwt8GFXM7W5Ncn16wmqokgpiKRLuS83KUxyZyv2sUYv"
+	sigMint     = "4Nd1mBQtrMJVYVfKf2PJy9NZUZdTAsp7D4xWLs4gDB4T5aT5Ln7nJgqSMv2jvAxQRXsYgTeW7rJRW2yLAvSAjq8X"
+	sigFailed   = "3yZe7d1uMhq4vJXQF8bXKvGjGbYPf7T5w9jpLwUQHYmF3P8PkzBn6s2JYtEkxHvWuGqDgc5XYXHGyRtWtE1tvbsy"
+	sigBurn     = "2jg8Zr7iGR3Gy2nWeAGNfVH6MVyzJu4RDqV4aCS7S3yj8cjw5iD1iYWZBVQR3TRPi6Dc2ZYLgUsYH3hr6Xs4ggWZ"
+
+	alice = "BQcdHdAQW1hczDbBi9hiegXAR7A98Q9jx3X3iBBBDiq4"
+	bob   = "HN7cABqLq46Es1jh92dQQisAq662SmxELLLsHHe4YWrH"
+)
+
+// newFixtureServer 按方法名回放testdata中录制的RPC响应，getTransaction按签名区分
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		name := req.Method
+		if req.Method == "getTransaction" && len(req.Params) > 0 {
+			var signature string
+			_ = json.Unmarshal(req.Params[0], &signature)
+			name += "_" + signature
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", name+".json"))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","error":{"code":-32601,"message":"no fixture"},"id":1}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+}
+
+func TestClientGetSlotAndSignatures(t *testing.T) {
+	server := newFixtureServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, 5*time.Second)
+	ctx := context.Background()
+
+	slot, err := client.GetSlot(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(250000100), slot)
+
+	signatures, err := client.GetSignaturesForAddress(ctx, usdcMint, "", "", 1000)
+	require.NoError(t, err)
+	require.Len(t, signatures, 5)
+	assert.Equal(t, sigTransfer, signatures[0].Signature)
+	assert.False(t, signatures[0].Failed())
+	assert.Equal(t, sigFailed, signatures[2].Signature)
+	assert.True(t, signatures[2].Failed())
+
+	block, err := client.GetBlock(ctx, 250000050)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(250000049), block.ParentSlot)
+	require.NotNil(t, block.BlockTime)
+}
+
+func TestClientGetTokenBalance(t *testing.T) {
+	server := newFixtureServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, 5*time.Second)
+	balance, err := client.GetTokenBalance(context.Background(), bob, usdcMint)
+	require.NoError(t, err)
+
+	// 两个代币账户的余额之和
+	assert.Equal(t, "4250000", balance.Amount)
+	assert.Equal(t, uint8(6), balance.Decimals)
+}
+
+func TestClientRPCError(t *testing.T) {
+	server := newFixtureServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, 5*time.Second)
+	_, err := client.GetTransaction(context.Background(), "unknown")
+
+	var rpcErr *RPCError
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, -32601, rpcErr.Code)
+}
+
+func TestExtractTokenEvents(t *testing.T) {
+	server := newFixtureServer(t)
+	defer server.Close()
+
+	client := NewClient(server.URL, 5*time.Second)
+	ctx := context.Background()
+	mints := map[string]bool{usdcMint: true}
+
+	t.Run("transferChecked", func(t *testing.T) {
+		tx, err := client.GetTransaction(ctx, sigTransfer)
+		require.NoError(t, err)
+
+		events := ExtractTokenEvents(tx, mints)
+		require.Len(t, events, 1)
+		event := events[0]
+		assert.Equal(t, EventTransfer, event.Kind)
+		assert.Equal(t, sigTransfer, event.Signature)
+		assert.Equal(t, uint64(250000050), event.Slot)
+		assert.Equal(t, alice, event.From)
+		assert.Equal(t, bob, event.To)
+		assert.Equal(t, "2500000", event.Amount)
+		require.NotNil(t, event.Decimals)
+		assert.Equal(t, uint8(6), *event.Decimals)
+		assert.Equal(t, alice, tx.FeePayer())
+	})
+
+	t.Run("mint and inner transfer", func(t *testing.T) {
+		tx, err := client.GetTransaction(ctx, sigMint)
+		require.NoError(t, err)
+
+		events := ExtractTokenEvents(tx, mints)
+		require.Len(t, events, 2)
+
+		assert.Equal(t, EventMint, events[0].Kind)
+		assert.Equal(t, SystemProgramID, events[0].From)
+		assert.Equal(t, alice, events[0].To)
+		assert.Equal(t, "1000000000", events[0].Amount)
+		assert.Equal(t, uint(0), events[0].Index)
+
+		// 内部的非checked transfer从余额快照补全mint，另一个mint的转账被过滤
+		assert.Equal(t, EventTransfer, events[1].Kind)
+		assert.Equal(t, usdcMint, events[1].Mint)
+		assert.Equal(t, alice, events[1].From)
+		assert.Equal(t, bob, events[1].To)
+		assert.Equal(t, uint(2), events[1].Index)
+
+		assert.Len(t, ExtractTokenEvents(tx, nil), 3)
+	})
+
+	t.Run("burnChecked", func(t *testing.T) {
+		tx, err := client.GetTransaction(ctx, sigBurn)
+		require.NoError(t, err)
+
+		events := ExtractTokenEvents(tx, mints)
+		require.Len(t, events, 1)
+		assert.Equal(t, EventBurn, events[0].Kind)
+		assert.Equal(t, bob, events[0].From)
+		assert.Equal(t, SystemProgramID, events[0].To)
+		assert.Equal(t, "500000", events[0].Amount)
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+		tx, err := client.GetTransaction(ctx, sigFailed)
+		require.NoError(t, err)
+
+		assert.False(t, tx.Succeeded())
+		assert.Empty(t, ExtractTokenEvents(tx, mints))
+	})
+}
@@ -0,0 +1,294 @@
+package solana
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// SPL Token程序地址
+const (
+	TokenProgramID     = "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"
+	Token2022ProgramID = "TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb"
+
+	// SystemProgramID 作为铸造/销毁的对手方地址，与EVM中的零地址语义一致
+	SystemProgramID = "11111111111111111111111111111111"
+)
+
+// 代币事件类型
+const (
+	EventTransfer = "transfer"
+	EventMint     = "mint"
+	EventBurn     = "burn"
+)
+
+// Transaction jsonParsed编码的交易
+type Transaction struct {
+	Slot        uint64 `json:"slot"`
+	BlockTime   *int64 `json:"blockTime"`
+	Meta        *Meta  `json:"meta"`
+	Transaction struct {
+		Signatures []string `json:"signatures"`
+		Message    struct {
+			AccountKeys     []AccountKey  `json:"accountKeys"`
+			Instructions    []Instruction `json:"instructions"`
+			RecentBlockhash string        `json:"recentBlockhash"`
+		} `json:"message"`
+	} `json:"transaction"`
+}
+
+// Meta 交易执行结果
+type Meta struct {
+	Err                  json.RawMessage     `json:"err"`
+	Fee                  uint64              `json:"fee"`
+	ComputeUnitsConsumed *uint64             `json:"computeUnitsConsumed"`
+	PreTokenBalances     []TokenBalance      `json:"preTokenBalances"`
+	PostTokenBalances    []TokenBalance      `json:"postTokenBalances"`
+	InnerInstructions    []InnerInstructions `json:"innerInstructions"`
+}
+
+// AccountKey 交易涉及的账户
+type AccountKey struct {
+	Pubkey   string `json:"pubkey"`
+	Signer   bool   `json:"signer"`
+	Writable bool   `json:"writable"`
+}
+
+// TokenBalance 代币账户余额快照
+type TokenBalance struct {
+	AccountIndex  int    `json:"accountIndex"`
+	Mint          string `json:"mint"`
+	Owner         string `json:"owner"`
+	ProgramID     string `json:"programId"`
+	UITokenAmount struct {
+		Amount   string `json:"amount"`
+		Decimals uint8  `json:"decimals"`
+	} `json:"uiTokenAmount"`
+}
+
+// InnerInstructions CPI调用产生的内部指令
+type InnerInstructions struct {
+	Index        int           `json:"index"`
+	Instructions []Instruction `json:"instructions"`
+}
+
+// Instruction 解析后的指令
+type Instruction struct {
+	Program   string          `json:"program"`
+	ProgramID string          `json:"programId"`
+	Parsed    json.RawMessage `json:"parsed"`
+}
+
+type parsedInstruction struct {
+	Type string          `json:"type"`
+	Info instructionInfo `json:"info"`
+}
+
+type instructionInfo struct {
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	Account     string       `json:"account"`
+	Mint        string       `json:"mint"`
+	Amount      string       `json:"amount"`
+	TokenAmount *tokenAmount `json:"tokenAmount"`
+}
+
+type tokenAmount struct {
+	Amount   string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// TokenEvent 从交易中提取的SPL代币转账、铸造或销毁事件
+type TokenEvent struct {
+	Signature string    `json:"signature"`
+	Slot      uint64    `json:"slot"`
+	BlockTime time.Time `json:"block_time"`
+	Index     uint      `json:"index"`
+	Kind      string    `json:"kind"`
+	Mint      string    `json:"mint"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Amount    string    `json:"amount"`
+	Decimals  *uint8    `json:"decimals,omitempty"`
+}
+
+// Signature 交易签名
+func (t *Transaction) Signature() string {
+	if len(t.Transaction.Signatures) == 0 {
+		return ""
+	}
+	return t.Transaction.Signatures[0]
+}
+
+// FeePayer 交易的手续费支付方（第一个签名账户）
+func (t *Transaction) FeePayer() string {
+	if len(t.Transaction.Message.AccountKeys) == 0 {
+		return ""
+	}
+	return t.Transaction.Message.AccountKeys[0].Pubkey
+}
+
+// Succeeded 交易是否执行成功
+func (t *Transaction) Succeeded() bool {
+	return t.Meta != nil && (len(t.Meta.Err) == 0 || string(t.Meta.Err) == "null")
+}
+
+// Time 交易所在区块时间
+func (t *Transaction) Time() time.Time {
+	if t.BlockTime == nil {
+		return time.Time{}
+	}
+	return time.Unix(*t.BlockTime, 0)
+}
+
+// ExtractTokenEvents 提取交易中与给定mint相关的代币事件，mints为空时返回全部
+// 外部指令与内部指令按执行顺序编号，编号作为事件在交易内的索引
+func ExtractTokenEvents(tx *Transaction, mints map[string]bool) []TokenEvent {
+	if !tx.Succeeded() {
+		return nil
+	}
+
+	accounts := tx.tokenAccounts()
+
+	var events []TokenEvent
+	var index uint
+
+	handle := func(instruction Instruction) {
+		event, ok := parseTokenInstruction(instruction, accounts)
+		index++
+		if !ok {
+			return
+		}
+		if len(mints) > 0 && !mints[event.Mint] {
+			return
+		}
+
+		event.Signature = tx.Signature()
+		event.Slot = tx.Slot
+		event.BlockTime = tx.Time()
+		event.Index = index - 1
+		events = append(events, event)
+	}
+
+	inner := make(map[int][]Instruction)
+	for _, group := range tx.Meta.InnerInstructions {
+		inner[group.Index] = group.Instructions
+	}
+
+	for i, instruction := range tx.Transaction.Message.Instructions {
+		handle(instruction)
+		for _, innerInstruction := range inner[i] {
+			handle(innerInstruction)
+		}
+	}
+
+	return events
+}
+
+// tokenAccountInfo 代币账户对应的mint和所有者
+type tokenAccountInfo struct {
+	Mint     string
+	Owner    string
+	Decimals uint8
+}
+
+func (t *Transaction) tokenAccounts() map[string]tokenAccountInfo {
+	accounts := make(map[string]tokenAccountInfo)
+	keys := t.Transaction.Message.AccountKeys
+
+	collect := func(balances []TokenBalance) {
+		for _, balance := range balances {
+			if balance.AccountIndex < 0 || balance.AccountIndex >= len(keys) {
+				continue
+			}
+			accounts[keys[balance.AccountIndex].Pubkey] = tokenAccountInfo{
+				Mint:     balance.Mint,
+				Owner:    balance.Owner,
+				Decimals: balance.UITokenAmount.Decimals,
+			}
+		}
+	}
+
+	collect(t.Meta.PreTokenBalances)
+	collect(t.Meta.PostTokenBalances)
+	return accounts
+}
+
+func parseTokenInstruction(instruction Instruction, accounts map[string]tokenAccountInfo) (TokenEvent, bool) {
+	if instruction.ProgramID != TokenProgramID && instruction.ProgramID != Token2022ProgramID {
+		return TokenEvent{}, false
+	}
+	if len(instruction.Parsed) == 0 || instruction.Parsed[0] != '{' {
+		return TokenEvent{}, false
+	}
+
+	var parsed parsedInstruction
+	if err := json.Unmarshal(instruction.Parsed, &parsed); err != nil {
+		return TokenEvent{}, false
+	}
+
+	info := parsed.Info
+	amount := info.Amount
+	var decimals *uint8
+	if info.TokenAmount != nil {
+		amount = info.TokenAmount.Amount
+		d := info.TokenAmount.Decimals
+		decimals = &d
+	}
+	if _, err := strconv.ParseUint(amount, 10, 64); err != nil {
+		return TokenEvent{}, false
+	}
+
+	event := TokenEvent{Amount: amount, Decimals: decimals}
+
+	switch parsed.Type {
+	case "transfer", "transferChecked":
+		event.Kind = EventTransfer
+		event.Mint = info.Mint
+		event.From = ownerOf(info.Source, accounts)
+		event.To = ownerOf(info.Destination, accounts)
+		// 非checked的transfer指令不包含mint，从代币账户余额中补全
+		if event.Mint == "" {
+			event.Mint = accounts[info.Source].Mint
+		}
+		if event.Mint == "" {
+			event.Mint = accounts[info.Destination].Mint
+		}
+	case "mintTo", "mintToChecked":
+		event.Kind = EventMint
+		event.Mint = info.Mint
+		event.From = SystemProgramID
+		event.To = ownerOf(info.Account, accounts)
+	case "burn", "burnChecked":
+		event.Kind = EventBurn
+		event.Mint = info.Mint
+		event.From = ownerOf(info.Account, accounts)
+		event.To = SystemProgramID
+	default:
+		return TokenEvent{}, false
+	}
+
+	if event.Mint == "" {
+		return TokenEvent{}, false
+	}
+
+	if event.Decimals == nil {
+		for _, account := range accounts {
+			if account.Mint == event.Mint {
+				d := account.Decimals
+				event.Decimals = &d
+				break
+			}
+		}
+	}
+
+	return event, true
+}
+
+// ownerOf 将代币账户映射为钱包所有者，无法映射时保留代币账户地址
+func ownerOf(tokenAccount string, accounts map[string]tokenAccountInfo) string {
+	if info, ok := accounts[tokenAccount]; ok && info.Owner != "" {
+		return info.Owner
+	}
+	return tokenAccount
+}
@@ -0,0 +1,97 @@
+package sourcehealth
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit 上游响应头中的限流信息
+type RateLimit struct {
+	Limit     int        `json:"limit,omitempty"`
+	Remaining int        `json:"remaining"`
+	ResetAt   *time.Time `json:"reset_at,omitempty"`
+}
+
+// Headroom 剩余请求比例；重置时间已过时视为额度已恢复，限额未知时返回空
+func (r *RateLimit) Headroom(now time.Time) *float64 {
+	headroom := 0.0
+	switch {
+	case r.ResetAt != nil && !r.ResetAt.After(now):
+		headroom = 1
+	case r.Limit > 0:
+		headroom = float64(r.Remaining) / float64(r.Limit)
+		if headroom > 1 {
+			headroom = 1
+		}
+	case r.Remaining > 0:
+		return nil
+	}
+	return &headroom
+}
+
+// epochThreshold X-RateLimit-Reset大于该值时按Unix时间戳解析，否则按剩余秒数解析
+const epochThreshold = 1_000_000_000
+
+// ParseRateLimit 解析X-RateLimit-*、IETF草案的RateLimit-*以及Retry-After响应头，没有限流信息时返回空
+func ParseRateLimit(header http.Header, status int, now time.Time) *RateLimit {
+	limit, hasLimit := headerInt(header, "X-RateLimit-Limit", "RateLimit-Limit")
+	remaining, hasRemaining := headerInt(header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+
+	var resetAt *time.Time
+	if reset, ok := headerInt(header, "X-RateLimit-Reset", "RateLimit-Reset"); ok {
+		t := now.Add(time.Duration(reset) * time.Second)
+		if reset > epochThreshold {
+			t = time.Unix(int64(reset), 0).UTC()
+		}
+		resetAt = &t
+	}
+	retryAt := parseRetryAfter(header.Get("Retry-After"), now)
+
+	if status == http.StatusTooManyRequests {
+		// 被限流时额度已用完，Retry-After优先于其他重置时间
+		remaining, hasRemaining = 0, true
+		if retryAt != nil {
+			resetAt = retryAt
+		}
+	}
+	if !hasLimit && !hasRemaining {
+		return nil
+	}
+	return &RateLimit{Limit: limit, Remaining: remaining, ResetAt: resetAt}
+}
+
+// headerInt 依次读取响应头，取第一个整数；IETF草案格式如"100, 100;w=60"只取第一项
+func headerInt(header http.Header, names ...string) (int, bool) {
+	for _, name := range names {
+		value := strings.TrimSpace(header.Get(name))
+		if value == "" {
+			continue
+		}
+		if i := strings.IndexAny(value, ",;"); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= 0 {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// parseRetryAfter Retry-After可以是秒数或HTTP日期
+func parseRetryAfter(value string, now time.Time) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		t := now.Add(time.Duration(seconds) * time.Second)
+		return &t
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return &t
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Package sourcehealth 记录对上游数据源的每次请求，计算最近一段时间的成功率、延迟分位数和限流余量。
+// 数据只保存在内存中，服务重启后重新统计
+package sourcehealth
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 健康状态
+const (
+	StatusUnknown  = "unknown"
+	StatusHealthy  = "healthy"
+	StatusDegraded = "degraded"
+	StatusFailing  = "failing"
+)
+
+const (
+	// degradedErrorRate 错误率达到该值视为降级
+	degradedErrorRate = 0.1
+	// failingErrorRate 错误率达到该值或最近连续失败达到failingStreak次视为故障
+	failingErrorRate = 0.5
+	failingStreak    = 3
+	// lowHeadroom 限流余量低于该比例视为降级
+	lowHeadroom = 0.1
+)
+
+// Sample 一次请求的结果
+type Sample struct {
+	At        time.Time
+	Latency   time.Duration
+	Status    int // HTTP状态码，请求未得到响应时为0
+	Error     string
+	RateLimit *RateLimit
+}
+
+// Failed 请求是否失败：网络错误或4xx、5xx响应
+func (s Sample) Failed() bool {
+	return s.Error != "" || s.Status >= 400
+}
+
+// Health 数据源最近一段时间的健康状况
+type Health struct {
+	Status        string     `json:"status"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastFailureAt *time.Time `json:"last_failure_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	Requests      int        `json:"requests"`
+	Errors        int        `json:"errors"`
+	ErrorRate     float64    `json:"error_rate"`
+	LatencyP50Ms  int64      `json:"latency_p50_ms"`
+	LatencyP95Ms  int64      `json:"latency_p95_ms"`
+	LatencyP99Ms  int64      `json:"latency_p99_ms"`
+	RateLimit     *RateLimit `json:"rate_limit,omitempty"`
+	// Headroom 剩余可用请求比例（0-1），未知时为空
+	Headroom *float64 `json:"headroom"`
+	// WindowSeconds 统计窗口
+	WindowSeconds int `json:"window_seconds"`
+}
+
+// Tracker 按数据源名称保存最近的请求样本
+type Tracker struct {
+	window     time.Duration
+	maxSamples int
+
+	mu      sync.Mutex
+	sources map[string]*series
+}
+
+type series struct {
+	samples       []Sample
+	lastSuccessAt *time.Time
+	lastFailureAt *time.Time
+	lastError     string
+	lastRateLimit *RateLimit
+	streak        int // 连续失败次数
+}
+
+// NewTracker 每个数据源保留window时间内、最多maxSamples个样本
+func NewTracker(window time.Duration, maxSamples int) *Tracker {
+	return &Tracker{window: window, maxSamples: maxSamples, sources: make(map[string]*series)}
+}
+
+// Record 记录一次请求
+func (t *Tracker) Record(name string, sample Sample) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.sources[name]
+	if s == nil {
+		s = &series{}
+		t.sources[name] = s
+	}
+	s.samples = append(s.samples, sample)
+	if len(s.samples) > t.maxSamples {
+		s.samples = s.samples[len(s.samples)-t.maxSamples:]
+	}
+	s.prune(sample.At.Add(-t.window))
+
+	at := sample.At
+	if sample.Failed() {
+		s.lastFailureAt = &at
+		s.lastError = sample.Error
+		if s.lastError == "" {
+			s.lastError = "HTTP " + strconv.Itoa(sample.Status)
+		}
+		s.streak++
+	} else {
+		s.lastSuccessAt = &at
+		s.streak = 0
+	}
+	if sample.RateLimit != nil {
+		s.lastRateLimit = sample.RateLimit
+	}
+}
+
+func (s *series) prune(cutoff time.Time) {
+	i := 0
+	for i < len(s.samples) && s.samples[i].At.Before(cutoff) {
+		i++
+	}
+	s.samples = s.samples[i:]
+}
+
+// Reset 删除数据源的全部样本，用于数据源被删除或更名后
+func (t *Tracker) Reset(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sources, name)
+}
+
+// Health 计算数据源的健康状况，perMinute为配置的每分钟请求上限，
+// 上游没有返回限流响应头时用它估算余量
+func (t *Tracker) Health(name string, perMinute *int, now time.Time) Health {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := Health{Status: StatusUnknown, WindowSeconds: int(t.window.Seconds())}
+	s := t.sources[name]
+	if s == nil {
+		health.Headroom = estimateHeadroom(nil, perMinute, now)
+		return health
+	}
+	s.prune(now.Add(-t.window))
+
+	health.LastSuccessAt = s.lastSuccessAt
+	health.LastFailureAt = s.lastFailureAt
+	health.LastError = s.lastError
+	health.Requests = len(s.samples)
+
+	latencies := make([]time.Duration, 0, len(s.samples))
+	for _, sample := range s.samples {
+		if sample.Failed() {
+			health.Errors++
+		}
+		if sample.Latency > 0 {
+			latencies = append(latencies, sample.Latency)
+		}
+	}
+	if health.Requests > 0 {
+		health.ErrorRate = float64(health.Errors) / float64(health.Requests)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	health.LatencyP50Ms = percentile(latencies, 0.50).Milliseconds()
+	health.LatencyP95Ms = percentile(latencies, 0.95).Milliseconds()
+	health.LatencyP99Ms = percentile(latencies, 0.99).Milliseconds()
+
+	if s.lastRateLimit != nil {
+		health.RateLimit = s.lastRateLimit
+		health.Headroom = s.lastRateLimit.Headroom(now)
+	}
+	if health.Headroom == nil {
+		health.Headroom = estimateHeadroom(s.samples, perMinute, now)
+	}
+
+	switch {
+	case health.Requests == 0:
+		health.Status = StatusUnknown
+	case health.ErrorRate >= failingErrorRate || s.streak >= failingStreak:
+		health.Status = StatusFailing
+	case health.ErrorRate >= degradedErrorRate || (health.Headroom != nil && *health.Headroom < lowHeadroom):
+		health.Status = StatusDegraded
+	default:
+		health.Status = StatusHealthy
+	}
+	return health
+}
+
+// percentile 最近秩法计算分位数，sorted须已升序排列
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// estimateHeadroom 按最近一分钟的请求数和配置的每分钟上限估算余量
+func estimateHeadroom(samples []Sample, perMinute *int, now time.Time) *float64 {
+	if perMinute == nil || *perMinute <= 0 {
+		return nil
+	}
+	cutoff := now.Add(-time.Minute)
+	used := 0
+	for _, sample := range samples {
+		if !sample.At.Before(cutoff) {
+			used++
+		}
+	}
+	headroom := 1 - float64(used)/float64(*perMinute)
+	if headroom < 0 {
+		headroom = 0
+	}
+	return &headroom
+}
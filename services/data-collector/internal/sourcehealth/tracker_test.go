@@ -0,0 +1,161 @@
+package sourcehealth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	tracker := NewTracker(15*time.Minute, 1000)
+	now := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+
+	health := tracker.Health("coingecko", nil, now)
+	assert.Equal(t, StatusUnknown, health.Status)
+	assert.Nil(t, health.Headroom)
+
+	for i := 1; i <= 100; i++ {
+		tracker.Record("coingecko", Sample{At: now.Add(-time.Duration(i) * time.Second), Latency: time.Duration(i) * time.Millisecond, Status: 200})
+	}
+	health = tracker.Health("coingecko", nil, now)
+	assert.Equal(t, StatusHealthy, health.Status)
+	assert.Equal(t, 100, health.Requests)
+	assert.Equal(t, int64(50), health.LatencyP50Ms)
+	assert.Equal(t, int64(95), health.LatencyP95Ms)
+	assert.Equal(t, int64(99), health.LatencyP99Ms)
+	require.NotNil(t, health.LastSuccessAt)
+	assert.Nil(t, health.LastFailureAt)
+
+	// 错误率超过10%降级
+	for i := 0; i < 15; i++ {
+		tracker.Record("coingecko", Sample{At: now, Latency: time.Second, Status: 500})
+		tracker.Record("coingecko", Sample{At: now, Latency: time.Millisecond, Status: 200})
+	}
+	health = tracker.Health("coingecko", nil, now)
+	assert.Equal(t, StatusDegraded, health.Status)
+	assert.Equal(t, 15, health.Errors)
+	assert.Equal(t, "HTTP 500", health.LastError)
+
+	// 连续失败视为故障
+	for i := 0; i < 3; i++ {
+		tracker.Record("coingecko", Sample{At: now, Error: "connection refused"})
+	}
+	health = tracker.Health("coingecko", nil, now)
+	assert.Equal(t, StatusFailing, health.Status)
+	assert.Equal(t, "connection refused", health.LastError)
+
+	// 超出窗口的样本不再统计，最近一次成功时间保留
+	health = tracker.Health("coingecko", nil, now.Add(time.Hour))
+	assert.Equal(t, StatusUnknown, health.Status)
+	assert.Equal(t, 0, health.Requests)
+	assert.NotNil(t, health.LastSuccessAt)
+}
+
+func TestMaxSamples(t *testing.T) {
+	tracker := NewTracker(time.Hour, 10)
+	now := time.Now()
+	for i := 0; i < 25; i++ {
+		tracker.Record("newsapi", Sample{At: now, Status: 200})
+	}
+	assert.Equal(t, 10, tracker.Health("newsapi", nil, now).Requests)
+}
+
+func TestEstimatedHeadroom(t *testing.T) {
+	tracker := NewTracker(15*time.Minute, 1000)
+	now := time.Now()
+	for i := 0; i < 24; i++ {
+		tracker.Record("coinmarketcap", Sample{At: now.Add(-time.Duration(i) * time.Second), Status: 200})
+	}
+	// 一分钟之前的请求不计入
+	tracker.Record("coinmarketcap", Sample{At: now.Add(-2 * time.Minute), Status: 200})
+
+	perMinute := 30
+	health := tracker.Health("coinmarketcap", &perMinute, now)
+	require.NotNil(t, health.Headroom)
+	assert.InDelta(t, 0.2, *health.Headroom, 0.001)
+	assert.Equal(t, StatusHealthy, health.Status)
+
+	for i := 0; i < 4; i++ {
+		tracker.Record("coinmarketcap", Sample{At: now, Status: 200})
+	}
+	health = tracker.Health("coinmarketcap", &perMinute, now)
+	assert.Equal(t, StatusDegraded, health.Status)
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	assert.Nil(t, ParseRateLimit(header, 200, now))
+
+	header.Set("X-RateLimit-Limit", "100")
+	header.Set("X-RateLimit-Remaining", "25")
+	header.Set("X-RateLimit-Reset", "1715681100")
+	limit := ParseRateLimit(header, 200, now)
+	require.NotNil(t, limit)
+	assert.Equal(t, 100, limit.Limit)
+	assert.Equal(t, 25, limit.Remaining)
+	assert.Equal(t, time.Date(2024, 5, 14, 10, 5, 0, 0, time.UTC), *limit.ResetAt)
+	assert.InDelta(t, 0.25, *limit.Headroom(now), 0.001)
+	// 重置时间之后额度恢复
+	assert.Equal(t, 1.0, *limit.Headroom(now.Add(10 * time.Minute)))
+
+	// IETF草案格式，重置时间为剩余秒数
+	header = http.Header{}
+	header.Set("RateLimit-Limit", "60, 60;w=60")
+	header.Set("RateLimit-Remaining", "6")
+	header.Set("RateLimit-Reset", "30")
+	limit = ParseRateLimit(header, 200, now)
+	require.NotNil(t, limit)
+	assert.Equal(t, 60, limit.Limit)
+	assert.Equal(t, now.Add(30*time.Second), *limit.ResetAt)
+
+	// 429只有Retry-After
+	header = http.Header{}
+	header.Set("Retry-After", "120")
+	limit = ParseRateLimit(header, http.StatusTooManyRequests, now)
+	require.NotNil(t, limit)
+	assert.Equal(t, 0, limit.Remaining)
+	assert.Equal(t, now.Add(2*time.Minute), *limit.ResetAt)
+	assert.Equal(t, 0.0, *limit.Headroom(now))
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "9")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewTracker(15*time.Minute, 1000)
+	client := &http.Client{Transport: &Transport{
+		Tracker: tracker,
+		Match: func(req *http.Request) string {
+			if req.URL.Path == "/other" {
+				return ""
+			}
+			return "test"
+		},
+	}}
+
+	for _, path := range []string{"/ok", "/missing", "/other"} {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := client.Get("http://127.0.0.1:1/unreachable")
+	require.Error(t, err)
+
+	health := tracker.Health("test", nil, time.Now())
+	assert.Equal(t, 3, health.Requests)
+	assert.Equal(t, 2, health.Errors)
+	require.NotNil(t, health.RateLimit)
+	assert.Equal(t, 9, health.RateLimit.Remaining)
+}
@@ -0,0 +1,44 @@
+package sourcehealth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/rwa-platform/shared/metrics"
+)
+
+// Transport 记录经过的请求，Match返回请求所属数据源的名称，返回空的请求不记录
+type Transport struct {
+	Base    http.RoundTripper
+	Match   func(req *http.Request) string
+	Tracker *Tracker
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	name := t.Match(req)
+	if name == "" {
+		return base.RoundTrip(req)
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	// 调用方主动取消的请求不代表数据源异常
+	if err != nil && req.Context().Err() != nil {
+		return resp, err
+	}
+
+	sample := Sample{At: start, Latency: time.Since(start)}
+	if err != nil {
+		sample.Error = err.Error()
+	} else {
+		sample.Status = resp.StatusCode
+		sample.RateLimit = ParseRateLimit(resp.Header, resp.StatusCode, time.Now())
+	}
+	t.Tracker.Record(name, sample)
+	metrics.ObserveUpstream(name, sample.Latency, sample.Status, err)
+	return resp, err
+}
@@ -16,9 +16,25 @@ import (
 	"github.com/rwa-platform/portfolio-service/internal/kafka"
 	"github.com/rwa-platform/portfolio-service/internal/redis"
 	"github.com/rwa-platform/portfolio-service/internal/services"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
+	kafkago "github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
+// consumerGroup Kafka消费组
+const consumerGroup = "portfolio-service-group"
+
+// consumerTopics 订阅的Kafka主题
+var consumerTopics = []string{
+	"transaction-events",
+	"balance-updates",
+	"price-updates",
+	"user-events",
+	"asset-events",
+}
+
 func main() {
 	// 初始化配置
 	cfg, err := config.Load()
@@ -31,11 +47,30 @@ func main() {
 
 	logrus.Info("Starting RWA Portfolio Service...")
 
+	// 初始化链路追踪，未启用时各接入点只传递trace上下文
+	if cfg.TracingEnabled {
+		shutdown, err := tracing.Setup(context.Background(), "portfolio-service", cfg.TracingEndpoint)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logrus.Errorf("Failed to flush traces: %v", err)
+			}
+		}()
+		logrus.Infof("Tracing enabled, exporting to %s", cfg.TracingEndpoint)
+	}
+
 	// 初始化数据库
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logrus.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("Failed to register database tracing: %v", err)
+	}
 
 	// 初始化Redis
 	redisClient, err := redis.NewClient(cfg.RedisURL)
@@ -50,7 +85,7 @@ func main() {
 	}
 	defer kafkaProducer.Close()
 
-	kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "portfolio-service-group")
+	kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, consumerGroup)
 	if err != nil {
 		logrus.Fatalf("Failed to create Kafka consumer: %v", err)
 	}
@@ -67,6 +102,17 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Prometheus指标在单独的端口上暴露
+	if cfg.MetricsEnabled {
+		go func() {
+			logrus.Infof("Metrics server starting on port %d", cfg.MetricsPort)
+			if err := metrics.Serve(ctx, cfg.MetricsPort); err != nil {
+				logrus.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+		go metrics.WatchConsumerLag(ctx, cfg.KafkaBrokers, consumerGroup, consumerTopics, 30*time.Second)
+	}
+
 	// 启动持仓同步服务
 	go syncService.StartPositionSync(ctx)
 	
@@ -83,7 +129,13 @@ func main() {
 	go startKafkaConsumers(ctx, kafkaConsumer, portfolioService, aggregationService, analyticsService)
 
 	// 初始化HTTP服务器
-	router := setupRouter(portfolioService, aggregationService, analyticsService, reportService, syncService)
+	statsSections := []stats.Section{
+		portfolioService.FillStats,
+		stats.CacheSection,
+		stats.RedisSection(redisClient),
+		stats.TableSection(db, "positions", "transactions", "portfolio_values", "asset_prices"),
+	}
+	router := setupRouter(portfolioService, aggregationService, analyticsService, reportService, syncService, statsSections)
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
@@ -139,6 +191,7 @@ func setupRouter(
 	analyticsService *services.AnalyticsService,
 	reportService *services.ReportService,
 	syncService *services.SyncService,
+	statsSections []stats.Section,
 ) *gin.Engine {
 	if gin.Mode() == gin.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
@@ -147,6 +200,8 @@ func setupRouter(
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware("portfolio-service"))
+	router.Use(metrics.Middleware())
 
 	// 健康检查
 	router.GET("/health", handlers.HealthCheck)
@@ -206,7 +261,7 @@ func setupRouter(
 		// 管理接口
 		admin := v1.Group("/admin")
 		{
-			admin.GET("/stats", handlers.GetSystemStats(portfolioService, aggregationService, analyticsService))
+			admin.GET("/stats", stats.Handler("portfolio-service", statsSections...))
 			admin.POST("/sync/all", handlers.SyncAllPortfolios(syncService))
 			admin.GET("/health/detailed", handlers.DetailedHealthCheck(portfolioService))
 			admin.GET("/metrics", handlers.GetMetrics(portfolioService))
@@ -223,19 +278,11 @@ func startKafkaConsumers(
 	aggregationService *services.AggregationService,
 	analyticsService *services.AnalyticsService,
 ) {
-	topics := []string{
-		"transaction-events",
-		"balance-updates",
-		"price-updates",
-		"user-events",
-		"asset-events",
-	}
-
-	for _, topic := range topics {
+	for _, topic := range consumerTopics {
 		go func(t string) {
-			if err := consumer.Subscribe(t, func(message []byte) error {
-				return handleKafkaMessage(t, message, portfolioService, aggregationService, analyticsService)
-			}); err != nil {
+			if err := consumer.Subscribe(t, metrics.ConsumeHandler(consumerGroup, func(ctx context.Context, message kafkago.Message) error {
+				return handleKafkaMessage(message.Topic, message.Value, portfolioService, aggregationService, analyticsService)
+			})); err != nil {
 				logrus.Errorf("Failed to subscribe to topic %s: %v", t, err)
 			}
 		}(topic)
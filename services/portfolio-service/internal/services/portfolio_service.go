@@ -11,10 +11,14 @@ import (
 	"github.com/rwa-platform/portfolio-service/internal/config"
 	"github.com/rwa-platform/portfolio-service/internal/kafka"
 	"github.com/rwa-platform/portfolio-service/internal/models"
+	"github.com/rwa-platform/shared/stats"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// portfolioCache 投资组合缓存的命中计数
+var portfolioCache = stats.NewCacheCounter("portfolio")
+
 type PortfolioService struct {
 	db     *gorm.DB
 	redis  *redis.Client
@@ -137,9 +141,11 @@ func (s *PortfolioService) GetPortfolio(userID string) (*Portfolio, error) {
 	if err == nil {
 		var portfolio Portfolio
 		if err := json.Unmarshal([]byte(cached), &portfolio); err == nil {
+			portfolioCache.Hit()
 			return &portfolio, nil
 		}
 	}
+	portfolioCache.Miss()
 
 	// 从数据库构建投资组合
 	portfolio, err := s.buildPortfolio(userID)
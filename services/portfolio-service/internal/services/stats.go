@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rwa-platform/shared/stats"
+)
+
+// FillStats 交易记录按渠道统计的写入量
+func (s *PortfolioService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.IngestionSection(s.db, "transactions", "channel_id", "timestamp")(ctx, report)
+}
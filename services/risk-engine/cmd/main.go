@@ -16,9 +16,27 @@ import (
 	"github.com/rwa-platform/risk-engine/internal/kafka"
 	"github.com/rwa-platform/risk-engine/internal/redis"
 	"github.com/rwa-platform/risk-engine/internal/services"
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
+	kafkago "github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
+// consumerGroup Kafka消费组
+const consumerGroup = "risk-engine-group"
+
+// consumerTopics 订阅的Kafka主题
+var consumerTopics = []string{
+	"asset-events",
+	"channel-events",
+	"user-events",
+	"transaction-events",
+	"market-events",
+	"regulatory-events",
+}
+
 func main() {
 	// 初始化配置
 	cfg, err := config.Load()
@@ -31,11 +49,30 @@ func main() {
 
 	logrus.Info("Starting RWA Risk Engine Service...")
 
+	// 初始化链路追踪，未启用时各接入点只传递trace上下文
+	if cfg.TracingEnabled {
+		shutdown, err := tracing.Setup(context.Background(), "risk-engine", cfg.TracingEndpoint)
+		if err != nil {
+			logrus.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				logrus.Errorf("Failed to flush traces: %v", err)
+			}
+		}()
+		logrus.Infof("Tracing enabled, exporting to %s", cfg.TracingEndpoint)
+	}
+
 	// 初始化数据库
 	db, err := database.NewConnection(cfg.DatabaseURL)
 	if err != nil {
 		logrus.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		logrus.Fatalf("Failed to register database tracing: %v", err)
+	}
 
 	// 初始化Redis
 	redisClient, err := redis.NewClient(cfg.RedisURL)
@@ -50,7 +87,7 @@ func main() {
 	}
 	defer kafkaProducer.Close()
 
-	kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, "risk-engine-group")
+	kafkaConsumer, err := kafka.NewConsumer(cfg.KafkaBrokers, consumerGroup)
 	if err != nil {
 		logrus.Fatalf("Failed to create Kafka consumer: %v", err)
 	}
@@ -62,15 +99,37 @@ func main() {
 	complianceService := services.NewComplianceService(db, redisClient, kafkaProducer, cfg)
 	alertService := services.NewAlertService(db, redisClient, kafkaProducer, cfg)
 
+	// 注册定时任务，任务定义和执行记录保存在数据库中
+	if err := scheduler.Migrate(db); err != nil {
+		logrus.Fatalf("Failed to migrate scheduler tables: %v", err)
+	}
+	jobScheduler := scheduler.New(db, "risk-engine")
+	for _, register := range []func(*scheduler.Scheduler) error{
+		riskService.RegisterJobs,
+		ratingService.RegisterJobs,
+	} {
+		if err := register(jobScheduler); err != nil {
+			logrus.Fatalf("Failed to register scheduled jobs: %v", err)
+		}
+	}
+
 	// 启动后台服务
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// 启动风险监控引擎
-	go riskService.StartRiskMonitoring(ctx)
-	
-	// 启动评分计算引擎
-	go ratingService.StartRatingEngine(ctx)
+	// Prometheus指标在单独的端口上暴露
+	if cfg.MetricsEnabled {
+		go func() {
+			logrus.Infof("Metrics server starting on port %d", cfg.MetricsPort)
+			if err := metrics.Serve(ctx, cfg.MetricsPort); err != nil {
+				logrus.Errorf("Metrics server failed: %v", err)
+			}
+		}()
+		go metrics.WatchConsumerLag(ctx, cfg.KafkaBrokers, consumerGroup, consumerTopics, 30*time.Second)
+	}
+
+	// 启动定时任务（风险监控、评分计算）
+	go jobScheduler.Start(ctx)
 	
 	// 启动合规检查引擎
 	go complianceService.StartComplianceEngine(ctx)
@@ -82,7 +141,15 @@ func main() {
 	go startKafkaConsumers(ctx, kafkaConsumer, riskService, ratingService, complianceService)
 
 	// 初始化HTTP服务器
-	router := setupRouter(riskService, ratingService, complianceService, alertService)
+	statsSections := []stats.Section{
+		riskService.FillStats,
+		ratingService.FillStats,
+		jobScheduler.FillStats,
+		stats.CacheSection,
+		stats.RedisSection(redisClient),
+		stats.TableSection(db, "risk_assessments", "ratings", "user_risk_profiles"),
+	}
+	router := setupRouter(riskService, ratingService, complianceService, alertService, statsSections)
 	
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
@@ -137,6 +204,7 @@ func setupRouter(
 	ratingService *services.RatingService,
 	complianceService *services.ComplianceService,
 	alertService *services.AlertService,
+	statsSections []stats.Section,
 ) *gin.Engine {
 	if gin.Mode() == gin.ReleaseMode {
 		gin.SetMode(gin.ReleaseMode)
@@ -145,6 +213,8 @@ func setupRouter(
 	router := gin.New()
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
+	router.Use(tracing.Middleware("risk-engine"))
+	router.Use(metrics.Middleware())
 
 	// 健康检查
 	router.GET("/health", handlers.HealthCheck)
@@ -197,7 +267,7 @@ func setupRouter(
 		// 管理接口
 		admin := v1.Group("/admin")
 		{
-			admin.GET("/stats", handlers.GetSystemStats(riskService, ratingService, complianceService, alertService))
+			admin.GET("/stats", stats.Handler("risk-engine", statsSections...))
 			admin.POST("/recalculate", handlers.RecalculateRatings(ratingService))
 			admin.GET("/health/detailed", handlers.DetailedHealthCheck(riskService))
 		}
@@ -213,19 +283,11 @@ func startKafkaConsumers(
 	ratingService *services.RatingService,
 	complianceService *services.ComplianceService,
 ) {
-	topics := []string{
-		"asset-events",
-		"channel-events",
-		"user-events",
-		"transaction-events",
-		"market-events",
-	}
-
-	for _, topic := range topics {
+	for _, topic := range consumerTopics {
 		go func(t string) {
-			if err := consumer.Subscribe(t, func(message []byte) error {
-				return handleKafkaMessage(t, message, riskService, ratingService, complianceService)
-			}); err != nil {
+			if err := consumer.Subscribe(t, metrics.ConsumeHandler(consumerGroup, func(ctx context.Context, message kafkago.Message) error {
+				return handleKafkaMessage(message.Topic, message.Value, riskService, ratingService, complianceService)
+			})); err != nil {
 				logrus.Errorf("Failed to subscribe to topic %s: %v", t, err)
 			}
 		}(topic)
@@ -253,6 +315,8 @@ func handleKafkaMessage(
 		return riskService.HandleTransactionEvent(message)
 	case "market-events":
 		return riskService.HandleMarketEvent(message)
+	case "regulatory-events":
+		return ratingService.HandleRegulatoryEvent(message)
 	default:
 		logrus.Warnf("Unknown topic: %s", topic)
 	}
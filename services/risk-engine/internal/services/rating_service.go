@@ -12,6 +12,7 @@ import (
 	"github.com/rwa-platform/risk-engine/internal/config"
 	"github.com/rwa-platform/risk-engine/internal/kafka"
 	"github.com/rwa-platform/risk-engine/internal/models"
+	"github.com/rwa-platform/shared/scheduler"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -92,24 +93,25 @@ func NewRatingService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *kaf
 	}
 }
 
-func (s *RatingService) StartRatingEngine(ctx context.Context) {
-	s.logger.Info("Starting rating engine")
-	
-	ticker := time.NewTicker(time.Duration(s.config.RatingUpdateInterval) * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Rating engine stopped")
-			return
-		case <-ticker.C:
-			s.performRatingUpdates(ctx)
-		}
+// ratingUpdateJob 评分更新的调度任务名
+const ratingUpdateJob = "rating-update"
+
+// RegisterJobs 注册评分更新任务，按RATING_UPDATE_INTERVAL固定间隔执行
+func (s *RatingService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	interval := time.Duration(s.config.RatingUpdateInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Hour
 	}
+	return jobs.Register(scheduler.Spec{
+		Name:     ratingUpdateJob,
+		Schedule: scheduler.Every(interval),
+		Timeout:  30 * time.Minute,
+		Missed:   scheduler.MissedRunOnce,
+		Run:      s.performRatingUpdates,
+	})
 }
 
-func (s *RatingService) performRatingUpdates(ctx context.Context) {
+func (s *RatingService) performRatingUpdates(ctx context.Context) error {
 	s.logger.Debug("Performing rating updates")
 
 	// 更新资产评分
@@ -120,6 +122,7 @@ func (s *RatingService) performRatingUpdates(ctx context.Context) {
 	
 	// 清理过期评分
 	s.cleanupExpiredRatings(ctx)
+	return nil
 }
 
 func (s *RatingService) CalculateRating(request *RatingRequest) (*RatingResult, error) {
@@ -339,7 +342,7 @@ func (s *RatingService) calculateAssetSecurityScore(asset *models.Asset) (float6
 
 	// 基于抵押品质量
 	if asset.CollateralType != "" {
-		collateralScore := s.evaluateCollateralQuality(asset.CollateralType)
+		collateralScore := s.evaluateCollateralQuality(asset.ID, asset.CollateralType)
 		score = (score*0.7 + collateralScore*0.3)
 
 		factors = append(factors, RatingFactor{
@@ -443,6 +446,20 @@ func (s *RatingService) calculateAssetStabilityScore(asset *models.Asset) (float
 		DataSources: []string{"fundamental_data"},
 	})
 
+	// 基于近期负面新闻（数据采集服务按实体关联到资产的新闻）
+	if news := s.getNegativeNews(asset.ID); news != nil {
+		newsScore := scoreNegativeNews(news)
+		score = score*0.85 + newsScore*0.15
+
+		factors = append(factors, RatingFactor{
+			Category:    "negative_news",
+			Score:       newsScore,
+			Weight:      0.15,
+			Description: fmt.Sprintf("%d negative news stories in the last 7 days", news.Stories),
+			DataSources: []string{"news"},
+		})
+	}
+
 	return math.Min(score, 1.0), factors
 }
 
@@ -486,6 +503,20 @@ func (s *RatingService) calculateAssetTransparencyScore(asset *models.Asset) (fl
 		DataSources: []string{"reporting_data"},
 	})
 
+	// 基于储备证明（数据采集服务监控的链上流通量与储备之比）
+	if reserve := s.getReserveStatus(asset.ID); reserve != nil {
+		reserveScore := scoreReserveStatus(reserve)
+		score = score*0.7 + reserveScore*0.3
+
+		factors = append(factors, RatingFactor{
+			Category:    "proof_of_reserve",
+			Score:       reserveScore,
+			Weight:      0.3,
+			Description: fmt.Sprintf("Collateralization ratio %.2f%% from proof of reserve", reserve.Ratio*100),
+			DataSources: []string{"proof_of_reserve"},
+		})
+	}
+
 	return math.Min(score, 1.0), factors
 }
 
@@ -741,11 +772,95 @@ func (s *RatingService) convertCreditRatingToScore(rating string) float64 {
 	return 0.5 // 默认值
 }
 
-func (s *RatingService) evaluateCollateralQuality(collateralType string) float64 {
-	// 评估抵押品质量
+func (s *RatingService) evaluateCollateralQuality(assetID, collateralType string) float64 {
+	// 有储备证明数据时按实际抵押率评估
+	if reserve := s.getReserveStatus(assetID); reserve != nil {
+		return scoreReserveStatus(reserve)
+	}
 	return 0.7 // 默认值
 }
 
+// reserveStatus 数据采集服务写入metric_data的最新抵押率
+type reserveStatus struct {
+	Ratio     float64
+	Stale     bool
+	Timestamp time.Time
+}
+
+// getReserveStatus 读取资产最新的抵押率，没有储备证明数据时返回nil
+func (s *RatingService) getReserveStatus(assetID string) *reserveStatus {
+	var row struct {
+		Value     float64
+		Stale     bool
+		Timestamp time.Time
+	}
+	err := s.db.Table("metric_data").
+		Select("value, COALESCE((metadata->>'stale')::boolean, false) AS stale, timestamp").
+		Where("asset_id = ? AND metric_type = ?", assetID, "collateralization_ratio").
+		Order("timestamp DESC").
+		Limit(1).
+		Scan(&row).Error
+	if err != nil || row.Timestamp.IsZero() {
+		return nil
+	}
+
+	// 监控本身停止更新也视为过期
+	stale := row.Stale || time.Since(row.Timestamp) > 48*time.Hour
+	return &reserveStatus{Ratio: row.Value, Stale: stale, Timestamp: row.Timestamp}
+}
+
+// negativeNews 最近7天关联到资产的负面新闻，转载的同一报道只计一次
+type negativeNews struct {
+	Stories int
+	Worst   float64
+}
+
+// getNegativeNews 读取资产最近的负面新闻，没有时返回nil
+func (s *RatingService) getNegativeNews(assetID string) *negativeNews {
+	var row negativeNews
+	err := s.db.Table("news_entities AS e").
+		Joins("JOIN news_articles AS a ON a.id = e.article_id").
+		Select("COUNT(DISTINCT COALESCE(a.story_id, a.id)) AS stories, COALESCE(MIN(a.sentiment), 0) AS worst").
+		Where("e.entity_type = ? AND e.entity_id = ? AND e.confidence >= ?", "asset", assetID, 0.6).
+		Where("a.sentiment <= ? AND a.published_at > ?", -0.3, time.Now().Add(-7*24*time.Hour)).
+		Scan(&row).Error
+	if err != nil || row.Stories == 0 {
+		return nil
+	}
+	return &row
+}
+
+// scoreNegativeNews 每篇负面报道扣分，情绪极差的报道额外扣分
+func scoreNegativeNews(news *negativeNews) float64 {
+	score := 1.0 - 0.15*float64(news.Stories)
+	if news.Worst <= -0.8 {
+		score -= 0.2
+	}
+	return math.Max(score, 0.1)
+}
+
+// scoreReserveStatus 足额抵押得高分，抵押不足按缺口快速降分，储备数据过期时减半
+func scoreReserveStatus(reserve *reserveStatus) float64 {
+	var score float64
+	switch {
+	case reserve.Ratio >= 1.02:
+		score = 1.0
+	case reserve.Ratio >= 1.0:
+		score = 0.9
+	case reserve.Ratio >= 0.98:
+		score = 0.6
+	case reserve.Ratio >= 0.95:
+		score = 0.4
+	default:
+		score = 0.1
+	}
+
+	if reserve.Stale {
+		score *= 0.5
+	}
+	return score
+}
+
 func (s *RatingService) getMarketDepth(assetID string) float64 {
 	// 获取市场深度
 	return 500000 // 默认值
@@ -786,9 +901,42 @@ func (s *RatingService) evaluateReportingFrequency(asset *models.Asset) float64
 	return 0.8 // 默认值
 }
 
+// regulatoryPenalties 已发布的监管事件按严重程度扣分
+var regulatoryPenalties = map[string]float64{
+	"low":      0.05,
+	"medium":   0.15,
+	"high":     0.3,
+	"critical": 0.5,
+}
+
 func (s *RatingService) evaluateRegulatoryCompliance(asset *models.Asset) float64 {
-	// 评估监管合规
-	return 0.9 // 默认值
+	// 评估监管合规：最近90天关联到资产的已发布监管事件按严重程度扣分，同一类型只计最严重的一次
+	var events []struct {
+		EventType string
+		Severity  string
+	}
+	assetIDs, _ := json.Marshal([]string{asset.ID})
+	err := s.db.Table("regulatory_events").
+		Select("event_type, severity").
+		Where("status = ? AND asset_ids @> ?", "published", string(assetIDs)).
+		Where("article_published_at > ?", time.Now().AddDate(0, 0, -90)).
+		Scan(&events).Error
+	if err != nil {
+		s.logger.Warnf("Failed to query regulatory events for asset %s: %v", asset.ID, err)
+		return 0.9 // 默认值
+	}
+
+	worst := make(map[string]float64)
+	for _, event := range events {
+		if penalty := regulatoryPenalties[event.Severity]; penalty > worst[event.EventType] {
+			worst[event.EventType] = penalty
+		}
+	}
+	score := 0.9
+	for _, penalty := range worst {
+		score -= penalty
+	}
+	return math.Max(score, 0.1)
 }
 
 func (s *RatingService) evaluateKYCCompliance(asset *models.Asset) float64 {
@@ -835,6 +983,51 @@ func (s *RatingService) cleanupExpiredRatings(ctx context.Context) {
 // Kafka事件处理
 func (s *RatingService) HandleAssetEvent(message []byte) error {
 	// 处理资产事件
+	var event struct {
+		Type    string `json:"type"`
+		AssetID string `json:"asset_id"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		return fmt.Errorf("failed to decode asset event: %v", err)
+	}
+
+	switch event.Type {
+	case "reserve_shortfall", "negative_news":
+		// 储备不足或过期（reasons区分）、出现关联的负面新闻时立即重新评级，不等待下一个评级周期
+		if _, err := s.calculateAssetRating(event.AssetID, map[string]interface{}{"trigger": event.Type}); err != nil {
+			return fmt.Errorf("failed to re-rate asset %s: %v", event.AssetID, err)
+		}
+	}
+	return nil
+}
+
+// HandleRegulatoryEvent 数据采集服务发布监管事件后重新评级关联的资产
+func (s *RatingService) HandleRegulatoryEvent(message []byte) error {
+	var event struct {
+		Type      string   `json:"type"`
+		ID        string   `json:"id"`
+		EventType string   `json:"event_type"`
+		Severity  string   `json:"severity"`
+		AssetIDs  []string `json:"asset_ids"`
+	}
+	if err := json.Unmarshal(message, &event); err != nil {
+		return fmt.Errorf("failed to decode regulatory event: %v", err)
+	}
+	if event.Type != "regulatory_event" {
+		return nil
+	}
+
+	for _, assetID := range event.AssetIDs {
+		context := map[string]interface{}{
+			"trigger":             event.Type,
+			"regulatory_event_id": event.ID,
+			"event_type":          event.EventType,
+			"severity":            event.Severity,
+		}
+		if _, err := s.calculateAssetRating(assetID, context); err != nil {
+			return fmt.Errorf("failed to re-rate asset %s: %v", assetID, err)
+		}
+	}
 	return nil
 }
 
@@ -12,10 +12,15 @@ import (
 	"github.com/rwa-platform/risk-engine/internal/config"
 	"github.com/rwa-platform/risk-engine/internal/kafka"
 	"github.com/rwa-platform/risk-engine/internal/models"
+	"github.com/rwa-platform/shared/scheduler"
+	"github.com/rwa-platform/shared/stats"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+// riskProfileCache 用户风险档案缓存的命中计数
+var riskProfileCache = stats.NewCacheCounter("risk_profile")
+
 type RiskService struct {
 	db     *gorm.DB
 	redis  *redis.Client
@@ -81,24 +86,26 @@ func NewRiskService(db *gorm.DB, redisClient *redis.Client, kafkaProducer *kafka
 	}
 }
 
-func (s *RiskService) StartRiskMonitoring(ctx context.Context) {
-	s.logger.Info("Starting risk monitoring service")
-	
-	ticker := time.NewTicker(time.Duration(s.config.RiskMonitoringInterval) * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			s.logger.Info("Risk monitoring service stopped")
-			return
-		case <-ticker.C:
-			s.performRiskMonitoring(ctx)
-		}
+// riskMonitoringJob 风险监控的调度任务名
+const riskMonitoringJob = "risk-monitoring"
+
+// RegisterJobs 注册风险监控任务，按RISK_MONITORING_INTERVAL固定间隔执行
+func (s *RiskService) RegisterJobs(jobs *scheduler.Scheduler) error {
+	interval := time.Duration(s.config.RiskMonitoringInterval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Minute
 	}
+	return jobs.Register(scheduler.Spec{
+		Name:     riskMonitoringJob,
+		Schedule: scheduler.Every(interval),
+		Timeout:  15 * time.Minute,
+		// 每次监控基于当前状态，错过的执行合并为一次即可
+		Missed: scheduler.MissedRunOnce,
+		Run:    s.performRiskMonitoring,
+	})
 }
 
-func (s *RiskService) performRiskMonitoring(ctx context.Context) {
+func (s *RiskService) performRiskMonitoring(ctx context.Context) error {
 	s.logger.Debug("Performing risk monitoring cycle")
 
 	// 监控用户风险变化
@@ -112,6 +119,7 @@ func (s *RiskService) performRiskMonitoring(ctx context.Context) {
 	
 	// 监控系统性风险
 	s.monitorSystemicRisk(ctx)
+	return nil
 }
 
 func (s *RiskService) AssessRisk(request *RiskAssessmentRequest) (*RiskAssessmentResult, error) {
@@ -516,10 +524,31 @@ func (s *RiskService) getMarketVolatility(assetID string) float64 {
 }
 
 func (s *RiskService) getMarketTrend(assetID string) float64 {
-	// 获取市场趋势指标
+	// 基于新闻情绪动量（数据采集服务写入metric_data），情绪恶化时趋势风险上升
+	if momentum, ok := s.getSentimentMomentum(assetID); ok {
+		return math.Max(0, math.Min(1, 0.2-momentum*0.5))
+	}
 	return 0.2 // 默认值
 }
 
+// getSentimentMomentum 读取资产最近48小时内的新闻情绪动量，范围-2到2，负值表示情绪在恶化
+func (s *RiskService) getSentimentMomentum(assetID string) (float64, bool) {
+	var row struct {
+		Value     float64
+		Timestamp time.Time
+	}
+	err := s.db.Table("metric_data").
+		Select("value, timestamp").
+		Where("asset_id = ? AND metric_type = ? AND timestamp > ?", assetID, "news_sentiment_momentum", time.Now().Add(-48*time.Hour)).
+		Order("timestamp DESC").
+		Limit(1).
+		Scan(&row).Error
+	if err != nil || row.Timestamp.IsZero() {
+		return 0, false
+	}
+	return row.Value, true
+}
+
 func (s *RiskService) getLiquidityScore(assetID string, amount float64) float64 {
 	// 计算流动性评分
 	return 0.7 // 默认值
@@ -588,9 +617,11 @@ func (s *RiskService) GetUserRiskProfile(userID string) (*RiskProfile, error) {
 	if err == nil {
 		var profile RiskProfile
 		if err := json.Unmarshal([]byte(cached), &profile); err == nil {
+			riskProfileCache.Hit()
 			return &profile, nil
 		}
 	}
+	riskProfileCache.Miss()
 
 	// 从数据库获取
 	var profile models.UserRiskProfile
@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/rwa-platform/shared/stats"
+)
+
+// FillStats 风险评估按风险等级统计的写入量
+func (s *RiskService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.IngestionSection(s.db, "risk_assessments", "risk_level", "created_at")(ctx, report)
+}
+
+// FillStats 评级结果按实体类型统计的写入量
+func (s *RatingService) FillStats(ctx context.Context, report *stats.Report) error {
+	return stats.IngestionSection(s.db, "ratings", "entity_type", "created_at")(ctx, report)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// WatchConsumerLag 定期比较消费组已提交的offset和各分区末尾的offset，更新kafka_consumer_lag，直到ctx取消
+func WatchConsumerLag(ctx context.Context, brokers []string, group string, topics []string, interval time.Duration) {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...), Timeout: 10 * time.Second}
+	logger := logrus.New()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := updateConsumerLag(ctx, client, group, topics); err != nil && ctx.Err() == nil {
+			logger.Warnf("Failed to update Kafka consumer lag for %s: %v", group, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func updateConsumerLag(ctx context.Context, client *kafka.Client, group string, topics []string) error {
+	metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+	if err != nil {
+		return err
+	}
+
+	partitions := make(map[string][]int)
+	ends := make(map[string][]kafka.OffsetRequest)
+	for _, topic := range metadata.Topics {
+		if topic.Error != nil {
+			continue
+		}
+		for _, partition := range topic.Partitions {
+			partitions[topic.Name] = append(partitions[topic.Name], partition.ID)
+			// 起点用于计算尚未提交offset的消费组的落后数
+			ends[topic.Name] = append(ends[topic.Name], kafka.FirstOffsetOf(partition.ID), kafka.LastOffsetOf(partition.ID))
+		}
+	}
+	if len(partitions) == 0 {
+		return nil
+	}
+
+	committed, err := client.OffsetFetch(ctx, &kafka.OffsetFetchRequest{GroupID: group, Topics: partitions})
+	if err != nil {
+		return err
+	}
+	if committed.Error != nil {
+		return committed.Error
+	}
+	offsets, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{Topics: ends})
+	if err != nil {
+		return err
+	}
+
+	for topic, partitionOffsets := range offsets.Topics {
+		committedByPartition := make(map[int]int64)
+		for _, p := range committed.Topics[topic] {
+			if p.Error == nil {
+				committedByPartition[p.Partition] = p.CommittedOffset
+			}
+		}
+		for _, p := range partitionOffsets {
+			if p.Error != nil {
+				continue
+			}
+			committedOffset, ok := committedByPartition[p.Partition]
+			if !ok {
+				continue
+			}
+			if lag, ok := consumerLag(p.FirstOffset, p.LastOffset, committedOffset); ok {
+				kafkaLag.WithLabelValues(topic, strconv.Itoa(p.Partition), group).Set(float64(lag))
+			}
+		}
+	}
+	return nil
+}
+
+// consumerLag 分区末尾与已提交offset之间的消息数；消费组尚未提交（-1）时从分区起点算起，
+// 起点或末尾未知（-1）时无法计算
+func consumerLag(first, last, committed int64) (int64, bool) {
+	if committed < 0 {
+		committed = first
+	}
+	if last < 0 || committed < 0 {
+		return 0, false
+	}
+	if lag := last - committed; lag > 0 {
+		return lag, true
+	}
+	return 0, true
+}
@@ -0,0 +1,194 @@
+// Package metrics 各服务共用的Prometheus指标，通过Serve在METRICS_PORT上暴露/metrics。
+//
+// 指标按用途分组：HTTP接口、采集周期、上游API、Kafka、连接池和业务指标。
+// 标签取值需要是有限集合（路由模板、任务名、数据源名、链名、资产符号等），不能使用ID或原始URL
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/rwa-platform/shared/tracing"
+	"github.com/segmentio/kafka-go"
+)
+
+const namespace = "rwa"
+
+// Registry 所有指标注册在这里，不使用默认注册表，避免依赖库注册的指标混入
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	cycleDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "collector_cycle_duration_seconds",
+		Help:      "Duration of collector and scheduled job cycles.",
+		Buckets:   []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	}, []string{"collector", "result"})
+
+	lastCycle = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "collector_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful cycle of each collector.",
+	}, []string{"collector"})
+
+	records = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "collector_records_total",
+		Help:      "Records processed by collectors, by result (saved, duplicate, skipped, failed).",
+	}, []string{"collector", "result"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of requests to upstream APIs by data source and status class.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"source", "status"})
+
+	upstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_errors_total",
+		Help:      "Failed requests to upstream APIs by data source and reason (network, rate_limited, client_error, server_error).",
+	}, []string{"source", "reason"})
+
+	kafkaPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_published_total",
+		Help:      "Kafka messages published by topic and result.",
+	}, []string{"topic", "result"})
+
+	kafkaConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_consumed_total",
+		Help:      "Kafka messages handled by topic, consumer group and result.",
+	}, []string{"topic", "group", "result"})
+
+	kafkaLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "kafka_consumer_lag",
+		Help:      "Messages between the committed offset of a consumer group and the end of each partition.",
+	}, []string{"topic", "partition", "group"})
+
+	indexerLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "indexer_block_lag",
+		Help:      "Blocks (or slots) between the chain head and the last indexed block.",
+	}, []string{"chain"})
+
+	matchingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "matching_duration_seconds",
+		Help:      "Latency of channel matching requests by result.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"result"})
+
+	// PriceStaleness 每个资产距离最近一次价格更新的秒数
+	PriceStaleness = NewStaleness(namespace+"_price_staleness_seconds",
+		"Seconds since the last price update of each asset.", "asset")
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		cycleDuration, lastCycle, records,
+		upstreamDuration, upstreamErrors,
+		kafkaPublished, kafkaConsumed, kafkaLag,
+		indexerLag, matchingDuration,
+		PriceStaleness,
+	)
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// ObserveCycle 记录一次采集周期或调度任务的耗时和结果
+func ObserveCycle(collector string, started time.Time, err error) {
+	cycleDuration.WithLabelValues(collector, result(err)).Observe(time.Since(started).Seconds())
+	if err == nil {
+		lastCycle.WithLabelValues(collector).SetToCurrentTime()
+	}
+}
+
+// AddRecords 累计采集处理的记录数
+func AddRecords(collector, outcome string, n int) {
+	if n > 0 {
+		records.WithLabelValues(collector, outcome).Add(float64(n))
+	}
+}
+
+// ObserveUpstream 记录一次上游请求，status为0表示没有得到响应
+func ObserveUpstream(source string, latency time.Duration, status int, err error) {
+	class := "error"
+	if err == nil && status > 0 {
+		class = strconv.Itoa(status/100) + "xx"
+	}
+	upstreamDuration.WithLabelValues(source, class).Observe(latency.Seconds())
+
+	switch {
+	case err != nil || status == 0:
+		upstreamErrors.WithLabelValues(source, "network").Inc()
+	case status == 429:
+		upstreamErrors.WithLabelValues(source, "rate_limited").Inc()
+	case status >= 500:
+		upstreamErrors.WithLabelValues(source, "server_error").Inc()
+	case status >= 400:
+		upstreamErrors.WithLabelValues(source, "client_error").Inc()
+	}
+}
+
+// ObservePublish 记录一次Kafka消息发送
+func ObservePublish(topic string, err error) {
+	kafkaPublished.WithLabelValues(topic, result(err)).Inc()
+}
+
+// ObserveConsume 记录一条Kafka消息的处理结果
+func ObserveConsume(topic, group string, err error) {
+	kafkaConsumed.WithLabelValues(topic, group, result(err)).Inc()
+}
+
+// ConsumeHandler 包装消息处理函数：从消息头恢复发送方的trace上下文，在consumer span中处理消息并记录处理结果
+func ConsumeHandler(group string, handler func(ctx context.Context, message kafka.Message) error) func(context.Context, kafka.Message) error {
+	return func(ctx context.Context, message kafka.Message) error {
+		ctx, span := tracing.StartConsume(ctx, message, group)
+		err := handler(ctx, message)
+		tracing.EndSpan(span, err)
+		ObserveConsume(message.Topic, group, err)
+		return err
+	}
+}
+
+// SetConsumerLag 由消费者在处理消息后直接更新分区的落后消息数
+func SetConsumerLag(topic string, partition int, group string, lag int64) {
+	kafkaLag.WithLabelValues(topic, strconv.Itoa(partition), group).Set(float64(lag))
+}
+
+// SetIndexerLag 更新链的索引落后区块数
+func SetIndexerLag(chain string, lag uint64) {
+	indexerLag.WithLabelValues(chain).Set(float64(lag))
+}
+
+// ObserveMatching 记录一次渠道撮合的耗时
+func ObserveMatching(started time.Time, err error) {
+	matchingDuration.WithLabelValues(result(err)).Observe(time.Since(started).Seconds())
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddlewareUsesRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/api/v1/news/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/v1/news/a", "/api/v1/news/b", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/api/v1/news/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(httpRequests.WithLabelValues("GET", unmatchedRoute, "404")))
+}
+
+func TestObserveUpstream(t *testing.T) {
+	ObserveUpstream("test-source", 20*time.Millisecond, 200, nil)
+	ObserveUpstream("test-source", time.Second, 429, nil)
+	ObserveUpstream("test-source", time.Second, 503, nil)
+	ObserveUpstream("test-source", 0, 0, errors.New("connection refused"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(upstreamErrors.WithLabelValues("test-source", "rate_limited")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(upstreamErrors.WithLabelValues("test-source", "server_error")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(upstreamErrors.WithLabelValues("test-source", "network")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(upstreamErrors.WithLabelValues("test-source", "client_error")))
+}
+
+func TestConsumeHandler(t *testing.T) {
+	var traceID trace.TraceID
+	handler := ConsumeHandler("test-group", func(ctx context.Context, message kafka.Message) error {
+		traceID = trace.SpanContextFromContext(ctx).TraceID()
+		if string(message.Value) == "bad" {
+			return errors.New("invalid message")
+		}
+		return nil
+	})
+
+	// 处理函数的上下文延续发送方的trace
+	message := kafka.Message{
+		Topic:   "test-topic",
+		Value:   []byte("ok"),
+		Headers: []kafka.Header{{Key: "traceparent", Value: []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")}},
+	}
+	require.NoError(t, handler(context.Background(), message))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID.String())
+	require.Error(t, handler(context.Background(), kafka.Message{Topic: "test-topic", Value: []byte("bad")}))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(kafkaConsumed.WithLabelValues("test-topic", "test-group", "success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(kafkaConsumed.WithLabelValues("test-topic", "test-group", "error")))
+}
+
+func TestStaleness(t *testing.T) {
+	now := time.Date(2024, 5, 14, 10, 0, 0, 0, time.UTC)
+	staleness := NewStaleness("test_staleness_seconds", "test", "asset")
+	staleness.now = func() time.Time { return now }
+
+	staleness.Touch("USDC", now.Add(-90*time.Second))
+	// 较早的更新不覆盖
+	staleness.Touch("USDC", now.Add(-time.Hour))
+	staleness.Touch("BUIDL", now.Add(-time.Hour))
+	staleness.Forget("BUIDL")
+
+	expected := `
+# HELP test_staleness_seconds test
+# TYPE test_staleness_seconds gauge
+test_staleness_seconds{asset="USDC"} 90
+`
+	require.NoError(t, testutil.CollectAndCompare(staleness, strings.NewReader(expected)))
+	assert.True(t, staleness.Tracked("USDC"))
+	assert.False(t, staleness.Tracked("BUIDL"))
+}
+
+func TestConsumerLag(t *testing.T) {
+	lag, ok := consumerLag(0, 100, 60)
+	assert.True(t, ok)
+	assert.Equal(t, int64(40), lag)
+	lag, ok = consumerLag(0, 100, 100)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), lag)
+}
+
+func TestConsumerLagWithoutCommit(t *testing.T) {
+	// 尚未提交offset时从分区起点算起，保留策略删除旧消息后起点不为0
+	lag, ok := consumerLag(70, 100, -1)
+	assert.True(t, ok)
+	assert.Equal(t, int64(30), lag)
+
+	// 没有请求到起点时不报告
+	_, ok = consumerLag(-1, 100, -1)
+	assert.False(t, ok)
+	_, ok = consumerLag(0, -1, 60)
+	assert.False(t, ok)
+}
+
+func TestRegistryGathers(t *testing.T) {
+	SetIndexerLag("ethereum", 12)
+	ObserveCycle("price-collection", time.Now().Add(-time.Second), nil)
+
+	families, err := Registry.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	assert.True(t, names["rwa_indexer_block_lag"])
+	assert.True(t, names["rwa_collector_cycle_duration_seconds"])
+	assert.True(t, names["rwa_collector_last_success_timestamp_seconds"])
+	assert.True(t, names["go_goroutines"])
+}
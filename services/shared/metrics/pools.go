@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// RegisterDB 输出数据库连接池状态（打开、使用中、空闲连接数及等待次数）
+func RegisterDB(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RedisPoolStats Redis连接池状态，与go-redis的PoolStats字段一致
+type RedisPoolStats struct {
+	Hits       uint32
+	Misses     uint32
+	Timeouts   uint32
+	TotalConns uint32
+	IdleConns  uint32
+	StaleConns uint32
+}
+
+// RegisterRedisPool 采集时调用stats读取Redis连接池状态
+func RegisterRedisPool(name string, stats func() RedisPoolStats) error {
+	return Registry.Register(&redisPoolCollector{name: name, stats: stats})
+}
+
+var (
+	redisHits       = prometheus.NewDesc(namespace+"_redis_pool_hits_total", "Times a free connection was found in the Redis pool.", []string{"pool"}, nil)
+	redisMisses     = prometheus.NewDesc(namespace+"_redis_pool_misses_total", "Times a free connection was not found in the Redis pool.", []string{"pool"}, nil)
+	redisTimeouts   = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total", "Times a wait for a Redis connection timed out.", []string{"pool"}, nil)
+	redisTotalConns = prometheus.NewDesc(namespace+"_redis_pool_connections", "Connections in the Redis pool.", []string{"pool"}, nil)
+	redisIdleConns  = prometheus.NewDesc(namespace+"_redis_pool_idle_connections", "Idle connections in the Redis pool.", []string{"pool"}, nil)
+	redisStaleConns = prometheus.NewDesc(namespace+"_redis_pool_stale_connections_total", "Stale connections removed from the Redis pool.", []string{"pool"}, nil)
+)
+
+type redisPoolCollector struct {
+	name  string
+	stats func() RedisPoolStats
+}
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisHits
+	ch <- redisMisses
+	ch <- redisTimeouts
+	ch <- redisTotalConns
+	ch <- redisIdleConns
+	ch <- redisStaleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.stats()
+	ch <- prometheus.MustNewConstMetric(redisHits, prometheus.CounterValue, float64(stats.Hits), c.name)
+	ch <- prometheus.MustNewConstMetric(redisMisses, prometheus.CounterValue, float64(stats.Misses), c.name)
+	ch <- prometheus.MustNewConstMetric(redisTimeouts, prometheus.CounterValue, float64(stats.Timeouts), c.name)
+	ch <- prometheus.MustNewConstMetric(redisTotalConns, prometheus.GaugeValue, float64(stats.TotalConns), c.name)
+	ch <- prometheus.MustNewConstMetric(redisIdleConns, prometheus.GaugeValue, float64(stats.IdleConns), c.name)
+	ch <- prometheus.MustNewConstMetric(redisStaleConns, prometheus.CounterValue, float64(stats.StaleConns), c.name)
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Staleness 按标签记录最近一次更新时间，采集时输出距今的秒数，
+// 停止更新的数据会持续增长，便于对过期数据告警
+type Staleness struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+func NewStaleness(name, help, label string) *Staleness {
+	return &Staleness{
+		desc: prometheus.NewDesc(name, help, []string{label}, nil),
+		now:  time.Now,
+		last: make(map[string]time.Time),
+	}
+}
+
+// Touch 记录更新时间，早于已记录时间的更新忽略
+func (s *Staleness) Touch(value string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if at.After(s.last[value]) {
+		s.last[value] = at
+	}
+}
+
+// Forget 不再输出该标签，用于资产停用等情况
+func (s *Staleness) Forget(value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.last, value)
+}
+
+// Tracked 是否已记录过该标签
+func (s *Staleness) Tracked(value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.last[value]
+	return ok
+}
+
+func (s *Staleness) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.desc
+}
+
+func (s *Staleness) Collect(ch chan<- prometheus.Metric) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for value, at := range s.last {
+		age := now.Sub(at).Seconds()
+		if age < 0 {
+			age = 0
+		}
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, age, value)
+	}
+}
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// MissedPolicy 服务停机或任务超时导致错过计划时间后的处理方式
+type MissedPolicy string
+
+const (
+	// MissedRunOnce 立即补执行一次，错过的多次合并为一次（默认）
+	MissedRunOnce MissedPolicy = "run_once"
+	// MissedSkip 跳过错过的执行，等待下一个计划时间
+	MissedSkip MissedPolicy = "skip"
+	// MissedCatchUp 依次补执行错过的每一次，最多补最近24小时
+	MissedCatchUp MissedPolicy = "catch_up"
+)
+
+// 执行的触发方式
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerCatchUp  = "catch_up"
+)
+
+// 执行状态，与sync_jobs中其他任务的状态一致
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+	RunStatusCancelled = "cancelled"
+)
+
+// RunType 调度执行在sync_jobs中的类型，其他服务按类型领取的任务不会与之混淆
+const RunType = "scheduled"
+
+var (
+	ErrJobNotFound     = errors.New("scheduled job not found")
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	ErrInvalidJob      = errors.New("invalid scheduled job")
+)
+
+// Job 调度任务定义，每个服务的每个任务一行。Schedule为代码注册的默认调度，
+// ScheduleOverride为管理接口设置的调度，优先于默认调度且不会被服务重启覆盖
+type Job struct {
+	ID                 string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Service            string     `gorm:"not null;uniqueIndex:idx_scheduled_job_name" json:"service"`
+	Name               string     `gorm:"not null;uniqueIndex:idx_scheduled_job_name" json:"name"`
+	Schedule           string     `gorm:"not null" json:"schedule"`
+	ScheduleOverride   *string    `json:"schedule_override"`
+	JitterSeconds      int        `gorm:"default:0" json:"jitter_seconds"`
+	TimeoutSeconds     int        `gorm:"default:0" json:"timeout_seconds"`
+	MissedPolicy       string     `gorm:"not null" json:"missed_policy"`
+	Enabled            bool       `gorm:"default:true" json:"enabled"`
+	ScheduledAt        *time.Time `json:"scheduled_at"`             // 下一次的计划时间
+	NextRunAt          *time.Time `gorm:"index" json:"next_run_at"` // 计划时间加上随机抖动
+	TriggerRequestedAt *time.Time `json:"trigger_requested_at"`
+	LockedBy           *string    `json:"locked_by"`
+	LockedUntil        *time.Time `json:"locked_until"`
+	LastRunAt          *time.Time `json:"last_run_at"`
+	LastStatus         *string    `json:"last_status"`
+	LastError          *string    `json:"last_error"`
+	LastDurationMs     *int64     `json:"last_duration_ms"`
+	RunCount           int        `gorm:"default:0" json:"run_count"`
+	FailureCount       int        `gorm:"default:0" json:"failure_count"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "scheduled_jobs"
+}
+
+// EffectiveSchedule 当前生效的调度表达式
+func (j *Job) EffectiveSchedule() string {
+	if j.ScheduleOverride != nil && *j.ScheduleOverride != "" {
+		return *j.ScheduleOverride
+	}
+	return j.Schedule
+}
+
+// Run 一次执行记录，写入sync_jobs表（与data-collector的models.SyncJob同表），Type为RunType
+type Run struct {
+	ID             string     `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Type           string     `gorm:"not null;index" json:"type"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	ScheduledJobID *string    `gorm:"type:uuid;index" json:"scheduled_job_id"`
+	Trigger        *string    `json:"trigger"`
+	ScheduledAt    *time.Time `json:"scheduled_at"`
+	DurationMs     *int64     `json:"duration_ms"`
+	ErrorMessage   *string    `json:"error_message"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Run) TableName() string {
+	return "sync_jobs"
+}
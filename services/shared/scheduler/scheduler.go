@@ -0,0 +1,651 @@
+// Package scheduler 各服务共用的持久化任务调度：任务定义和执行记录保存在Postgres中，
+// 支持cron和固定间隔调度、随机抖动、超时、错过执行的处理策略和手动触发。
+// 同一服务的多个实例通过scheduled_jobs上的租约保证同一任务同时只有一个实例执行
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/rwa-platform/shared/metrics"
+	"github.com/rwa-platform/shared/stats"
+	"github.com/rwa-platform/shared/tracing"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// pollInterval 检查到期任务和其他实例设置的手动触发的最长间隔
+	pollInterval = 5 * time.Second
+	// defaultTimeout 未设置超时的任务的执行时限，同时决定租约长度
+	defaultTimeout = 30 * time.Minute
+	// leaseMargin 租约比超时多出的时间，超时的任务返回并写入结果前不会被其他实例领取
+	leaseMargin = time.Minute
+	// missedGrace 晚于计划时间超过该值（加上抖动）视为错过
+	missedGrace = time.Minute
+	// catchUpWindow MissedCatchUp最多补执行的时间范围
+	catchUpWindow = 24 * time.Hour
+	// runRetention 执行记录的保留时间
+	runRetention = 30 * 24 * time.Hour
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Spec 任务注册参数，Schedule为cron表达式或Every生成的固定间隔
+type Spec struct {
+	Name     string
+	Schedule string
+	Jitter   time.Duration
+	Timeout  time.Duration
+	Missed   MissedPolicy
+	Run      func(ctx context.Context) error
+}
+
+// JobUpdate 管理接口对任务的修改，Schedule为空字符串时恢复默认调度
+type JobUpdate struct {
+	Enabled  *bool   `json:"enabled"`
+	Schedule *string `json:"schedule"`
+}
+
+// RunQuery 执行记录筛选条件
+type RunQuery struct {
+	JobID  string
+	Status string
+	Page   int
+	Limit  int
+}
+
+type Scheduler struct {
+	db       *gorm.DB
+	service  string
+	instance string
+	logger   *logrus.Logger
+
+	mu      sync.Mutex
+	specs   map[string]*Spec
+	running map[string]bool
+	started bool
+
+	// wake 手动触发或执行结束后立即检查到期任务
+	wake chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Migrate 创建scheduled_jobs表，并为sync_jobs补充执行记录需要的列
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Job{}, &Run{})
+}
+
+// New 创建服务的调度器，service用于区分各服务的任务
+func New(db *gorm.DB, service string) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		db:       db,
+		service:  service,
+		instance: fmt.Sprintf("%s/%s/%d", service, hostname, os.Getpid()),
+		logger:   logrus.New(),
+		specs:    make(map[string]*Spec),
+		running:  make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register 注册任务，需在Start之前调用
+func (s *Scheduler) Register(spec Spec) error {
+	if spec.Name == "" || spec.Run == nil {
+		return fmt.Errorf("%w: name and run function are required", ErrInvalidJob)
+	}
+	if _, err := parseSchedule(spec.Schedule); err != nil {
+		return err
+	}
+	switch spec.Missed {
+	case "":
+		spec.Missed = MissedRunOnce
+	case MissedRunOnce, MissedSkip, MissedCatchUp:
+	default:
+		return fmt.Errorf("%w: unknown missed run policy %s", ErrInvalidJob, spec.Missed)
+	}
+	if spec.Timeout <= 0 {
+		spec.Timeout = defaultTimeout
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return fmt.Errorf("%w: scheduler already started", ErrInvalidJob)
+	}
+	if _, ok := s.specs[spec.Name]; ok {
+		return fmt.Errorf("%w: duplicate job %s", ErrInvalidJob, spec.Name)
+	}
+	s.specs[spec.Name] = &spec
+	return nil
+}
+
+// parseSchedule 解析并确认表达式在可预见的时间内会触发（排除2月30日这类表达式）
+func parseSchedule(spec string) (Schedule, error) {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w: %s never fires", ErrInvalidSchedule, spec)
+	}
+	return schedule, nil
+}
+
+// Start 同步任务定义并开始调度，ctx取消后等待执行中的任务返回
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+
+	s.logger.Infof("Starting job scheduler for %s", s.service)
+	if err := s.syncDefinitions(ctx); err != nil {
+		s.logger.Errorf("Failed to sync scheduled jobs: %v", err)
+	}
+
+	for {
+		wait := s.dispatch(ctx)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.wg.Wait()
+			s.logger.Info("Job scheduler stopped")
+			return
+		case <-timer.C:
+		case <-s.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// syncDefinitions 写入或更新注册的任务；默认调度变化且没有被覆盖时从新调度重新计算下一次执行时间
+func (s *Scheduler) syncDefinitions(ctx context.Context) error {
+	s.mu.Lock()
+	specs := make([]*Spec, 0, len(s.specs))
+	for _, spec := range s.specs {
+		specs = append(specs, spec)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	for _, spec := range specs {
+		var job Job
+		err := s.db.WithContext(ctx).Where("service = ? AND name = ?", s.service, spec.Name).First(&job).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to query scheduled job %s: %v", spec.Name, err)
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			job = Job{
+				Service:        s.service,
+				Name:           spec.Name,
+				Schedule:       spec.Schedule,
+				JitterSeconds:  int(spec.Jitter / time.Second),
+				TimeoutSeconds: int(spec.Timeout / time.Second),
+				MissedPolicy:   string(spec.Missed),
+				Enabled:        true,
+			}
+			slot := firstSlot(spec.Schedule, now)
+			job.ScheduledAt = &slot
+			next := withJitter(slot, spec.Jitter)
+			job.NextRunAt = &next
+			if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+				return fmt.Errorf("failed to create scheduled job %s: %v", spec.Name, err)
+			}
+			continue
+		}
+
+		updates := map[string]interface{}{
+			"schedule":        spec.Schedule,
+			"jitter_seconds":  int(spec.Jitter / time.Second),
+			"timeout_seconds": int(spec.Timeout / time.Second),
+			"missed_policy":   string(spec.Missed),
+		}
+		if job.Schedule != spec.Schedule && job.ScheduleOverride == nil {
+			slot := firstSlot(spec.Schedule, now)
+			updates["scheduled_at"] = slot
+			updates["next_run_at"] = withJitter(slot, spec.Jitter)
+		}
+		if err := s.db.WithContext(ctx).Model(&Job{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update scheduled job %s: %v", spec.Name, err)
+		}
+	}
+	return nil
+}
+
+// firstSlot 新任务的首次计划时间：固定间隔的任务立即执行，cron任务等到下一个匹配时间
+func firstSlot(spec string, now time.Time) time.Time {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return now
+	}
+	if _, ok := schedule.(intervalSchedule); ok {
+		return now
+	}
+	return schedule.Next(now)
+}
+
+func withJitter(slot time.Time, jitter time.Duration) time.Time {
+	if jitter <= 0 {
+		return slot
+	}
+	return slot.Add(time.Duration(rand.Int63n(int64(jitter))))
+}
+
+// dispatch 领取并启动到期的任务，返回距下一次检查的等待时间
+func (s *Scheduler) dispatch(ctx context.Context) time.Duration {
+	s.mu.Lock()
+	names := make([]string, 0, len(s.specs))
+	for name := range s.specs {
+		names = append(names, name)
+	}
+	s.mu.Unlock()
+	if len(names) == 0 {
+		return pollInterval
+	}
+
+	var jobs []Job
+	if err := s.db.WithContext(ctx).Where("service = ? AND name IN ?", s.service, names).Find(&jobs).Error; err != nil {
+		if ctx.Err() == nil {
+			s.logger.Errorf("Failed to load scheduled jobs: %v", err)
+		}
+		return pollInterval
+	}
+
+	now := time.Now()
+	wait := pollInterval
+	for i := range jobs {
+		job := &jobs[i]
+		s.mu.Lock()
+		spec, running := s.specs[job.Name], s.running[job.Name]
+		s.mu.Unlock()
+		if running {
+			continue
+		}
+
+		manual := job.TriggerRequestedAt != nil
+		scheduled := job.Enabled && job.NextRunAt != nil && !job.NextRunAt.After(now)
+		if !manual && !scheduled {
+			if job.Enabled && job.NextRunAt != nil && job.NextRunAt.Sub(now) < wait {
+				wait = job.NextRunAt.Sub(now)
+			}
+			continue
+		}
+		if job.LockedUntil != nil && job.LockedUntil.After(now) {
+			// 其他实例正在执行
+			continue
+		}
+		s.claim(ctx, spec, job, manual, scheduled, now)
+	}
+	return wait
+}
+
+// claim 通过条件更新取得租约，同时推进下一次计划时间；其他实例已领取时更新不到任何行
+func (s *Scheduler) claim(ctx context.Context, spec *Spec, job *Job, manual, scheduled bool, now time.Time) {
+	timeout := time.Duration(job.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = spec.Timeout
+	}
+
+	trigger := TriggerManual
+	run := manual
+	var slot *time.Time
+	updates := map[string]interface{}{}
+	if scheduled {
+		schedule, err := parseSchedule(job.EffectiveSchedule())
+		if err != nil {
+			s.logger.Errorf("Scheduled job %s has an invalid schedule %q: %v", job.Name, job.EffectiveSchedule(), err)
+			return
+		}
+		due := *job.NextRunAt
+		if job.ScheduledAt != nil {
+			due = *job.ScheduledAt
+		}
+		grace := missedGrace + time.Duration(job.JitterSeconds)*time.Second
+		next, runNow, catchUp := plan(schedule, MissedPolicy(job.MissedPolicy), due, now, grace)
+		updates["scheduled_at"] = next
+		updates["next_run_at"] = withJitter(next, time.Duration(job.JitterSeconds)*time.Second)
+		if runNow {
+			run = true
+			slot = &due
+			trigger = TriggerSchedule
+			if catchUp {
+				trigger = TriggerCatchUp
+			}
+		} else {
+			s.logger.Warnf("Skipping missed run of %s scheduled at %s", job.Name, due.Format(time.RFC3339))
+		}
+	}
+	if manual {
+		updates["trigger_requested_at"] = nil
+	}
+	if run {
+		updates["locked_by"] = s.instance
+		updates["locked_until"] = now.Add(timeout + leaseMargin)
+	}
+
+	query := s.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", job.ID, now)
+	if scheduled && manual {
+		query = query.Where("(enabled AND next_run_at <= ?) OR trigger_requested_at IS NOT NULL", now)
+	} else if scheduled {
+		query = query.Where("enabled AND next_run_at <= ?", now)
+	} else {
+		query = query.Where("trigger_requested_at IS NOT NULL")
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			s.logger.Errorf("Failed to claim scheduled job %s: %v", job.Name, result.Error)
+		}
+		return
+	}
+	if result.RowsAffected == 0 || !run {
+		return
+	}
+
+	s.mu.Lock()
+	s.running[job.Name] = true
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(ctx, spec, job.ID, trigger, slot, timeout)
+	}()
+}
+
+// plan 按错过执行的策略决定是否执行，以及下一次计划时间；catchUp表示本次为补执行
+func plan(schedule Schedule, policy MissedPolicy, due, now time.Time, grace time.Duration) (next time.Time, run, catchUp bool) {
+	late := now.Sub(due) > grace
+	next = schedule.Next(due)
+
+	switch policy {
+	case MissedSkip:
+		if late {
+			return schedule.Next(now), false, false
+		}
+	case MissedCatchUp:
+		if late {
+			if earliest := now.Add(-catchUpWindow); next.Before(earliest) {
+				next = schedule.Next(earliest)
+			}
+			return next, true, true
+		}
+		return next, true, false
+	}
+
+	if !next.After(now) {
+		next = schedule.Next(now)
+	}
+	return next, true, false
+}
+
+// execute 执行任务并记录结果，记录使用独立的上下文，服务关闭时也能写入取消状态
+func (s *Scheduler) execute(ctx context.Context, spec *Spec, jobID, trigger string, slot *time.Time, timeout time.Duration) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, spec.Name)
+		s.mu.Unlock()
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}()
+
+	// 持有租约时仍为running的记录来自异常退出的实例
+	abandoned := "abandoned: lease expired before the run finished"
+	s.db.Model(&Run{}).
+		Where("scheduled_job_id = ? AND status = ?", jobID, RunStatusRunning).
+		Updates(map[string]interface{}{"status": RunStatusFailed, "error_message": abandoned, "completed_at": time.Now()})
+
+	started := time.Now()
+	run := &Run{
+		Type:           RunType,
+		Status:         RunStatusRunning,
+		ScheduledJobID: &jobID,
+		Trigger:        &trigger,
+		ScheduledAt:    slot,
+		StartedAt:      &started,
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		s.logger.Errorf("Failed to record run of %s: %v", spec.Name, err)
+	}
+
+	jobCtx, span := tracing.StartJob(ctx, spec.Name, trigger)
+	runCtx, cancel := context.WithTimeout(jobCtx, timeout)
+	err := safeRun(runCtx, spec.Run)
+	cancel()
+	tracing.EndSpan(span, err)
+
+	completed := time.Now()
+	duration := completed.Sub(started).Milliseconds()
+	status := RunStatusCompleted
+	var message *string
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		status = RunStatusCancelled
+		msg := "cancelled: service shutting down"
+		message = &msg
+	case errors.Is(err, context.DeadlineExceeded) || runCtx.Err() == context.DeadlineExceeded:
+		status = RunStatusFailed
+		msg := fmt.Sprintf("timed out after %s: %v", timeout, err)
+		message = &msg
+	default:
+		status = RunStatusFailed
+		msg := err.Error()
+		message = &msg
+	}
+	if status == RunStatusFailed {
+		s.logger.Errorf("Scheduled job %s failed after %dms: %s", spec.Name, duration, *message)
+	} else {
+		s.logger.Debugf("Scheduled job %s %s in %dms", spec.Name, status, duration)
+	}
+	// 停机取消的执行不计入失败
+	if status != RunStatusCancelled {
+		metrics.ObserveCycle(spec.Name, started, err)
+	}
+
+	if run.ID != "" {
+		s.db.Model(&Run{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"status":        status,
+			"error_message": message,
+			"completed_at":  completed,
+			"duration_ms":   duration,
+		})
+	}
+	failures := 0
+	if status == RunStatusFailed {
+		failures = 1
+	}
+	err = s.db.Model(&Job{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"last_run_at":      started,
+		"last_status":      status,
+		"last_error":       message,
+		"last_duration_ms": duration,
+		"run_count":        gorm.Expr("run_count + 1"),
+		"failure_count":    gorm.Expr("failure_count + ?", failures),
+		"locked_by":        nil,
+		"locked_until":     nil,
+	}).Error
+	if err != nil {
+		s.logger.Errorf("Failed to release scheduled job %s: %v", spec.Name, err)
+	}
+
+	s.db.Where("scheduled_job_id = ? AND type = ? AND created_at < ?", jobID, RunType, time.Now().Add(-runRetention)).Delete(&Run{})
+}
+
+// safeRun 任务panic时作为失败记录，不影响调度器
+func safeRun(ctx context.Context, run func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// Trigger 手动触发本服务的任务
+func (s *Scheduler) Trigger(ctx context.Context, name string) error {
+	var job Job
+	if err := s.db.WithContext(ctx).Where("service = ? AND name = ?", s.service, name).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrJobNotFound
+		}
+		return fmt.Errorf("failed to query scheduled job: %v", err)
+	}
+	_, err := s.TriggerJob(ctx, job.ID)
+	return err
+}
+
+// TriggerJob 手动触发任务，可以是其他服务的任务，由该服务的调度器在下一次检查时执行
+func (s *Scheduler) TriggerJob(ctx context.Context, id string) (*Job, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&Job{}).Where("id = ?", id).Update("trigger_requested_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to trigger scheduled job: %v", err)
+	}
+	job.TriggerRequestedAt = &now
+
+	if job.Service == s.service {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return job, nil
+}
+
+// GetJob 获取任务
+func (s *Scheduler) GetJob(ctx context.Context, id string) (*Job, error) {
+	if !uuidPattern.MatchString(id) {
+		return nil, ErrJobNotFound
+	}
+	var job Job
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to query scheduled job: %v", err)
+	}
+	return &job, nil
+}
+
+// ListJobs 列出任务及最近一次执行的状态、耗时和错误，service为空时列出所有服务的任务
+func (s *Scheduler) ListJobs(ctx context.Context, service string) ([]Job, error) {
+	query := s.db.WithContext(ctx).Model(&Job{})
+	if service != "" {
+		query = query.Where("service = ?", service)
+	}
+	jobs := []Job{}
+	if err := query.Order("service, name").Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to query scheduled jobs: %v", err)
+	}
+	return jobs, nil
+}
+
+// FillStats 把本服务各任务最近一次成功执行的时间写入/admin/stats报告，最近一次执行失败时附带错误
+func (s *Scheduler) FillStats(ctx context.Context, report *stats.Report) error {
+	lastSuccess := s.db.Model(&Run{}).
+		Select("MAX(completed_at)").
+		Where("sync_jobs.scheduled_job_id = scheduled_jobs.id AND sync_jobs.status = ?", RunStatusCompleted)
+
+	var rows []struct {
+		Name          string
+		LastStatus    *string
+		LastError     *string
+		LastSuccessAt *time.Time
+	}
+	err := s.db.WithContext(ctx).Model(&Job{}).
+		Select("scheduled_jobs.name, scheduled_jobs.last_status, scheduled_jobs.last_error, (?) AS last_success_at", lastSuccess).
+		Where("scheduled_jobs.service = ?", s.service).
+		Order("scheduled_jobs.name").
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("failed to query scheduled job stats: %v", err)
+	}
+
+	for _, row := range rows {
+		activity := stats.Activity{Name: row.Name, Kind: stats.ActivityKindJob, LastSuccessAt: row.LastSuccessAt}
+		if row.LastStatus != nil && *row.LastStatus == RunStatusFailed && row.LastError != nil {
+			activity.LastError = *row.LastError
+		}
+		report.Activity = append(report.Activity, activity)
+	}
+	return nil
+}
+
+// ListRuns 按开始时间倒序列出任务的执行记录
+func (s *Scheduler) ListRuns(ctx context.Context, q RunQuery) ([]Run, int, error) {
+	if _, err := s.GetJob(ctx, q.JobID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&Run{}).Where("scheduled_job_id = ? AND type = ?", q.JobID, RunType)
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count job runs: %v", err)
+	}
+	runs := []Run{}
+	err := query.Order("started_at DESC").Offset((q.Page - 1) * q.Limit).Limit(q.Limit).Find(&runs).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query job runs: %v", err)
+	}
+	return runs, int(total), nil
+}
+
+// UpdateJob 暂停、恢复任务或覆盖调度；调度变化时从当前时间重新计算下一次执行
+func (s *Scheduler) UpdateJob(ctx context.Context, id string, update JobUpdate) (*Job, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if update.Enabled != nil {
+		updates["enabled"] = *update.Enabled
+		job.Enabled = *update.Enabled
+	}
+	if update.Schedule != nil {
+		if *update.Schedule == "" {
+			updates["schedule_override"] = nil
+			job.ScheduleOverride = nil
+		} else {
+			if _, err := parseSchedule(*update.Schedule); err != nil {
+				return nil, err
+			}
+			updates["schedule_override"] = *update.Schedule
+			job.ScheduleOverride = update.Schedule
+		}
+		schedule, _ := parseSchedule(job.EffectiveSchedule())
+		if schedule != nil {
+			slot := schedule.Next(time.Now())
+			next := withJitter(slot, time.Duration(job.JitterSeconds)*time.Second)
+			updates["scheduled_at"] = slot
+			updates["next_run_at"] = next
+			job.ScheduledAt = &slot
+			job.NextRunAt = &next
+		}
+	}
+	if len(updates) == 0 {
+		return job, nil
+	}
+	if err := s.db.WithContext(ctx).Model(&Job{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update scheduled job: %v", err)
+	}
+	return job, nil
+}
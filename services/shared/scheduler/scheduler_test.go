@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"*/15 * * * *", "2024-05-14T10:07:30Z", "2024-05-14T10:15:00Z"},
+		{"0 */6 * * *", "2024-05-14T10:07:00Z", "2024-05-14T12:00:00Z"},
+		{"30 9 * * MON-FRI", "2024-05-17T10:00:00Z", "2024-05-20T09:30:00Z"},
+		{"0 0 1 JAN,JUL *", "2024-05-14T00:00:00Z", "2024-07-01T00:00:00Z"},
+		{"5/20 * * * *", "2024-05-14T10:26:00Z", "2024-05-14T10:45:00Z"},
+		// 周日可以写作7
+		{"0 12 * * 7", "2024-05-14T00:00:00Z", "2024-05-19T12:00:00Z"},
+		// 日和周都有限制时满足其一即可
+		{"0 0 13 * FRI", "2024-05-14T00:00:00Z", "2024-05-17T00:00:00Z"},
+		{"@daily", "2024-05-14T10:07:00Z", "2024-05-15T00:00:00Z"},
+		{"@hourly", "2024-05-14T10:00:00Z", "2024-05-14T11:00:00Z"},
+		{"0 0 29 2 *", "2024-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		schedule, err := Parse(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, at(tt.want), schedule.Next(at(tt.after)), tt.spec)
+	}
+}
+
+func TestParseEvery(t *testing.T) {
+	schedule, err := Parse(Every(90 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, at("2024-05-14T10:01:30Z"), schedule.Next(at("2024-05-14T10:00:00Z")))
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "@every 10ms", "@every soon", "@sometimes"} {
+		_, err := Parse(spec)
+		assert.True(t, errors.Is(err, ErrInvalidSchedule), spec)
+	}
+
+	// 永远不会触发的表达式在注册时拒绝
+	s := New(nil, "test")
+	err := s.Register(Spec{Name: "never", Schedule: "0 0 30 2 *", Run: func(context.Context) error { return nil }})
+	assert.True(t, errors.Is(err, ErrInvalidSchedule))
+}
+
+func TestRegister(t *testing.T) {
+	s := New(nil, "test")
+	run := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Register(Spec{Name: "prices", Schedule: Every(time.Minute), Run: run}))
+	assert.Equal(t, MissedRunOnce, s.specs["prices"].Missed)
+	assert.Equal(t, defaultTimeout, s.specs["prices"].Timeout)
+
+	assert.True(t, errors.Is(s.Register(Spec{Name: "prices", Schedule: Every(time.Minute), Run: run}), ErrInvalidJob))
+	assert.True(t, errors.Is(s.Register(Spec{Name: "other", Schedule: Every(time.Minute)}), ErrInvalidJob))
+	assert.True(t, errors.Is(s.Register(Spec{Name: "other", Schedule: Every(time.Minute), Missed: "later", Run: run}), ErrInvalidJob))
+}
+
+func TestPlan(t *testing.T) {
+	hourly, _ := Parse("@hourly")
+	due := at("2024-05-14T10:00:00Z")
+
+	// 按时执行
+	next, run, catchUp := plan(hourly, MissedRunOnce, due, due.Add(10*time.Second), missedGrace)
+	assert.True(t, run)
+	assert.False(t, catchUp)
+	assert.Equal(t, at("2024-05-14T11:00:00Z"), next)
+
+	// 停机5小时：补执行一次，之后回到正常计划
+	now := at("2024-05-14T15:20:00Z")
+	next, run, _ = plan(hourly, MissedRunOnce, due, now, missedGrace)
+	assert.True(t, run)
+	assert.Equal(t, at("2024-05-14T16:00:00Z"), next)
+
+	// 跳过错过的执行
+	next, run, _ = plan(hourly, MissedSkip, due, now, missedGrace)
+	assert.False(t, run)
+	assert.Equal(t, at("2024-05-14T16:00:00Z"), next)
+
+	// 逐个补执行错过的计划时间
+	next, run, catchUp = plan(hourly, MissedCatchUp, due, now, missedGrace)
+	assert.True(t, run)
+	assert.True(t, catchUp)
+	assert.Equal(t, at("2024-05-14T11:00:00Z"), next)
+
+	// 补执行最多覆盖最近24小时
+	next, _, _ = plan(hourly, MissedCatchUp, at("2024-05-01T10:00:00Z"), now, missedGrace)
+	assert.Equal(t, at("2024-05-13T16:00:00Z"), next)
+}
+
+func TestSafeRun(t *testing.T) {
+	err := safeRun(context.Background(), func(context.Context) error { panic("boom") })
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestEffectiveSchedule(t *testing.T) {
+	job := Job{Schedule: "@hourly"}
+	assert.Equal(t, "@hourly", job.EffectiveSchedule())
+	override := "*/5 * * * *"
+	job.ScheduleOverride = &override
+	assert.Equal(t, override, job.EffectiveSchedule())
+}
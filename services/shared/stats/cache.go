@@ -0,0 +1,81 @@
+package stats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// CacheCounter 进程内的缓存命中计数，服务重启后清零
+type CacheCounter struct {
+	name   string
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*CacheCounter)
+)
+
+// NewCacheCounter 返回name对应的计数器，同名计数器只创建一次
+func NewCacheCounter(name string) *CacheCounter {
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+	if counter, ok := caches[name]; ok {
+		return counter
+	}
+	counter := &CacheCounter{name: name}
+	caches[name] = counter
+	return counter
+}
+
+// Hit 记录一次缓存命中
+func (c *CacheCounter) Hit() {
+	c.hits.Add(1)
+}
+
+// Miss 记录一次缓存未命中
+func (c *CacheCounter) Miss() {
+	c.misses.Add(1)
+}
+
+// Observe 按hit记录命中或未命中
+func (c *CacheCounter) Observe(hit bool) {
+	if hit {
+		c.Hit()
+	} else {
+		c.Miss()
+	}
+}
+
+// Stats 当前的命中情况
+func (c *CacheCounter) Stats() Cache {
+	return newCache(c.name, c.hits.Load(), c.misses.Load())
+}
+
+func newCache(name string, hits, misses int64) Cache {
+	cache := Cache{Name: name, Hits: hits, Misses: misses}
+	if total := hits + misses; total > 0 {
+		rate := float64(hits) / float64(total)
+		cache.HitRate = &rate
+	}
+	return cache
+}
+
+// CacheSection 把本进程所有缓存计数器追加到报告，按名称排序
+func CacheSection(ctx context.Context, report *Report) error {
+	cachesMu.Lock()
+	counters := make([]*CacheCounter, 0, len(caches))
+	for _, counter := range caches {
+		counters = append(counters, counter)
+	}
+	cachesMu.Unlock()
+
+	sort.Slice(counters, func(i, j int) bool { return counters[i].name < counters[j].name })
+	for _, counter := range counters {
+		report.Caches = append(report.Caches, counter.Stats())
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CountTables 统计各表的记录数并读取连接池状态
+func CountTables(ctx context.Context, db *gorm.DB, tables ...string) (Database, error) {
+	result := Database{Tables: make(map[string]int64, len(tables))}
+	for _, table := range tables {
+		var count int64
+		if err := db.WithContext(ctx).Table(table).Count(&count).Error; err != nil {
+			return result, fmt.Errorf("failed to count %s: %v", table, err)
+		}
+		result.Tables[table] = count
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return result, fmt.Errorf("failed to get underlying sql.DB: %v", err)
+	}
+	result.Pool = PoolFrom(sqlDB.Stats())
+	return result, nil
+}
+
+// TableSection 把CountTables的结果写入报告
+func TableSection(db *gorm.DB, tables ...string) Section {
+	return func(ctx context.Context, report *Report) error {
+		database, err := CountTables(ctx, db, tables...)
+		if err != nil {
+			return err
+		}
+		report.Database = &database
+		return nil
+	}
+}
+
+// CountIngestion 按sourceColumn分组统计table最近7天写入的记录数，timeColumn为写入时间列
+func CountIngestion(ctx context.Context, db *gorm.DB, table, sourceColumn, timeColumn string, now time.Time) ([]Ingestion, error) {
+	var rows []struct {
+		Source   string
+		LastHour int64
+		LastDay  int64
+		LastWeek int64
+		LatestAt *time.Time
+	}
+	err := db.WithContext(ctx).Table(table).
+		Select(fmt.Sprintf(`%[1]s AS source,
+			COUNT(*) FILTER (WHERE %[2]s >= ?) AS last_hour,
+			COUNT(*) FILTER (WHERE %[2]s >= ?) AS last_day,
+			COUNT(*) AS last_week,
+			MAX(%[2]s) AS latest_at`, sourceColumn, timeColumn),
+			now.Add(-time.Hour), now.Add(-24*time.Hour)).
+		Where(fmt.Sprintf("%s >= ?", timeColumn), now.Add(-7*24*time.Hour)).
+		Group(sourceColumn).
+		Order(sourceColumn).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count ingestion for %s: %v", table, err)
+	}
+
+	result := make([]Ingestion, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, Ingestion{
+			Dataset:  table,
+			Source:   row.Source,
+			LastHour: row.LastHour,
+			LastDay:  row.LastDay,
+			LastWeek: row.LastWeek,
+			LatestAt: row.LatestAt,
+		})
+	}
+	return result, nil
+}
+
+// IngestionSection 把CountIngestion的结果追加到报告
+func IngestionSection(db *gorm.DB, table, sourceColumn, timeColumn string) Section {
+	return func(ctx context.Context, report *Report) error {
+		ingestion, err := CountIngestion(ctx, db, table, sourceColumn, timeColumn, report.GeneratedAt)
+		if err != nil {
+			return err
+		}
+		report.Ingestion = append(report.Ingestion, ingestion...)
+		return nil
+	}
+}
+
+// PendingSection 把model中满足条件的记录数作为积压追加到报告
+func PendingSection(name string, db *gorm.DB, model interface{}, query string, args ...interface{}) Section {
+	return func(ctx context.Context, report *Report) error {
+		var count int64
+		if err := db.WithContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count %s: %v", name, err)
+		}
+		report.Queues = append(report.Queues, Queue{Name: name, Kind: QueueKindTable, Depth: count})
+		return nil
+	}
+}
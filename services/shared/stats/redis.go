@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// QueueSection 把Redis列表key的长度作为队列深度追加到报告
+func QueueSection(client *redis.Client, key string) Section {
+	return func(ctx context.Context, report *Report) error {
+		depth, err := client.LLen(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read length of %s: %v", key, err)
+		}
+		report.Queues = append(report.Queues, Queue{Name: key, Kind: QueueKindRedis, Depth: depth})
+		return nil
+	}
+}
+
+// RedisSection 把Redis服务端的keyspace命中情况作为名为redis的缓存追加到报告。
+// 该数据是Redis实例级别的，所有共用该实例的服务看到的值相同
+func RedisSection(client *redis.Client) Section {
+	return func(ctx context.Context, report *Report) error {
+		info, err := client.Info(ctx, "stats").Result()
+		if err != nil {
+			return fmt.Errorf("failed to read redis stats: %v", err)
+		}
+		hits, misses := parseKeyspace(info)
+		report.Caches = append(report.Caches, newCache("redis", hits, misses))
+		return nil
+	}
+}
+
+// parseKeyspace 从INFO stats的输出中读取keyspace_hits和keyspace_misses
+func parseKeyspace(info string) (hits, misses int64) {
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "keyspace_hits":
+			hits, _ = strconv.ParseInt(value, 10, 64)
+		case "keyspace_misses":
+			misses, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return hits, misses
+}
@@ -0,0 +1,135 @@
+// Package stats 各服务/admin/stats接口的统一响应结构。
+//
+// 每个服务由若干Section填充同一个Report：按来源统计的写入量、后台任务最近成功时间、
+// 队列和积压、缓存命中率以及数据库表记录数和连接池状态。某个Section失败时错误记入Errors，
+// 其余部分照常返回，运维看板可以用同一套解析逻辑读取所有服务
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 队列类型
+const (
+	QueueKindRedis  = "redis_list" // Redis列表，深度为LLEN
+	QueueKindTable  = "table"      // 数据库中待处理的记录
+	QueueKindBlocks = "blocks"     // 链索引器落后链头的区块数
+)
+
+// 活动类型
+const (
+	ActivityKindJob       = "job"       // 调度任务
+	ActivityKindCollector = "collector" // 常驻采集器（链索引器、订阅源等）
+)
+
+// Report /admin/stats的响应数据
+type Report struct {
+	Service     string      `json:"service"`
+	GeneratedAt time.Time   `json:"generated_at"`
+	Ingestion   []Ingestion `json:"ingestion"`
+	Activity    []Activity  `json:"activity"`
+	Queues      []Queue     `json:"queues"`
+	Caches      []Cache     `json:"caches"`
+	Database    *Database   `json:"database"`
+	Errors      []string    `json:"errors,omitempty"`
+}
+
+// Ingestion 一个数据集中某个来源最近1小时、24小时、7天写入的记录数
+type Ingestion struct {
+	Dataset  string     `json:"dataset"`
+	Source   string     `json:"source"`
+	LastHour int64      `json:"last_1h"`
+	LastDay  int64      `json:"last_24h"`
+	LastWeek int64      `json:"last_7d"`
+	LatestAt *time.Time `json:"latest_at"`
+}
+
+// Activity 后台任务或采集器最近一次成功的时间，从未成功时LastSuccessAt为空
+type Activity struct {
+	Name          string     `json:"name"`
+	Kind          string     `json:"kind"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastError     string     `json:"last_error,omitempty"`
+}
+
+// Queue 队列深度或积压的记录数
+type Queue struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Depth int64  `json:"depth"`
+}
+
+// Cache 缓存命中情况，没有查询时HitRate为空
+type Cache struct {
+	Name    string   `json:"name"`
+	Hits    int64    `json:"hits"`
+	Misses  int64    `json:"misses"`
+	HitRate *float64 `json:"hit_rate"`
+}
+
+// Database 主要表的记录数和连接池状态
+type Database struct {
+	Tables map[string]int64 `json:"tables"`
+	Pool   Pool             `json:"pool"`
+}
+
+// Pool 数据库连接池状态
+type Pool struct {
+	OpenConnections   int   `json:"open_connections"`
+	InUse             int   `json:"in_use"`
+	Idle              int   `json:"idle"`
+	WaitCount         int64 `json:"wait_count"`
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+// PoolFrom 转换database/sql的连接池统计
+func PoolFrom(s sql.DBStats) Pool {
+	return Pool{
+		OpenConnections:   s.OpenConnections,
+		InUse:             s.InUse,
+		Idle:              s.Idle,
+		WaitCount:         s.WaitCount,
+		WaitDurationMs:    s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     s.MaxIdleClosed,
+		MaxIdleTimeClosed: s.MaxIdleTimeClosed,
+		MaxLifetimeClosed: s.MaxLifetimeClosed,
+	}
+}
+
+// Section 填充报告的一部分
+type Section func(ctx context.Context, report *Report) error
+
+// Collect 依次执行各Section，失败的Section不影响其他部分
+func Collect(ctx context.Context, service string, sections ...Section) *Report {
+	report := &Report{
+		Service:     service,
+		GeneratedAt: time.Now().UTC(),
+		Ingestion:   []Ingestion{},
+		Activity:    []Activity{},
+		Queues:      []Queue{},
+		Caches:      []Cache{},
+	}
+	for _, section := range sections {
+		if err := section(ctx, report); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+	return report
+}
+
+// Handler /admin/stats的处理函数
+func Handler(service string, sections ...Section) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"data": Collect(c.Request.Context(), service, sections...),
+		})
+	}
+}
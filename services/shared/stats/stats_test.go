@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectKeepsOtherSections(t *testing.T) {
+	report := Collect(context.Background(), "test-service",
+		func(ctx context.Context, r *Report) error {
+			r.Queues = append(r.Queues, Queue{Name: "matching:queue", Kind: QueueKindRedis, Depth: 3})
+			return nil
+		},
+		func(ctx context.Context, r *Report) error {
+			return errors.New("failed to count assets: connection refused")
+		},
+	)
+
+	assert.Equal(t, "test-service", report.Service)
+	assert.Equal(t, []Queue{{Name: "matching:queue", Kind: QueueKindRedis, Depth: 3}}, report.Queues)
+	assert.Equal(t, []string{"failed to count assets: connection refused"}, report.Errors)
+}
+
+func TestHandlerSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/admin/stats", Handler("test-service"))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var body struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	// 没有数据的部分返回空数组而不是null，看板不需要区分
+	for _, key := range []string{"ingestion", "activity", "queues", "caches"} {
+		assert.Equal(t, "[]", string(body.Data[key]), key)
+	}
+	assert.Equal(t, "null", string(body.Data["database"]))
+	assert.NotContains(t, body.Data, "errors")
+}
+
+func TestCacheCounter(t *testing.T) {
+	counter := NewCacheCounter("test:cache")
+	assert.Same(t, counter, NewCacheCounter("test:cache"))
+	assert.Nil(t, counter.Stats().HitRate)
+
+	counter.Observe(true)
+	counter.Observe(true)
+	counter.Observe(true)
+	counter.Miss()
+
+	cache := counter.Stats()
+	assert.Equal(t, int64(3), cache.Hits)
+	assert.Equal(t, int64(1), cache.Misses)
+	require.NotNil(t, cache.HitRate)
+	assert.InDelta(t, 0.75, *cache.HitRate, 1e-9)
+
+	report := Collect(context.Background(), "test-service", CacheSection)
+	assert.Contains(t, report.Caches, cache)
+}
+
+func TestParseKeyspace(t *testing.T) {
+	info := "# Stats\r\ntotal_connections_received:12\r\nkeyspace_hits:900\r\nkeyspace_misses:100\r\nevicted_keys:0\r\n"
+	hits, misses := parseKeyspace(info)
+	assert.Equal(t, int64(900), hits)
+	assert.Equal(t, int64(100), misses)
+}
+
+func TestPoolFrom(t *testing.T) {
+	pool := PoolFrom(sql.DBStats{OpenConnections: 5, InUse: 2, Idle: 3, WaitCount: 7, WaitDuration: 1500 * time.Millisecond})
+	assert.Equal(t, Pool{OpenConnections: 5, InUse: 2, Idle: 3, WaitCount: 7, WaitDurationMs: 1500}, pool)
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey 语句实例上保存span的键
+const gormSpanKey = "tracing:span"
+
+// GormPlugin 为数据库操作创建client span，通过db.Use(tracing.GormPlugin{})注册。
+//
+// 只在语句的Context中已有span时记录（需要db.WithContext(ctx)），
+// 后台任务中大量未传入上下文的查询不会各自成为孤立的trace
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", startGormSpan("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", endGormSpan),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", startGormSpan("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", endGormSpan),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", startGormSpan("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", endGormSpan),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", startGormSpan("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", endGormSpan),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", startGormSpan("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", endGormSpan),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", startGormSpan("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", endGormSpan),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startGormSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+		ctx, span := tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endGormSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+
+	attributes := []attribute.KeyValue{
+		semconv.DBStatement(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	}
+	if db.Statement.Table != "" {
+		attributes = append(attributes, semconv.DBSQLTable(db.Statement.Table))
+	}
+	span.SetAttributes(attributes...)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
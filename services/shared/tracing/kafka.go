@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier 以Kafka消息头读写trace上下文
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range *c.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, header := range *c.headers {
+		if header.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, header := range *c.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// StartPublish 为发送到topic的消息创建producer span，并把span的trace上下文写入消息头。
+// topic单独传入，因为由Writer指定topic时消息本身不能设置Topic。调用方在发送完成后调用EndSpan
+func StartPublish(ctx context.Context, topic string, message *kafka.Message) (context.Context, trace.Span) {
+	ctx, span := tracer().Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingKafkaMessageKey(string(message.Key)),
+		),
+	)
+	InjectKafka(ctx, message)
+	return ctx, span
+}
+
+// InjectKafka 把ctx中的trace上下文写入消息头
+func InjectKafka(ctx context.Context, message *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &message.Headers})
+}
+
+// StartConsume 从消息头恢复发送方的trace上下文，创建consumer span作为处理消息的父span。
+// 调用方在处理完成后调用EndSpan
+func StartConsume(ctx context.Context, message kafka.Message, group string) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &message.Headers})
+	return tracer().Start(ctx, message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationReceive,
+			semconv.MessagingDestinationName(message.Topic),
+			semconv.MessagingKafkaConsumerGroup(group),
+			semconv.MessagingKafkaDestinationPartition(message.Partition),
+			semconv.MessagingKafkaMessageOffset(int(message.Offset)),
+			semconv.MessagingKafkaMessageKey(string(message.Key)),
+		),
+	)
+}
@@ -0,0 +1,134 @@
+// Package tracing 各服务共用的OpenTelemetry链路追踪。
+//
+// Setup初始化全局TracerProvider和W3C TraceContext传播器；HTTP接口、出站HTTP请求、数据库、Redis和Kafka消息
+// 分别通过Middleware、Transport、GormPlugin、RedisHook和StartPublish/StartConsume接入，后台任务通过StartJob创建根span。
+// 未调用Setup时全局TracerProvider为空实现，上述接入点只传递上下文，不产生开销
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 本包创建的span所属的instrumentation scope
+const instrumentationName = "github.com/rwa-platform/shared/tracing"
+
+func init() {
+	// 即使未启用追踪也传递上游的trace上下文，下游服务仍能串联
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup 按endpoint创建导出器并注册为全局TracerProvider，返回的函数在退出时刷新并关闭导出器。
+//
+// endpoint为http(s)地址时通过OTLP/HTTP发送（如http://otel-collector:4318）；
+// 为file://路径时以JSON逐行写入本地文件，便于离线调试
+func Setup(ctx context.Context, serviceName, endpoint string) (func(context.Context) error, error) {
+	exporter, err := newExporter(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %v", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: %v", endpoint, err)
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	case "file":
+		path := u.Path
+		if u.Host != "" {
+			// file://traces.jsonl 视为相对路径
+			path = filepath.Join(u.Host, u.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create tracing directory: %v", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open tracing file: %v", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return &fileExporter{SpanExporter: exporter, file: file}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tracing endpoint %q: expected http(s):// or file://", endpoint)
+	}
+}
+
+// fileExporter 关闭导出器时同时关闭文件
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	err := e.SpanExporter.Shutdown(ctx)
+	if closeErr := e.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// StartJob 为一次后台任务执行创建span，任务中传入该ctx的数据库、Redis、HTTP和Kafka操作都归入同一个trace
+func StartJob(ctx context.Context, name, trigger string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, "job "+name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("job.name", name),
+			attribute.String("job.trigger", trigger),
+		),
+	)
+}
+
+// EndSpan 记录错误（如果有）并结束span
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestKafkaPropagation(t *testing.T) {
+	recorder := useRecorder(t)
+
+	ctx, parent := tracer().Start(context.Background(), "match channels")
+	message := kafka.Message{Key: []byte("asset-1"), Value: []byte(`{}`)}
+	_, publish := StartPublish(ctx, "channel-events", &message)
+	EndSpan(publish, nil)
+	parent.End()
+
+	require.Len(t, message.Headers, 1)
+	assert.Equal(t, "traceparent", message.Headers[0].Key)
+
+	// 消费方拿到的消息带有topic
+	message.Topic = "channel-events"
+	_, consume := StartConsume(context.Background(), message, "risk-engine-group")
+	EndSpan(consume, errors.New("invalid message"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	publishSpan, consumeSpan := spans[0], spans[2]
+	assert.Equal(t, "channel-events publish", publishSpan.Name())
+	assert.Equal(t, "channel-events process", consumeSpan.Name())
+	assert.Equal(t, parent.SpanContext().TraceID(), consumeSpan.SpanContext().TraceID())
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), consumeSpan.Parent().SpanID())
+	assert.Equal(t, codes.Error, consumeSpan.Status().Code)
+}
+
+func TestInjectKafkaReplacesHeader(t *testing.T) {
+	useRecorder(t)
+
+	message := kafka.Message{Headers: []kafka.Header{{Key: "traceparent", Value: []byte("stale")}, {Key: "source", Value: []byte("test")}}}
+	ctx, span := tracer().Start(context.Background(), "publish")
+	InjectKafka(ctx, &message)
+	span.End()
+
+	require.Len(t, message.Headers, 2)
+	assert.Contains(t, string(message.Headers[0].Value), span.SpanContext().TraceID().String())
+	assert.Equal(t, "source", message.Headers[1].Key)
+}
+
+func TestStartConsumeWithoutHeaders(t *testing.T) {
+	recorder := useRecorder(t)
+
+	_, span := StartConsume(context.Background(), kafka.Message{Topic: "price-updates"}, "portfolio-service-group")
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.False(t, spans[0].Parent().IsValid())
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces", "spans.jsonl")
+	shutdown, err := Setup(context.Background(), "test-service", "file://"+path)
+	require.NoError(t, err)
+
+	_, span := tracer().Start(context.Background(), "collect prices")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(data), `"Name":"collect prices"`))
+	assert.True(t, strings.Contains(string(data), "test-service"))
+}
+
+func TestSetupRejectsUnknownScheme(t *testing.T) {
+	_, err := Setup(context.Background(), "test-service", "localhost:4318")
+	assert.Error(t, err)
+}